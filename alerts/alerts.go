@@ -90,6 +90,10 @@ func IDForAccount(alertID [32]byte, id rhpv3.Account) types.Hash256 {
 	return types.HashBytes(append(alertID[:], id[:]...))
 }
 
+func IDForBucket(alertID [32]byte, bucket string) types.Hash256 {
+	return types.HashBytes(append(alertID[:], []byte(bucket)...))
+}
+
 func IDForContract(alertID [32]byte, fcid types.FileContractID) types.Hash256 {
 	return types.HashBytes(append(alertID[:], fcid[:]...))
 }
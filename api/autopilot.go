@@ -52,6 +52,38 @@ type (
 		Upload      uint64         `json:"upload"`
 		Storage     uint64         `json:"storage"`
 		Prune       bool           `json:"prune"`
+
+		// MinFailedCycles and MinFailedHours dampen churn by keeping
+		// contracts in the set until their hosts failed the checks for the
+		// given number of consecutive maintenance cycles or hours, whichever
+		// is reached first. Only failed host checks are dampened and the
+		// failures are tracked in memory, so they are reset when the
+		// autopilot restarts. MaxRemovals caps the number of contracts
+		// removed from a set per cycle. Zero values disable the respective
+		// policy.
+		MinFailedCycles uint64 `json:"minFailedCycles,omitempty"`
+		MinFailedHours  uint64 `json:"minFailedHours,omitempty"`
+		MaxRemovals     uint64 `json:"maxRemovals,omitempty"`
+
+		// Sets are additional contract sets that are maintained next to the
+		// default set. Every set only contains contracts with hosts that
+		// pass the set's host filter.
+		Sets []ContractSetConfig `json:"sets,omitempty"`
+	}
+
+	// ContractSetConfig contains the settings of an additional contract set
+	// maintained by the autopilot.
+	ContractSetConfig struct {
+		Name   string `json:"name"`
+		Amount uint64 `json:"amount"`
+
+		// Hosts restricts the set to contracts with the given hosts, if
+		// empty all hosts are considered.
+		Hosts []types.PublicKey `json:"hosts,omitempty"`
+
+		// MinScore is the minimum score a host needs to have for its
+		// contract to be part of the set.
+		MinScore float64 `json:"minScore,omitempty"`
 	}
 
 	// HostsConfig contains all hosts settings used in the autopilot.
@@ -61,6 +93,20 @@ type (
 		MinProtocolVersion         string                      `json:"minProtocolVersion"`
 		MaxConsecutiveScanFailures uint64                      `json:"maxConsecutiveScanFailures"`
 		ScoreOverrides             map[types.PublicKey]float64 `json:"scoreOverrides"`
+
+		// MaxCountryShare, MaxASNShare and MaxProviderShare cap the share of
+		// contracts in a set that may be formed with hosts in the same
+		// country, autonomous system or hosting provider. A share of 0
+		// disables the limit. The limits are only enforced when the
+		// autopilot is configured with a GeoIP database.
+		MaxCountryShare  float64 `json:"maxCountryShare,omitempty"`
+		MaxASNShare      float64 `json:"maxASNShare,omitempty"`
+		MaxProviderShare float64 `json:"maxProviderShare,omitempty"`
+
+		// ScoreWeights adjusts the contribution of the individual score
+		// components to a host's score, the keys are the component names
+		// of the score breakdown.
+		ScoreWeights map[string]HostScoreWeight `json:"scoreWeights,omitempty"`
 	}
 )
 
@@ -127,6 +173,83 @@ type (
 		} `json:"unusable"`
 		Recommendation *ConfigRecommendation `json:"recommendation,omitempty"`
 	}
+
+	// MaintenancePlanRequest is the request type for the /maintenance/plan
+	// endpoint. If a config is given, the plan is computed as if the config
+	// was applied.
+	MaintenancePlanRequest struct {
+		AutopilotConfig *AutopilotConfig `json:"autopilotConfig,omitempty"`
+	}
+
+	// MaintenancePlan describes the changes contract maintenance would make
+	// if it was performed right now.
+	MaintenancePlan struct {
+		RemainingAllowance types.Currency    `json:"remainingAllowance"`
+		ProjectedSpending  types.Currency    `json:"projectedSpending"`
+		UsableHosts        uint64            `json:"usableHosts"`
+		Sets               []ContractSetPlan `json:"sets"`
+		Archivals          []PlannedArchival `json:"archivals,omitempty"`
+	}
+
+	// ContractSetPlan describes how maintenance would change a contract set.
+	ContractSetPlan struct {
+		Name       string             `json:"name"`
+		Size       int                `json:"size"`
+		Renewals   []PlannedRenewal   `json:"renewals,omitempty"`
+		Refreshes  []PlannedRenewal   `json:"refreshes,omitempty"`
+		Formations []PlannedFormation `json:"formations,omitempty"`
+		Removals   []PlannedRemoval   `json:"removals,omitempty"`
+	}
+
+	// PlannedArchival is a contract that would be archived.
+	PlannedArchival struct {
+		ContractID types.FileContractID `json:"contractID"`
+		HostKey    types.PublicKey      `json:"hostKey"`
+		Reason     string               `json:"reason"`
+	}
+
+	// PlannedFormation is a contract that would be formed.
+	PlannedFormation struct {
+		HostKey        types.PublicKey `json:"hostKey"`
+		NetAddress     string          `json:"netAddress"`
+		EndHeight      uint64          `json:"endHeight"`
+		RenterFunds    types.Currency  `json:"renterFunds"`
+		HostCollateral types.Currency  `json:"hostCollateral"`
+		EstimatedCost  types.Currency  `json:"estimatedCost"`
+	}
+
+	// PlannedRemoval is a contract that would be removed from a set.
+	PlannedRemoval struct {
+		ContractID types.FileContractID `json:"contractID"`
+		HostKey    types.PublicKey      `json:"hostKey"`
+		Size       uint64               `json:"size"`
+		Reason     string               `json:"reason"`
+	}
+
+	// PlannedRenewal is a contract that would be renewed or refreshed.
+	PlannedRenewal struct {
+		ContractID    types.FileContractID `json:"contractID"`
+		HostKey       types.PublicKey      `json:"hostKey"`
+		EndHeight     uint64               `json:"endHeight"`
+		RenterFunds   types.Currency       `json:"renterFunds"`
+		EstimatedCost types.Currency       `json:"estimatedCost"`
+		Reason        string               `json:"reason"`
+	}
+
+	// SpendingForecast projects the autopilot's spending at the end of the
+	// current period based on recent usage trends.
+	SpendingForecast struct {
+		Allowance         types.Currency `json:"allowance"`
+		Spent             types.Currency `json:"spent"`
+		ContractFunds     types.Currency `json:"contractFunds"`
+		RefreshOverhead   types.Currency `json:"refreshOverhead"`
+		SpendingRate      types.Currency `json:"spendingRate"` // per day
+		ProjectedSpending types.Currency `json:"projectedSpending"`
+		PeriodEndHeight   uint64         `json:"periodEndHeight"`
+		PeriodEnd         TimeRFC3339    `json:"periodEnd"`
+		ExhaustedAt       *TimeRFC3339   `json:"exhaustedAt,omitempty"`
+		BreachesAllowance bool           `json:"breachesAllowance"`
+	}
 )
 
 func (c AutopilotConfig) Validate() error {
@@ -135,9 +258,66 @@ func (c AutopilotConfig) Validate() error {
 	} else if c.Hosts.MinProtocolVersion != "" && !utils.IsVersion(c.Hosts.MinProtocolVersion) {
 		return fmt.Errorf("invalid min protocol version '%s'", c.Hosts.MinProtocolVersion)
 	}
+	for name, share := range map[string]float64{
+		"country":  c.Hosts.MaxCountryShare,
+		"ASN":      c.Hosts.MaxASNShare,
+		"provider": c.Hosts.MaxProviderShare,
+	} {
+		if share < 0 || share > 1 {
+			return fmt.Errorf("max %s share must be between 0 and 1, got %v", name, share)
+		}
+	}
+	for component, w := range c.Hosts.ScoreWeights {
+		switch component {
+		case HostScoreAge, HostScoreCollateral, HostScoreInteractions, HostScorePrices, HostScoreStorageRemaining, HostScoreUptime, HostScoreVersion:
+		default:
+			return fmt.Errorf("unknown score component '%s'", component)
+		}
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("invalid weight for score component '%s': %w", component, err)
+		}
+	}
+
+	names := map[string]struct{}{c.Contracts.Set: {}}
+	for _, set := range c.Contracts.Sets {
+		if set.Name == "" {
+			return errors.New("contract set name can't be empty")
+		} else if _, exists := names[set.Name]; exists {
+			return fmt.Errorf("duplicate contract set '%s'", set.Name)
+		} else if set.Amount == 0 {
+			return fmt.Errorf("contract set '%s' needs a non-zero amount of contracts", set.Name)
+		}
+		names[set.Name] = struct{}{}
+	}
 	return nil
 }
 
+// SetNames returns the names of all contract sets that are maintained, the
+// default set comes first.
+func (c ContractsConfig) SetNames() []string {
+	names := []string{c.Set}
+	for _, set := range c.Sets {
+		names = append(names, set.Name)
+	}
+	return names
+}
+
+// AllowsHost returns true if a host with the given key and score passes the
+// set's host filter.
+func (c ContractSetConfig) AllowsHost(hk types.PublicKey, score float64) bool {
+	if score < c.MinScore {
+		return false
+	} else if len(c.Hosts) == 0 {
+		return true
+	}
+	for _, h := range c.Hosts {
+		if h == hk {
+			return true
+		}
+	}
+	return false
+}
+
 func (c ContractsConfig) SortContractsForMaintenance(contracts []Contract) {
 	sort.SliceStable(contracts, func(i, j int) bool {
 		iInSet := contracts[i].InSet(c.Set)
@@ -59,3 +59,73 @@ func TestSortContractsForMaintenance(t *testing.T) {
 		t.Fatal("unexpected sort order")
 	}
 }
+
+func TestContractSetConfig(t *testing.T) {
+	cfg := AutopilotConfig{Contracts: ContractsConfig{
+		Set: "autopilot",
+		Sets: []ContractSetConfig{
+			{Name: "curated", Amount: 10, Hosts: []types.PublicKey{{1}}},
+			{Name: "scored", Amount: 20, MinScore: 0.5},
+		},
+	}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	} else if names := cfg.Contracts.SetNames(); !reflect.DeepEqual(names, []string{"autopilot", "curated", "scored"}) {
+		t.Fatal("unexpected set names", names)
+	}
+
+	// assert the host filters
+	curated, scored := cfg.Contracts.Sets[0], cfg.Contracts.Sets[1]
+	if !curated.AllowsHost(types.PublicKey{1}, 0) {
+		t.Fatal("expected host to be allowed")
+	} else if curated.AllowsHost(types.PublicKey{2}, 1) {
+		t.Fatal("expected host to be filtered")
+	} else if !scored.AllowsHost(types.PublicKey{2}, 0.5) {
+		t.Fatal("expected host to be allowed")
+	} else if scored.AllowsHost(types.PublicKey{2}, 0.4) {
+		t.Fatal("expected host to be filtered")
+	}
+
+	// assert invalid sets are rejected
+	for _, sets := range [][]ContractSetConfig{
+		{{Name: "", Amount: 1}},
+		{{Name: "autopilot", Amount: 1}},
+		{{Name: "curated", Amount: 1}, {Name: "curated", Amount: 1}},
+		{{Name: "curated", Amount: 0}},
+	} {
+		cfg.Contracts.Sets = sets
+		if err := cfg.Validate(); err == nil {
+			t.Fatal("expected error", sets)
+		}
+	}
+}
+
+func TestAutopilotConfigValidateScoreWeights(t *testing.T) {
+	tests := []struct {
+		weights map[string]HostScoreWeight
+		valid   bool
+	}{
+		{nil, true},
+		{map[string]HostScoreWeight{HostScorePrices: {Weight: 0.5, Exponent: 2}}, true},
+		{map[string]HostScoreWeight{HostScoreAge: {Weight: 0, Exponent: 1}}, true},
+		{map[string]HostScoreWeight{"foo": {Weight: 1, Exponent: 1}}, false},
+		{map[string]HostScoreWeight{HostScoreUptime: {Weight: 1.5, Exponent: 1}}, false},
+		{map[string]HostScoreWeight{HostScoreUptime: {Weight: 1, Exponent: 0}}, false},
+	}
+	for i, test := range tests {
+		cfg := AutopilotConfig{Hosts: HostsConfig{ScoreWeights: test.weights}}
+		if err := cfg.Validate(); (err == nil) != test.valid {
+			t.Fatalf("%d: unexpected error %v", i, err)
+		}
+	}
+
+	// weights are applied to the breakdown
+	sb := HostScoreBreakdown{Age: 0.5, Collateral: 1, Interactions: 1, Prices: 0.5, StorageRemaining: 1, Uptime: 1, Version: 1}
+	weighted := sb.Weighted(map[string]HostScoreWeight{
+		HostScoreAge:    {Weight: 0.5, Exponent: 1},
+		HostScorePrices: {Weight: 1, Exponent: 2},
+	})
+	if weighted.Age != 0.75 || weighted.Prices != 0.25 || weighted.Collateral != 1 {
+		t.Fatalf("unexpected breakdown %v", weighted)
+	}
+}
@@ -2,10 +2,22 @@ package api
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
 )
 
 const (
 	DefaultBucketName = "default"
+
+	BucketVersioningEnabled   = "Enabled"
+	BucketVersioningSuspended = "Suspended"
+
+	// BucketQuotaWarningThreshold is the fraction of a bucket's quota that
+	// can be used before an alert is registered.
+	BucketQuotaWarningThreshold = 0.9
 )
 
 var (
@@ -20,6 +32,14 @@ var (
 	// ErrBucketNotFound is returned when an bucket can't be retrieved from the
 	// database.
 	ErrBucketNotFound = errors.New("bucket not found")
+
+	// ErrBucketQuotaExceeded is returned when an upload would exceed the
+	// quota of a bucket.
+	ErrBucketQuotaExceeded = errors.New("bucket quota exceeded")
+
+	// ErrInvalidBucketPolicy is returned when a bucket policy fails
+	// validation.
+	ErrInvalidBucketPolicy = errors.New("invalid bucket policy")
 )
 
 type (
@@ -31,6 +51,92 @@ type (
 
 	BucketPolicy struct {
 		PublicReadAccess bool `json:"publicReadAccess"`
+
+		// Versioning is the versioning state of the bucket, it is either
+		// empty if versioning was never enabled, or it is set to one of
+		// BucketVersioningEnabled or BucketVersioningSuspended.
+		Versioning string `json:"versioning,omitempty"`
+
+		// LifecycleRules are periodically applied to the bucket's objects
+		// and multipart uploads by the bus.
+		LifecycleRules []LifecycleRule `json:"lifecycleRules,omitempty"`
+
+		// Quota limits the size and number of objects in the bucket, it is
+		// enforced by the worker when uploading.
+		Quota BucketQuota `json:"quota"`
+
+		// Redundancy overrides the default redundancy settings for objects
+		// uploaded to the bucket, if nil the default settings apply.
+		Redundancy *RedundancySettings `json:"redundancy,omitempty"`
+
+		// ContractSet binds the bucket to a contract set, all objects
+		// uploaded to the bucket are stored on the contracts in that set
+		// unless the upload explicitly specifies a different set.
+		ContractSet string `json:"contractSet,omitempty"`
+
+		// Website configures the bucket to be served as a static website by
+		// the worker, it requires the bucket to be publicly readable.
+		Website *BucketWebsite `json:"website,omitempty"`
+	}
+
+	// BucketWebsite contains the website configuration of a bucket.
+	BucketWebsite struct {
+		// IndexDocument is served for requests to a directory, e.g.
+		// 'index.html'.
+		IndexDocument string `json:"indexDocument"`
+
+		// ErrorDocument is served for requests to objects that don't exist,
+		// if empty a plain error is returned.
+		ErrorDocument string `json:"errorDocument,omitempty"`
+
+		// RedirectRules are evaluated in order, the first rule that matches
+		// a request redirects it.
+		RedirectRules []WebsiteRedirectRule `json:"redirectRules,omitempty"`
+	}
+
+	// WebsiteRedirectRule redirects requests for objects with the given
+	// prefix or requests that result in the given error code. The path of the
+	// redirect is built by replacing either the prefix or the whole path,
+	// if the hostname is empty the redirect is relative to the website.
+	WebsiteRedirectRule struct {
+		KeyPrefixEquals             string `json:"keyPrefixEquals,omitempty"`
+		HTTPErrorCodeReturnedEquals int    `json:"httpErrorCodeReturnedEquals,omitempty"`
+
+		HostName             string `json:"hostName,omitempty"`
+		Protocol             string `json:"protocol,omitempty"`
+		ReplaceKeyPrefixWith string `json:"replaceKeyPrefixWith,omitempty"`
+		ReplaceKeyWith       string `json:"replaceKeyWith,omitempty"`
+		HTTPRedirectCode     int    `json:"httpRedirectCode,omitempty"`
+	}
+
+	// BucketQuota limits the amount of data stored in a bucket. A limit of 0
+	// means the respective dimension is not limited.
+	BucketQuota struct {
+		MaxSize    uint64 `json:"maxSize,omitempty"`
+		MaxObjects uint64 `json:"maxObjects,omitempty"`
+	}
+
+	// LifecycleRule describes which objects and multipart uploads are
+	// removed from a bucket once they reach a certain age. The prefix is
+	// matched against the object's path, an empty prefix matches all objects
+	// in the bucket.
+	LifecycleRule struct {
+		ID     string `json:"id"`
+		Prefix string `json:"prefix"`
+
+		// ExpirationDays is the number of days after which objects are
+		// deleted, 0 means objects never expire.
+		ExpirationDays uint64 `json:"expirationDays,omitempty"`
+
+		// NoncurrentVersionExpirationDays is the number of days after which
+		// noncurrent versions are deleted, counted from the moment they were
+		// replaced by a newer version. 0 means they never expire.
+		NoncurrentVersionExpirationDays uint64 `json:"noncurrentVersionExpirationDays,omitempty"`
+
+		// AbortIncompleteMultipartUploadDays is the number of days after
+		// which incomplete multipart uploads are aborted, 0 means they are
+		// never aborted.
+		AbortIncompleteMultipartUploadDays uint64 `json:"abortIncompleteMultipartUploadDays,omitempty"`
 	}
 
 	CreateBucketOptions struct {
@@ -48,3 +154,187 @@ type (
 		Policy BucketPolicy `json:"policy"`
 	}
 )
+
+// Validate returns an error if the policy is not valid.
+func (bp BucketPolicy) Validate() error {
+	switch bp.Versioning {
+	case "", BucketVersioningEnabled, BucketVersioningSuspended:
+	default:
+		return fmt.Errorf("%w: unknown versioning state '%s'", ErrInvalidBucketPolicy, bp.Versioning)
+	}
+
+	ids := make(map[string]struct{})
+	for i, rule := range bp.LifecycleRules {
+		if rule.ExpirationDays == 0 && rule.NoncurrentVersionExpirationDays == 0 && rule.AbortIncompleteMultipartUploadDays == 0 {
+			return fmt.Errorf("%w: lifecycle rule %d has no action", ErrInvalidBucketPolicy, i)
+		} else if rule.ID == "" {
+			continue
+		} else if _, exists := ids[rule.ID]; exists {
+			return fmt.Errorf("%w: duplicate lifecycle rule id '%s'", ErrInvalidBucketPolicy, rule.ID)
+		}
+		ids[rule.ID] = struct{}{}
+	}
+
+	if bp.Redundancy != nil {
+		if err := bp.Redundancy.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBucketPolicy, err)
+		}
+	}
+
+	if bp.Website != nil {
+		if !bp.PublicReadAccess {
+			return fmt.Errorf("%w: website requires public read access", ErrInvalidBucketPolicy)
+		} else if err := bp.Website.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBucketPolicy, err)
+		}
+	}
+	return nil
+}
+
+// WebsiteEnabled returns true if the bucket should be served as a static
+// website.
+func (bp BucketPolicy) WebsiteEnabled() bool {
+	return bp.Website != nil && bp.PublicReadAccess
+}
+
+// Validate returns an error if the website configuration is not valid.
+func (w BucketWebsite) Validate() error {
+	if w.IndexDocument == "" {
+		return errors.New("website index document can't be empty")
+	} else if strings.Contains(w.IndexDocument, "/") {
+		return fmt.Errorf("website index document '%s' can't contain a slash", w.IndexDocument)
+	}
+	for i, rule := range w.RedirectRules {
+		if rule.ReplaceKeyPrefixWith != "" && rule.ReplaceKeyWith != "" {
+			return fmt.Errorf("website redirect rule %d can't replace both the key and its prefix", i)
+		} else if rule.HTTPRedirectCode != 0 && (rule.HTTPRedirectCode < 300 || rule.HTTPRedirectCode > 399) {
+			return fmt.Errorf("website redirect rule %d has invalid redirect code %d", i, rule.HTTPRedirectCode)
+		} else if rule.HTTPErrorCodeReturnedEquals != 0 && (rule.HTTPErrorCodeReturnedEquals < 400 || rule.HTTPErrorCodeReturnedEquals > 599) {
+			return fmt.Errorf("website redirect rule %d has invalid error code condition %d", i, rule.HTTPErrorCodeReturnedEquals)
+		}
+		switch rule.Protocol {
+		case "", "http", "https":
+		default:
+			return fmt.Errorf("website redirect rule %d has invalid protocol '%s'", i, rule.Protocol)
+		}
+	}
+	return nil
+}
+
+// Redirect returns the first redirect rule that matches a request for the
+// given key which resulted in the given status code, a status code of 0
+// indicates the object wasn't looked up yet. The key is returned with the
+// rule's replacement applied.
+func (w BucketWebsite) Redirect(key string, status int) (WebsiteRedirectRule, string, bool) {
+	for _, rule := range w.RedirectRules {
+		if rule.HTTPErrorCodeReturnedEquals != 0 && rule.HTTPErrorCodeReturnedEquals != status {
+			continue
+		} else if rule.HTTPErrorCodeReturnedEquals == 0 && status != 0 {
+			continue // rules without an error code are evaluated before the lookup
+		} else if !strings.HasPrefix(key, rule.KeyPrefixEquals) {
+			continue
+		}
+
+		switch {
+		case rule.ReplaceKeyWith != "":
+			key = rule.ReplaceKeyWith
+		case rule.ReplaceKeyPrefixWith != "":
+			key = rule.ReplaceKeyPrefixWith + strings.TrimPrefix(key, rule.KeyPrefixEquals)
+		}
+		return rule, key, true
+	}
+	return WebsiteRedirectRule{}, "", false
+}
+
+// RedirectCode returns the status code of the redirect, it defaults to 301.
+func (r WebsiteRedirectRule) RedirectCode() int {
+	if r.HTTPRedirectCode == 0 {
+		return http.StatusMovedPermanently
+	}
+	return r.HTTPRedirectCode
+}
+
+// Enabled returns true if the quota limits either the size or the number of
+// objects in a bucket.
+func (q BucketQuota) Enabled() bool {
+	return q.MaxSize > 0 || q.MaxObjects > 0
+}
+
+// Check returns an error if uploading an object of the given size to a bucket
+// with the given stats would exceed the quota. A negative size indicates the
+// size is unknown, in which case the upload is only rejected if the bucket is
+// already full. Since the worker can't tell whether an upload overwrites an
+// existing object, every upload is counted as a new object. Multipart uploads
+// are accounted for by including the size of their uploaded parts, parts
+// don't create objects so they're not checked against the object limit.
+func (q BucketQuota) Check(stats ObjectsStatsResponse, size int64, isPart bool) error {
+	used, objects := q.Used(stats)
+	if q.MaxSize > 0 {
+		if size < 0 && used >= q.MaxSize {
+			return fmt.Errorf("%w: bucket uses %d of %d bytes", ErrBucketQuotaExceeded, used, q.MaxSize)
+		} else if size >= 0 && used+uint64(size) > q.MaxSize {
+			return fmt.Errorf("%w: uploading %d bytes exceeds the limit of %d bytes, bucket uses %d bytes", ErrBucketQuotaExceeded, size, q.MaxSize, used)
+		}
+	}
+	if q.MaxObjects > 0 && !isPart && objects >= q.MaxObjects {
+		return fmt.Errorf("%w: bucket contains %d of %d objects", ErrBucketQuotaExceeded, objects, q.MaxObjects)
+	}
+	return nil
+}
+
+// Remaining returns the number of bytes that can still be uploaded to a bucket
+// with the given stats, it's negative if the size of the bucket isn't limited.
+func (q BucketQuota) Remaining(stats ObjectsStatsResponse) int64 {
+	if q.MaxSize == 0 {
+		return -1
+	} else if used, _ := q.Used(stats); used < q.MaxSize {
+		return int64(q.MaxSize - used)
+	}
+	return 0
+}
+
+// Usage returns the fraction of the quota that is used by a bucket with the
+// given stats, if both limits are set the larger fraction is returned.
+func (q BucketQuota) Usage(stats ObjectsStatsResponse) (usage float64) {
+	used, objects := q.Used(stats)
+	if q.MaxSize > 0 {
+		usage = float64(used) / float64(q.MaxSize)
+	}
+	if q.MaxObjects > 0 {
+		usage = math.Max(usage, float64(objects)/float64(q.MaxObjects))
+	}
+	return
+}
+
+// Used returns the size and number of objects a bucket with the given stats
+// uses of its quota. Unfinished multipart uploads count towards the size and
+// noncurrent versions of objects in versioned buckets count towards both.
+func (q BucketQuota) Used(stats ObjectsStatsResponse) (size, objects uint64) {
+	size = stats.TotalObjectsSize + stats.TotalUnfinishedObjectsSize + stats.TotalNoncurrentVersionsSize
+	objects = stats.NumObjects + stats.NumNoncurrentVersions
+	return
+}
+
+// Expired returns true if an object that was last modified at the given time
+// expired according to the rule.
+func (r LifecycleRule) Expired(modTime, now time.Time) bool {
+	return r.ExpirationDays > 0 && now.Sub(modTime) >= time.Duration(r.ExpirationDays)*24*time.Hour
+}
+
+// NoncurrentExpired returns true if a version that became noncurrent at the
+// given time should be deleted according to the rule.
+func (r LifecycleRule) NoncurrentExpired(noncurrentSince, now time.Time) bool {
+	return r.NoncurrentVersionExpirationDays > 0 && now.Sub(noncurrentSince) >= time.Duration(r.NoncurrentVersionExpirationDays)*24*time.Hour
+}
+
+// Abandoned returns true if a multipart upload that was created at the given
+// time should be aborted according to the rule.
+func (r LifecycleRule) Abandoned(createdAt, now time.Time) bool {
+	return r.AbortIncompleteMultipartUploadDays > 0 && now.Sub(createdAt) >= time.Duration(r.AbortIncompleteMultipartUploadDays)*24*time.Hour
+}
+
+// VersioningEnabled returns true if new versions should be created when an
+// object is overwritten or deleted.
+func (bp BucketPolicy) VersioningEnabled() bool {
+	return bp.Versioning == BucketVersioningEnabled
+}
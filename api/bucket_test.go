@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBucketQuota(t *testing.T) {
+	quota := BucketQuota{MaxSize: 100, MaxObjects: 10}
+	if !quota.Enabled() {
+		t.Fatal("expected quota to be enabled")
+	} else if (BucketQuota{}).Enabled() {
+		t.Fatal("expected empty quota to be disabled")
+	}
+
+	tests := []struct {
+		stats    ObjectsStatsResponse
+		size     int64
+		isPart   bool
+		exceeded bool
+		usage    float64
+	}{
+		{ObjectsStatsResponse{}, 100, false, false, 0},
+		{ObjectsStatsResponse{}, 101, false, true, 0},
+		{ObjectsStatsResponse{NumObjects: 5, TotalObjectsSize: 50}, 50, false, false, 0.5},
+		{ObjectsStatsResponse{NumObjects: 5, TotalObjectsSize: 50, TotalUnfinishedObjectsSize: 30}, 30, false, true, 0.8},
+		{ObjectsStatsResponse{NumObjects: 9, TotalObjectsSize: 10}, -1, false, false, 0.9},
+		{ObjectsStatsResponse{NumObjects: 10, TotalObjectsSize: 10}, 0, false, true, 1},
+		{ObjectsStatsResponse{NumObjects: 10, TotalObjectsSize: 10}, 10, true, false, 1},
+		{ObjectsStatsResponse{NumObjects: 10, TotalObjectsSize: 10}, 91, true, true, 1},
+		{ObjectsStatsResponse{NumObjects: 1, TotalObjectsSize: 99}, -1, false, false, 0.99},
+		{ObjectsStatsResponse{NumObjects: 1, TotalObjectsSize: 100}, -1, false, true, 1},
+		{ObjectsStatsResponse{NumObjects: 1, TotalObjectsSize: 40, NumNoncurrentVersions: 4, TotalNoncurrentVersionsSize: 40}, 20, false, false, 0.8},
+		{ObjectsStatsResponse{NumObjects: 1, TotalObjectsSize: 40, NumNoncurrentVersions: 4, TotalNoncurrentVersionsSize: 40}, 21, false, true, 0.8},
+		{ObjectsStatsResponse{NumObjects: 1, NumNoncurrentVersions: 9}, 0, false, true, 1},
+	}
+	for i, test := range tests {
+		if err := quota.Check(test.stats, test.size, test.isPart); errors.Is(err, ErrBucketQuotaExceeded) != test.exceeded {
+			t.Fatalf("%d: unexpected error %v", i, err)
+		} else if usage := quota.Usage(test.stats); usage != test.usage {
+			t.Fatalf("%d: unexpected usage %v != %v", i, usage, test.usage)
+		} else if used, _ := quota.Used(test.stats); quota.Remaining(test.stats) != int64(quota.MaxSize)-int64(min(used, quota.MaxSize)) {
+			t.Fatalf("%d: unexpected remaining size %v", i, quota.Remaining(test.stats))
+		}
+	}
+}
+
+func TestBucketPolicyRedundancy(t *testing.T) {
+	bp := BucketPolicy{Redundancy: &RedundancySettings{MinShards: 10, TotalShards: 20}}
+	if err := bp.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	bp.Redundancy.TotalShards = 5
+	if err := bp.Validate(); !errors.Is(err, ErrInvalidBucketPolicy) {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func TestBucketWebsite(t *testing.T) {
+	bp := BucketPolicy{Website: &BucketWebsite{IndexDocument: "index.html"}}
+	if err := bp.Validate(); !errors.Is(err, ErrInvalidBucketPolicy) {
+		t.Fatal("expected website to require public read access", err)
+	}
+	bp.PublicReadAccess = true
+	if err := bp.Validate(); err != nil {
+		t.Fatal(err)
+	} else if !bp.WebsiteEnabled() {
+		t.Fatal("expected website to be enabled")
+	}
+
+	for _, ws := range []BucketWebsite{
+		{},
+		{IndexDocument: "dir/index.html"},
+		{IndexDocument: "index.html", RedirectRules: []WebsiteRedirectRule{{ReplaceKeyWith: "a", ReplaceKeyPrefixWith: "b"}}},
+		{IndexDocument: "index.html", RedirectRules: []WebsiteRedirectRule{{HTTPRedirectCode: 200}}},
+		{IndexDocument: "index.html", RedirectRules: []WebsiteRedirectRule{{HTTPErrorCodeReturnedEquals: 200}}},
+		{IndexDocument: "index.html", RedirectRules: []WebsiteRedirectRule{{Protocol: "ftp"}}},
+	} {
+		if err := ws.Validate(); err == nil {
+			t.Fatalf("expected %+v to be invalid", ws)
+		}
+	}
+
+	ws := BucketWebsite{
+		IndexDocument: "index.html",
+		RedirectRules: []WebsiteRedirectRule{
+			{KeyPrefixEquals: "docs/", ReplaceKeyPrefixWith: "documents/"},
+			{KeyPrefixEquals: "old/", HTTPErrorCodeReturnedEquals: 404, ReplaceKeyWith: "gone.html", HTTPRedirectCode: 302},
+		},
+	}
+	tests := []struct {
+		key    string
+		status int
+		newKey string
+		code   int
+		ok     bool
+	}{
+		{"docs/a.html", 0, "documents/a.html", 301, true},
+		{"docs/a.html", 404, "", 0, false},
+		{"old/a.html", 0, "", 0, false},
+		{"old/a.html", 404, "gone.html", 302, true},
+		{"old/a.html", 403, "", 0, false},
+		{"a.html", 0, "", 0, false},
+	}
+	for _, test := range tests {
+		rule, newKey, ok := ws.Redirect(test.key, test.status)
+		if ok != test.ok {
+			t.Fatalf("%v %v: unexpected match %v", test.key, test.status, ok)
+		} else if !ok {
+			continue
+		} else if newKey != test.newKey || rule.RedirectCode() != test.code {
+			t.Fatalf("%v %v: unexpected redirect %v %v", test.key, test.status, newKey, rule.RedirectCode())
+		}
+	}
+}
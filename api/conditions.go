@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrPreconditionFailed is returned when the conditions of a request are
+	// not met.
+	ErrPreconditionFailed = errors.New("precondition failed")
+
+	// ErrNotModified is returned when the conditions of a read request
+	// indicate that the client's copy of the object is still up-to-date.
+	ErrNotModified = errors.New("not modified")
+)
+
+type (
+	// ObjectConditions contain the preconditions of a request as specified by
+	// the If-Match, If-None-Match, If-Modified-Since and If-Unmodified-Since
+	// headers. The etag conditions are a comma separated list of etags or '*'
+	// to match any object.
+	ObjectConditions struct {
+		IfMatch           string    `json:"ifMatch,omitempty"`
+		IfNoneMatch       string    `json:"ifNoneMatch,omitempty"`
+		IfModifiedSince   time.Time `json:"ifModifiedSince"`
+		IfUnmodifiedSince time.Time `json:"ifUnmodifiedSince"`
+	}
+)
+
+// ObjectConditionsFromHeader parses the conditions of a request from the given
+// header. Invalid dates are ignored, as required by RFC 9110.
+func ObjectConditionsFromHeader(h http.Header) ObjectConditions {
+	c := ObjectConditions{
+		IfMatch:     h.Get("If-Match"),
+		IfNoneMatch: h.Get("If-None-Match"),
+	}
+	if t, err := http.ParseTime(h.Get("If-Modified-Since")); err == nil {
+		c.IfModifiedSince = t
+	}
+	if t, err := http.ParseTime(h.Get("If-Unmodified-Since")); err == nil {
+		c.IfUnmodifiedSince = t
+	}
+	return c
+}
+
+// ApplyHeaders sets the headers that correspond to the conditions.
+func (c ObjectConditions) ApplyHeaders(h http.Header) {
+	if c.IfMatch != "" {
+		h.Set("If-Match", c.IfMatch)
+	}
+	if c.IfNoneMatch != "" {
+		h.Set("If-None-Match", c.IfNoneMatch)
+	}
+	if !c.IfModifiedSince.IsZero() {
+		h.Set("If-Modified-Since", c.IfModifiedSince.UTC().Format(http.TimeFormat))
+	}
+	if !c.IfUnmodifiedSince.IsZero() {
+		h.Set("If-Unmodified-Since", c.IfUnmodifiedSince.UTC().Format(http.TimeFormat))
+	}
+}
+
+// HasWriteConditions returns true if the conditions contain any conditions
+// that are evaluated when writing an object.
+func (c ObjectConditions) HasWriteConditions() bool {
+	return c.IfMatch != "" || c.IfNoneMatch != ""
+}
+
+// CheckRead evaluates the conditions of a GET or HEAD request against the
+// object's current etag and modification time in the order specified by RFC
+// 9110. It returns ErrPreconditionFailed or ErrNotModified if the object
+// shouldn't be served.
+func (c ObjectConditions) CheckRead(eTag string, modTime time.Time) error {
+	// the dates in the headers have a precision of seconds
+	modTime = modTime.Truncate(time.Second)
+
+	if c.IfMatch != "" {
+		if !etagListMatches(c.IfMatch, eTag, true) {
+			return ErrPreconditionFailed
+		}
+	} else if !c.IfUnmodifiedSince.IsZero() && modTime.After(c.IfUnmodifiedSince) {
+		return ErrPreconditionFailed
+	}
+
+	if c.IfNoneMatch != "" {
+		if etagListMatches(c.IfNoneMatch, eTag, true) {
+			return ErrNotModified
+		}
+	} else if !c.IfModifiedSince.IsZero() && !modTime.After(c.IfModifiedSince) {
+		return ErrNotModified
+	}
+	return nil
+}
+
+// CheckWrite evaluates the etag conditions of a request that creates or
+// overwrites an object. If-Match requires the object to exist with a matching
+// etag, If-None-Match requires it to either not exist or, unless it is '*',
+// to have a different etag.
+func (c ObjectConditions) CheckWrite(eTag string, exists bool) error {
+	if c.IfMatch != "" && !etagListMatches(c.IfMatch, eTag, exists) {
+		return ErrPreconditionFailed
+	} else if c.IfNoneMatch != "" && etagListMatches(c.IfNoneMatch, eTag, exists) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// etagListMatches returns true if the etag matches any of the etags in the
+// list, the wildcard '*' matches any existing object.
+func etagListMatches(list, eTag string, exists bool) bool {
+	if !exists {
+		return false
+	}
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		candidate = strings.TrimPrefix(candidate, "W/")
+		if strings.Trim(candidate, `"`) == eTag {
+			return true
+		}
+	}
+	return false
+}
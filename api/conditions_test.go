@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestObjectConditionsCheckRead(t *testing.T) {
+	modTime := time.Date(2024, 1, 1, 12, 0, 0, 500, time.UTC)
+	before, after := modTime.Add(-time.Hour), modTime.Add(time.Hour)
+
+	tests := []struct {
+		conditions ObjectConditions
+		err        error
+	}{
+		{ObjectConditions{}, nil},
+		{ObjectConditions{IfMatch: `"etag"`}, nil},
+		{ObjectConditions{IfMatch: `"other", W/"etag"`}, nil},
+		{ObjectConditions{IfMatch: "*"}, nil},
+		{ObjectConditions{IfMatch: `"other"`}, ErrPreconditionFailed},
+		{ObjectConditions{IfUnmodifiedSince: after}, nil},
+		{ObjectConditions{IfUnmodifiedSince: modTime.Truncate(time.Second)}, nil},
+		{ObjectConditions{IfUnmodifiedSince: before}, ErrPreconditionFailed},
+		{ObjectConditions{IfMatch: `"etag"`, IfUnmodifiedSince: before}, nil}, // If-Match takes precedence
+		{ObjectConditions{IfNoneMatch: `"etag"`}, ErrNotModified},
+		{ObjectConditions{IfNoneMatch: "*"}, ErrNotModified},
+		{ObjectConditions{IfNoneMatch: `"other"`}, nil},
+		{ObjectConditions{IfModifiedSince: after}, ErrNotModified},
+		{ObjectConditions{IfModifiedSince: modTime.Truncate(time.Second)}, ErrNotModified},
+		{ObjectConditions{IfModifiedSince: before}, nil},
+		{ObjectConditions{IfNoneMatch: `"other"`, IfModifiedSince: after}, nil}, // If-None-Match takes precedence
+		{ObjectConditions{IfMatch: `"other"`, IfNoneMatch: `"etag"`}, ErrPreconditionFailed},
+	}
+	for i, test := range tests {
+		if err := test.conditions.CheckRead("etag", modTime); !errors.Is(err, test.err) {
+			t.Fatalf("%d: unexpected error %v, expected %v", i, err, test.err)
+		}
+	}
+}
+
+func TestObjectConditionsCheckWrite(t *testing.T) {
+	tests := []struct {
+		conditions ObjectConditions
+		exists     bool
+		err        error
+	}{
+		{ObjectConditions{}, false, nil},
+		{ObjectConditions{}, true, nil},
+		{ObjectConditions{IfNoneMatch: "*"}, false, nil},
+		{ObjectConditions{IfNoneMatch: "*"}, true, ErrPreconditionFailed},
+		{ObjectConditions{IfNoneMatch: `"etag"`}, true, ErrPreconditionFailed},
+		{ObjectConditions{IfNoneMatch: `"other"`}, true, nil},
+		{ObjectConditions{IfMatch: "*"}, false, ErrPreconditionFailed},
+		{ObjectConditions{IfMatch: "*"}, true, nil},
+		{ObjectConditions{IfMatch: `"etag"`}, false, ErrPreconditionFailed},
+		{ObjectConditions{IfMatch: `"etag"`}, true, nil},
+		{ObjectConditions{IfMatch: `"other"`}, true, ErrPreconditionFailed},
+	}
+	for i, test := range tests {
+		if err := test.conditions.CheckWrite("etag", test.exists); !errors.Is(err, test.err) {
+			t.Fatalf("%d: unexpected error %v, expected %v", i, err, test.err)
+		}
+	}
+}
+
+func TestObjectConditionsHeaders(t *testing.T) {
+	c := ObjectConditions{
+		IfMatch:           `"foo"`,
+		IfNoneMatch:       "*",
+		IfModifiedSince:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		IfUnmodifiedSince: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	h := make(http.Header)
+	c.ApplyHeaders(h)
+	if parsed := ObjectConditionsFromHeader(h); parsed.IfMatch != c.IfMatch ||
+		parsed.IfNoneMatch != c.IfNoneMatch ||
+		!parsed.IfModifiedSince.Equal(c.IfModifiedSince) ||
+		!parsed.IfUnmodifiedSince.Equal(c.IfUnmodifiedSince) {
+		t.Fatal("unexpected conditions", parsed)
+	}
+
+	// assert invalid dates are ignored
+	h.Set("If-Modified-Since", "yesterday")
+	if parsed := ObjectConditionsFromHeader(h); !parsed.IfModifiedSince.IsZero() {
+		t.Fatal("expected invalid date to be ignored")
+	}
+}
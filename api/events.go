@@ -15,6 +15,8 @@ const (
 	ModuleContract    = "contract"
 	ModuleContractSet = "contract_set"
 	ModuleHost        = "host"
+	ModuleMultipart   = "multipart"
+	ModuleObject      = "object"
 	ModuleSetting     = "setting"
 
 	EventAdd     = "add"
@@ -22,6 +24,10 @@ const (
 	EventDelete  = "delete"
 	EventArchive = "archive"
 	EventRenew   = "renew"
+
+	EventCompleted = "completed"
+	EventCreated   = "created"
+	EventDeleted   = "deleted"
 )
 
 var (
@@ -64,6 +70,41 @@ type (
 		Timestamp time.Time              `json:"timestamp"`
 	}
 
+	// EventObjectCreated is broadcast when an object is created or
+	// overwritten by an upload, copy or rename. If Batch is true, all objects
+	// with the path as prefix were created by renaming a directory and the
+	// object's metadata is omitted.
+	EventObjectCreated struct {
+		Bucket    string    `json:"bucket"`
+		Path      string    `json:"path"`
+		Batch     bool      `json:"batch"`
+		ETag      string    `json:"eTag,omitempty"`
+		MimeType  string    `json:"mimeType,omitempty"`
+		Size      int64     `json:"size"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+
+	// EventObjectDeleted is broadcast when an object is deleted or renamed. If
+	// Batch is true, all objects with the path as prefix were deleted. If
+	// VersionID is set, only that version of the object was deleted.
+	EventObjectDeleted struct {
+		Bucket    string    `json:"bucket"`
+		Path      string    `json:"path"`
+		Batch     bool      `json:"batch"`
+		VersionID string    `json:"versionID,omitempty"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+
+	// EventMultipartCompleted is broadcast when a multipart upload is
+	// completed, the resulting object is announced by an EventObjectCreated.
+	EventMultipartCompleted struct {
+		Bucket    string    `json:"bucket"`
+		Path      string    `json:"path"`
+		UploadID  string    `json:"uploadID"`
+		ETag      string    `json:"eTag"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+
 	EventSettingUpdate struct {
 		Key       string      `json:"key"`
 		Update    interface{} `json:"update"`
@@ -131,6 +172,33 @@ var (
 		}
 	}
 
+	WebhookMultipartCompleted = func(url string, headers map[string]string) webhooks.Webhook {
+		return webhooks.Webhook{
+			Event:   EventCompleted,
+			Headers: headers,
+			Module:  ModuleMultipart,
+			URL:     url,
+		}
+	}
+
+	WebhookObjectCreated = func(url string, headers map[string]string) webhooks.Webhook {
+		return webhooks.Webhook{
+			Event:   EventCreated,
+			Headers: headers,
+			Module:  ModuleObject,
+			URL:     url,
+		}
+	}
+
+	WebhookObjectDeleted = func(url string, headers map[string]string) webhooks.Webhook {
+		return webhooks.Webhook{
+			Event:   EventDeleted,
+			Headers: headers,
+			Module:  ModuleObject,
+			URL:     url,
+		}
+	}
+
 	WebhookSettingUpdate = func(url string, headers map[string]string) webhooks.Webhook {
 		return webhooks.Webhook{
 			Event:   EventUpdate,
@@ -201,6 +269,29 @@ func ParseEventWebhook(event webhooks.Event) (interface{}, error) {
 			}
 			return e, nil
 		}
+	case ModuleMultipart:
+		if event.Event == EventCompleted {
+			var e EventMultipartCompleted
+			if err := json.Unmarshal(bytes, &e); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
+	case ModuleObject:
+		switch event.Event {
+		case EventCreated:
+			var e EventObjectCreated
+			if err := json.Unmarshal(bytes, &e); err != nil {
+				return nil, err
+			}
+			return e, nil
+		case EventDeleted:
+			var e EventObjectDeleted
+			if err := json.Unmarshal(bytes, &e); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
 	case ModuleSetting:
 		switch event.Event {
 		case EventUpdate:
@@ -219,3 +310,21 @@ func ParseEventWebhook(event webhooks.Event) (interface{}, error) {
 	}
 	return nil, fmt.Errorf("%w: module %s event %s", ErrUnknownEvent, event.Module, event.Event)
 }
+
+// ObjectBucket implements webhooks.ObjectPayload.
+func (e EventMultipartCompleted) ObjectBucket() string { return e.Bucket }
+
+// ObjectPath implements webhooks.ObjectPayload.
+func (e EventMultipartCompleted) ObjectPath() (string, bool) { return e.Path, false }
+
+// ObjectBucket implements webhooks.ObjectPayload.
+func (e EventObjectCreated) ObjectBucket() string { return e.Bucket }
+
+// ObjectPath implements webhooks.ObjectPayload.
+func (e EventObjectCreated) ObjectPath() (string, bool) { return e.Path, e.Batch }
+
+// ObjectBucket implements webhooks.ObjectPayload.
+func (e EventObjectDeleted) ObjectBucket() string { return e.Bucket }
+
+// ObjectPath implements webhooks.ObjectPayload.
+func (e EventObjectDeleted) ObjectPath() (string, bool) { return e.Path, e.Batch }
@@ -3,6 +3,7 @@ package api
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/url"
 	"strings"
 	"time"
@@ -22,6 +23,18 @@ const (
 	UsabilityFilterModeUnusable = "unusable"
 )
 
+// Host score components, used as keys for the score weights in the hosts
+// config.
+const (
+	HostScoreAge              = "age"
+	HostScoreCollateral       = "collateral"
+	HostScoreInteractions     = "interactions"
+	HostScorePrices           = "prices"
+	HostScoreStorageRemaining = "storageRemaining"
+	HostScoreUptime           = "uptime"
+	HostScoreVersion          = "version"
+)
+
 var (
 	// ErrHostNotFound is returned when a host can't be retrieved from the
 	// database.
@@ -34,6 +47,7 @@ var (
 	ErrUsabilityHostOffline               = errors.New("host is offline")
 	ErrUsabilityHostLowScore              = errors.New("host's score is below minimum")
 	ErrUsabilityHostRedundantIP           = errors.New("host has redundant IP")
+	ErrUsabilityHostNotDiverse            = errors.New("host exceeds the diversity limits")
 	ErrUsabilityHostPriceGouging          = errors.New("host is price gouging")
 	ErrUsabilityHostNotAcceptingContracts = errors.New("host is not accepting contracts")
 	ErrUsabilityHostNotCompletingScan     = errors.New("host is not completing scan")
@@ -227,6 +241,23 @@ type (
 		Prices           float64 `json:"prices"`
 	}
 
+	// HostScoreRequest is the request type sent to custom host scorers, they
+	// are sent a list of requests and respond with the hosts' score
+	// breakdowns in the same order.
+	HostScoreRequest struct {
+		Host           Host               `json:"host"`
+		ScoreBreakdown HostScoreBreakdown `json:"scoreBreakdown"`
+	}
+
+	// HostScoreWeight configures how much a component contributes to a
+	// host's score. The component's score s is adjusted to
+	// 1 - weight + weight * s^exponent, a weight of 0 disables the
+	// component and a weight and exponent of 1 leave it unchanged.
+	HostScoreWeight struct {
+		Weight   float64 `json:"weight"`
+		Exponent float64 `json:"exponent"`
+	}
+
 	HostUsabilityBreakdown struct {
 		Blocked               bool `json:"blocked"`
 		Offline               bool `json:"offline"`
@@ -293,6 +324,40 @@ func (sb HostScoreBreakdown) Score() float64 {
 	return sb.Age * sb.Collateral * sb.Interactions * sb.StorageRemaining * sb.Uptime * sb.Version * sb.Prices
 }
 
+// Weighted returns the breakdown with the given weights applied to its
+// components, components without a weight are left unchanged.
+func (sb HostScoreBreakdown) Weighted(weights map[string]HostScoreWeight) HostScoreBreakdown {
+	for component, score := range map[string]*float64{
+		HostScoreAge:              &sb.Age,
+		HostScoreCollateral:       &sb.Collateral,
+		HostScoreInteractions:     &sb.Interactions,
+		HostScorePrices:           &sb.Prices,
+		HostScoreStorageRemaining: &sb.StorageRemaining,
+		HostScoreUptime:           &sb.Uptime,
+		HostScoreVersion:          &sb.Version,
+	} {
+		if w, ok := weights[component]; ok {
+			*score = w.Apply(*score)
+		}
+	}
+	return sb
+}
+
+// Apply applies the weight to the given component score.
+func (w HostScoreWeight) Apply(score float64) float64 {
+	return 1 - w.Weight + w.Weight*math.Pow(score, w.Exponent)
+}
+
+// Validate returns an error if the weight is invalid.
+func (w HostScoreWeight) Validate() error {
+	if w.Weight < 0 || w.Weight > 1 {
+		return fmt.Errorf("weight must be between 0 and 1, got %v", w.Weight)
+	} else if w.Exponent <= 0 {
+		return fmt.Errorf("exponent must be positive, got %v", w.Exponent)
+	}
+	return nil
+}
+
 func (ub HostUsabilityBreakdown) IsUsable() bool {
 	return !ub.Blocked && !ub.Offline && !ub.LowScore && !ub.RedundantIP && !ub.Gouging && !ub.NotAcceptingContracts && !ub.NotAnnounced && !ub.NotCompletingScan
 }
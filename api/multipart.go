@@ -2,10 +2,19 @@ package api
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 
 	"go.thebigfile.com/renterd/object"
 )
 
+const (
+	// ResumableUploadOffsetHeader is the header that contains the offset of
+	// a resumable upload. It is set by the client when uploading a chunk and
+	// by the worker when returning the upload's current offset.
+	ResumableUploadOffsetHeader = "Upload-Offset"
+)
+
 var (
 	// ErrInvalidMultipartEncryptionSettings is returned if the multipart upload
 	// has an invalid combination of encryption params. e.g. when encryption is
@@ -20,6 +29,21 @@ var (
 	// wasn't found.
 	ErrPartNotFound = errors.New("multipart upload part not found")
 
+	// ErrPartCopyNotSupported is returned if a part can't be copied by
+	// referencing the slabs of the source object. This is the case if either
+	// the source object or the multipart upload use object encryption, since
+	// the data would have to be re-encrypted.
+	ErrPartCopyNotSupported = errors.New("part can't be copied without re-encrypting the data")
+
+	// ErrInvalidPartCopyRange is returned if the range of a part copy is not
+	// within the bounds of the source object.
+	ErrInvalidPartCopyRange = errors.New("invalid part copy range")
+
+	// ErrUploadOffsetMismatch is returned if a chunk of a resumable upload,
+	// or a part that is expected to start at a certain offset, is uploaded at
+	// an offset that doesn't match the upload's current offset.
+	ErrUploadOffsetMismatch = errors.New("upload offset mismatch")
+
 	// ErrUploadAlreadyExists is returned when starting an upload with an id
 	// that's already in use.
 	ErrUploadAlreadyExists = errors.New("upload already exists")
@@ -57,11 +81,70 @@ type (
 		Metadata    ObjectUserMetadata
 	}
 
+	// CompleteMultipartOptions contains the options the object is stored
+	// with, they match the ones of a single-part upload.
 	CompleteMultipartOptions struct {
 		Metadata ObjectUserMetadata
+
+		ChecksumAlgorithm string
+		Checksum          string
+
+		CustomerKeyFingerprint string
+
+		// Conditions are evaluated atomically against the object that is
+		// being overwritten, if any.
+		Conditions ObjectConditions
+
+		// Lock is the retention and legal hold the object is stored with.
+		Lock ObjectLock
+	}
+
+	AddMultipartPartOptions struct {
+		// Offset is the offset within the upload at which the part is
+		// expected to start, if set the part is only added if it's the next
+		// part of the upload and the parts before it add up to the offset.
+		Offset *int64
+	}
+
+	CopyMultipartPartOptions struct {
+		// Range is the range of the source object that is copied, if nil the
+		// whole object is copied.
+		Range *DownloadRange
+	}
+
+	// ResumableUpload is an upload that is created by the worker's
+	// /resumable endpoints. It is backed by a multipart upload where every
+	// chunk that is uploaded is added as one or more consecutive parts.
+	ResumableUpload struct {
+		UploadID string `json:"uploadID"`
+		Bucket   string `json:"bucket"`
+		Path     string `json:"path"`
+
+		// Offset is the number of bytes that were uploaded, the next chunk
+		// has to be uploaded at this offset.
+		Offset int64 `json:"offset"`
+	}
+
+	UploadResumableChunkOptions struct {
+		ContractSet   string
+		MinShards     int
+		TotalShards   int
+		ContentLength int64
 	}
 )
 
+func (opts UploadResumableChunkOptions) Apply(values url.Values) {
+	if opts.ContractSet != "" {
+		values.Set("contractset", opts.ContractSet)
+	}
+	if opts.MinShards != 0 {
+		values.Set("minshards", fmt.Sprint(opts.MinShards))
+	}
+	if opts.TotalShards != 0 {
+		values.Set("totalshards", fmt.Sprint(opts.TotalShards))
+	}
+}
+
 type (
 	MultipartAbortRequest struct {
 		Bucket   string `json:"bucket"`
@@ -76,9 +159,30 @@ type (
 		ContractSet string             `json:"contractSet"`
 		UploadID    string             `json:"uploadID"`
 		PartNumber  int                `json:"partNumber"`
+		Offset      *int64             `json:"offset,omitempty"`
 		Slices      []object.SlabSlice `json:"slices"`
 	}
 
+	MultipartCopyPartRequest struct {
+		Bucket       string `json:"bucket"`
+		Path         string `json:"path"`
+		ContractSet  string `json:"contractSet"`
+		UploadID     string `json:"uploadID"`
+		PartNumber   int    `json:"partNumber"`
+		SourceBucket string `json:"sourceBucket"`
+		SourcePath   string `json:"sourcePath"`
+
+		// Offset and Length specify the range of the source object that is
+		// copied, a length of -1 copies everything from the offset onwards.
+		Offset int64 `json:"offset"`
+		Length int64 `json:"length"`
+	}
+
+	MultipartCopyPartResponse struct {
+		ETag         string      `json:"eTag"`
+		LastModified TimeRFC3339 `json:"lastModified"`
+	}
+
 	MultipartCompleteResponse struct {
 		ETag string `json:"eTag"`
 	}
@@ -89,6 +193,14 @@ type (
 		Path     string                   `json:"path"`
 		UploadID string                   `json:"uploadID"`
 		Parts    []MultipartCompletedPart `json:"parts"`
+
+		ChecksumAlgorithm string `json:"checksumAlgorithm,omitempty"`
+		Checksum          string `json:"checksum,omitempty"`
+
+		CustomerKeyFingerprint string `json:"customerKeyFingerprint,omitempty"`
+
+		Conditions ObjectConditions `json:"conditions"`
+		Lock       ObjectLock       `json:"lock"`
 	}
 
 	MultipartCreateRequest struct {
@@ -135,4 +247,13 @@ type (
 		NextUploadIDMarker string            `json:"nextUploadIDMarker"`
 		Uploads            []MultipartUpload `json:"uploads"`
 	}
+
+	// ResumableUploadCreateRequest is the request type for the
+	// /worker/resumable endpoint.
+	ResumableUploadCreateRequest struct {
+		Bucket   string             `json:"bucket"`
+		Path     string             `json:"path"`
+		MimeType string             `json:"mimeType"`
+		Metadata ObjectUserMetadata `json:"metadata"`
+	}
 )
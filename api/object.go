@@ -8,13 +8,34 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"go.thebigfile.com/renterd/object"
 )
 
 const (
-	ObjectMetadataPrefix = "X-Sia-Meta-"
+	ObjectMetadataPrefix          = "X-Sia-Meta-"
+	ObjectVersionIDHeader         = "X-Sia-Version-Id"
+	ObjectChecksumHeader          = "X-Sia-Checksum"
+	ObjectChecksumAlgorithmHeader = "X-Sia-Checksum-Algorithm"
+	ObjectCustomerKeyHeader       = "X-Sia-Customer-Key"
+	ObjectLockModeHeader          = "X-Sia-Object-Lock-Mode"
+	ObjectLockRetainUntilHeader   = "X-Sia-Object-Lock-Retain-Until"
+	ObjectLockLegalHoldHeader     = "X-Sia-Object-Lock-Legal-Hold"
+
+	ChecksumAlgorithmCRC32C = "CRC32C"
+	ChecksumAlgorithmSHA256 = "SHA256"
+
+	ArchiveFormatTar = "tar"
+	ArchiveFormatZip = "zip"
+
+	// MaxObjectTags is the maximum number of tags an object can have, the
+	// limits on tags are the same as in S3.
+	MaxObjectTags        = 10
+	MaxObjectTagKeyLen   = 128
+	MaxObjectTagValueLen = 256
 
 	ObjectsRenameModeSingle = "single"
 	ObjectsRenameModeMulti  = "multi"
@@ -25,9 +46,40 @@ const (
 
 	ObjectSortDirAsc  = "asc"
 	ObjectSortDirDesc = "desc"
+
+	// ObjectVersionIDNull is the version ID of objects that were created
+	// while versioning was never enabled or suspended on their bucket.
+	ObjectVersionIDNull = "null"
 )
 
 var (
+	// ErrInvalidArchiveFormat is returned when an archive is requested in an
+	// unsupported format.
+	ErrInvalidArchiveFormat = errors.New("invalid archive format")
+
+	// ErrChecksumMismatch is returned when the checksum of an object's
+	// content doesn't match the expected checksum.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrChecksumNotAvailable is returned when checksum verification is
+	// requested for an object that was uploaded without a checksum.
+	ErrChecksumNotAvailable = errors.New("object has no checksum")
+
+	// ErrInvalidChecksumAlgorithm is returned when an unknown checksum
+	// algorithm is requested.
+	ErrInvalidChecksumAlgorithm = errors.New("invalid checksum algorithm")
+
+	// ErrCustomerKeyRequired is returned when an object that was uploaded
+	// with a customer key is requested without one.
+	ErrCustomerKeyRequired = errors.New("object is encrypted with a customer key")
+
+	// ErrCustomerKeyMismatch is returned when the customer key of a request
+	// doesn't match the key the object was uploaded with.
+	ErrCustomerKeyMismatch = errors.New("customer key mismatch")
+
+	// ErrInvalidCustomerKey is returned when a customer key can't be parsed.
+	ErrInvalidCustomerKey = errors.New("invalid customer key")
+
 	// ErrObjectExists is returned when an operation fails because an object
 	// already exists.
 	ErrObjectExists = errors.New("object already exists")
@@ -40,6 +92,18 @@ var (
 	// from the database.
 	ErrObjectCorrupted = errors.New("object corrupted")
 
+	// ErrObjectVersionNotFound is returned when a specific version of an
+	// object can't be retrieved from the database.
+	ErrObjectVersionNotFound = errors.New("object version not found")
+
+	// ErrObjectVersionIsDeleteMarker is returned when trying to retrieve the
+	// contents of an object version that is a delete marker.
+	ErrObjectVersionIsDeleteMarker = errors.New("object version is a delete marker")
+
+	// ErrInvalidObjectTags is returned when the tags of an object exceed
+	// the limits on tags.
+	ErrInvalidObjectTags = errors.New("invalid object tags")
+
 	// ErrInvalidObjectSortParameters is returned when invalid sort parameters
 	// were provided
 	ErrInvalidObjectSortParameters = errors.New("invalid sort parameters")
@@ -53,6 +117,7 @@ type (
 	// Object wraps an object.Object with its metadata.
 	Object struct {
 		Metadata ObjectUserMetadata `json:"metadata,omitempty"`
+		Tags     ObjectTags         `json:"tags,omitempty"`
 		ObjectMetadata
 		*object.Object
 	}
@@ -65,6 +130,30 @@ type (
 		Name     string      `json:"name"`
 		Size     int64       `json:"size"`
 		MimeType string      `json:"mimeType,omitempty"`
+
+		// VersionID is only set when an object is fetched individually, it
+		// is empty for objects that were uploaded while versioning wasn't
+		// enabled on their bucket.
+		VersionID string `json:"versionID,omitempty"`
+
+		// ChecksumAlgorithm and Checksum are only set when an object is
+		// fetched individually and was uploaded with a checksum. The
+		// checksum is base64 encoded, the same way S3 encodes it.
+		ChecksumAlgorithm string `json:"checksumAlgorithm,omitempty"`
+		Checksum          string `json:"checksum,omitempty"`
+
+		// CustomerKeyFingerprint is the fingerprint of the customer key the
+		// object was uploaded with, it's empty if the object was uploaded
+		// without one.
+		CustomerKeyFingerprint string `json:"customerKeyFingerprint,omitempty"`
+	}
+
+	// ObjectVersion describes a single version of an object, which is either
+	// a regular object or a delete marker.
+	ObjectVersion struct {
+		ObjectMetadata
+		IsDeleteMarker bool `json:"isDeleteMarker"`
+		IsLatest       bool `json:"isLatest"`
 	}
 
 	// ObjectUserMetadata contains user-defined metadata about an object and can
@@ -76,6 +165,11 @@ type (
 	// well
 	ObjectUserMetadata map[string]string
 
+	// ObjectTags are user-defined key/value pairs that can be attached to an
+	// object. Unlike the user metadata, tags can be updated without
+	// re-uploading or copying the object.
+	ObjectTags map[string]string
+
 	// ObjectsResponse is the response type for the /bus/objects endpoint.
 	ObjectsResponse struct {
 		HasMore bool             `json:"hasMore"`
@@ -83,6 +177,15 @@ type (
 		Object  *Object          `json:"object,omitempty"`
 	}
 
+	// DeleteObjectResponse is the response type for the DELETE /bus/objects
+	// endpoint.
+	DeleteObjectResponse struct {
+		// DeleteMarker is true if a delete marker was inserted in place of
+		// the object, VersionID is the version id of that marker.
+		DeleteMarker bool   `json:"deleteMarker"`
+		VersionID    string `json:"versionID,omitempty"`
+	}
+
 	// GetObjectResponse is the response type for the GET /worker/object endpoint.
 	GetObjectResponse struct {
 		Content io.ReadCloser `json:"content"`
@@ -97,6 +200,12 @@ type (
 		Range        *ContentRange
 		Size         int64
 		Metadata     ObjectUserMetadata
+		VersionID    string
+
+		ChecksumAlgorithm string
+		Checksum          string
+
+		CustomerKeyFingerprint string
 	}
 
 	// ObjectsDeleteRequest is the request type for the /bus/objects/list endpoint.
@@ -107,6 +216,10 @@ type (
 		SortDir string `json:"sortDir"`
 		Prefix  string `json:"prefix"`
 		Marker  string `json:"marker"`
+
+		// Tags filters the objects, only objects that have all of the tags
+		// are returned.
+		Tags ObjectTags `json:"tags,omitempty"`
 	}
 
 	// ObjectsListResponse is the response type for the /bus/objects/list endpoint.
@@ -116,6 +229,25 @@ type (
 		Objects    []ObjectMetadata `json:"objects"`
 	}
 
+	// ObjectVersionsRequest is the request type for the /bus/objects/versions
+	// endpoint.
+	ObjectVersionsRequest struct {
+		Bucket          string `json:"bucket"`
+		Limit           int    `json:"limit"`
+		Prefix          string `json:"prefix"`
+		KeyMarker       string `json:"keyMarker"`
+		VersionIDMarker string `json:"versionIDMarker"`
+	}
+
+	// ObjectVersionsResponse is the response type for the
+	// /bus/objects/versions endpoint.
+	ObjectVersionsResponse struct {
+		HasMore             bool            `json:"hasMore"`
+		NextKeyMarker       string          `json:"nextKeyMarker"`
+		NextVersionIDMarker string          `json:"nextVersionIDMarker"`
+		Versions            []ObjectVersion `json:"versions"`
+	}
+
 	// ObjectsRenameRequest is the request type for the /bus/objects/rename endpoint.
 	ObjectsRenameRequest struct {
 		Bucket string `json:"bucket"`
@@ -138,6 +270,9 @@ type (
 		TotalUnfinishedObjectsSize uint64  `json:"totalUnfinishedObjectsSize"` // size of all unfinished objects
 		TotalSectorsSize           uint64  `json:"totalSectorsSize"`           // uploaded size of all objects
 		TotalUploadedSize          uint64  `json:"totalUploadedSize"`          // uploaded size of all objects including redundant sectors
+
+		NumNoncurrentVersions       uint64 `json:"numNoncurrentVersions"`       // number of noncurrent versions, excluding delete markers
+		TotalNoncurrentVersionsSize uint64 `json:"totalNoncurrentVersionsSize"` // size of all noncurrent versions
 	}
 )
 
@@ -151,6 +286,49 @@ func ExtractObjectUserMetadataFrom(metadata map[string]string) ObjectUserMetadat
 	return oum
 }
 
+// ParseObjectTagQuery parses tags that were encoded as query values of the
+// form 'key:value'. The key can't contain a colon, the value can.
+func ParseObjectTagQuery(values []string) (ObjectTags, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	tags := make(ObjectTags)
+	for _, v := range values {
+		key, value, found := strings.Cut(v, ":")
+		if !found || key == "" {
+			return nil, fmt.Errorf("%w: tag '%s' is not of the form 'key:value'", ErrInvalidObjectTags, v)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// Validate returns an error if the tags exceed the limits on tags.
+func (t ObjectTags) Validate() error {
+	if len(t) > MaxObjectTags {
+		return fmt.Errorf("%w: an object can have at most %d tags", ErrInvalidObjectTags, MaxObjectTags)
+	}
+	for k, v := range t {
+		if k == "" {
+			return fmt.Errorf("%w: tag key can't be empty", ErrInvalidObjectTags)
+		} else if utf8.RuneCountInString(k) > MaxObjectTagKeyLen {
+			return fmt.Errorf("%w: tag key '%s' exceeds %d characters", ErrInvalidObjectTags, k, MaxObjectTagKeyLen)
+		} else if utf8.RuneCountInString(v) > MaxObjectTagValueLen {
+			return fmt.Errorf("%w: value of tag '%s' exceeds %d characters", ErrInvalidObjectTags, k, MaxObjectTagValueLen)
+		}
+	}
+	return nil
+}
+
+func (t ObjectTags) queryValues() []string {
+	values := make([]string, 0, len(t))
+	for k, v := range t {
+		values = append(values, k+":"+v)
+	}
+	sort.Strings(values)
+	return values
+}
+
 // ContentType returns the object's MimeType for use in the 'Content-Type'
 // header, if the object's mime type is empty we try and deduce it from the
 // extension in the object's name.
@@ -172,6 +350,18 @@ type (
 		ETag     string
 		MimeType string
 		Metadata ObjectUserMetadata
+
+		ChecksumAlgorithm string
+		Checksum          string
+
+		CustomerKeyFingerprint string
+
+		// Conditions are evaluated atomically against the object that is
+		// being overwritten, if any.
+		Conditions ObjectConditions
+
+		// Lock is the retention and legal hold the object is stored with.
+		Lock ObjectLock
 	}
 
 	// AddObjectRequest is the request type for the /bus/object/*key endpoint.
@@ -182,6 +372,14 @@ type (
 		ETag        string             `json:"eTag"`
 		MimeType    string             `json:"mimeType"`
 		Metadata    ObjectUserMetadata `json:"metadata"`
+
+		ChecksumAlgorithm string `json:"checksumAlgorithm,omitempty"`
+		Checksum          string `json:"checksum,omitempty"`
+
+		CustomerKeyFingerprint string `json:"customerKeyFingerprint,omitempty"`
+
+		Conditions ObjectConditions `json:"conditions"`
+		Lock       ObjectLock       `json:"lock"`
 	}
 
 	// CopyObjectOptions is the options type for the bus client.
@@ -203,17 +401,33 @@ type (
 	}
 
 	DeleteObjectOptions struct {
-		Batch bool
+		Batch     bool
+		VersionID string
 	}
 
 	HeadObjectOptions struct {
 		IgnoreDelim bool
 		Range       *DownloadRange
+		VersionID   string
+		CustomerKey *object.CustomerKey
+		Conditions  ObjectConditions
+	}
+
+	DownloadArchiveOptions struct {
+		// Format is the format of the archive, it defaults to zip.
+		Format      string
+		CustomerKey *object.CustomerKey
 	}
 
 	DownloadObjectOptions struct {
 		GetObjectOptions
-		Range *DownloadRange
+		Range       *DownloadRange
+		CustomerKey *object.CustomerKey
+		Conditions  ObjectConditions
+
+		// VerifyChecksum causes the download to fail if the downloaded
+		// content doesn't match the checksum that was computed on upload.
+		VerifyChecksum bool
 	}
 
 	GetObjectOptions struct {
@@ -225,6 +439,7 @@ type (
 		OnlyMetadata bool
 		SortBy       string
 		SortDir      string
+		VersionID    string
 	}
 
 	ListObjectOptions struct {
@@ -233,12 +448,21 @@ type (
 		Limit   int
 		SortBy  string
 		SortDir string
+		Tags    ObjectTags
+	}
+
+	ListObjectVersionsOptions struct {
+		Prefix          string
+		KeyMarker       string
+		VersionIDMarker string
+		Limit           int
 	}
 
 	SearchObjectOptions struct {
 		Key    string
 		Offset int
 		Limit  int
+		Tags   ObjectTags
 	}
 
 	// UploadObjectOptions is the options type for the worker client.
@@ -249,6 +473,24 @@ type (
 		ContentLength int64
 		MimeType      string
 		Metadata      ObjectUserMetadata
+
+		// ChecksumAlgorithm is the algorithm used to compute a checksum of
+		// the object's content while it is uploaded. If Checksum is set
+		// too, the upload fails if the computed checksum doesn't match.
+		ChecksumAlgorithm string
+		Checksum          string
+
+		// CustomerKey is mixed into the object's encryption key, the object
+		// can only be downloaded by providing the same key.
+		CustomerKey *object.CustomerKey
+
+		// Conditions allow for create-only uploads and optimistic
+		// concurrency when overwriting an object, they are evaluated
+		// atomically when the object is stored.
+		Conditions ObjectConditions
+
+		// Lock is the retention and legal hold the object is stored with.
+		Lock ObjectLock
 	}
 
 	UploadMultipartUploadPartOptions struct {
@@ -273,12 +515,23 @@ func (opts UploadObjectOptions) ApplyValues(values url.Values) {
 	if opts.MimeType != "" {
 		values.Set("mimetype", opts.MimeType)
 	}
+	if opts.ChecksumAlgorithm != "" {
+		values.Set("checksumalgorithm", opts.ChecksumAlgorithm)
+	}
 }
 
 func (opts UploadObjectOptions) ApplyHeaders(h http.Header) {
 	for k, v := range opts.Metadata {
 		h.Set(ObjectMetadataPrefix+k, v)
 	}
+	if opts.Checksum != "" {
+		h.Set(ObjectChecksumHeader, opts.Checksum)
+	}
+	if opts.CustomerKey != nil {
+		h.Set(ObjectCustomerKeyHeader, opts.CustomerKey.String())
+	}
+	opts.Conditions.ApplyHeaders(h)
+	opts.Lock.ApplyHeaders(h)
 }
 
 func (opts UploadMultipartUploadPartOptions) Apply(values url.Values) {
@@ -296,8 +549,23 @@ func (opts UploadMultipartUploadPartOptions) Apply(values url.Values) {
 	}
 }
 
+func (opts DownloadArchiveOptions) ApplyValues(values url.Values) {
+	if opts.Format != "" {
+		values.Set("format", opts.Format)
+	}
+}
+
+func (opts DownloadArchiveOptions) ApplyHeaders(h http.Header) {
+	if opts.CustomerKey != nil {
+		h.Set(ObjectCustomerKeyHeader, opts.CustomerKey.String())
+	}
+}
+
 func (opts DownloadObjectOptions) ApplyValues(values url.Values) {
 	opts.GetObjectOptions.Apply(values)
+	if opts.VerifyChecksum {
+		values.Set("verifychecksum", "true")
+	}
 }
 
 func (opts DownloadObjectOptions) ApplyHeaders(h http.Header) {
@@ -308,18 +576,28 @@ func (opts DownloadObjectOptions) ApplyHeaders(h http.Header) {
 			h.Set("Range", fmt.Sprintf("bytes=%v-%v", opts.Range.Offset, opts.Range.Offset+opts.Range.Length-1))
 		}
 	}
+	if opts.CustomerKey != nil {
+		h.Set(ObjectCustomerKeyHeader, opts.CustomerKey.String())
+	}
+	opts.Conditions.ApplyHeaders(h)
 }
 
 func (opts DeleteObjectOptions) Apply(values url.Values) {
 	if opts.Batch {
 		values.Set("batch", "true")
 	}
+	if opts.VersionID != "" {
+		values.Set("versionid", opts.VersionID)
+	}
 }
 
 func (opts HeadObjectOptions) Apply(values url.Values) {
 	if opts.IgnoreDelim {
 		values.Set("ignoreDelim", "true")
 	}
+	if opts.VersionID != "" {
+		values.Set("versionid", opts.VersionID)
+	}
 }
 
 func (opts HeadObjectOptions) ApplyHeaders(h http.Header) {
@@ -330,6 +608,10 @@ func (opts HeadObjectOptions) ApplyHeaders(h http.Header) {
 			h.Set("Range", fmt.Sprintf("bytes=%v-%v", opts.Range.Offset, opts.Range.Offset+opts.Range.Length-1))
 		}
 	}
+	if opts.CustomerKey != nil {
+		h.Set(ObjectCustomerKeyHeader, opts.CustomerKey.String())
+	}
+	opts.Conditions.ApplyHeaders(h)
 }
 
 func (opts GetObjectOptions) Apply(values url.Values) {
@@ -357,6 +639,9 @@ func (opts GetObjectOptions) Apply(values url.Values) {
 	if opts.SortDir != "" {
 		values.Set("sortDir", opts.SortDir)
 	}
+	if opts.VersionID != "" {
+		values.Set("versionid", opts.VersionID)
+	}
 }
 
 func (opts SearchObjectOptions) Apply(values url.Values) {
@@ -369,6 +654,9 @@ func (opts SearchObjectOptions) Apply(values url.Values) {
 	if opts.Limit != 0 {
 		values.Set("limit", fmt.Sprint(opts.Limit))
 	}
+	for _, tag := range opts.Tags.queryValues() {
+		values.Add("tag", tag)
+	}
 }
 
 func FormatETag(eTag string) string {
@@ -378,3 +666,32 @@ func FormatETag(eTag string) string {
 func ObjectPathEscape(path string) string {
 	return url.PathEscape(strings.TrimPrefix(path, "/"))
 }
+
+// CustomerKeyFromHeader parses the customer key from the given header, it
+// returns nil if the header isn't set.
+func CustomerKeyFromHeader(h http.Header) (*object.CustomerKey, error) {
+	v := h.Get(ObjectCustomerKeyHeader)
+	if v == "" {
+		return nil, nil
+	}
+	ck, err := object.ParseCustomerKey(v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCustomerKey, err)
+	}
+	return &ck, nil
+}
+
+// CheckCustomerKey checks whether the given customer key matches the
+// fingerprint of the key an object was uploaded with.
+func CheckCustomerKey(fingerprint string, ck *object.CustomerKey) error {
+	if fingerprint == "" && ck == nil {
+		return nil
+	} else if fingerprint == "" {
+		return fmt.Errorf("%w: object is not encrypted with a customer key", ErrCustomerKeyMismatch)
+	} else if ck == nil {
+		return ErrCustomerKeyRequired
+	} else if ck.Fingerprint() != fingerprint {
+		return ErrCustomerKeyMismatch
+	}
+	return nil
+}
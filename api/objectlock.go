@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// ObjectLockModeGovernance protects an object from being deleted or
+	// overwritten, the retention can be shortened or removed by bypassing the
+	// governance mode explicitly.
+	ObjectLockModeGovernance = "GOVERNANCE"
+
+	// ObjectLockModeCompliance protects an object from being deleted or
+	// overwritten, the retention can only be extended.
+	ObjectLockModeCompliance = "COMPLIANCE"
+)
+
+var (
+	// ErrObjectLocked is returned when an operation would delete, overwrite
+	// or rename an object that is under retention or a legal hold.
+	ErrObjectLocked = errors.New("object is locked")
+
+	// ErrInvalidObjectRetention is returned when an object's retention is not
+	// valid.
+	ErrInvalidObjectRetention = errors.New("invalid object retention")
+)
+
+type (
+	// ObjectLock contains the retention and legal hold of an object. An
+	// object is locked as long as its retention is active or it is under a
+	// legal hold.
+	ObjectLock struct {
+		Retention ObjectRetention `json:"retention"`
+		LegalHold bool            `json:"legalHold"`
+	}
+
+	// ObjectRetention protects an object until the retain until date. An
+	// empty mode means the object has no retention.
+	ObjectRetention struct {
+		Mode        string      `json:"mode,omitempty"`
+		RetainUntil TimeRFC3339 `json:"retainUntil"`
+	}
+
+	// ObjectLegalHold is the request type for the PUT /legalhold/*path
+	// endpoint.
+	ObjectLegalHold struct {
+		Enabled bool `json:"enabled"`
+	}
+
+	// ObjectRetentionRequest is the request type for the PUT /retention/*path
+	// endpoint.
+	ObjectRetentionRequest struct {
+		Retention        ObjectRetention `json:"retention"`
+		BypassGovernance bool            `json:"bypassGovernance"`
+	}
+)
+
+// ObjectLockFromHeader parses the lock an object is uploaded with from the
+// given header.
+func ObjectLockFromHeader(h http.Header) (lock ObjectLock, _ error) {
+	lock.Retention.Mode = h.Get(ObjectLockModeHeader)
+	if v := h.Get(ObjectLockRetainUntilHeader); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return ObjectLock{}, fmt.Errorf("%w: invalid retain until date '%s'", ErrInvalidObjectRetention, v)
+		}
+		lock.Retention.RetainUntil = TimeRFC3339(t)
+	}
+	if err := lock.Retention.Validate(); err != nil {
+		return ObjectLock{}, err
+	}
+	if v := h.Get(ObjectLockLegalHoldHeader); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return ObjectLock{}, fmt.Errorf("invalid legal hold '%s': %w", v, err)
+		}
+		lock.LegalHold = enabled
+	}
+	return lock, nil
+}
+
+// ApplyHeaders sets the headers that correspond to the lock.
+func (l ObjectLock) ApplyHeaders(h http.Header) {
+	if l.Retention.Mode != "" {
+		h.Set(ObjectLockModeHeader, l.Retention.Mode)
+	}
+	if !l.Retention.RetainUntil.IsZero() {
+		h.Set(ObjectLockRetainUntilHeader, l.Retention.RetainUntil.Std().Format(time.RFC3339Nano))
+	}
+	if l.LegalHold {
+		h.Set(ObjectLockLegalHoldHeader, "true")
+	}
+}
+
+// Locked returns true if the object can't be deleted, overwritten or renamed
+// at the given time.
+func (l ObjectLock) Locked(now time.Time) bool {
+	return l.LegalHold || l.Retention.Active(now)
+}
+
+// Active returns true if the retention protects the object at the given time.
+func (r ObjectRetention) Active(now time.Time) bool {
+	return r.Mode != "" && r.RetainUntil.Std().After(now)
+}
+
+// Validate returns an error if the retention is not valid.
+func (r ObjectRetention) Validate() error {
+	switch r.Mode {
+	case "":
+		if !r.RetainUntil.IsZero() {
+			return fmt.Errorf("%w: retain until date requires a mode", ErrInvalidObjectRetention)
+		}
+	case ObjectLockModeGovernance, ObjectLockModeCompliance:
+		if r.RetainUntil.IsZero() {
+			return fmt.Errorf("%w: mode requires a retain until date", ErrInvalidObjectRetention)
+		}
+	default:
+		return fmt.Errorf("%w: unknown mode '%s'", ErrInvalidObjectRetention, r.Mode)
+	}
+	return nil
+}
+
+// CheckUpdate returns an error if the retention can't be replaced with the
+// given one at the given time. An active retention can always be made
+// stricter, it can only be shortened, weakened or removed if it's in
+// governance mode and the governance mode is bypassed.
+func (r ObjectRetention) CheckUpdate(update ObjectRetention, bypassGovernance bool, now time.Time) error {
+	if !r.Active(now) {
+		return nil
+	} else if lockModeStrictness(update.Mode) >= lockModeStrictness(r.Mode) && !update.RetainUntil.Std().Before(r.RetainUntil.Std()) {
+		return nil
+	} else if r.Mode == ObjectLockModeGovernance && bypassGovernance {
+		return nil
+	}
+	return fmt.Errorf("%w: retention in %s mode until %v can't be shortened or removed", ErrObjectLocked, r.Mode, r.RetainUntil)
+}
+
+func lockModeStrictness(mode string) int {
+	switch mode {
+	case ObjectLockModeCompliance:
+		return 2
+	case ObjectLockModeGovernance:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestObjectRetentionValidate(t *testing.T) {
+	until := TimeRFC3339(time.Now().Add(time.Hour))
+	tests := []struct {
+		retention ObjectRetention
+		valid     bool
+	}{
+		{ObjectRetention{}, true},
+		{ObjectRetention{Mode: ObjectLockModeGovernance, RetainUntil: until}, true},
+		{ObjectRetention{Mode: ObjectLockModeCompliance, RetainUntil: until}, true},
+		{ObjectRetention{RetainUntil: until}, false},
+		{ObjectRetention{Mode: ObjectLockModeGovernance}, false},
+		{ObjectRetention{Mode: "foo", RetainUntil: until}, false},
+	}
+	for i, test := range tests {
+		if err := test.retention.Validate(); (err == nil) != test.valid {
+			t.Fatalf("%d: unexpected error %v", i, err)
+		} else if err != nil && !errors.Is(err, ErrInvalidObjectRetention) {
+			t.Fatalf("%d: expected ErrInvalidObjectRetention, got %v", i, err)
+		}
+	}
+}
+
+func TestObjectRetentionCheckUpdate(t *testing.T) {
+	now := time.Now()
+	early := TimeRFC3339(now.Add(time.Minute))
+	late := TimeRFC3339(now.Add(time.Hour))
+	expired := TimeRFC3339(now.Add(-time.Minute))
+
+	none := ObjectRetention{}
+	governance := ObjectRetention{Mode: ObjectLockModeGovernance, RetainUntil: late}
+	compliance := ObjectRetention{Mode: ObjectLockModeCompliance, RetainUntil: late}
+
+	tests := []struct {
+		current ObjectRetention
+		update  ObjectRetention
+		bypass  bool
+		allowed bool
+	}{
+		// objects without an active retention can always be updated
+		{none, governance, false, true},
+		{ObjectRetention{Mode: ObjectLockModeCompliance, RetainUntil: expired}, none, false, true},
+
+		// active retentions can be extended or made stricter
+		{ObjectRetention{Mode: ObjectLockModeGovernance, RetainUntil: early}, governance, false, true},
+		{governance, compliance, false, true},
+
+		// governance can only be shortened or removed when bypassed
+		{governance, ObjectRetention{Mode: ObjectLockModeGovernance, RetainUntil: early}, false, false},
+		{governance, none, false, false},
+		{governance, none, true, true},
+
+		// compliance can never be shortened, weakened or removed
+		{compliance, ObjectRetention{Mode: ObjectLockModeCompliance, RetainUntil: early}, true, false},
+		{compliance, governance, true, false},
+		{compliance, none, true, false},
+	}
+	for i, test := range tests {
+		if err := test.current.CheckUpdate(test.update, test.bypass, now); (err == nil) != test.allowed {
+			t.Fatalf("%d: unexpected error %v", i, err)
+		} else if err != nil && !errors.Is(err, ErrObjectLocked) {
+			t.Fatalf("%d: expected ErrObjectLocked, got %v", i, err)
+		}
+	}
+
+	// legal holds lock objects regardless of their retention
+	if !(ObjectLock{LegalHold: true}).Locked(now) {
+		t.Fatal("expected object to be locked")
+	} else if !(ObjectLock{Retention: governance}).Locked(now) {
+		t.Fatal("expected object to be locked")
+	} else if (ObjectLock{Retention: governance}).Locked(late.Std()) {
+		t.Fatal("expected object to be unlocked")
+	}
+}
+
+func TestObjectLockHeaders(t *testing.T) {
+	lock := ObjectLock{
+		Retention: ObjectRetention{Mode: ObjectLockModeGovernance, RetainUntil: TimeRFC3339(time.Now().Add(time.Hour))},
+		LegalHold: true,
+	}
+	h := make(http.Header)
+	lock.ApplyHeaders(h)
+	if got, err := ObjectLockFromHeader(h); err != nil {
+		t.Fatal(err)
+	} else if got.Retention.Mode != lock.Retention.Mode || !got.Retention.RetainUntil.Std().Equal(lock.Retention.RetainUntil.Std()) || !got.LegalHold {
+		t.Fatal("unexpected lock", got)
+	}
+
+	// no headers means no lock
+	if got, err := ObjectLockFromHeader(make(http.Header)); err != nil {
+		t.Fatal(err)
+	} else if got.Locked(time.Now()) {
+		t.Fatal("unexpected lock", got)
+	}
+
+	// invalid retentions are rejected
+	h = make(http.Header)
+	h.Set(ObjectLockModeHeader, ObjectLockModeCompliance)
+	if _, err := ObjectLockFromHeader(h); !errors.Is(err, ErrInvalidObjectRetention) {
+		t.Fatal("expected ErrInvalidObjectRetention", err)
+	}
+	h.Set(ObjectLockRetainUntilHeader, "tomorrow")
+	if _, err := ObjectLockFromHeader(h); !errors.Is(err, ErrInvalidObjectRetention) {
+		t.Fatal("expected ErrInvalidObjectRetention", err)
+	}
+}
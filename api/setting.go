@@ -3,6 +3,7 @@ package api
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	rhpv2 "go.thebigfile.com/core/rhp/v2"
@@ -10,6 +11,7 @@ import (
 )
 
 const (
+	SettingBandwidth        = "bandwidth"
 	SettingContractSet      = "contractset"
 	SettingGouging          = "gouging"
 	SettingPricePinning     = "pricepinning"
@@ -24,6 +26,20 @@ const (
 	S3SecretKeyLen    = 40
 )
 
+const (
+	S3ActionDelete = "delete"
+	S3ActionList   = "list"
+	S3ActionRead   = "read"
+	S3ActionWrite  = "write"
+
+	// S3ActionBypassGovernance allows shortening or removing an object's
+	// retention in governance mode, it's not implied by any other action.
+	S3ActionBypassGovernance = "bypassGovernance"
+
+	// S3BucketWildcard matches all buckets in an access grant.
+	S3BucketWildcard = "*"
+)
+
 var (
 	// ErrInvalidRedundancySettings is returned if the redundancy settings are
 	// not valid
@@ -33,6 +49,14 @@ var (
 	// database.
 	ErrSettingNotFound = errors.New("setting not found")
 
+	// ErrS3AccessKeyNotFound is returned if a policy is set for an access key
+	// that doesn't exist.
+	ErrS3AccessKeyNotFound = errors.New("s3 access key not found")
+
+	// ErrS3PolicyNotFound is returned if a requested access key doesn't have
+	// a policy.
+	ErrS3PolicyNotFound = errors.New("s3 access key policy not found")
+
 	// DefaultGougingSettings define the default gouging settings the bus is
 	// configured with on startup. These values can be adjusted using the
 	// settings API.
@@ -86,6 +110,22 @@ var (
 )
 
 type (
+	// BandwidthSettings limit the bandwidth the workers use for object
+	// downloads and uploads. A transfer is limited by the global limit, the
+	// limit of its bucket and the per-request limit at the same time.
+	BandwidthSettings struct {
+		Global     BandwidthLimits            `json:"global"`
+		PerRequest BandwidthLimits            `json:"perRequest"`
+		Buckets    map[string]BandwidthLimits `json:"buckets,omitempty"`
+	}
+
+	// BandwidthLimits contain the maximum download and upload speeds in bytes
+	// per second, a limit of 0 means the speed is not limited.
+	BandwidthLimits struct {
+		Download uint64 `json:"download"`
+		Upload   uint64 `json:"upload"`
+	}
+
 	// ContractSetSetting contains the default contract set used by the worker for
 	// uploads and migrations.
 	ContractSetSetting struct {
@@ -189,6 +229,25 @@ type (
 	// S3AuthenticationSettings contains S3 auth settings.
 	S3AuthenticationSettings struct {
 		V4Keypairs map[string]string `json:"v4Keypairs"`
+
+		// Policies restricts the access of individual access keys, keys
+		// without a policy have full access to all buckets.
+		Policies map[string]S3AccessKeyPolicy `json:"policies,omitempty"`
+	}
+
+	// S3AccessKeyPolicy contains the grants of an access key. An access key
+	// with a policy is only allowed to perform the actions that are granted
+	// explicitly.
+	S3AccessKeyPolicy struct {
+		Grants []S3AccessGrant `json:"grants"`
+	}
+
+	// S3AccessGrant grants a set of actions on all objects within a bucket
+	// whose key starts with the given prefix.
+	S3AccessGrant struct {
+		Bucket  string   `json:"bucket"`
+		Prefix  string   `json:"prefix"`
+		Actions []string `json:"actions"`
 	}
 
 	// UploadPackingSettings contains upload packing settings.
@@ -198,6 +257,17 @@ type (
 	}
 )
 
+// Validate returns an error if the bandwidth settings are not considered
+// valid.
+func (bs BandwidthSettings) Validate() error {
+	for bucket := range bs.Buckets {
+		if bucket == "" {
+			return errors.New("bandwidth limits must have a bucket name")
+		}
+	}
+	return nil
+}
+
 // IsPinned returns true if the pin is enabled and the value is greater than 0.
 func (p Pin) IsPinned() bool {
 	return p.Pinned && p.Value > 0
@@ -270,6 +340,55 @@ func (rs RedundancySettings) Validate() error {
 	return nil
 }
 
+// Allows returns true if the policy grants the given action on the key within
+// the bucket. Bucket-level operations are checked by passing an empty key, in
+// which case the grant must not be restricted to a prefix.
+func (p S3AccessKeyPolicy) Allows(action, bucket, key string) bool {
+	for _, g := range p.Grants {
+		if g.Bucket != S3BucketWildcard && g.Bucket != bucket {
+			continue
+		} else if !strings.HasPrefix(key, g.Prefix) {
+			continue
+		}
+		for _, a := range g.Actions {
+			if a == action {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AllowsBucket returns true if the policy grants any action within the given
+// bucket.
+func (p S3AccessKeyPolicy) AllowsBucket(bucket string) bool {
+	for _, g := range p.Grants {
+		if g.Bucket == S3BucketWildcard || g.Bucket == bucket {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the policy is not considered valid.
+func (p S3AccessKeyPolicy) Validate() error {
+	for i, g := range p.Grants {
+		if g.Bucket == "" {
+			return fmt.Errorf("grant %d: bucket cannot be empty", i)
+		} else if len(g.Actions) == 0 {
+			return fmt.Errorf("grant %d: no actions specified", i)
+		}
+		for _, a := range g.Actions {
+			switch a {
+			case S3ActionBypassGovernance, S3ActionDelete, S3ActionList, S3ActionRead, S3ActionWrite:
+			default:
+				return fmt.Errorf("grant %d: unknown action '%s'", i, a)
+			}
+		}
+	}
+	return nil
+}
+
 // Validate returns an error if the authentication settings are not considered
 // valid.
 func (s3as S3AuthenticationSettings) Validate() error {
@@ -284,5 +403,12 @@ func (s3as S3AuthenticationSettings) Validate() error {
 			return fmt.Errorf("SecretAccessKey must be %d characters long but was %d", S3SecretKeyLen, len(secretAccessKey))
 		}
 	}
+	for accessKeyID, policy := range s3as.Policies {
+		if _, exists := s3as.V4Keypairs[accessKeyID]; !exists {
+			return fmt.Errorf("policy for unknown AccessKeyID %s", accessKeyID)
+		} else if err := policy.Validate(); err != nil {
+			return fmt.Errorf("invalid policy for AccessKeyID %s: %w", accessKeyID, err)
+		}
+	}
 	return nil
 }
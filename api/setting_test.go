@@ -0,0 +1,54 @@
+package api
+
+import "testing"
+
+func TestS3AccessKeyPolicy(t *testing.T) {
+	policy := S3AccessKeyPolicy{
+		Grants: []S3AccessGrant{
+			{Bucket: "photos", Prefix: "public/", Actions: []string{S3ActionRead, S3ActionList}},
+			{Bucket: "photos", Prefix: "uploads/", Actions: []string{S3ActionWrite}},
+			{Bucket: S3BucketWildcard, Prefix: "shared/", Actions: []string{S3ActionDelete}},
+		},
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		action string
+		bucket string
+		key    string
+		want   bool
+	}{
+		{S3ActionRead, "photos", "public/cat.jpg", true},
+		{S3ActionRead, "photos", "private/cat.jpg", false},
+		{S3ActionRead, "videos", "public/cat.jpg", false},
+		{S3ActionWrite, "photos", "uploads/cat.jpg", true},
+		{S3ActionWrite, "photos", "public/cat.jpg", false},
+		{S3ActionBypassGovernance, "photos", "uploads/cat.jpg", false},
+		{S3ActionDelete, "videos", "shared/dog.mp4", true},
+		{S3ActionDelete, "videos", "dog.mp4", false},
+		{S3ActionList, "photos", "", false},
+	}
+	for _, test := range tests {
+		if got := policy.Allows(test.action, test.bucket, test.key); got != test.want {
+			t.Errorf("Allows(%s, %s, %s) = %v, want %v", test.action, test.bucket, test.key, got, test.want)
+		}
+	}
+
+	if !policy.AllowsBucket("videos") {
+		t.Fatal("expected wildcard grant to allow bucket")
+	}
+
+	// assert invalid policies are rejected
+	invalid := []S3AccessKeyPolicy{
+		{Grants: []S3AccessGrant{{Prefix: "foo/", Actions: []string{S3ActionRead}}}},
+		{Grants: []S3AccessGrant{{Bucket: "photos"}}},
+		{Grants: []S3AccessGrant{{Bucket: "photos", Actions: []string{"admin"}}}},
+	}
+	for i, p := range invalid {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("expected policy %d to be invalid", i)
+		}
+	}
+}
@@ -3,8 +3,6 @@ package api
 import (
 	"errors"
 	"fmt"
-	"math"
-	"net/http"
 	"strconv"
 	"strings"
 
@@ -26,10 +24,6 @@ var (
 	// ErrHostOnPrivateNetwork is returned by the worker API when a host can't
 	// be scanned since it is on a private network.
 	ErrHostOnPrivateNetwork = errors.New("host is on a private network")
-
-	// ErrMultiRangeNotSupported is returned by the worker API when a request
-	// tries to download multiple ranges at once.
-	ErrMultiRangeNotSupported = errors.New("multipart ranges are not supported")
 )
 
 type (
@@ -139,6 +133,7 @@ type (
 		AvgOverdrivePct      float64           `json:"avgOverdrivePct"`
 		HealthyDownloaders   uint64            `json:"healthyDownloaders"`
 		NumDownloaders       uint64            `json:"numDownloaders"`
+		ThroughputMBPS       float64           `json:"throughputMbps"`
 		DownloadersStats     []DownloaderStats `json:"downloadersStats"`
 	}
 	DownloaderStats struct {
@@ -153,6 +148,7 @@ type (
 		AvgOverdrivePct        float64         `json:"avgOverdrivePct"`
 		HealthyUploaders       uint64          `json:"healthyUploaders"`
 		NumUploaders           uint64          `json:"numUploaders"`
+		ThroughputMBPS         float64         `json:"throughputMbps"`
 		UploadersStats         []UploaderStats `json:"uploadersStats"`
 	}
 	UploaderStats struct {
@@ -199,6 +195,12 @@ func (r *DownloadRange) ContentRange(size int64) *ContentRange {
 	}
 }
 
+// String returns the range formatted as the value of a "Content-Range"
+// header.
+func (cr ContentRange) String() string {
+	return fmt.Sprintf("bytes %d-%d/%d", cr.Offset, cr.Offset+cr.Length-1, cr.Size)
+}
+
 func ParseContentRange(contentRange string) (ContentRange, error) {
 	parts := strings.Split(contentRange, " ")
 	if len(parts) != 2 || parts[0] != "bytes" {
@@ -232,22 +234,27 @@ func ParseContentRange(contentRange string) (ContentRange, error) {
 	}, nil
 }
 
-func ParseDownloadRange(req *http.Request) (DownloadRange, error) {
-	// parse the request range we pass math.MaxInt64 since a range header in a
-	// request doesn't have a size
-	ranges, err := http_range.ParseRange(req.Header.Get("Range"), math.MaxInt64)
+// ParseDownloadRanges parses the ranges of a "Range" header against the size
+// of the object. Suffix ranges are resolved to the last bytes of the object and
+// ranges that exceed the object are truncated. Like net/http, the ranges are
+// ignored if they add up to more than the object's size, in which case the
+// whole object should be served.
+func ParseDownloadRanges(header string, size int64) ([]DownloadRange, error) {
+	ranges, err := http_range.ParseRange(header, size)
 	if err != nil {
-		return DownloadRange{}, err
+		return nil, err
 	}
 
-	// extract requested offset and length
-	dr := DownloadRange{Offset: 0, Length: -1}
-	if len(ranges) == 1 {
-		dr.Offset, dr.Length = ranges[0].Start, ranges[0].Length
-	} else if len(ranges) > 1 {
-		return DownloadRange{}, ErrMultiRangeNotSupported
+	var total int64
+	drs := make([]DownloadRange, 0, len(ranges))
+	for _, r := range ranges {
+		total += r.Length
+		drs = append(drs, DownloadRange{Offset: r.Start, Length: r.Length})
+	}
+	if total > size {
+		return nil, nil
 	}
-	return dr, nil
+	return drs, nil
 }
 
 func (r RHPScanResponse) Error() error {
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gotd/contrib/http_range"
+)
+
+func TestParseDownloadRanges(t *testing.T) {
+	tests := []struct {
+		header string
+		ranges []DownloadRange
+		err    error
+	}{
+		{"", nil, nil},
+		{"bytes=0-9", []DownloadRange{{0, 10}}, nil},
+		{"bytes=90-", []DownloadRange{{90, 10}}, nil},
+		{"bytes=90-200", []DownloadRange{{90, 10}}, nil},
+		{"bytes=-10", []DownloadRange{{90, 10}}, nil},
+		{"bytes=-200", []DownloadRange{{0, 100}}, nil},
+		{"bytes=0-9, 20-29, -10", []DownloadRange{{0, 10}, {20, 10}, {90, 10}}, nil},
+		{"bytes=0-99, 0-99", nil, nil}, // exceeds the object's size
+		{"bytes=100-", nil, http_range.ErrNoOverlap},
+		{"bytes=9-0", nil, http_range.ErrInvalid},
+		{"items=0-9", nil, http_range.ErrInvalid},
+	}
+	for _, test := range tests {
+		ranges, err := ParseDownloadRanges(test.header, 100)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("%q: unexpected error %v, expected %v", test.header, err, test.err)
+		} else if len(ranges) != len(test.ranges) || (len(ranges) > 0 && !reflect.DeepEqual(ranges, test.ranges)) {
+			t.Fatalf("%q: unexpected ranges %v, expected %v", test.header, ranges, test.ranges)
+		}
+	}
+}
@@ -7,14 +7,17 @@ import (
 
 	"go.thebigfile.com/core/types"
 	"go.thebigfile.com/renterd/alerts"
+	"go.thebigfile.com/renterd/api"
 	"go.thebigfile.com/renterd/object"
 )
 
 var (
-	alertHealthRefreshID = alerts.RandomAlertID() // constant until restarted
-	alertLowBalanceID    = alerts.RandomAlertID() // constant until restarted
-	alertMigrationID     = alerts.RandomAlertID() // constant until restarted
-	alertPruningID       = alerts.RandomAlertID() // constant until restarted
+	alertBucketRedundancyID = alerts.RandomAlertID() // constant until restarted
+	alertHealthRefreshID    = alerts.RandomAlertID() // constant until restarted
+	alertLowBalanceID       = alerts.RandomAlertID() // constant until restarted
+	alertMigrationID        = alerts.RandomAlertID() // constant until restarted
+	alertPruningID          = alerts.RandomAlertID() // constant until restarted
+	alertSpendingForecastID = alerts.RandomAlertID() // constant until restarted
 )
 
 func (ap *Autopilot) RegisterAlert(ctx context.Context, a alerts.Alert) {
@@ -51,6 +54,33 @@ func newAccountLowBalanceAlert(address types.Address, balance, allowance types.C
 	}
 }
 
+func newSpendingForecastAlert(f api.SpendingForecast) alerts.Alert {
+	severity := alerts.SeverityWarning
+	if f.Spent.Cmp(f.Allowance) >= 0 {
+		severity = alerts.SeverityCritical
+	}
+
+	data := map[string]any{
+		"allowance":         f.Allowance,
+		"spent":             f.Spent,
+		"projectedSpending": f.ProjectedSpending,
+		"spendingRate":      f.SpendingRate,
+		"periodEnd":         f.PeriodEnd,
+		"hint":              fmt.Sprintf("At the current rate, spending in this period is projected to reach %v, which exceeds the configured allowance of %v. Consider increasing the allowance to avoid contracts not being refreshed or renewed.", f.ProjectedSpending, f.Allowance),
+	}
+	if f.ExhaustedAt != nil {
+		data["exhaustedAt"] = *f.ExhaustedAt
+	}
+
+	return alerts.Alert{
+		ID:        alertSpendingForecastID,
+		Severity:  severity,
+		Message:   "Allowance is projected to be exceeded",
+		Data:      data,
+		Timestamp: time.Now(),
+	}
+}
+
 func newContractPruningFailedAlert(hk types.PublicKey, version, release string, fcid types.FileContractID, err error) alerts.Alert {
 	return alerts.Alert{
 		ID:       alerts.IDForContract(alertPruningID, fcid),
@@ -142,6 +172,23 @@ func newMigrationFailedAlert(slabKey object.EncryptionKey, health float64, objec
 	}
 }
 
+func newBucketRedundancyAlert(bucket, set string, rs api.RedundancySettings, contracts int) alerts.Alert {
+	return alerts.Alert{
+		ID:       alerts.IDForBucket(alertBucketRedundancyID, bucket),
+		Severity: alerts.SeverityWarning,
+		Message:  "Contract set too small for bucket redundancy",
+		Data: map[string]interface{}{
+			"bucket":      bucket,
+			"contractSet": set,
+			"contracts":   contracts,
+			"minShards":   rs.MinShards,
+			"totalShards": rs.TotalShards,
+			"hint":        "Slabs are migrated with the redundancy of the bucket they were uploaded to, slabs of this bucket can't be fully repaired until the contract set contains at least as many contracts as the bucket's total shards.",
+		},
+		Timestamp: time.Now(),
+	}
+}
+
 func newRefreshHealthFailedAlert(err error) alerts.Alert {
 	return alerts.Alert{
 		ID:       alertHealthRefreshID,
@@ -1,11 +1,14 @@
 package autopilot
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -61,6 +64,7 @@ type Bus interface {
 	// metrics
 	RecordContractSetChurnMetric(ctx context.Context, metrics ...api.ContractSetChurnMetric) error
 	RecordContractPruneMetric(ctx context.Context, metrics ...api.ContractPruneMetric) error
+	ContractMetrics(ctx context.Context, start time.Time, n uint64, interval time.Duration, opts api.ContractMetricsQueryOpts) ([]api.ContractMetric, error)
 
 	// buckets
 	ListBuckets(ctx context.Context) ([]api.Bucket, error)
@@ -103,6 +107,9 @@ type Autopilot struct {
 	m *migrator
 	s scanner.Scanner
 
+	geoIP      *contractor.GeoIPDatabase
+	hostScorer contractor.HostScorer
+
 	tickerDuration time.Duration
 	wg             sync.WaitGroup
 
@@ -119,10 +126,26 @@ type Autopilot struct {
 	pruningAlertIDs  map[types.FileContractID]types.Hash256
 
 	maintenanceTxnIDs []types.TransactionID
+
+	forecastMu      sync.Mutex
+	forecast        api.SpendingForecast
+	forecastKey     types.Hash256
+	forecastUpdated time.Time
+}
+
+// Option is an option that can be passed to New.
+type Option func(*Autopilot)
+
+// WithHostScorer configures the autopilot to score hosts using the given
+// scorer, it takes precedence over the scorer URL in the config.
+func WithHostScorer(hs contractor.HostScorer) Option {
+	return func(ap *Autopilot) {
+		ap.hostScorer = hs
+	}
 }
 
 // New initializes an Autopilot.
-func New(cfg config.Autopilot, bus Bus, workers []Worker, logger *zap.Logger) (_ *Autopilot, err error) {
+func New(cfg config.Autopilot, bus Bus, workers []Worker, logger *zap.Logger, opts ...Option) (_ *Autopilot, err error) {
 	logger = logger.Named("autopilot").Named(cfg.ID)
 	shutdownCtx, shutdownCtxCancel := context.WithCancel(context.Background())
 	ap := &Autopilot{
@@ -145,6 +168,21 @@ func New(cfg config.Autopilot, bus Bus, workers []Worker, logger *zap.Logger) (_
 		return
 	}
 
+	if cfg.GeoIPDatabase != "" {
+		ap.geoIP, err = contractor.LoadGeoIPDatabase(cfg.GeoIPDatabase)
+		if err != nil {
+			err = fmt.Errorf("failed to load GeoIP database: %w", err)
+			return
+		}
+	}
+
+	if cfg.HostScorerURL != "" {
+		ap.hostScorer = contractor.NewHTTPHostScorer(cfg.HostScorerURL)
+	}
+	for _, opt := range opts {
+		opt(ap)
+	}
+
 	ap.c = contractor.New(bus, bus, ap.logger, cfg.RevisionSubmissionBuffer, cfg.RevisionBroadcastInterval)
 	ap.m = newMigrator(ap, cfg.MigrationHealthCutoff, cfg.MigratorParallelSlabsPerWorker)
 
@@ -158,13 +196,15 @@ func (ap *Autopilot) Config(ctx context.Context) (api.Autopilot, error) {
 // Handler returns an HTTP handler that serves the autopilot api.
 func (ap *Autopilot) Handler() http.Handler {
 	return jape.Mux(map[string]jape.Handler{
-		"GET    /config":        ap.configHandlerGET,
-		"PUT    /config":        ap.configHandlerPUT,
-		"POST   /config":        ap.configHandlerPOST,
-		"POST   /hosts":         ap.hostsHandlerPOST,
-		"GET    /host/:hostKey": ap.hostHandlerGET,
-		"GET    /state":         ap.stateHandlerGET,
-		"POST   /trigger":       ap.triggerHandlerPOST,
+		"GET    /config":            ap.configHandlerGET,
+		"PUT    /config":            ap.configHandlerPUT,
+		"POST   /config":            ap.configHandlerPOST,
+		"POST   /hosts":             ap.hostsHandlerPOST,
+		"GET    /host/:hostKey":     ap.hostHandlerGET,
+		"POST   /maintenance/plan":  ap.maintenancePlanHandlerPOST,
+		"GET    /spending/forecast": ap.spendingForecastHandlerGET,
+		"GET    /state":             ap.stateHandlerGET,
+		"POST   /trigger":           ap.triggerHandlerPOST,
 	})
 }
 
@@ -197,7 +237,7 @@ func (ap *Autopilot) configHandlerPOST(jc jape.Context) {
 	}
 
 	// evaluate the config
-	res, err := contractor.EvaluateConfig(reqCfg, cs, fee, rs, gs, hosts)
+	res, err := contractor.EvaluateConfig(ctx, reqCfg, cs, fee, rs, gs, hosts, ap.hostScorer, ap.logger.Named("evaluateConfig"))
 	if errors.Is(err, contractor.ErrMissingRequiredFields) {
 		jc.Error(err, http.StatusBadRequest)
 		return
@@ -208,6 +248,50 @@ func (ap *Autopilot) configHandlerPOST(jc jape.Context) {
 	jc.Encode(res)
 }
 
+func (ap *Autopilot) maintenancePlanHandlerPOST(jc jape.Context) {
+	ctx := jc.Request.Context()
+
+	// decode and validate the request
+	var req api.MaintenancePlanRequest
+	if jc.Decode(&req) != nil {
+		return
+	} else if req.AutopilotConfig != nil {
+		if err := req.AutopilotConfig.Validate(); err != nil {
+			jc.Error(fmt.Errorf("invalid autopilot config: %w", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// build the maintenance state, applying the config if given
+	state, err := ap.buildState(ctx)
+	if jc.Check("failed to build state", err) != nil {
+		return
+	} else if req.AutopilotConfig != nil {
+		state.AP.Config = *req.AutopilotConfig
+	}
+
+	// perform contract maintenance in dry-run mode
+	var plan api.MaintenancePlan
+	ap.workers.withWorker(func(w Worker) {
+		plan, err = ap.c.PlanContractMaintenance(ctx, w, state)
+	})
+	if errors.Is(err, contractor.ErrMaintenanceSkipped) {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	} else if jc.Check("failed to plan contract maintenance", err) != nil {
+		return
+	}
+	jc.Encode(plan)
+}
+
+func (ap *Autopilot) spendingForecastHandlerGET(jc jape.Context) {
+	forecast, err := ap.spendingForecast(jc.Request.Context())
+	if jc.Check("failed to forecast spending", err) != nil {
+		return
+	}
+	jc.Encode(forecast)
+}
+
 func (ap *Autopilot) Run() {
 	ap.startStopMu.Lock()
 	if ap.isRunning() {
@@ -297,6 +381,12 @@ func (ap *Autopilot) Run() {
 				ap.logger.Errorf("wallet maintenance failed, err: %v", err)
 			}
 
+			// forecast spending
+			err = ap.performSpendingForecast(ap.shutdownCtx)
+			if err != nil {
+				ap.logger.Errorf("spending forecast failed, err: %v", err)
+			}
+
 			// build maintenance state
 			state, err := ap.buildState(ap.shutdownCtx)
 			if err != nil {
@@ -646,6 +736,148 @@ func (ap *Autopilot) performWalletMaintenance(ctx context.Context) error {
 	return nil
 }
 
+func (ap *Autopilot) performSpendingForecast(ctx context.Context) error {
+	if ap.isStopped() {
+		return nil
+	}
+
+	autopilot, err := ap.Config(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch autopilot config: %w", err)
+	} else if autopilot.Config.Contracts.Allowance.IsZero() {
+		return nil // no allowance - nothing to forecast
+	}
+
+	forecast, err := ap.spendingForecast(ctx)
+	if err != nil {
+		return err
+	}
+
+	// register an alert if the allowance is projected to be exceeded
+	if forecast.BreachesAllowance {
+		ap.RegisterAlert(ctx, newSpendingForecastAlert(forecast))
+	} else {
+		ap.DismissAlert(ctx, alertSpendingForecastID)
+	}
+	return nil
+}
+
+func (ap *Autopilot) spendingForecast(ctx context.Context) (api.SpendingForecast, error) {
+	autopilot, err := ap.Config(ctx)
+	if err != nil {
+		return api.SpendingForecast{}, fmt.Errorf("failed to fetch autopilot config: %w", err)
+	}
+	cs, err := ap.bus.ConsensusState(ctx)
+	if err != nil {
+		return api.SpendingForecast{}, fmt.Errorf("failed to fetch consensus state: %w", err)
+	}
+	fee, err := ap.bus.RecommendedFee(ctx)
+	if err != nil {
+		return api.SpendingForecast{}, fmt.Errorf("failed to fetch recommended fee: %w", err)
+	}
+
+	// fetch contracts and their hosts
+	contracts, err := ap.bus.Contracts(ctx, api.ContractsOpts{})
+	if err != nil {
+		return api.SpendingForecast{}, fmt.Errorf("failed to fetch contracts: %w", err)
+	}
+
+	// fetching the metrics of every contract and its ancestors is expensive,
+	// so the forecast is only recomputed when the config, the period or the
+	// contracts change or once per forecast interval
+	ap.forecastMu.Lock()
+	defer ap.forecastMu.Unlock()
+	key, err := spendingForecastKey(autopilot, contracts)
+	if err != nil {
+		return api.SpendingForecast{}, err
+	} else if key == ap.forecastKey && time.Since(ap.forecastUpdated) < contractor.ForecastInterval {
+		return ap.forecast, nil
+	}
+
+	hks := make([]types.PublicKey, 0, len(contracts))
+	for _, c := range contracts {
+		hks = append(hks, c.HostKey)
+	}
+	var hosts []api.Host
+	if len(hks) > 0 {
+		hosts, err = ap.bus.SearchHosts(ctx, api.SearchHostOptions{KeyIn: hks, Limit: -1, FilterMode: api.HostFilterModeAll})
+		if err != nil {
+			return api.SpendingForecast{}, fmt.Errorf("failed to fetch hosts: %w", err)
+		}
+	}
+
+	// fetch the ancestors of the contracts that were renewed within the
+	// forecast window, renewals and refreshes reset the spending of a
+	// contract so the metrics of the whole lineage are needed
+	windowStartHeight := contractor.ForecastWindowStartHeight(cs.BlockHeight)
+	var ancestors []api.ArchivedContract
+	fcids := make([]types.FileContractID, 0, len(contracts))
+	for _, c := range contracts {
+		fcids = append(fcids, c.ID)
+		if c.RenewedFrom == (types.FileContractID{}) || c.StartHeight < windowStartHeight {
+			continue
+		}
+		archived, err := ap.bus.AncestorContracts(ctx, c.ID, windowStartHeight)
+		if err != nil {
+			return api.SpendingForecast{}, fmt.Errorf("failed to fetch ancestors of contract %v: %w", c.ID, err)
+		}
+
+		// the oldest ancestor within the window was renewed from the
+		// contract that was active at the start of the window
+		oldest := api.ArchivedContract{RenewedFrom: c.RenewedFrom, StartHeight: c.StartHeight}
+		for _, a := range archived {
+			fcids = append(fcids, a.ID)
+			if a.StartHeight < oldest.StartHeight {
+				oldest = a
+			}
+		}
+		if oldest.RenewedFrom != (types.FileContractID{}) {
+			fcids = append(fcids, oldest.RenewedFrom)
+		}
+		ancestors = append(ancestors, archived...)
+	}
+
+	// fetch the metrics of every contract within the forecast window
+	now := time.Now()
+	start := now.Add(-contractor.ForecastWindow * contractor.ForecastInterval)
+	var metrics []api.ContractMetric
+	for _, fcid := range fcids {
+		cms, err := ap.bus.ContractMetrics(ctx, start, contractor.ForecastWindow+1, contractor.ForecastInterval, api.ContractMetricsQueryOpts{ContractID: fcid})
+		if err != nil {
+			return api.SpendingForecast{}, fmt.Errorf("failed to fetch metrics of contract %v: %w", fcid, err)
+		}
+		metrics = append(metrics, cms...)
+	}
+
+	ap.forecast = contractor.ForecastSpending(autopilot, contracts, ancestors, metrics, hosts, fee, cs.BlockHeight, now)
+	ap.forecastKey = key
+	ap.forecastUpdated = now
+	return ap.forecast, nil
+}
+
+// spendingForecastKey returns a hash of the autopilot and the contracts that
+// changes whenever the spending forecast needs to be recomputed.
+func spendingForecastKey(autopilot api.Autopilot, contracts []api.ContractMetadata) (types.Hash256, error) {
+	b, err := json.Marshal(autopilot)
+	if err != nil {
+		return types.Hash256{}, fmt.Errorf("failed to marshal autopilot: %w", err)
+	}
+	ids := make([]types.FileContractID, 0, len(contracts))
+	for _, c := range contracts {
+		ids = append(ids, c.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
+
+	h := types.NewHasher()
+	h.E.WriteBytes(b)
+	for _, id := range ids {
+		id.EncodeTo(h.E)
+	}
+	return h.Sum(), nil
+}
+
 func (ap *Autopilot) configHandlerGET(jc jape.Context) {
 	autopilot, err := ap.bus.Autopilot(jc.Request.Context(), ap.id)
 	if utils.IsErr(err, api.ErrAutopilotNotFound) {
@@ -844,6 +1076,18 @@ func (ap *Autopilot) buildState(ctx context.Context) (*contractor.MaintenanceSta
 		return nil, fmt.Errorf("could not fetch redundancy settings, err: %v", err)
 	}
 
+	// fetch the redundancy settings of buckets that override them
+	buckets, err := ap.bus.ListBuckets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch buckets, err: %v", err)
+	}
+	var bucketRS []api.RedundancySettings
+	for _, b := range buckets {
+		if b.Policy.Redundancy != nil {
+			bucketRS = append(bucketRS, *b.Policy.Redundancy)
+		}
+	}
+
 	// fetch gouging settings
 	gs, err := ap.bus.GougingSettings(ctx)
 	if err != nil {
@@ -890,9 +1134,13 @@ func (ap *Autopilot) buildState(ctx context.Context) (*contractor.MaintenanceSta
 	}
 
 	return &contractor.MaintenanceState{
-		GS: gs,
-		RS: rs,
-		AP: autopilot,
+		GS:       gs,
+		RS:       rs,
+		AP:       autopilot,
+		BucketRS: bucketRS,
+		GeoIP:    ap.geoIP,
+
+		HostScorer: ap.hostScorer,
 
 		Address:                address,
 		Fee:                    fee,
@@ -52,6 +52,21 @@ func (c *Client) HostInfos(ctx context.Context, filterMode, usabilityMode, addre
 	return
 }
 
+// MaintenancePlan performs contract maintenance in dry-run mode and returns
+// the changes it would make. If a config is given, the plan is computed as if
+// the config was applied.
+func (c *Client) MaintenancePlan(ctx context.Context, cfg *api.AutopilotConfig) (plan api.MaintenancePlan, err error) {
+	err = c.c.WithContext(ctx).POST("/maintenance/plan", api.MaintenancePlanRequest{AutopilotConfig: cfg}, &plan)
+	return
+}
+
+// SpendingForecast returns the projected spending at the end of the current
+// period.
+func (c *Client) SpendingForecast(ctx context.Context) (forecast api.SpendingForecast, err error) {
+	err = c.c.WithContext(ctx).GET("/spending/forecast", &forecast)
+	return
+}
+
 // State returns the current state of the autopilot.
 func (c *Client) State() (state api.AutopilotStateResponse, err error) {
 	err = c.c.GET("/state", &state)
@@ -1,10 +1,13 @@
 package contractor
 
 import (
+	"sort"
+	"strings"
 	"time"
 
 	"go.thebigfile.com/core/types"
 	"go.thebigfile.com/renterd/alerts"
+	"go.thebigfile.com/renterd/api"
 )
 
 type (
@@ -12,6 +15,13 @@ type (
 		additions map[types.FileContractID]contractSetAdditions
 		removals  map[types.FileContractID]contractSetRemovals
 	}
+
+	// contractFailure tracks for how long a contract in a set has been
+	// failing its checks.
+	contractFailure struct {
+		firstFailure time.Time
+		cycles       uint64
+	}
 )
 
 func newAccumulatedChurn() *accumulatedChurn {
@@ -66,3 +76,108 @@ func (c *accumulatedChurn) Reset() {
 	c.additions = make(map[types.FileContractID]contractSetAdditions)
 	c.removals = make(map[types.FileContractID]contractSetRemovals)
 }
+
+// dampenChurn returns the contracts of the old set that failed their checks
+// but should remain in the set according to the churn dampening policy of the
+// contracts config. The failures of the contracts in the set are updated, so
+// it's expected to be called once per maintenance cycle and set.
+func (c *Contractor) dampenChurn(ctx *mCtx, oldSet, kept []api.ContractMetadata, churnReasons map[types.FileContractID]string) []api.ContractMetadata {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	failures, keep := dampenedContracts(ctx.ContractsConfig(), c.setFailures[ctx.ContractSet()], oldSet, kept, churnReasons, time.Now())
+	c.setFailures[ctx.ContractSet()] = failures
+	return keep
+}
+
+// dampenedContracts updates the failures of the contracts in the old set that
+// weren't kept and returns the updated failures as well as the contracts that
+// should be kept anyway, largest contracts first. Failures of contracts that
+// were kept or left the set are forgotten, so only consecutive failures are
+// counted.
+func dampenedContracts(cfg api.ContractsConfig, failures map[types.FileContractID]contractFailure, oldSet, kept []api.ContractMetadata, churnReasons map[types.FileContractID]string, now time.Time) (map[types.FileContractID]contractFailure, []api.ContractMetadata) {
+	keptIDs := make(map[types.FileContractID]struct{})
+	for _, c := range kept {
+		keptIDs[c.ID] = struct{}{}
+		keptIDs[c.RenewedFrom] = struct{}{}
+	}
+
+	updated := make(map[types.FileContractID]contractFailure)
+	var keep, remove []api.ContractMetadata
+	for _, c := range oldSet {
+		if _, ok := keptIDs[c.ID]; ok {
+			continue
+		}
+
+		// hosts that exceed the diversity limits won't recover by waiting,
+		// but they are removed gradually within the limit on removals to
+		// avoid dropping a lot of contracts at once when the limits change
+		reason := churnReasons[c.ID]
+		if reason == api.ErrUsabilityHostNotDiverse.Error() {
+			remove = append(remove, c)
+			continue
+		}
+
+		// only failed host checks might recover, contracts that violate the
+		// user's policy or that failed themselves, e.g. because they expired
+		// or couldn't be refreshed or renewed, are always removed right away
+		if !isHostCheckFailure(reason) {
+			continue
+		}
+
+		f, ok := failures[c.ID]
+		if !ok {
+			f.firstFailure = now
+		}
+		f.cycles++
+		updated[c.ID] = f
+
+		if failedLongEnough(cfg, f, now) {
+			remove = append(remove, c)
+		} else {
+			keep = append(keep, c)
+		}
+	}
+
+	// cap the number of removals, removing the smallest contracts first to
+	// avoid unnecessary migrations
+	if cfg.MaxRemovals > 0 && uint64(len(remove)) > cfg.MaxRemovals {
+		sort.Slice(remove, func(i, j int) bool {
+			return remove[i].Size < remove[j].Size
+		})
+		keep = append(keep, remove[cfg.MaxRemovals:]...)
+	}
+
+	sort.Slice(keep, func(i, j int) bool {
+		return keep[i].Size > keep[j].Size
+	})
+	return updated, keep
+}
+
+// isHostCheckFailure returns true if all of the given churn reasons are
+// failed host checks that might recover, e.g. because the host was offline
+// for a while or temporarily raised its prices.
+func isHostCheckFailure(reason string) bool {
+	if reason == "" {
+		return false
+	}
+	for _, r := range strings.Split(reason, ",") {
+		switch r {
+		case api.ErrUsabilityHostOffline.Error(),
+			api.ErrUsabilityHostLowScore.Error(),
+			api.ErrUsabilityHostPriceGouging.Error(),
+			api.ErrUsabilityHostNotCompletingScan.Error():
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func failedLongEnough(cfg api.ContractsConfig, f contractFailure, now time.Time) bool {
+	if cfg.MinFailedCycles == 0 && cfg.MinFailedHours == 0 {
+		return true
+	}
+	return (cfg.MinFailedCycles > 0 && f.cycles >= cfg.MinFailedCycles) ||
+		(cfg.MinFailedHours > 0 && now.Sub(f.firstFailure) >= time.Duration(cfg.MinFailedHours)*time.Hour)
+}
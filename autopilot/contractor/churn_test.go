@@ -0,0 +1,355 @@
+package contractor
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"go.thebigfile.com/core/types"
+	"go.thebigfile.com/renterd/alerts"
+	"go.thebigfile.com/renterd/api"
+	"go.uber.org/zap"
+)
+
+type maintenanceTestBus struct {
+	Bus
+
+	contracts map[types.FileContractID]api.ContractMetadata
+	hosts     []api.Host
+}
+
+func (b *maintenanceTestBus) ConsensusState(_ context.Context) (api.ConsensusState, error) {
+	return api.ConsensusState{BlockHeight: 100}, nil
+}
+
+func (b *maintenanceTestBus) Contracts(_ context.Context, opts api.ContractsOpts) (contracts []api.ContractMetadata, _ error) {
+	for _, c := range b.contracts {
+		if opts.ContractSet == "" || c.InSet(opts.ContractSet) {
+			contracts = append(contracts, c)
+		}
+	}
+	return
+}
+
+func (b *maintenanceTestBus) Host(_ context.Context, hk types.PublicKey) (api.Host, error) {
+	for _, h := range b.hosts {
+		if h.PublicKey == hk {
+			return h, nil
+		}
+	}
+	return api.Host{}, api.ErrHostNotFound
+}
+
+func (b *maintenanceTestBus) SearchHosts(_ context.Context, _ api.SearchHostOptions) ([]api.Host, error) {
+	return nil, nil
+}
+
+func (b *maintenanceTestBus) UpdateContractSet(_ context.Context, set string, toAdd, toRemove []types.FileContractID) error {
+	for _, id := range toRemove {
+		c := b.contracts[id]
+		var sets []string
+		for _, s := range c.ContractSets {
+			if s != set {
+				sets = append(sets, s)
+			}
+		}
+		c.ContractSets = sets
+		b.contracts[id] = c
+	}
+	for _, id := range toAdd {
+		c := b.contracts[id]
+		if !c.InSet(set) {
+			c.ContractSets = append(c.ContractSets, set)
+		}
+		b.contracts[id] = c
+	}
+	return nil
+}
+
+func (b *maintenanceTestBus) setIDs(set string) (ids []types.FileContractID) {
+	contracts, _ := b.Contracts(context.Background(), api.ContractsOpts{ContractSet: set})
+	for _, c := range contracts {
+		ids = append(ids, c.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i][0] < ids[j][0] })
+	return
+}
+
+type maintenanceTestWorker struct {
+	Worker
+
+	bus   *maintenanceTestBus
+	calls int
+}
+
+func (w *maintenanceTestWorker) Contracts(_ context.Context, _ time.Duration) (resp api.ContractsResponse, _ error) {
+	w.calls++
+	for _, c := range w.bus.contracts {
+		resp.Contracts = append(resp.Contracts, api.Contract{ContractMetadata: c})
+	}
+	return
+}
+
+func TestDampenedContracts(t *testing.T) {
+	now := time.Now()
+	c1 := api.ContractMetadata{ID: types.FileContractID{1}, Size: 1}
+	c2 := api.ContractMetadata{ID: types.FileContractID{2}, Size: 2}
+	c3 := api.ContractMetadata{ID: types.FileContractID{3}, Size: 3}
+	renewed := api.ContractMetadata{ID: types.FileContractID{4}, RenewedFrom: c3.ID}
+	oldSet := []api.ContractMetadata{c1, c2, c3}
+	offline := map[types.FileContractID]string{
+		c1.ID: api.ErrUsabilityHostOffline.Error(),
+		c2.ID: api.ErrUsabilityHostOffline.Error(),
+		c3.ID: api.ErrUsabilityHostOffline.Error(),
+	}
+
+	ids := func(contracts []api.ContractMetadata) (ids []types.FileContractID) {
+		for _, c := range contracts {
+			ids = append(ids, c.ID)
+		}
+		return
+	}
+	assertKept := func(kept []api.ContractMetadata, expected ...types.FileContractID) {
+		t.Helper()
+		if len(kept) != len(expected) {
+			t.Fatalf("expected %v to be kept, got %v", expected, ids(kept))
+		}
+		for i := range kept {
+			if kept[i].ID != expected[i] {
+				t.Fatalf("expected %v to be kept, got %v", expected, ids(kept))
+			}
+		}
+	}
+
+	// without a policy nothing is kept, but failures are tracked
+	var cfg api.ContractsConfig
+	failures, kept := dampenedContracts(cfg, nil, oldSet, []api.ContractMetadata{renewed}, offline, now)
+	assertKept(kept)
+	if len(failures) != 2 || failures[c1.ID].cycles != 1 || failures[c2.ID].cycles != 1 {
+		t.Fatal("unexpected failures", failures)
+	}
+
+	// require 2 consecutive failed cycles, the contracts fail for the second
+	// time and are removed
+	cfg.MinFailedCycles = 2
+	_, kept = dampenedContracts(cfg, nil, oldSet, []api.ContractMetadata{renewed}, offline, now)
+	assertKept(kept, c2.ID, c1.ID)
+	failures, kept = dampenedContracts(cfg, failures, oldSet, []api.ContractMetadata{renewed}, offline, now)
+	assertKept(kept)
+
+	// if the contract passes its checks in between, the failure is reset
+	failures, _ = dampenedContracts(cfg, nil, oldSet, []api.ContractMetadata{c1, renewed}, offline, now)
+	if _, ok := failures[c1.ID]; ok {
+		t.Fatal("expected failure to be reset")
+	}
+
+	// blocked hosts and failed contracts are removed right away
+	_, kept = dampenedContracts(cfg, nil, oldSet, []api.ContractMetadata{renewed}, map[types.FileContractID]string{
+		c1.ID: api.ErrUsabilityHostOffline.Error() + "," + api.ErrUsabilityHostPriceGouging.Error(),
+		c2.ID: api.ErrUsabilityHostBlocked.Error(),
+	}, now)
+	assertKept(kept, c1.ID)
+	for _, reason := range []string{
+		errContractExpired.Error(),
+		errContractNoRevision.Error(),
+		errContractNotConfirmed.Error(),
+		errContractOutOfFunds.Error(),
+		api.ErrUsabilityHostOffline.Error() + "," + errContractMaxRevisionNumber.Error(),
+		"",
+	} {
+		_, kept = dampenedContracts(cfg, nil, oldSet, []api.ContractMetadata{renewed}, map[types.FileContractID]string{
+			c1.ID: reason,
+			c2.ID: api.ErrUsabilityHostLowScore.Error(),
+		}, now)
+		assertKept(kept, c2.ID)
+	}
+
+	// require the contracts to fail for an hour
+	cfg.MinFailedCycles = 0
+	cfg.MinFailedHours = 1
+	failures, kept = dampenedContracts(cfg, nil, oldSet, nil, offline, now)
+	assertKept(kept, c3.ID, c2.ID, c1.ID)
+	_, kept = dampenedContracts(cfg, failures, oldSet, nil, offline, now.Add(time.Hour))
+	assertKept(kept)
+
+	// whichever threshold is reached first applies
+	cfg.MinFailedCycles = 2
+	_, kept = dampenedContracts(cfg, failures, oldSet, nil, offline, now.Add(time.Minute))
+	assertKept(kept)
+
+	// cap the removals, the smallest contracts are removed first
+	cfg = api.ContractsConfig{MaxRemovals: 1}
+	_, kept = dampenedContracts(cfg, nil, oldSet, nil, offline, now)
+	assertKept(kept, c3.ID, c2.ID)
+
+	// hosts that exceed the diversity limits aren't dampened, but their
+	// removals are capped
+	notDiverse := map[types.FileContractID]string{
+		c1.ID: api.ErrUsabilityHostNotDiverse.Error(),
+		c2.ID: api.ErrUsabilityHostNotDiverse.Error(),
+	}
+	cfg = api.ContractsConfig{MinFailedCycles: 10}
+	failures, kept = dampenedContracts(cfg, nil, oldSet, []api.ContractMetadata{renewed}, notDiverse, now)
+	assertKept(kept)
+	if len(failures) != 0 {
+		t.Fatal("unexpected failures", failures)
+	}
+	cfg.MaxRemovals = 1
+	_, kept = dampenedContracts(cfg, nil, oldSet, []api.ContractMetadata{renewed}, notDiverse, now)
+	assertKept(kept, c2.ID)
+}
+
+func TestMaintainContractSetDampenedChurn(t *testing.T) {
+	const set = "autopilot"
+	newHost := func(i byte, addr string) api.Host {
+		return api.Host{
+			PublicKey:         types.PublicKey{i},
+			ResolvedAddresses: []string{addr},
+			Checks:            map[string]api.HostCheck{api.DefaultAutopilotID: {}},
+		}
+	}
+	newContract := func(i byte, size uint64) api.ContractMetadata {
+		return api.ContractMetadata{
+			ID:           types.FileContractID{i},
+			HostKey:      types.PublicKey{i},
+			Size:         size,
+			State:        api.ContractStateActive,
+			WindowStart:  1000,
+			ContractSets: []string{set},
+		}
+	}
+
+	// h1 is kept since it's not scanned yet, h2 shares its subnet, h3 is
+	// offline, h4 is blocked and h5 is unknown to the bus
+	h1 := newHost(1, "1.2.3.4")
+	h1.Checks[api.DefaultAutopilotID] = api.HostCheck{Usability: api.HostUsabilityBreakdown{NotCompletingScan: true}}
+	h2 := newHost(2, "1.2.3.5")
+	h3 := newHost(3, "2.3.4.5")
+	h3.Checks[api.DefaultAutopilotID] = api.HostCheck{Usability: api.HostUsabilityBreakdown{Offline: true}}
+	h4 := newHost(4, "3.4.5.6")
+	h4.Blocked = true
+
+	bus := &maintenanceTestBus{
+		contracts: make(map[types.FileContractID]api.ContractMetadata),
+		hosts:     []api.Host{h1, h2, h3, h4},
+	}
+	for i := byte(1); i <= 5; i++ {
+		c := newContract(i, uint64(60-10*i))
+		bus.contracts[c.ID] = c
+	}
+	w := &maintenanceTestWorker{bus: bus}
+	c := New(bus, alerts.NewManager(), zap.NewNop().Sugar(), 0, time.Hour)
+
+	ctx := newMaintenanceCtx(context.Background(), &MaintenanceState{
+		AP: api.Autopilot{
+			ID: api.DefaultAutopilotID,
+			Config: api.AutopilotConfig{
+				Contracts: api.ContractsConfig{
+					Amount:          5,
+					Set:             set,
+					MinFailedCycles: 2,
+				},
+			},
+		},
+	})
+	maintain := func() {
+		t.Helper()
+		remaining := types.Siacoins(1)
+		if _, _, err := maintainContractSets(ctx, c.alerter, bus, w, c, c, c.logger, &remaining); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertSet := func(expected ...types.FileContractID) {
+		t.Helper()
+		ids := bus.setIDs(set)
+		if len(ids) != len(expected) {
+			t.Fatalf("expected set %v, got %v", expected, ids)
+		}
+		for i := range ids {
+			if ids[i] != expected[i] {
+				t.Fatalf("expected set %v, got %v", expected, ids)
+			}
+		}
+	}
+
+	// the contract with the offline host is dampened, the policy failures
+	// are removed right away
+	maintain()
+	assertSet(types.FileContractID{1}, types.FileContractID{3})
+
+	// the offline host fails for the second time and is removed
+	maintain()
+	assertSet(types.FileContractID{1})
+}
+
+func TestMaintainContractSets(t *testing.T) {
+	const set, curated = "autopilot", "curated"
+
+	// all hosts are kept since they're not scanned yet
+	bus := &maintenanceTestBus{
+		contracts: make(map[types.FileContractID]api.ContractMetadata),
+	}
+	for i := byte(1); i <= 3; i++ {
+		bus.hosts = append(bus.hosts, api.Host{
+			PublicKey:         types.PublicKey{i},
+			ResolvedAddresses: []string{fmt.Sprintf("%d.2.3.4", i)},
+			Checks: map[string]api.HostCheck{
+				api.DefaultAutopilotID: {Usability: api.HostUsabilityBreakdown{NotCompletingScan: true}},
+			},
+		})
+		bus.contracts[types.FileContractID{i}] = api.ContractMetadata{
+			ID:           types.FileContractID{i},
+			HostKey:      types.PublicKey{i},
+			Size:         uint64(i),
+			State:        api.ContractStateActive,
+			WindowStart:  1000,
+			ContractSets: []string{set, curated},
+		}
+	}
+	w := &maintenanceTestWorker{bus: bus}
+	c := New(bus, alerts.NewManager(), zap.NewNop().Sugar(), 0, time.Hour)
+
+	// the curated set only allows h1 and h3
+	ctx := newMaintenanceCtx(context.Background(), &MaintenanceState{
+		AP: api.Autopilot{
+			ID: api.DefaultAutopilotID,
+			Config: api.AutopilotConfig{
+				Contracts: api.ContractsConfig{
+					Amount: 5,
+					Set:    set,
+					Sets: []api.ContractSetConfig{{
+						Name:   curated,
+						Amount: 5,
+						Hosts:  []types.PublicKey{{1}, {3}},
+					}},
+				},
+			},
+		},
+	})
+
+	remaining := types.Siacoins(1)
+	sets, changes, err := maintainContractSets(ctx, c.alerter, bus, w, c, c, c.logger, &remaining)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(sets) != 2 || len(changes) != 2 {
+		t.Fatalf("expected 2 sets, got %v", len(sets))
+	} else if w.calls != 1 {
+		t.Fatalf("expected contracts to be fetched once, got %v", w.calls)
+	}
+
+	// assert the sets
+	if ids := bus.setIDs(set); !reflect.DeepEqual(ids, []types.FileContractID{{1}, {2}, {3}}) {
+		t.Fatal("unexpected default set", ids)
+	} else if ids := bus.setIDs(curated); !reflect.DeepEqual(ids, []types.FileContractID{{1}, {3}}) {
+		t.Fatal("unexpected curated set", ids)
+	}
+
+	// assert the contract was dropped from the curated set because of its
+	// host filter
+	if reason := changes[1].churnReasons[types.FileContractID{2}]; reason != errHostNotInSetFilter.Error() {
+		t.Fatal("unexpected churn reason", reason)
+	}
+}
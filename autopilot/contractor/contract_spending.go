@@ -29,6 +29,12 @@ func currentPeriodSpending(contracts []api.ContractMetadata, currentPeriod uint6
 	return totalAllocated
 }
 
+// contractOverhead returns what forming, renewing or refreshing a contract with
+// the given host costs on top of the funds that are put into the contract.
+func contractOverhead(h api.Host, fee types.Currency) types.Currency {
+	return h.Settings.ContractPrice.Add(fee.Mul64(estimatedFileContractTransactionSetSize))
+}
+
 func remainingAllowance(ctx context.Context, bus Bus, state *MaintenanceState) (types.Currency, error) {
 	contracts, err := bus.Contracts(ctx, api.ContractsOpts{})
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"math"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/montanaflynn/stats"
@@ -78,6 +79,10 @@ const (
 	// timeoutBroadcastRevision is the amount of time we wait for the broadcast
 	// of a revision to succeed.
 	timeoutBroadcastRevision = time.Minute
+
+	// timeoutHostScore is the amount of time we wait for a custom host scorer
+	// to score all hosts.
+	timeoutHostScore = time.Minute
 )
 
 type Bus interface {
@@ -105,6 +110,7 @@ type Worker interface {
 
 type contractChecker interface {
 	isUsableContract(cfg api.AutopilotConfig, s rhpv2.HostSettings, pt rhpv3.HostPriceTable, rs api.RedundancySettings, contract api.Contract, inSet bool, bh uint64, f *hostSet) (usable, refresh, renew bool, reasons []string)
+	dampenChurn(ctx *mCtx, oldSet, kept []api.ContractMetadata, churnReasons map[types.FileContractID]string) []api.ContractMetadata
 	pruneContractRefreshFailures(contracts []api.ContractMetadata)
 	shouldArchive(c api.Contract, bh uint64) error
 }
@@ -130,7 +136,9 @@ type (
 		revisionLastBroadcast     map[types.FileContractID]time.Time
 		revisionSubmissionBuffer  uint64
 
+		mu                  sync.Mutex
 		firstRefreshFailure map[types.FileContractID]time.Time
+		setFailures         map[string]map[types.FileContractID]contractFailure
 	}
 
 	scoredHost struct {
@@ -139,6 +147,39 @@ type (
 		score float64
 	}
 
+	// checkedContract is a contract along with the results of the checks
+	// that don't depend on the contract set.
+	checkedContract struct {
+		api.Contract
+		host  api.Host
+		check api.HostCheck
+
+		// reason is set if the contract can't be kept by any set
+		reason string
+
+		// usable, renew and refresh are set once the contract is kept by a
+		// set
+		usable  bool
+		renew   bool
+		refresh bool
+	}
+
+	// setCheck is the result of checking the existing contracts for a
+	// contract set.
+	setCheck struct {
+		kept         []api.ContractMetadata
+		churnReasons map[types.FileContractID]string
+		ipFilter     *hostSet
+	}
+
+	// contractSetChange describes how a contract set changed during
+	// maintenance.
+	contractSetChange struct {
+		oldSet       []api.ContractMetadata
+		newSet       []api.ContractMetadata
+		churnReasons map[types.FileContractID]string
+	}
+
 	contractSetAdditions struct {
 		HostKey   types.PublicKey       `json:"hostKey"`
 		Additions []contractSetAddition `json:"additions"`
@@ -174,6 +215,7 @@ func New(bus Bus, alerter alerts.Alerter, logger *zap.SugaredLogger, revisionSub
 		revisionSubmissionBuffer:  revisionSubmissionBuffer,
 
 		firstRefreshFailure: make(map[types.FileContractID]time.Time),
+		setFailures:         make(map[string]map[types.FileContractID]contractFailure),
 	}
 }
 
@@ -251,6 +293,9 @@ func (c *Contractor) initialContractFunding(settings rhpv2.HostSettings, txnFee,
 }
 
 func (c *Contractor) pruneContractRefreshFailures(contracts []api.ContractMetadata) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	contractMap := make(map[types.FileContractID]struct{})
 	for _, contract := range contracts {
 		contractMap[contract.ID] = struct{}{}
@@ -499,6 +544,9 @@ func (c *Contractor) shouldArchive(contract api.Contract, bh uint64) error {
 }
 
 func (c *Contractor) shouldForgiveFailedRefresh(fcid types.FileContractID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	lastFailure, exists := c.firstRefreshFailure[fcid]
 	if !exists {
 		lastFailure = time.Now()
@@ -514,7 +562,11 @@ func addLeeway(n uint64, pct float64) uint64 {
 	return uint64(math.Ceil(float64(n) * pct))
 }
 
-func calculateMinScore(candidates []scoredHost, numContracts uint64, logger *zap.SugaredLogger) float64 {
+// calculateMinScore computes the min score a host needs to be considered
+// usable. The score is chosen such that at least 'numContracts' contracts can
+// be formed with hosts that together don't exceed the limits of the given
+// diversity set.
+func calculateMinScore(candidates []scoredHost, numContracts uint64, diversity *hostSet, logger *zap.SugaredLogger) float64 {
 	logger = logger.Named("calculateMinScore")
 
 	// return early if there's no hosts
@@ -561,7 +613,23 @@ func calculateMinScore(candidates []scoredHost, numContracts uint64, logger *zap
 	})
 	if len(candidates) < int(numContracts) {
 		return minValidScore
-	} else if cutoff := candidates[numContracts-1].score; minScore > cutoff {
+	}
+	var accepted uint64
+	cutoff := minValidScore
+	for _, candidate := range candidates {
+		if accepted == numContracts {
+			break
+		} else if _, exceeds := diversity.ExceedsDiversityLimits(candidate.host); exceeds {
+			continue
+		}
+		diversity.Add(candidate.host)
+		cutoff = candidate.score
+		accepted++
+	}
+	if accepted < numContracts {
+		logger.Warn("min host score is set to the smallest non-zero float because there are not enough diverse candidate hosts")
+		return minValidScore
+	} else if minScore > cutoff {
 		minScore = cutoff
 	}
 
@@ -681,7 +749,7 @@ func computeContractSetChanged(ctx *mCtx, alerter alerts.Alerter, bus Bus, churn
 
 	// log a warning if the contract set does not contain enough contracts
 	logFn := logger.Infow
-	if len(newSet) < int(ctx.state.RS.TotalShards) {
+	if len(newSet) < ctx.MaxTotalShards() {
 		logFn = logger.Warnw
 	}
 
@@ -843,30 +911,24 @@ func renterFundsToExpectedStorage(renterFunds types.Currency, duration uint64, p
 
 // performContractChecks performs maintenance on existing contracts,
 // renewing/refreshing any that need it and filtering out contracts that should
-// no longer be used. The 'ipFilter' is updated to contain all hosts that we
-// keep contracts with and the 'dropOutReasons' map is updated with the reasons
-// for dropping out of the set. If a contract is refreshed or renewed, the
-// 'remainingFunds' are adjusted.
-func performContractChecks(ctx *mCtx, alerter alerts.Alerter, bus Bus, w Worker, cc contractChecker, cr contractReviser, ipFilter *hostSet, logger *zap.SugaredLogger, remainingFunds *types.Currency) ([]api.ContractMetadata, map[types.FileContractID]string, error) {
-	var filteredContracts []api.ContractMetadata
-	keepContract := func(c api.ContractMetadata, h api.Host) {
-		filteredContracts = append(filteredContracts, c)
-		ipFilter.Add(h)
-	}
-	churnReasons := make(map[types.FileContractID]string)
-
+// no longer be used by the given sets. The checks that don't depend on the
+// set, as well as renewals and refreshes, are only performed once for all
+// sets. For every set, the returned check contains the contracts that are
+// kept, the reasons for dropping the others out of the set and an 'ipFilter'
+// that contains all hosts the set keeps contracts with. If a contract is
+// refreshed or renewed, the 'remainingFunds' are adjusted.
+func performContractChecks(ctx *mCtx, sets []*mCtx, alerter alerts.Alerter, bus Bus, w Worker, cc contractChecker, cr contractReviser, logger *zap.SugaredLogger, remainingFunds *types.Currency) ([]setCheck, error) {
 	// fetch all contracts we already have
 	logger.Info("fetching existing contracts")
 	start := time.Now()
 	resp, err := w.Contracts(ctx, timeoutHostRevision)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	contracts := resp.Contracts
 	logger.With("elapsed", time.Since(start)).Info("done fetching existing contracts")
 
 	// print the reason for the missing revisions
-	for _, c := range contracts {
+	for _, c := range resp.Contracts {
 		if c.Revision == nil {
 			logger.With("error", resp.Errors[c.HostKey]).
 				With("hostKey", c.HostKey).
@@ -874,47 +936,27 @@ func performContractChecks(ctx *mCtx, alerter alerts.Alerter, bus Bus, w Worker,
 		}
 	}
 
-	// sort them by whether they are in the current set and their size
-	ctx.SortContractsForMaintenance(contracts)
-
-	// allow for a leeway of 10% of the required contracts for special cases such as failing to fetch
-	remainingLeeway := addLeeway(ctx.WantedContracts(), 1-leewayPctRequiredContracts)
-
-	// perform checks on contracts one-by-one renewing/refreshing
-	// contracts as necessary and filtering out contracts that should no
-	// longer be used
-	logger.With("contracts", len(contracts)).Info("checking existing contracts")
-	var renewed, refreshed int
-	for _, c := range contracts {
-		inSet := c.InSet(ctx.Set())
+	// fetch recent consensus state
+	cs, err := bus.ConsensusState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch consensus state: %w", err)
+	}
+	bh := cs.BlockHeight
+	logger = logger.With("blockHeight", bh)
 
+	// perform the checks that don't depend on the set once
+	logger.With("contracts", len(resp.Contracts)).Info("checking existing contracts")
+	contracts := make(map[types.FileContractID]checkedContract)
+	for _, c := range resp.Contracts {
 		logger := logger.With("contractID", c.ID).
-			With("inSet", inSet).
 			With("hostKey", c.HostKey).
 			With("revisionNumber", c.RevisionNumber).
 			With("size", c.FileSize()).
 			With("state", c.State).
-			With("remainingLeeway", remainingLeeway).
-			With("revisionAvailable", c.Revision != nil).
-			With("filteredContracts", len(filteredContracts)).
-			With("wantedContracts", ctx.WantedContracts())
+			With("revisionAvailable", c.Revision != nil)
 
 		logger.Debug("checking contract")
-
-		// abort if we have enough contracts
-		if uint64(len(filteredContracts)) >= ctx.WantedContracts() {
-			churnReasons[c.ID] = "truncated"
-			logger.Debug("ignoring contract since we have enough contracts")
-			continue
-		}
-
-		// fetch recent consensus state
-		cs, err := bus.ConsensusState(ctx)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to fetch consensus state: %w", err)
-		}
-		bh := cs.BlockHeight
-		logger = logger.With("blockHeight", bh)
+		checked := checkedContract{Contract: c}
 
 		// check if contract is ready to be archived.
 		if reason := cc.shouldArchive(c, bh); reason != nil {
@@ -925,158 +967,231 @@ func performContractChecks(ctx *mCtx, alerter alerts.Alerter, bus Bus, w Worker,
 			} else {
 				logger.Debug("successfully archived contract")
 			}
-			churnReasons[c.ID] = reason.Error()
-			continue
-		}
-
-		// fetch host
-		host, err := bus.Host(ctx, c.HostKey)
-		if err != nil {
+			checked.reason = reason.Error()
+		} else if host, err := bus.Host(ctx, c.HostKey); err != nil {
 			logger.With(zap.Error(err)).Warn("missing host")
-			churnReasons[c.ID] = api.ErrUsabilityHostNotFound.Error()
-			continue
+			checked.reason = api.ErrUsabilityHostNotFound.Error()
+		} else if host.Blocked {
+			logger.With("addresses", host.ResolvedAddresses).Info("host is blocked")
+			checked.reason = api.ErrUsabilityHostBlocked.Error()
+		} else if check, ok := host.Checks[ctx.ApID()]; !ok {
+			logger.Warn("missing host check")
+			checked.reason = api.ErrUsabilityHostNotFound.Error()
+		} else {
+			checked.host = host
+			checked.check = check
+		}
+		contracts[c.ID] = checked
+	}
+
+	// filter the contracts for every set, the contracts that need to be
+	// renewed or refreshed are tentatively kept
+	checks := make([]setCheck, len(sets))
+	kept := make([][]checkedContract, len(sets))
+	for i, setCtx := range sets {
+		logger := logger.With("contractSet", setCtx.ContractSet())
+		ipFilter := newHostSet(ctx.state.GeoIP, setCtx.DiversityLimits(), logger.Named("ipFilter"))
+		churnReasons := make(map[types.FileContractID]string)
+		keepContract := func(c checkedContract) {
+			kept[i] = append(kept[i], c)
+			ipFilter.Add(c.host)
 		}
 
-		// extend logger
-		logger = logger.With("addresses", host.ResolvedAddresses).
-			With("blocked", host.Blocked)
+		// sort them by whether they are in the set and their size
+		sorted := append([]api.Contract(nil), resp.Contracts...)
+		setCtx.SortContractsForMaintenance(sorted)
 
-		// check if host is blocked
-		if host.Blocked {
-			logger.Info("host is blocked")
-			churnReasons[c.ID] = api.ErrUsabilityHostBlocked.Error()
-			continue
-		}
+		// allow for a leeway of 10% of the required contracts for special
+		// cases such as failing to fetch
+		remainingLeeway := addLeeway(setCtx.WantedContracts(), 1-leewayPctRequiredContracts)
 
-		// check if host has a redundant ip
-		if ctx.ShouldFilterRedundantIPs() && ipFilter.HasRedundantIP(host) {
-			logger.Info("host has redundant IP")
-			churnReasons[c.ID] = api.ErrUsabilityHostRedundantIP.Error()
-			continue
-		}
+		for _, sc := range sorted {
+			c := contracts[sc.ID]
+			host, check := c.host, c.check
+			inSet := c.InSet(setCtx.Set())
 
-		// get check
-		check, ok := host.Checks[ctx.ApID()]
-		if !ok {
-			logger.Warn("missing host check")
-			churnReasons[c.ID] = api.ErrUsabilityHostNotFound.Error()
-			continue
-		}
+			logger := logger.With("contractID", c.ID).
+				With("inSet", inSet).
+				With("hostKey", c.HostKey).
+				With("remainingLeeway", remainingLeeway).
+				With("filteredContracts", len(kept[i])).
+				With("wantedContracts", setCtx.WantedContracts())
+
+			// abort if we have enough contracts
+			if uint64(len(kept[i])) >= setCtx.WantedContracts() {
+				churnReasons[c.ID] = "truncated"
+				logger.Debug("ignoring contract since we have enough contracts")
+				continue
+			}
 
-		// NOTE: if we have a contract with a host that is not scanned, we either
-		// added the host and contract manually or reset the host scans. In that case,
-		// we ignore the fact that the host is not scanned for now to avoid churn.
-		if inSet && check.Usability.NotCompletingScan {
-			keepContract(c.ContractMetadata, host)
-			logger.Info("ignoring contract with unscanned host")
-			continue // no more checks until host is scanned
-		}
+			// check if the contract failed the checks that don't depend on
+			// the set
+			if c.reason != "" {
+				churnReasons[c.ID] = c.reason
+				continue
+			}
 
-		// check usability
-		if !check.Usability.IsUsable() {
-			reasons := strings.Join(check.Usability.UnusableReasons(), ",")
-			logger.With("reasons", reasons).Info("unusable host")
-			churnReasons[c.ID] = reasons
-			continue
-		}
+			// check if host has a redundant ip
+			if setCtx.ShouldFilterRedundantIPs() && ipFilter.HasRedundantIP(host) {
+				logger.Info("host has redundant IP")
+				churnReasons[c.ID] = api.ErrUsabilityHostRedundantIP.Error()
+				continue
+			}
 
-		// check if revision is available
-		if c.Revision == nil {
-			if inSet && remainingLeeway > 0 {
-				logger.Debug("keeping contract due to leeway")
-				keepContract(c.ContractMetadata, host)
-				remainingLeeway--
-			} else {
-				logger.Debug("ignoring contract without revision")
-				churnReasons[c.ID] = errContractNoRevision.Error()
+			// check if keeping the host would exceed the diversity limits
+			if reason, exceeds := ipFilter.ExceedsDiversityLimits(host); exceeds {
+				logger.With("reason", reason).Info("host exceeds the diversity limits")
+				churnReasons[c.ID] = api.ErrUsabilityHostNotDiverse.Error()
+				continue
 			}
-			continue // no more checks without revision
-		}
 
-		// check if contract is usable
-		usable, needsRefresh, needsRenew, reasons := cc.isUsableContract(ctx.AutopilotConfig(), host.Settings, host.PriceTable.HostPriceTable, ctx.state.RS, c, inSet, bh, ipFilter)
+			// check if the host passes the set's host filter
+			if !setCtx.AllowsHost(host.PublicKey, check.Score.Score()) {
+				logger.Debug("host does not pass the set's host filter")
+				churnReasons[c.ID] = errHostNotInSetFilter.Error()
+				continue
+			}
 
-		// extend logger
-		logger = logger.With("usable", usable).
-			With("needsRefresh", needsRefresh).
-			With("needsRenew", needsRenew).
-			With("reasons", reasons)
+			// NOTE: if we have a contract with a host that is not scanned, we either
+			// added the host and contract manually or reset the host scans. In that case,
+			// we ignore the fact that the host is not scanned for now to avoid churn.
+			if inSet && check.Usability.NotCompletingScan {
+				c.usable = true
+				keepContract(c)
+				logger.Info("ignoring contract with unscanned host")
+				continue // no more checks until host is scanned
+			}
 
-			// remember reason for potential drop of contract
-		if len(reasons) > 0 {
-			churnReasons[c.ID] = strings.Join(reasons, ",")
-		}
+			// check usability
+			if !check.Usability.IsUsable() {
+				reasons := strings.Join(check.Usability.UnusableReasons(), ",")
+				logger.With("reasons", reasons).Info("unusable host")
+				churnReasons[c.ID] = reasons
+				continue
+			}
 
-		contract := c.ContractMetadata
+			// check if revision is available
+			if c.Revision == nil {
+				if inSet && remainingLeeway > 0 {
+					logger.Debug("keeping contract due to leeway")
+					c.usable = true
+					keepContract(c)
+					remainingLeeway--
+				} else {
+					logger.Debug("ignoring contract without revision")
+					churnReasons[c.ID] = errContractNoRevision.Error()
+				}
+				continue // no more checks without revision
+			}
 
-		// renew/refresh as necessary
-		var ourFault bool
-		if needsRenew {
-			var renewedContract api.ContractMetadata
-			renewedContract, ourFault, err = cr.renewContract(ctx, w, c, host, remainingFunds, logger)
-			if err != nil {
-				logger = logger.With(zap.Error(err)).With("ourFault", ourFault)
+			// check if contract is usable
+			usable, needsRefresh, needsRenew, reasons := cc.isUsableContract(ctx.AutopilotConfig(), host.Settings, host.PriceTable.HostPriceTable, ctx.state.RS, c.Contract, inSet, bh, ipFilter)
+			logger = logger.With("usable", usable).
+				With("needsRefresh", needsRefresh).
+				With("needsRenew", needsRenew).
+				With("reasons", reasons)
 
-				// don't register an alert for hosts that are out of funds since the
-				// user can't do anything about it
-				if !(rhp3.IsErrHost(err) && utils.IsErr(err, wallet.ErrNotEnoughFunds)) {
-					alerter.RegisterAlert(ctx, newContractRenewalFailedAlert(contract, !ourFault, err))
+			// remember reason for potential drop of contract
+			if len(reasons) > 0 {
+				churnReasons[c.ID] = strings.Join(reasons, ",")
+			}
+
+			// if the contract is not usable and can't be renewed or
+			// refreshed we ignore it
+			if !usable && !needsRenew && !needsRefresh {
+				if inSet {
+					logger.Info("contract is not usable, removing from set")
+				} else {
+					logger.Debug("contract is not usable, remains out of set")
 				}
-				logger.Error("failed to renew contract")
+				continue
+			}
+
+			// we keep the contract, add the host to the filter
+			logger.Debug("contract is usable and is added / stays in set")
+			c.usable, c.renew, c.refresh = usable, needsRenew, needsRefresh
+			keepContract(c)
+		}
+		checks[i] = setCheck{churnReasons: churnReasons}
+	}
+
+	// renew and refresh the contracts that are kept by any set, every
+	// contract is renewed or refreshed only once
+	type revision struct {
+		contract api.ContractMetadata
+		ok       bool
+		ourFault bool
+	}
+	revised := make(map[types.FileContractID]revision)
+	var renewed, refreshed int
+	for i := range sets {
+		for _, c := range kept[i] {
+			if _, ok := revised[c.ID]; ok || (!c.renew && !c.refresh) {
+				continue
+			}
+			logger := logger.With("contractID", c.ID).With("hostKey", c.HostKey)
+
+			var rev revision
+			if c.renew {
+				rev.contract, rev.ourFault, err = cr.renewContract(ctx, w, c.Contract, c.host, remainingFunds, logger)
 			} else {
-				logger.Info("successfully renewed contract")
-				alerter.DismissAlerts(ctx, alerts.IDForContract(alertRenewalFailedID, contract.ID))
-				contract = renewedContract
-				usable = true
-				renewed++
+				rev.contract, rev.ourFault, err = cr.refreshContract(ctx, w, c.Contract, c.host, remainingFunds, logger)
 			}
-		} else if needsRefresh {
-			var refreshedContract api.ContractMetadata
-			refreshedContract, ourFault, err = cr.refreshContract(ctx, w, c, host, remainingFunds, logger)
 			if err != nil {
-				logger = logger.With(zap.Error(err)).With("ourFault", ourFault)
+				logger = logger.With(zap.Error(err)).With("ourFault", rev.ourFault)
 
 				// don't register an alert for hosts that are out of funds since the
 				// user can't do anything about it
 				if !(rhp3.IsErrHost(err) && utils.IsErr(err, wallet.ErrNotEnoughFunds)) {
-					alerter.RegisterAlert(ctx, newContractRenewalFailedAlert(contract, !ourFault, err))
+					alerter.RegisterAlert(ctx, newContractRenewalFailedAlert(c.ContractMetadata, !rev.ourFault, err))
+				}
+				if c.renew {
+					logger.Error("failed to renew contract")
+				} else {
+					logger.Error("failed to refresh contract")
 				}
-				logger.Error("failed to refresh contract")
 			} else {
-				logger.Info("successfully refreshed contract")
-				alerter.DismissAlerts(ctx, alerts.IDForContract(alertRenewalFailedID, contract.ID))
-				contract = refreshedContract
-				usable = true
-				refreshed++
+				alerter.DismissAlerts(ctx, alerts.IDForContract(alertRenewalFailedID, c.ID))
+				rev.ok = true
+				if c.renew {
+					logger.Info("successfully renewed contract")
+					renewed++
+				} else {
+					logger.Info("successfully refreshed contract")
+					refreshed++
+				}
 			}
+			revised[c.ID] = rev
 		}
+	}
 
-		// if the renewal/refresh failing was our fault (e.g. we ran out of
-		// funds), we should not drop the contract
-		if !usable && ourFault {
-			logger.Info("keeping contract even though renewal/refresh failed")
-			usable = true
-		}
-
-		// if the contract is not usable we ignore it
-		if !usable {
-			if inSet {
-				logger.Info("contract is not usable, removing from set")
-			} else {
-				logger.Debug("contract is not usable, remains out of set")
+	// replace renewed and refreshed contracts, if the renewal/refresh failing
+	// was our fault (e.g. we ran out of funds), we should not drop the
+	// contract
+	for i, setCtx := range sets {
+		logger := logger.With("contractSet", setCtx.ContractSet())
+		checks[i].ipFilter = newHostSet(ctx.state.GeoIP, setCtx.DiversityLimits(), logger.Named("ipFilter"))
+		for _, c := range kept[i] {
+			contract := c.ContractMetadata
+			if rev, ok := revised[c.ID]; ok {
+				if rev.ok {
+					contract = rev.contract
+				} else if !c.usable && !rev.ourFault {
+					logger.With("contractID", c.ID).Info("contract is not usable, removing from set")
+					continue
+				} else if !c.usable {
+					logger.With("contractID", c.ID).Info("keeping contract even though renewal/refresh failed")
+				}
 			}
-			continue
+			checks[i].kept = append(checks[i].kept, contract)
+			checks[i].ipFilter.Add(c.host)
 		}
-
-		// we keep the contract, add the host to the filter
-		logger.Debug("contract is usable and is added / stays in set")
-		keepContract(contract, host)
+		logger.With("filteredContracts", len(checks[i].kept)).Info("checking existing contracts done")
 	}
 	logger.With("refreshed", refreshed).
 		With("renewed", renewed).
-		With("filteredContracts", len(filteredContracts)).
 		Info("checking existing contracts done")
-	return filteredContracts, churnReasons, nil
+	return checks, nil
 }
 
 // performContracdtFormations forms up to 'wanted' new contracts with hosts. The
@@ -1129,6 +1244,9 @@ func performContractFormations(ctx *mCtx, bus Bus, w Worker, cr contractReviser,
 		} else if score := hc.Score.Score(); score == 0 {
 			logger.Error("host has a score of 0")
 			continue
+		} else if !ctx.AllowsHost(host.PublicKey, score) {
+			logger.Debug("host does not pass the set's host filter")
+			continue
 		}
 		candidates = append(candidates, newScoredHost(host, hc.Score))
 	}
@@ -1187,6 +1305,12 @@ func performContractFormations(ctx *mCtx, bus Bus, w Worker, cr contractReviser,
 			continue
 		}
 
+		// check if forming a contract would exceed the diversity limits
+		if reason, exceeds := ipFilter.ExceedsDiversityLimits(candidate.host); exceeds {
+			logger.With("reason", reason).Info("host exceeds the diversity limits")
+			continue
+		}
+
 		formedContract, proceed, err := cr.formContract(ctx, w, candidate.host, minInitialContractFunds, maxInitialContractFunds, remainingFunds, logger)
 		if err != nil {
 			logger.With(zap.Error(err)).Error("failed to form contract")
@@ -1214,19 +1338,11 @@ func performHostChecks(ctx *mCtx, bus Bus, logger *zap.SugaredLogger) error {
 		return fmt.Errorf("failed to fetch all hosts: %w", err)
 	}
 
-	var scoredHosts []scoredHost
-	for _, host := range hosts {
-		// score host
-		sb, err := ctx.HostScore(host)
-		if err != nil {
-			logger.With(zap.Error(err)).Info("failed to score host")
-			continue
-		}
-		scoredHosts = append(scoredHosts, newScoredHost(host, sb))
-	}
+	scoredHosts := scoreHosts(ctx, ctx.AutopilotConfig(), ctx.state.RS, ctx.state.HostScorer, hosts, logger)
 
 	// compute minimum score for usable hosts
-	minScore := calculateMinScore(scoredHosts, ctx.WantedContracts(), logger)
+	diversity := newHostSet(ctx.state.GeoIP, ctx.DiversityLimits(), logger.Named("diversity"))
+	minScore := calculateMinScore(scoredHosts, ctx.WantedContracts(), diversity, logger)
 
 	// run host checks using the latest consensus state
 	cs, err := bus.ConsensusState(ctx)
@@ -1258,10 +1374,6 @@ func performPostMaintenanceTasks(ctx *mCtx, bus Bus, w Worker, alerter alerts.Al
 	if err != nil {
 		return fmt.Errorf("failed to fetch all contracts: %w", err)
 	}
-	setContracts, err := bus.Contracts(ctx, api.ContractsOpts{ContractSet: ctx.ContractSet()})
-	if err != nil {
-		return fmt.Errorf("failed to fetch contracts: %w", err)
-	}
 	allHosts, err := bus.SearchHosts(ctx, api.SearchHostOptions{
 		Limit:         -1,
 		FilterMode:    api.HostFilterModeAllowed,
@@ -1275,7 +1387,16 @@ func performPostMaintenanceTasks(ctx *mCtx, bus Bus, w Worker, alerter alerts.Al
 		usedHosts[c.HostKey] = struct{}{}
 	}
 
-	// run revision broadcast on contracts in the new set
+	// run revision broadcast on contracts in any of the new sets
+	var setContracts []api.ContractMetadata
+	for _, c := range allContracts {
+		for _, set := range ctx.ContractsConfig().SetNames() {
+			if c.InSet(set) {
+				setContracts = append(setContracts, c)
+				break
+			}
+		}
+	}
 	rb.broadcastRevisions(ctx, w, setContracts, logger)
 
 	// register alerts for used hosts with lost sectors
@@ -1300,8 +1421,7 @@ func performPostMaintenanceTasks(ctx *mCtx, bus Bus, w Worker, alerter alerts.Al
 
 func performContractMaintenance(ctx *mCtx, alerter alerts.Alerter, bus Bus, churn *accumulatedChurn, w Worker, cc contractChecker, cr contractReviser, rb revisionBroadcaster, logger *zap.SugaredLogger) (bool, error) {
 	logger = logger.Named("performContractMaintenance").
-		Named(hex.EncodeToString(frand.Bytes(16))). // uuid for this iteration
-		With("contractSet", ctx.ContractSet())
+		Named(hex.EncodeToString(frand.Bytes(16))) // uuid for this iteration
 
 	// check if we want to run maintenance
 	if reason, skip := canSkipContractMaintenance(ctx, ctx.ContractsConfig()); skip {
@@ -1323,26 +1443,91 @@ func performContractMaintenance(ctx *mCtx, alerter alerts.Alerter, bus Bus, chur
 		return false, err
 	}
 
-	// STEP 2: perform contract maintenance
-	ipFilter := &hostSet{
-		logger:          logger.Named("ipFilter"),
-		subnetToHostKey: make(map[string]string),
-	}
-	keptContracts, churnReasons, err := performContractChecks(ctx, alerter, bus, w, cc, cr, ipFilter, logger, &remaining)
+	// STEP 2: maintain the default set and all additional sets
+	sets, changes, err := maintainContractSets(ctx, alerter, bus, w, cc, cr, logger, &remaining)
 	if err != nil {
 		return false, err
 	}
 
-	// STEP 3: perform contract formation
-	formedContracts, err := performContractFormations(ctx, bus, w, cr, ipFilter, logger, &remaining, int(ctx.WantedContracts())-len(keptContracts))
-	if err != nil {
+	// STEP 3: perform minor maintenance such as cleanups and broadcasting
+	// revisions
+	if err := performPostMaintenanceTasks(ctx, bus, w, alerter, cc, rb, logger); err != nil {
 		return false, err
 	}
 
+	// STEP 4: log changes and register alerts
+	var changed bool
+	for i, change := range changes {
+		setChanged, err := computeContractSetChanged(sets[i], alerter, bus, churn, logger.With("contractSet", sets[i].ContractSet()), change.oldSet, change.newSet, change.churnReasons)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || setChanged
+	}
+	return changed, nil
+}
+
+// maintainContractSets checks, renews and refreshes the existing contracts
+// once, then forms new contracts where necessary and updates the default set
+// as well as all additional sets. It returns the maintenance context and the
+// change of every set.
+func maintainContractSets(ctx *mCtx, alerter alerts.Alerter, bus Bus, w Worker, cc contractChecker, cr contractReviser, logger *zap.SugaredLogger, remaining *types.Currency) ([]*mCtx, []contractSetChange, error) {
+	sets := []*mCtx{ctx}
+	for _, set := range ctx.ContractsConfig().Sets {
+		sets = append(sets, ctx.forSet(set))
+	}
+
+	// perform contract checks
+	checks, err := performContractChecks(ctx, sets, alerter, bus, w, cc, cr, logger, remaining)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes := make([]contractSetChange, 0, len(sets))
+	for i, setCtx := range sets {
+		change, err := maintainContractSet(setCtx, checks[i], bus, w, cc, cr, logger.With("contractSet", setCtx.ContractSet()), remaining)
+		if err != nil {
+			return nil, nil, err
+		}
+		changes = append(changes, change)
+	}
+	return sets, changes, nil
+}
+
+// maintainContractSet forms new contracts if necessary and updates the
+// contract set of the given maintenance context using the result of checking
+// the existing contracts.
+func maintainContractSet(ctx *mCtx, check setCheck, bus Bus, w Worker, cc contractChecker, cr contractReviser, logger *zap.SugaredLogger, remaining *types.Currency) (contractSetChange, error) {
+	keptContracts, churnReasons, ipFilter := check.kept, check.churnReasons, check.ipFilter
+
 	// fetch old set
 	oldSet, err := bus.Contracts(ctx, api.ContractsOpts{ContractSet: ctx.ContractSet()})
 	if err != nil && !utils.IsErr(err, api.ErrContractSetNotFound) {
-		return false, fmt.Errorf("failed to fetch old contract set: %w", err)
+		return contractSetChange{}, fmt.Errorf("failed to fetch old contract set: %w", err)
+	}
+
+	// keep contracts that failed their checks if the churn should be dampened
+	for _, c := range cc.dampenChurn(ctx, oldSet, keptContracts, churnReasons) {
+		if uint64(len(keptContracts)) >= ctx.WantedContracts() {
+			break
+		}
+		host, err := bus.Host(ctx, c.HostKey)
+		if err != nil {
+			logger.With(zap.Error(err)).With("contractID", c.ID).Warn("failed to fetch host of dampened contract")
+			continue
+		}
+		logger.With("contractID", c.ID).
+			With("hostKey", c.HostKey).
+			With("reason", churnReasons[c.ID]).
+			Info("keeping failing contract in set to dampen churn")
+		keptContracts = append(keptContracts, c)
+		ipFilter.Add(host)
+	}
+
+	// perform contract formation
+	formedContracts, err := performContractFormations(ctx, bus, w, cr, ipFilter, logger, remaining, int(ctx.WantedContracts())-len(keptContracts))
+	if err != nil {
+		return contractSetChange{}, err
 	}
 
 	// merge kept and formed contracts into new set
@@ -1350,19 +1535,15 @@ func performContractMaintenance(ctx *mCtx, alerter alerts.Alerter, bus Bus, chur
 	newSet = append(newSet, keptContracts...)
 	newSet = append(newSet, formedContracts...)
 
-	// STEP 4: update contract set
+	// update contract set
 	if err := updateContractSet(ctx, bus, oldSet, newSet); err != nil {
-		return false, err
+		return contractSetChange{}, err
 	}
-
-	// STEP 5: perform minor maintenance such as cleanups and broadcasting
-	// revisions
-	if err := performPostMaintenanceTasks(ctx, bus, w, alerter, cc, rb, logger); err != nil {
-		return false, err
-	}
-
-	// STEP 6: log changes and register alerts
-	return computeContractSetChanged(ctx, alerter, bus, churn, logger, oldSet, newSet, churnReasons)
+	return contractSetChange{
+		oldSet:       oldSet,
+		newSet:       newSet,
+		churnReasons: churnReasons,
+	}, nil
 }
 
 func updateContractSet(ctx *mCtx, bus Bus, oldSet, newSet []api.ContractMetadata) error {
@@ -18,19 +18,19 @@ func TestCalculateMinScore(t *testing.T) {
 	}
 
 	// Test with 100 hosts which makes for a random set size of 250
-	minScore := calculateMinScore(candidates, 100, zap.NewNop().Sugar())
+	minScore := calculateMinScore(candidates, 100, testHostSet(), zap.NewNop().Sugar())
 	if minScore != 0.002 {
 		t.Fatalf("expected minScore to be 0.002 but was %v", minScore)
 	}
 
 	// Test with 0 hosts
-	minScore = calculateMinScore([]scoredHost{}, 100, zap.NewNop().Sugar())
+	minScore = calculateMinScore([]scoredHost{}, 100, testHostSet(), zap.NewNop().Sugar())
 	if minScore != math.SmallestNonzeroFloat64 {
 		t.Fatalf("expected minScore to be math.SmallestNonzeroFLoat64 but was %v", minScore)
 	}
 
 	// Test with 300 hosts which is 50 more than we have
-	minScore = calculateMinScore(candidates, 300, zap.NewNop().Sugar())
+	minScore = calculateMinScore(candidates, 300, testHostSet(), zap.NewNop().Sugar())
 	if minScore != math.SmallestNonzeroFloat64 {
 		t.Fatalf("expected minScore to be math.SmallestNonzeroFLoat64 but was %v", minScore)
 	}
@@ -1,19 +1,21 @@
 package contractor
 
 import (
+	"context"
 	"errors"
 
 	"go.thebigfile.com/core/types"
 	"go.thebigfile.com/renterd/api"
 	"go.thebigfile.com/renterd/internal/gouging"
+	"go.uber.org/zap"
 )
 
 var ErrMissingRequiredFields = errors.New("missing required fields in configuration, both allowance and amount must be set")
 
-func countUsableHosts(cfg api.AutopilotConfig, cs api.ConsensusState, fee types.Currency, period uint64, rs api.RedundancySettings, gs api.GougingSettings, hosts []api.Host) (usables uint64) {
+func countUsableHosts(cfg api.AutopilotConfig, cs api.ConsensusState, fee types.Currency, period uint64, gs api.GougingSettings, hosts []scoredHost) (usables uint64) {
 	gc := gouging.NewChecker(gs, cs, fee, &period, &cfg.Contracts.RenewWindow)
 	for _, host := range hosts {
-		hc := checkHost(gc, scoreHost(host, cfg, rs.Redundancy()), minValidScore)
+		hc := checkHost(gc, host, minValidScore)
 		if hc.Usability.IsUsable() {
 			usables++
 		}
@@ -23,8 +25,9 @@ func countUsableHosts(cfg api.AutopilotConfig, cs api.ConsensusState, fee types.
 
 // EvaluateConfig evaluates the given configuration and if the gouging settings
 // are too strict for the number of contracts required by 'cfg', it will provide
-// a recommendation on how to loosen it.
-func EvaluateConfig(cfg api.AutopilotConfig, cs api.ConsensusState, fee types.Currency, rs api.RedundancySettings, gs api.GougingSettings, hosts []api.Host) (resp api.ConfigEvaluationResponse, _ error) {
+// a recommendation on how to loosen it. Hosts are scored like they are during
+// contract maintenance, using the given custom host scorer if it's not nil.
+func EvaluateConfig(ctx context.Context, cfg api.AutopilotConfig, cs api.ConsensusState, fee types.Currency, rs api.RedundancySettings, gs api.GougingSettings, hosts []api.Host, scorer HostScorer, logger *zap.SugaredLogger) (resp api.ConfigEvaluationResponse, _ error) {
 	// we need an allowance and a target amount of contracts to evaluate
 	if cfg.Contracts.Allowance.IsZero() || cfg.Contracts.Amount == 0 {
 		return api.ConfigEvaluationResponse{}, ErrMissingRequiredFields
@@ -34,9 +37,12 @@ func EvaluateConfig(cfg api.AutopilotConfig, cs api.ConsensusState, fee types.Cu
 	gc := gouging.NewChecker(gs, cs, fee, &period, &cfg.Contracts.RenewWindow)
 
 	resp.Hosts = uint64(len(hosts))
-	for i, host := range hosts {
+	for i := range hosts {
 		hosts[i].PriceTable.HostBlockHeight = cs.BlockHeight // ignore block height
-		hc := checkHost(gc, scoreHost(host, cfg, rs.Redundancy()), minValidScore)
+	}
+	scored := scoreHosts(ctx, cfg, rs, scorer, hosts, logger)
+	for _, host := range scored {
+		hc := checkHost(gc, host, minValidScore)
 		if hc.Usability.IsUsable() {
 			resp.Usable++
 			continue
@@ -99,35 +105,35 @@ func EvaluateConfig(cfg api.AutopilotConfig, cs api.ConsensusState, fee types.Cu
 	// MaxRPCPrice
 	tmpGS := maxGS()
 	tmpGS.MaxRPCPrice = gs.MaxRPCPrice
-	if optimiseGougingSetting(&tmpGS, &tmpGS.MaxRPCPrice, cfg, cs, fee, period, rs, hosts) {
+	if optimiseGougingSetting(&tmpGS, &tmpGS.MaxRPCPrice, cfg, cs, fee, period, scored) {
 		optimisedGS.MaxRPCPrice = tmpGS.MaxRPCPrice
 		success = true
 	}
 	// MaxContractPrice
 	tmpGS = maxGS()
 	tmpGS.MaxContractPrice = gs.MaxContractPrice
-	if optimiseGougingSetting(&tmpGS, &tmpGS.MaxContractPrice, cfg, cs, fee, period, rs, hosts) {
+	if optimiseGougingSetting(&tmpGS, &tmpGS.MaxContractPrice, cfg, cs, fee, period, scored) {
 		optimisedGS.MaxContractPrice = tmpGS.MaxContractPrice
 		success = true
 	}
 	// MaxDownloadPrice
 	tmpGS = maxGS()
 	tmpGS.MaxDownloadPrice = gs.MaxDownloadPrice
-	if optimiseGougingSetting(&tmpGS, &tmpGS.MaxDownloadPrice, cfg, cs, fee, period, rs, hosts) {
+	if optimiseGougingSetting(&tmpGS, &tmpGS.MaxDownloadPrice, cfg, cs, fee, period, scored) {
 		optimisedGS.MaxDownloadPrice = tmpGS.MaxDownloadPrice
 		success = true
 	}
 	// MaxUploadPrice
 	tmpGS = maxGS()
 	tmpGS.MaxUploadPrice = gs.MaxUploadPrice
-	if optimiseGougingSetting(&tmpGS, &tmpGS.MaxUploadPrice, cfg, cs, fee, period, rs, hosts) {
+	if optimiseGougingSetting(&tmpGS, &tmpGS.MaxUploadPrice, cfg, cs, fee, period, scored) {
 		optimisedGS.MaxUploadPrice = tmpGS.MaxUploadPrice
 		success = true
 	}
 	// MaxStoragePrice
 	tmpGS = maxGS()
 	tmpGS.MaxStoragePrice = gs.MaxStoragePrice
-	if optimiseGougingSetting(&tmpGS, &tmpGS.MaxStoragePrice, cfg, cs, fee, period, rs, hosts) {
+	if optimiseGougingSetting(&tmpGS, &tmpGS.MaxStoragePrice, cfg, cs, fee, period, scored) {
 		optimisedGS.MaxStoragePrice = tmpGS.MaxStoragePrice
 		success = true
 	}
@@ -143,7 +149,7 @@ func EvaluateConfig(cfg api.AutopilotConfig, cs api.ConsensusState, fee types.Cu
 
 // optimiseGougingSetting tries to optimise one field of the gouging settings to
 // try and hit the target number of contracts.
-func optimiseGougingSetting(gs *api.GougingSettings, field *types.Currency, cfg api.AutopilotConfig, cs api.ConsensusState, fee types.Currency, currentPeriod uint64, rs api.RedundancySettings, hosts []api.Host) bool {
+func optimiseGougingSetting(gs *api.GougingSettings, field *types.Currency, cfg api.AutopilotConfig, cs api.ConsensusState, fee types.Currency, currentPeriod uint64, hosts []scoredHost) bool {
 	if cfg.Contracts.Amount == 0 {
 		return true // nothing to do
 	}
@@ -154,7 +160,7 @@ func optimiseGougingSetting(gs *api.GougingSettings, field *types.Currency, cfg
 	nSteps := 0
 	prevVal := *field // to keep accurate value
 	for {
-		nUsable := countUsableHosts(cfg, cs, fee, currentPeriod, rs, *gs, hosts)
+		nUsable := countUsableHosts(cfg, cs, fee, currentPeriod, *gs, hosts)
 		targetHit := nUsable >= cfg.Contracts.Amount
 
 		if targetHit && nSteps == 0 {
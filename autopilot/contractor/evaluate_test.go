@@ -1,6 +1,7 @@
 package contractor
 
 import (
+	"context"
 	"math"
 	"testing"
 	"time"
@@ -9,6 +10,7 @@ import (
 	rhpv3 "go.thebigfile.com/core/rhp/v3"
 	"go.thebigfile.com/core/types"
 	"go.thebigfile.com/renterd/api"
+	"go.uber.org/zap"
 )
 
 func TestOptimiseGougingSetting(t *testing.T) {
@@ -67,10 +69,15 @@ func TestOptimiseGougingSetting(t *testing.T) {
 		HostBlockHeightLeeway: math.MaxInt32,
 	}
 
+	// hosts are scored again after their prices change
+	scored := func() []scoredHost {
+		return scoreHosts(context.Background(), cfg, rs, nil, hosts, zap.NewNop().Sugar())
+	}
+
 	// confirm all hosts are usable
 	assertUsable := func(n int) {
 		t.Helper()
-		nUsable := countUsableHosts(cfg, cs, fee, 0, rs, gs, hosts)
+		nUsable := countUsableHosts(cfg, cs, fee, 0, gs, scored())
 		if nUsable != uint64(n) {
 			t.Fatalf("expected %v usable hosts, got %v", len(hosts), nUsable)
 		}
@@ -82,7 +89,7 @@ func TestOptimiseGougingSetting(t *testing.T) {
 		hosts[i].Settings.StoragePrice = types.Siacoins(uint32(i + 1))
 	}
 	assertUsable(1)
-	if !optimiseGougingSetting(&gs, &gs.MaxStoragePrice, cfg, cs, fee, 0, rs, hosts) {
+	if !optimiseGougingSetting(&gs, &gs.MaxStoragePrice, cfg, cs, fee, 0, scored()) {
 		t.Fatal("optimising failed")
 	}
 	assertUsable(len(hosts))
@@ -94,7 +101,7 @@ func TestOptimiseGougingSetting(t *testing.T) {
 	// hosts
 	hosts[0].Settings.StoragePrice = types.Siacoins(100000)
 	assertUsable(9)
-	if optimiseGougingSetting(&gs, &gs.MaxStoragePrice, cfg, cs, fee, 0, rs, hosts) {
+	if optimiseGougingSetting(&gs, &gs.MaxStoragePrice, cfg, cs, fee, 0, scored()) {
 		t.Fatal("optimising succeeded")
 	}
 	if gs.MaxStoragePrice.ExactString() != "41631744000000000000000000000" { // ~41.63 KS
@@ -107,7 +114,7 @@ func TestOptimiseGougingSetting(t *testing.T) {
 	}
 	gs.MaxStoragePrice = types.MaxCurrency.Sub(types.Siacoins(1))
 	assertUsable(0)
-	if optimiseGougingSetting(&gs, &gs.MaxStoragePrice, cfg, cs, fee, 0, rs, hosts) {
+	if optimiseGougingSetting(&gs, &gs.MaxStoragePrice, cfg, cs, fee, 0, scored()) {
 		t.Fatal("optimising succeeded")
 	}
 	if gs.MaxStoragePrice.ExactString() != "340282366920937463463374607431768211455" { // ~340.3 TS
@@ -0,0 +1,162 @@
+package contractor
+
+import (
+	"time"
+
+	"go.thebigfile.com/core/types"
+	"go.thebigfile.com/renterd/api"
+)
+
+const (
+	// ForecastInterval is the interval at which contract metrics are
+	// aggregated when forecasting spending.
+	ForecastInterval = 24 * time.Hour
+
+	// ForecastWindow is the number of intervals that are considered when
+	// estimating the spending trend.
+	ForecastWindow = 7
+)
+
+// ForecastWindowStartHeight returns the estimated height at the start of the
+// forecast window.
+func ForecastWindowStartHeight(bh uint64) uint64 {
+	blocks := uint64(ForecastWindow * ForecastInterval / targetBlockTime)
+	if bh < blocks {
+		return 0
+	}
+	return bh - blocks
+}
+
+// ForecastSpending projects the spending at the end of the current period. It
+// extrapolates the trend in usage spending found in the given contract
+// metrics, which are expected to be the metrics of the given contracts and
+// their ancestors within the forecast window, and assumes that every contract
+// in the set needs to be refreshed once its remaining funds are exhausted.
+//
+// Renewals and refreshes reset a contract's spending, so the trend is computed
+// per contract. A contract that started within the window contributes all of
+// its spending, other contracts contribute the spending since their first
+// metric in the window. Performance metrics aren't taken into account since
+// they don't record any spending.
+func ForecastSpending(ap api.Autopilot, contracts []api.ContractMetadata, ancestors []api.ArchivedContract, metrics []api.ContractMetric, hosts []api.Host, fee types.Currency, bh uint64, now time.Time) (f api.SpendingForecast) {
+	f.Allowance = ap.Config.Contracts.Allowance
+	f.PeriodEndHeight = ap.CurrentPeriod + ap.Config.Contracts.Period
+
+	// calculate the time remaining in the period
+	var remaining time.Duration
+	if f.PeriodEndHeight > bh {
+		remaining = time.Duration(f.PeriodEndHeight-bh) * targetBlockTime
+	}
+	f.PeriodEnd = api.TimeRFC3339(now.Add(remaining))
+
+	// what was spent in the current period is computed the same way contract
+	// maintenance computes the remaining allowance
+	f.Spent = currentPeriodSpending(contracts, ap.Config.Contracts.Period)
+
+	// estimate the cost of refreshing the contracts in the set
+	overheads := make(map[types.PublicKey]types.Currency)
+	for _, h := range hosts {
+		overheads[h.PublicKey] = contractOverhead(h, fee)
+	}
+	var overhead types.Currency
+	for _, c := range contracts {
+		if c.InSet(ap.Config.Contracts.Set) {
+			overhead = overhead.Add(overheads[c.HostKey])
+		}
+	}
+
+	// the allowance might already be exhausted
+	f.ProjectedSpending = f.Spent
+	if f.Spent.Cmp(f.Allowance) >= 0 {
+		exhausted := api.TimeRFC3339(now)
+		f.ExhaustedAt = &exhausted
+		f.BreachesAllowance = true
+		return
+	}
+
+	// find the first and last metric of every contract
+	first := make(map[types.FileContractID]api.ContractMetric)
+	last := make(map[types.FileContractID]api.ContractMetric)
+	var windowStart, windowEnd time.Time
+	for _, m := range metrics {
+		ts := time.Time(m.Timestamp)
+		if fm, ok := first[m.ContractID]; !ok || ts.Before(time.Time(fm.Timestamp)) {
+			first[m.ContractID] = m
+		}
+		if lm, ok := last[m.ContractID]; !ok || !ts.Before(time.Time(lm.Timestamp)) {
+			last[m.ContractID] = m
+		}
+		if windowStart.IsZero() || ts.Before(windowStart) {
+			windowStart = ts
+		}
+		if ts.After(windowEnd) {
+			windowEnd = ts
+		}
+	}
+
+	// without a trend there's nothing to extrapolate
+	window := windowEnd.Sub(windowStart)
+	if window < time.Second {
+		return
+	}
+
+	// sum up the usage of every contract
+	startHeights := make(map[types.FileContractID]uint64)
+	for _, c := range contracts {
+		startHeights[c.ID] = c.StartHeight
+	}
+	for _, c := range ancestors {
+		startHeights[c.ID] = c.StartHeight
+	}
+	windowStartHeight := ForecastWindowStartHeight(bh)
+	var used types.Currency
+	for fcid, m := range last {
+		spent := usageSpending(m)
+		if sh, ok := startHeights[fcid]; !ok || sh < windowStartHeight {
+			if base := usageSpending(first[fcid]); spent.Cmp(base) > 0 {
+				spent = spent.Sub(base)
+			} else {
+				spent = types.ZeroCurrency
+			}
+		}
+		used = used.Add(spent)
+	}
+	for _, c := range contracts {
+		f.ContractFunds = f.ContractFunds.Add(last[c.ID].RemainingFunds)
+	}
+	if used.IsZero() {
+		return
+	}
+	windowSecs := uint64(window.Seconds())
+	f.SpendingRate = used.Mul64(uint64(ForecastInterval.Seconds())).Div64(windowSecs)
+
+	// project the usage until the end of the period, whatever exceeds the
+	// funds in the contracts needs to be paid for by refreshing them
+	projected := used.Mul64(uint64(remaining.Seconds())).Div64(windowSecs)
+	if projected.Cmp(f.ContractFunds) > 0 {
+		f.RefreshOverhead = overhead
+		f.ProjectedSpending = f.Spent.Add(projected.Sub(f.ContractFunds)).Add(overhead)
+	}
+	f.BreachesAllowance = f.ProjectedSpending.Cmp(f.Allowance) > 0
+
+	// estimate when the allowance runs out, the first refresh incurs the
+	// overhead so we can only use what's left after paying for it
+	threshold := f.ContractFunds
+	if left := f.Allowance.Sub(f.Spent); left.Cmp(overhead) > 0 {
+		threshold = threshold.Add(left.Sub(overhead))
+	}
+	secs := threshold.Mul64(windowSecs).Div(used)
+	if secs.Cmp(types.NewCurrency64(uint64(remaining.Seconds()))) <= 0 {
+		exhausted := api.TimeRFC3339(now.Add(time.Duration(secs.Big().Uint64()) * time.Second))
+		f.ExhaustedAt = &exhausted
+	}
+	return
+}
+
+func usageSpending(m api.ContractMetric) types.Currency {
+	return m.UploadSpending.
+		Add(m.DownloadSpending).
+		Add(m.FundAccountSpending).
+		Add(m.DeleteSpending).
+		Add(m.ListSpending)
+}
@@ -0,0 +1,133 @@
+package contractor
+
+import (
+	"testing"
+	"time"
+
+	rhpv2 "go.thebigfile.com/core/rhp/v2"
+	"go.thebigfile.com/core/types"
+	"go.thebigfile.com/renterd/api"
+)
+
+func TestForecastSpending(t *testing.T) {
+	now := time.Now()
+	hk := types.PublicKey{1}
+
+	// one contract in the set, formed in the current period, what was spent
+	// is based on the window start like the remaining allowance
+	contracts := []api.ContractMetadata{
+		{
+			ID:           types.FileContractID{1},
+			HostKey:      hk,
+			StartHeight:  1000,
+			WindowStart:  1000,
+			TotalCost:    types.Siacoins(40),
+			ContractSets: []string{"autopilot"},
+		},
+		{
+			ID:          types.FileContractID{2},
+			HostKey:     hk,
+			StartHeight: 400, // previous period, before the forecast window
+			WindowStart: 1408,
+			TotalCost:   types.Siacoins(100),
+		},
+	}
+	hosts := []api.Host{{
+		PublicKey: hk,
+		Settings:  rhpv2.HostSettings{ContractPrice: types.Siacoins(1)},
+	}}
+
+	// 10SC of usage per day, 5SC left in the contracts
+	metrics := []api.ContractMetric{
+		{ContractID: types.FileContractID{2}, Timestamp: api.TimeRFC3339(now.Add(-24 * time.Hour)), UploadSpending: types.Siacoins(2)},
+		{ContractID: types.FileContractID{2}, Timestamp: api.TimeRFC3339(now), UploadSpending: types.Siacoins(8), DownloadSpending: types.Siacoins(4), RemainingFunds: types.Siacoins(5)},
+	}
+
+	// half a week left in the period
+	ap := api.Autopilot{CurrentPeriod: 1000}
+	ap.Config.Contracts.Period = 1008
+	ap.Config.Contracts.Set = "autopilot"
+	bh := uint64(1504)
+
+	forecast := func(allowance types.Currency, metrics []api.ContractMetric) api.SpendingForecast {
+		t.Helper()
+		ap.Config.Contracts.Allowance = allowance
+		return ForecastSpending(ap, contracts, nil, metrics, hosts, types.ZeroCurrency, bh, now)
+	}
+
+	// assert the forecast within the allowance, we project 35SC of usage of
+	// which 30SC exceed the contract funds, plus 1SC to refresh the contract
+	f := forecast(types.Siacoins(100), metrics)
+	if !f.Spent.Equals(types.Siacoins(40)) {
+		t.Fatal("unexpected spent", f.Spent)
+	} else if !f.SpendingRate.Equals(types.Siacoins(10)) {
+		t.Fatal("unexpected rate", f.SpendingRate)
+	} else if !f.RefreshOverhead.Equals(types.Siacoins(1)) {
+		t.Fatal("unexpected overhead", f.RefreshOverhead)
+	} else if !f.ProjectedSpending.Equals(types.Siacoins(71)) {
+		t.Fatal("unexpected projected spending", f.ProjectedSpending)
+	} else if f.BreachesAllowance || f.ExhaustedAt != nil {
+		t.Fatal("unexpected breach", f.BreachesAllowance, f.ExhaustedAt)
+	} else if f.PeriodEndHeight != 2008 {
+		t.Fatal("unexpected period end height", f.PeriodEndHeight)
+	} else if time.Time(f.PeriodEnd).Sub(now) != 84*time.Hour {
+		t.Fatal("unexpected period end", f.PeriodEnd)
+	}
+
+	// lower the allowance, the remaining 20SC cover 5SC of funds plus 19SC of
+	// usage after the refresh, which runs out after 2.4 days
+	f = forecast(types.Siacoins(60), metrics)
+	if !f.BreachesAllowance {
+		t.Fatal("expected breach")
+	} else if f.ExhaustedAt == nil {
+		t.Fatal("expected exhaustion time")
+	} else if d := time.Time(*f.ExhaustedAt).Sub(now); d != 57*time.Hour+36*time.Minute {
+		t.Fatal("unexpected exhaustion time", d)
+	}
+
+	// exhaust the allowance
+	f = forecast(types.Siacoins(40), metrics)
+	if !f.BreachesAllowance {
+		t.Fatal("expected breach")
+	} else if f.ExhaustedAt == nil || !time.Time(*f.ExhaustedAt).Equal(now) {
+		t.Fatal("unexpected exhaustion time", f.ExhaustedAt)
+	}
+
+	// without a trend only the spent amount is projected
+	f = forecast(types.Siacoins(60), metrics[1:])
+	if !f.ProjectedSpending.Equals(f.Spent) || f.BreachesAllowance || f.ExhaustedAt != nil {
+		t.Fatal("unexpected forecast", f)
+	}
+}
+
+func TestForecastSpendingRenewals(t *testing.T) {
+	now := time.Now()
+	hk := types.PublicKey{1}
+
+	// the contract was renewed within the forecast window, its ancestor
+	// started before the window
+	ancestor := api.ArchivedContract{ID: types.FileContractID{1}, HostKey: hk, StartHeight: 400, RenewedTo: types.FileContractID{2}}
+	contract := api.ContractMetadata{ID: types.FileContractID{2}, HostKey: hk, StartHeight: 1400, RenewedFrom: ancestor.ID}
+
+	// the ancestor's spending increases by 4SC before it's renewed, the
+	// renewal resets the spending which then increases by 6SC
+	metrics := []api.ContractMetric{
+		{ContractID: ancestor.ID, Timestamp: api.TimeRFC3339(now.Add(-48 * time.Hour)), UploadSpending: types.Siacoins(20)},
+		{ContractID: ancestor.ID, Timestamp: api.TimeRFC3339(now.Add(-24 * time.Hour)), UploadSpending: types.Siacoins(24)},
+		{ContractID: contract.ID, Timestamp: api.TimeRFC3339(now.Add(-24 * time.Hour)), UploadSpending: types.Siacoins(1)},
+		{ContractID: contract.ID, Timestamp: api.TimeRFC3339(now), UploadSpending: types.Siacoins(6), RemainingFunds: types.Siacoins(5)},
+	}
+
+	ap := api.Autopilot{CurrentPeriod: 1000}
+	ap.Config.Contracts.Period = 1008
+	ap.Config.Contracts.Allowance = types.Siacoins(1000)
+
+	// assert the usage of both contracts is taken into account, 10SC over two
+	// days
+	f := ForecastSpending(ap, []api.ContractMetadata{contract}, []api.ArchivedContract{ancestor}, metrics, nil, types.ZeroCurrency, 1504, now)
+	if !f.SpendingRate.Equals(types.Siacoins(5)) {
+		t.Fatal("unexpected rate", f.SpendingRate)
+	} else if !f.ContractFunds.Equals(types.Siacoins(5)) {
+		t.Fatal("unexpected contract funds", f.ContractFunds)
+	}
+}
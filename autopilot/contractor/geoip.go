@@ -0,0 +1,152 @@
+package contractor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.thebigfile.com/renterd/api"
+)
+
+// asDescriptionSeparators separate the name of a provider from the rest of an
+// AS description.
+const asDescriptionSeparators = " -_,."
+
+type (
+	// GeoIPDatabase resolves IP addresses to the country, autonomous system
+	// and hosting provider they belong to. It's loaded from a tab-separated
+	// IP-to-ASN file in the format of the iptoasn.com databases, every line
+	// contains the first and last address of a range followed by the AS
+	// number, the country code and the description of the AS.
+	GeoIPDatabase struct {
+		ranges []ipRange // sorted by start address
+	}
+
+	ipRange struct {
+		start netip.Addr
+		end   netip.Addr
+		loc   hostLocation
+	}
+
+	// hostLocation describes where a host is located, hosts in the same
+	// location share a single point of failure.
+	hostLocation struct {
+		Country  string
+		ASN      uint32
+		Provider string
+	}
+)
+
+// LoadGeoIPDatabase loads the GeoIP database at the given path.
+func LoadGeoIPDatabase(path string) (*GeoIPDatabase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseGeoIPDatabase(f)
+}
+
+func parseGeoIPDatabase(r io.Reader) (*GeoIPDatabase, error) {
+	var db GeoIPDatabase
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		if s.Text() == "" || strings.HasPrefix(s.Text(), "#") {
+			continue
+		}
+		fields := strings.SplitN(s.Text(), "\t", 5)
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("line %d: expected at least 4 fields, got %d", line, len(fields))
+		}
+		start, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid start address: %w", line, err)
+		}
+		end, err := netip.ParseAddr(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid end address: %w", line, err)
+		} else if start.Is4() != end.Is4() || end.Less(start) {
+			return nil, fmt.Errorf("line %d: invalid range %v-%v", line, start, end)
+		}
+		asn, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid AS number: %w", line, err)
+		} else if asn == 0 {
+			continue // range is not routed
+		}
+		var description string
+		if len(fields) == 5 {
+			description = fields[4]
+		}
+		db.ranges = append(db.ranges, ipRange{
+			start: start.Unmap(),
+			end:   end.Unmap(),
+			loc: hostLocation{
+				Country:  strings.ToUpper(fields[3]),
+				ASN:      uint32(asn),
+				Provider: providerFromASDescription(description),
+			},
+		})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+	return &db, nil
+}
+
+// Lookup returns the location of the given address.
+func (db *GeoIPDatabase) Lookup(addr netip.Addr) (hostLocation, bool) {
+	addr = addr.Unmap()
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return addr.Less(db.ranges[i].start)
+	})
+	if i == 0 {
+		return hostLocation{}, false
+	} else if r := db.ranges[i-1]; r.end.Less(addr) {
+		return hostLocation{}, false
+	} else {
+		return r.loc, true
+	}
+}
+
+// LocateHost returns the location of the first of the host's resolved
+// addresses that is found in the database.
+func (db *GeoIPDatabase) LocateHost(host api.Host) (hostLocation, bool) {
+	for _, address := range host.ResolvedAddresses {
+		addr, err := netip.ParseAddr(address)
+		if err != nil {
+			continue
+		} else if loc, ok := db.Lookup(addr); ok {
+			return loc, true
+		}
+	}
+	return hostLocation{}, false
+}
+
+// providerFromASDescription derives the hosting provider from the description
+// of an AS. Providers often operate multiple ASes that share the provider's
+// name as their prefix, e.g. 'AMAZON-02' and 'AMAZON-AES', so the description
+// is cut at the first separator. Some descriptions start with a generic 'AS'
+// or 'ASN' prefix, e.g. 'AS-CHOOPA' or 'ASN-QUADRANET-GLOBAL', which is
+// stripped first.
+func providerFromASDescription(description string) string {
+	description = strings.ToUpper(strings.TrimSpace(description))
+	for _, prefix := range []string{"ASN", "AS"} {
+		if rest, ok := strings.CutPrefix(description, prefix); ok && len(rest) > 1 && strings.IndexByte(asDescriptionSeparators, rest[0]) >= 0 {
+			description = rest[1:]
+			break
+		}
+	}
+	if i := strings.IndexAny(description, asDescriptionSeparators); i > 0 {
+		description = description[:i]
+	}
+	return description
+}
@@ -0,0 +1,97 @@
+package contractor
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"go.thebigfile.com/renterd/api"
+)
+
+const testGeoIPDatabase = `# start	end	asn	country	description
+3.0.0.0	3.0.0.255	300	us	AMAZON-02
+1.0.0.0	1.0.0.255	100	us	AMAZON-AES - Amazon.com, Inc.
+2.0.0.0	2.0.0.255	200	US	HETZNER-AS
+4.0.0.0	4.0.0.255	0	None	Not routed
+2001:db8::	2001:db8::ffff	400	DE	Hetzner Online GmbH
+`
+
+func TestGeoIPDatabase(t *testing.T) {
+	db, err := parseGeoIPDatabase(strings.NewReader(testGeoIPDatabase))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(db.ranges) != 4 {
+		t.Fatalf("expected 4 ranges, got %d", len(db.ranges))
+	}
+
+	tests := []struct {
+		addr  string
+		found bool
+		loc   hostLocation
+	}{
+		{"1.0.0.0", true, hostLocation{"US", 100, "AMAZON"}},
+		{"1.0.0.255", true, hostLocation{"US", 100, "AMAZON"}},
+		{"::ffff:3.0.0.1", true, hostLocation{"US", 300, "AMAZON"}},
+		{"2.0.0.1", true, hostLocation{"US", 200, "HETZNER"}},
+		{"2001:db8::1", true, hostLocation{"DE", 400, "HETZNER"}},
+		{"0.255.255.255", false, hostLocation{}},
+		{"1.0.1.0", false, hostLocation{}},
+		{"4.0.0.1", false, hostLocation{}},
+		{"5.0.0.1", false, hostLocation{}},
+	}
+	for _, test := range tests {
+		loc, found := db.Lookup(netip.MustParseAddr(test.addr))
+		if found != test.found {
+			t.Fatalf("%v: expected found to be %v", test.addr, test.found)
+		} else if loc != test.loc {
+			t.Fatalf("%v: expected location %+v, got %+v", test.addr, test.loc, loc)
+		}
+	}
+
+	// the first address that's found is used to locate the host
+	loc, found := db.LocateHost(api.Host{ResolvedAddresses: []string{"invalid", "5.0.0.1", "2.0.0.1", "1.0.0.1"}})
+	if !found {
+		t.Fatal("expected host to be located")
+	} else if loc.ASN != 200 {
+		t.Fatalf("expected host to be located in AS200, got AS%d", loc.ASN)
+	}
+
+	// invalid databases are rejected
+	for _, invalid := range []string{
+		"1.0.0.0\t1.0.0.255\t100",
+		"foo\t1.0.0.255\t100\tUS\tFOO",
+		"1.0.0.255\t1.0.0.0\t100\tUS\tFOO",
+		"1.0.0.0\t2001:db8::\t100\tUS\tFOO",
+		"1.0.0.0\t1.0.0.255\tAS100\tUS\tFOO",
+	} {
+		if _, err := parseGeoIPDatabase(strings.NewReader(invalid)); err == nil {
+			t.Fatalf("expected '%s' to be rejected", invalid)
+		}
+	}
+}
+
+func TestProviderFromASDescription(t *testing.T) {
+	tests := []struct {
+		description string
+		provider    string
+	}{
+		{"AMAZON-02", "AMAZON"},
+		{"AMAZON-AES - Amazon.com, Inc.", "AMAZON"},
+		{"HETZNER-AS", "HETZNER"},
+		{"Hetzner Online GmbH", "HETZNER"},
+		{"OVH", "OVH"},
+		{"AS-CHOOPA - The Constant Company, LLC", "CHOOPA"},
+		{"AS-COLOCROSSING - ColoCrossing", "COLOCROSSING"},
+		{"ASN-QUADRANET-GLOBAL - QuadraNet Enterprises LLC", "QUADRANET"},
+		{"as-choopa", "CHOOPA"},
+		{"AS_BLAZINGSEO", "BLAZINGSEO"},
+		{"ASUSTEK-AS", "ASUSTEK"},
+		{"AS", "AS"},
+		{"AS-", "AS"},
+	}
+	for _, test := range tests {
+		if provider := providerFromASDescription(test.description); provider != test.provider {
+			t.Errorf("%q: expected provider %q, got %q", test.description, test.provider, provider)
+		}
+	}
+}
@@ -37,6 +37,8 @@ var (
 	errContractNoRevision        = errors.New("contract has no revision")
 	errContractExpired           = errors.New("contract has expired")
 	errContractNotConfirmed      = errors.New("contract hasn't been confirmed on chain in time")
+
+	errHostNotInSetFilter = errors.New("host doesn't pass the contract set's host filter")
 )
 
 type unusableHostsBreakdown struct {
@@ -271,7 +273,3 @@ func newScoredHost(h api.Host, sb api.HostScoreBreakdown) scoredHost {
 		score: sb.Score(),
 	}
 }
-
-func scoreHost(h api.Host, cfg api.AutopilotConfig, expectedRedundancy float64) scoredHost {
-	return newScoredHost(h, hostScore(cfg, h, expectedRedundancy))
-}
@@ -47,7 +47,7 @@ func hostScore(cfg api.AutopilotConfig, h api.Host, expectedRedundancy float64)
 		StorageRemaining: storageRemainingScore(h.Settings, h.StoredData, allocationPerHost),
 		Uptime:           uptimeScore(h),
 		Version:          versionScore(h.Settings, cfg.Hosts.MinProtocolVersion),
-	}
+	}.Weighted(cfg.Hosts.ScoreWeights)
 }
 
 // priceAdjustmentScore computes a score between 0 and 1 for a host giving its
@@ -1,7 +1,12 @@
 package contractor
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"math"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -10,6 +15,7 @@ import (
 	"go.thebigfile.com/core/types"
 	"go.thebigfile.com/renterd/api"
 	"go.thebigfile.com/renterd/internal/test"
+	"go.uber.org/zap"
 )
 
 var cfg = api.AutopilotConfig{
@@ -114,6 +120,133 @@ func TestHostScore(t *testing.T) {
 	_ = hostScore(cfg, h1, redundancy)
 }
 
+func TestHostScoreWeights(t *testing.T) {
+	h := test.NewHost(test.RandomHostKey(), test.NewHostPriceTable(), test.NewHostSettings())
+	h.KnownSince = time.Now().Add(-24 * time.Hour)
+
+	cfg := cfg
+	cfg.Contracts.Allowance = types.Siacoins(1000)
+	cfg.Contracts.Amount = 50
+	sb := hostScore(cfg, h, 3)
+	if sb.Age == 1 {
+		t.Fatal("expected young host to have an age score below 1")
+	}
+
+	// disabling the age component should max out its score
+	cfg.Hosts.ScoreWeights = map[string]api.HostScoreWeight{
+		api.HostScoreAge: {Weight: 0, Exponent: 1},
+	}
+	if weighted := hostScore(cfg, h, 3); weighted.Age != 1 {
+		t.Fatalf("expected age score to be 1, got %v", weighted.Age)
+	} else if weighted.Score() <= sb.Score() {
+		t.Fatal("expected disabling a component to increase the score")
+	}
+
+	// a higher exponent should punish the host harder
+	cfg.Hosts.ScoreWeights = map[string]api.HostScoreWeight{
+		api.HostScoreAge: {Weight: 1, Exponent: 2},
+	}
+	if weighted := hostScore(cfg, h, 3); weighted.Age != math.Pow(sb.Age, 2) {
+		t.Fatalf("expected age score to be %v, got %v", math.Pow(sb.Age, 2), weighted.Age)
+	}
+
+	// a neutral weight should not change the score
+	cfg.Hosts.ScoreWeights = map[string]api.HostScoreWeight{
+		api.HostScoreAge: {Weight: 1, Exponent: 1},
+	}
+	if weighted := hostScore(cfg, h, 3); weighted != sb {
+		t.Fatalf("expected score to be unchanged, got %v", weighted)
+	}
+}
+
+func TestHTTPHostScorer(t *testing.T) {
+	var requests int
+	var lastReqs []api.HostScoreRequest
+	var respond []api.HostScoreBreakdown
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if err := json.NewDecoder(r.Body).Decode(&lastReqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(respond)
+	}))
+	defer srv.Close()
+
+	sb := api.HostScoreBreakdown{Age: 1, Collateral: 1, Interactions: 1, Prices: 1, StorageRemaining: 1, Uptime: 1, Version: 1}
+	hosts := []scoredHost{
+		newScoredHost(test.NewHost(test.RandomHostKey(), test.NewHostPriceTable(), test.NewHostSettings()), sb),
+		newScoredHost(test.NewHost(test.RandomHostKey(), test.NewHostPriceTable(), test.NewHostSettings()), sb),
+	}
+	scorer := NewHTTPHostScorer(srv.URL)
+
+	// assert the scorer receives all hosts and their breakdowns in a single
+	// request, the invalid breakdown is ignored
+	adjusted := sb
+	adjusted.Uptime = 0.5
+	invalid := sb
+	invalid.Uptime = 2
+	respond = []api.HostScoreBreakdown{adjusted, invalid}
+	customHostScores(context.Background(), scorer, hosts, zap.NewNop().Sugar())
+	if requests != 1 {
+		t.Fatalf("expected 1 request, got %d", requests)
+	} else if len(lastReqs) != 2 || lastReqs[0].Host.PublicKey != hosts[0].host.PublicKey || lastReqs[1].ScoreBreakdown != sb {
+		t.Fatalf("unexpected request %+v", lastReqs)
+	} else if hosts[0].sb != adjusted || hosts[1].sb != sb {
+		t.Fatalf("unexpected breakdowns %v %v", hosts[0].sb, hosts[1].sb)
+	}
+
+	// assert a response with the wrong number of breakdowns is ignored
+	respond = []api.HostScoreBreakdown{sb}
+	customHostScores(context.Background(), scorer, hosts, zap.NewNop().Sugar())
+	if hosts[0].sb != adjusted {
+		t.Fatalf("unexpected breakdown %v", hosts[0].sb)
+	}
+}
+
+type hostScorerFunc func(ctx context.Context, reqs []api.HostScoreRequest) ([]api.HostScoreBreakdown, error)
+
+func (fn hostScorerFunc) ScoreHosts(ctx context.Context, reqs []api.HostScoreRequest) ([]api.HostScoreBreakdown, error) {
+	return fn(ctx, reqs)
+}
+
+func TestCustomHostScores(t *testing.T) {
+	sb := api.HostScoreBreakdown{Age: 1, Collateral: 1, Interactions: 1, Prices: 1, StorageRemaining: 1, Uptime: 1, Version: 1}
+	var hosts []scoredHost
+	for i := 0; i < 3; i++ {
+		hosts = append(hosts, newScoredHost(api.Host{PublicKey: types.PublicKey{byte(i)}}, sb))
+	}
+
+	// the scorer is called with a deadline
+	var calls int
+	scorer := hostScorerFunc(func(ctx context.Context, reqs []api.HostScoreRequest) ([]api.HostScoreBreakdown, error) {
+		calls++
+		if _, ok := ctx.Deadline(); !ok {
+			t.Fatal("expected deadline")
+		}
+		return nil, errors.New("scorer failed")
+	})
+
+	// assert no host was dropped and all of them kept their breakdown
+	customHostScores(context.Background(), scorer, hosts, zap.NewNop().Sugar())
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+	for i, h := range hosts {
+		if h.host.PublicKey[0] != byte(i) {
+			t.Fatalf("unexpected host at index %d", i)
+		} else if h.sb != sb || h.score != sb.Score() {
+			t.Fatalf("unexpected breakdown for host %d: %v", i, h.sb)
+		}
+	}
+
+	// the scorer isn't called without hosts
+	customHostScores(context.Background(), scorer, nil, zap.NewNop().Sugar())
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
 func TestPriceAdjustmentScore(t *testing.T) {
 	score := func(cpp uint32) float64 {
 		t.Helper()
@@ -0,0 +1,138 @@
+package contractor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"go.thebigfile.com/renterd/api"
+	"go.thebigfile.com/renterd/internal/utils"
+	"go.uber.org/zap"
+)
+
+type (
+	// HostScorer allows for scoring hosts using external data, e.g. latency
+	// or reliability metrics gathered by the operator. It's passed the hosts
+	// and the score breakdowns computed by the autopilot, with the configured
+	// weights applied, and returns the breakdowns the hosts are scored with
+	// in the same order.
+	HostScorer interface {
+		ScoreHosts(ctx context.Context, reqs []api.HostScoreRequest) ([]api.HostScoreBreakdown, error)
+	}
+
+	httpHostScorer struct {
+		url string
+	}
+)
+
+// NewHTTPHostScorer returns a HostScorer that scores hosts by sending all
+// api.HostScoreRequests in a single request to the given URL, the response is
+// expected to contain the hosts' score breakdowns in the same order.
+func NewHTTPHostScorer(url string) HostScorer {
+	return &httpHostScorer{url: url}
+}
+
+func (s *httpHostScorer) ScoreHosts(ctx context.Context, reqs []api.HostScoreRequest) ([]api.HostScoreBreakdown, error) {
+	body, err := json.Marshal(reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp []api.HostScoreBreakdown
+	if _, _, err := utils.DoRequest(req, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// validateScoreBreakdown returns an error if any of the scores of the given
+// breakdown returned by a custom host scorer is not between 0 and 1.
+func validateScoreBreakdown(sb api.HostScoreBreakdown) error {
+	for _, score := range []float64{sb.Age, sb.Collateral, sb.Interactions, sb.Prices, sb.StorageRemaining, sb.Uptime, sb.Version} {
+		if math.IsNaN(score) || score < 0 || score > 1 {
+			return fmt.Errorf("invalid score breakdown '%v', scores must be between 0 and 1", sb)
+		}
+	}
+	return nil
+}
+
+// customHostScores scores the given hosts using the given scorer, all hosts are
+// scored in a single call that has to complete within timeoutHostScore. If
+// the scorer fails, all hosts keep the breakdown computed by the autopilot,
+// hosts it returns an invalid breakdown for keep theirs as well.
+func customHostScores(ctx context.Context, scorer HostScorer, hosts []scoredHost, logger *zap.SugaredLogger) {
+	if len(hosts) == 0 {
+		return
+	}
+
+	reqs := make([]api.HostScoreRequest, len(hosts))
+	for i, h := range hosts {
+		reqs[i] = api.HostScoreRequest{
+			Host:           h.host,
+			ScoreBreakdown: h.sb,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeoutHostScore)
+	defer cancel()
+	sbs, err := scorer.ScoreHosts(ctx, reqs)
+	if err == nil && len(sbs) != len(hosts) {
+		err = fmt.Errorf("expected %d score breakdowns, got %d", len(hosts), len(sbs))
+	}
+	if err != nil {
+		logger.With(zap.Error(err)).Info("custom host scorer failed, falling back to the autopilot's scores")
+		return
+	}
+
+	for i, sb := range sbs {
+		if err := validateScoreBreakdown(sb); err != nil {
+			logger.With(zap.Error(err)).
+				With("hostKey", hosts[i].host.PublicKey).
+				Info("custom host scorer returned an invalid breakdown, falling back to the autopilot's score")
+			continue
+		}
+		hosts[i] = newScoredHost(hosts[i].host, sb)
+	}
+}
+
+// scoreHosts scores the given hosts with the configured weights applied and
+// adjusts the scores using the custom host scorer, if one is configured. It's
+// used by both contract maintenance and the config evaluation so hosts are
+// scored the same way. Hosts that can't be scored are skipped.
+func scoreHosts(ctx context.Context, cfg api.AutopilotConfig, rs api.RedundancySettings, scorer HostScorer, hosts []api.Host, logger *zap.SugaredLogger) []scoredHost {
+	scored := make([]scoredHost, 0, len(hosts))
+	for _, h := range hosts {
+		sb, err := safeHostScore(cfg, h, rs.Redundancy())
+		if err != nil {
+			logger.With(zap.Error(err)).
+				With("hostKey", h.PublicKey).
+				Info("failed to score host")
+			continue
+		}
+		scored = append(scored, newScoredHost(h, sb))
+	}
+	if scorer != nil {
+		customHostScores(ctx, scorer, scored, logger)
+	}
+	return scored
+}
+
+func safeHostScore(cfg api.AutopilotConfig, h api.Host, expectedRedundancy float64) (sb api.HostScoreBreakdown, err error) {
+	// host settings that cause a panic should result in a score of 0
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("panic while scoring host")
+		}
+	}()
+	return hostScore(cfg, h, expectedRedundancy), nil
+}
@@ -3,6 +3,8 @@ package contractor
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"time"
 
 	"go.thebigfile.com/renterd/api"
@@ -18,10 +20,77 @@ type (
 	hostSet struct {
 		subnetToHostKey map[string]string
 
+		// geo is used to locate the hosts in the set to enforce the
+		// diversity limits, if it's nil the limits are not enforced
+		geo       *GeoIPDatabase
+		limits    diversityLimits
+		countries map[string]int
+		asns      map[uint32]int
+		providers map[string]int
+
 		logger *zap.SugaredLogger
 	}
+
+	// diversityLimits contains the maximum number of hosts in a set that
+	// may share a country, AS or hosting provider, 0 means unlimited.
+	diversityLimits struct {
+		country  int
+		asn      int
+		provider int
+	}
 )
 
+func newHostSet(geo *GeoIPDatabase, limits diversityLimits, logger *zap.SugaredLogger) *hostSet {
+	return &hostSet{
+		subnetToHostKey: make(map[string]string),
+
+		geo:       geo,
+		limits:    limits,
+		countries: make(map[string]int),
+		asns:      make(map[uint32]int),
+		providers: make(map[string]int),
+
+		logger: logger,
+	}
+}
+
+// newDiversityLimits turns the shares of the config into limits for a set
+// with the given number of contracts. Every limit allows for at least one
+// host so a low share never prevents a set from being formed.
+func newDiversityLimits(cfg api.HostsConfig, contracts uint64) diversityLimits {
+	limit := func(share float64) int {
+		if share <= 0 {
+			return 0
+		}
+		return max(1, int(math.Floor(share*float64(contracts))))
+	}
+	return diversityLimits{
+		country:  limit(cfg.MaxCountryShare),
+		asn:      limit(cfg.MaxASNShare),
+		provider: limit(cfg.MaxProviderShare),
+	}
+}
+
+// ExceedsDiversityLimits returns true if adding the host to the set would
+// exceed one of the diversity limits. Hosts that can't be located never
+// exceed the limits.
+func (hs *hostSet) ExceedsDiversityLimits(host api.Host) (string, bool) {
+	if hs.geo == nil {
+		return "", false
+	}
+	loc, ok := hs.geo.LocateHost(host)
+	if !ok {
+		return "", false
+	} else if hs.limits.country > 0 && loc.Country != "" && hs.countries[loc.Country] >= hs.limits.country {
+		return fmt.Sprintf("too many hosts in country %s", loc.Country), true
+	} else if hs.limits.asn > 0 && hs.asns[loc.ASN] >= hs.limits.asn {
+		return fmt.Sprintf("too many hosts in AS%d", loc.ASN), true
+	} else if hs.limits.provider > 0 && loc.Provider != "" && hs.providers[loc.Provider] >= hs.limits.provider {
+		return fmt.Sprintf("too many hosts with provider %s", loc.Provider), true
+	}
+	return "", false
+}
+
 func (hs *hostSet) HasRedundantIP(host api.Host) bool {
 	// compat code for hosts that have been scanned before ResolvedAddresses
 	// were introduced
@@ -69,4 +138,12 @@ func (hs *hostSet) Add(host api.Host) {
 	for _, subnet := range subnets {
 		hs.subnetToHostKey[subnet] = host.PublicKey.String()
 	}
+
+	if hs.geo != nil {
+		if loc, ok := hs.geo.LocateHost(host); ok {
+			hs.countries[loc.Country]++
+			hs.asns[loc.ASN]++
+			hs.providers[loc.Provider]++
+		}
+	}
 }
@@ -1,6 +1,7 @@
 package contractor
 
 import (
+	"strings"
 	"testing"
 
 	"go.thebigfile.com/core/types"
@@ -8,11 +9,12 @@ import (
 	"go.uber.org/zap"
 )
 
+func testHostSet() *hostSet {
+	return newHostSet(nil, diversityLimits{}, zap.NewNop().Sugar())
+}
+
 func TestHostSet(t *testing.T) {
-	hs := &hostSet{
-		subnetToHostKey: make(map[string]string),
-		logger:          zap.NewNop().Sugar(),
-	}
+	hs := testHostSet()
 
 	// Host with no subnets
 	host1 := api.Host{
@@ -75,3 +77,60 @@ func TestHostSet(t *testing.T) {
 		t.Fatal("Expected host with one overlapping subnet to be considered redundant")
 	}
 }
+
+func TestHostSetDiversityLimits(t *testing.T) {
+	db, err := parseGeoIPDatabase(strings.NewReader(testGeoIPDatabase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newHost := func(addr string) api.Host {
+		return api.Host{
+			PublicKey:         types.GeneratePrivateKey().PublicKey(),
+			ResolvedAddresses: []string{addr},
+		}
+	}
+
+	// allow 2 hosts per country and per provider, 1 per AS
+	hs := newHostSet(db, diversityLimits{country: 2, asn: 1, provider: 2}, zap.NewNop().Sugar())
+	hs.Add(newHost("1.0.0.1")) // AS100 in US
+
+	if _, exceeds := hs.ExceedsDiversityLimits(newHost("1.0.0.2")); !exceeds {
+		t.Fatal("expected host in the same AS to exceed the limits")
+	} else if _, exceeds := hs.ExceedsDiversityLimits(newHost("2.0.0.1")); exceeds {
+		t.Fatal("expected host in a different AS to not exceed the limits")
+	}
+	hs.Add(newHost("2.0.0.1")) // AS200 in US
+
+	if reason, exceeds := hs.ExceedsDiversityLimits(newHost("3.0.0.1")); !exceeds {
+		t.Fatal("expected third host in the same country to exceed the limits")
+	} else if !strings.Contains(reason, "country US") {
+		t.Fatalf("unexpected reason '%s'", reason)
+	}
+
+	// hosts that can't be located never exceed the limits
+	if _, exceeds := hs.ExceedsDiversityLimits(newHost("9.9.9.9")); exceeds {
+		t.Fatal("expected unknown host to not exceed the limits")
+	}
+
+	// without a database the limits are not enforced
+	hs = newHostSet(nil, diversityLimits{country: 1, asn: 1, provider: 1}, zap.NewNop().Sugar())
+	hs.Add(newHost("1.0.0.1"))
+	if _, exceeds := hs.ExceedsDiversityLimits(newHost("1.0.0.2")); exceeds {
+		t.Fatal("expected limits to not be enforced without a database")
+	}
+}
+
+func TestNewDiversityLimits(t *testing.T) {
+	limits := newDiversityLimits(api.HostsConfig{
+		MaxCountryShare:  0.5,
+		MaxASNShare:      0.001,
+		MaxProviderShare: 0,
+	}, 50)
+	if limits.country != 25 {
+		t.Fatalf("expected country limit to be 25, got %d", limits.country)
+	} else if limits.asn != 1 {
+		t.Fatalf("expected ASN limit to be 1, got %d", limits.asn)
+	} else if limits.provider != 0 {
+		t.Fatalf("expected provider limit to be 0, got %d", limits.provider)
+	}
+}
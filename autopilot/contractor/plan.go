@@ -0,0 +1,389 @@
+package contractor
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+
+	"go.thebigfile.com/core/types"
+	"go.thebigfile.com/renterd/alerts"
+	"go.thebigfile.com/renterd/api"
+	"go.uber.org/zap"
+	"lukechampine.com/frand"
+)
+
+// ErrMaintenanceSkipped is returned when a maintenance plan is requested
+// for a config that contract maintenance would be skipped for.
+var ErrMaintenanceSkipped = errors.New("contract maintenance would be skipped")
+
+type (
+	// planBus wraps the bus to perform contract maintenance in dry-run mode.
+	// Reads are passed through to the bus, writes are recorded instead of
+	// being applied. Host checks are kept in memory and applied to the hosts
+	// returned by the bus, that way the contract checks and formations see
+	// the host checks of the dry run.
+	planBus struct {
+		Bus
+
+		autopilotID string
+		fee         types.Currency
+
+		checks    map[types.PublicKey]api.HostCheck
+		archivals map[types.FileContractID]api.PlannedArchival
+		formed    []api.ContractMetadata
+		renewed   map[types.FileContractID]api.ContractMetadata
+		planned   map[types.FileContractID]plannedContract
+	}
+
+	// planWorker wraps the worker to perform contract maintenance in dry-run
+	// mode. Scanning a host or fetching its price table would update the
+	// host in the bus, so the settings and price table the bus already knows
+	// are returned instead, even if they are outdated.
+	planWorker struct {
+		Worker
+
+		bus Bus
+	}
+
+	// plannedContract is a contract that would be formed, renewed or
+	// refreshed by the dry run.
+	plannedContract struct {
+		formation *api.PlannedFormation
+		renewal   *api.PlannedRenewal
+		refresh   bool
+	}
+)
+
+func newPlanBus(bus Bus, state *MaintenanceState) *planBus {
+	return &planBus{
+		Bus: bus,
+
+		autopilotID: state.AP.ID,
+		fee:         state.Fee,
+
+		archivals: make(map[types.FileContractID]api.PlannedArchival),
+		checks:    make(map[types.PublicKey]api.HostCheck),
+		renewed:   make(map[types.FileContractID]api.ContractMetadata),
+		planned:   make(map[types.FileContractID]plannedContract),
+	}
+}
+
+// PlanContractMaintenance performs contract maintenance in dry-run mode and
+// returns the changes it would make. No contracts are formed, renewed,
+// refreshed or archived, neither the host checks nor the contract sets are
+// updated and hosts aren't scanned.
+func (c *Contractor) PlanContractMaintenance(ctx context.Context, w Worker, state *MaintenanceState) (api.MaintenancePlan, error) {
+	logger := c.logger.Named("planContractMaintenance").
+		Named(hex.EncodeToString(frand.Bytes(16))) // uuid for this dry run
+
+	// the dry run uses a copy of the contractor that operates on the plan bus
+	// and doesn't share any state with the contractor
+	pb := newPlanBus(c.bus, state)
+	c.mu.Lock()
+	dry := &Contractor{
+		alerter: alerts.NewManager(),
+		bus:     pb,
+		churn:   newAccumulatedChurn(),
+		logger:  logger,
+
+		revisionLastBroadcast:    make(map[types.FileContractID]time.Time),
+		revisionSubmissionBuffer: c.revisionSubmissionBuffer,
+
+		firstRefreshFailure: maps.Clone(c.firstRefreshFailure),
+		setFailures:         make(map[string]map[types.FileContractID]contractFailure),
+	}
+	for set, failures := range c.setFailures {
+		dry.setFailures[set] = maps.Clone(failures)
+	}
+	c.mu.Unlock()
+
+	return planContractMaintenance(newMaintenanceCtx(ctx, state), pb, &planWorker{Worker: w, bus: pb}, dry, logger)
+}
+
+func planContractMaintenance(ctx *mCtx, pb *planBus, w Worker, c *Contractor, logger *zap.SugaredLogger) (api.MaintenancePlan, error) {
+	if reason, skip := canSkipContractMaintenance(ctx, ctx.ContractsConfig()); skip {
+		return api.MaintenancePlan{}, fmt.Errorf("%w: %s", ErrMaintenanceSkipped, reason)
+	}
+
+	remaining, err := remainingAllowance(ctx, pb, ctx.state)
+	if err != nil {
+		return api.MaintenancePlan{}, fmt.Errorf("failed to compute remaining allowance: %w", err)
+	}
+	plan := api.MaintenancePlan{RemainingAllowance: remaining}
+
+	// perform host checks
+	if err := performHostChecks(ctx, pb, logger); err != nil {
+		return api.MaintenancePlan{}, err
+	}
+	for _, hc := range pb.checks {
+		if hc.Usability.IsUsable() {
+			plan.UsableHosts++
+		}
+	}
+
+	// maintain the default set and all additional sets
+	sets, changes, err := maintainContractSets(ctx, c.alerter, pb, w, c, c, logger, &remaining)
+	if err != nil {
+		return api.MaintenancePlan{}, err
+	}
+	for i, setCtx := range sets {
+		plan.Sets = append(plan.Sets, pb.setPlan(setCtx.ContractSet(), changes[i]))
+	}
+
+	// sum up the projected spending
+	for _, pc := range pb.planned {
+		if pc.formation != nil {
+			plan.ProjectedSpending = plan.ProjectedSpending.Add(pc.formation.EstimatedCost)
+		} else {
+			plan.ProjectedSpending = plan.ProjectedSpending.Add(pc.renewal.EstimatedCost)
+		}
+	}
+	for _, archival := range pb.archivals {
+		plan.Archivals = append(plan.Archivals, archival)
+	}
+	sort.Slice(plan.Archivals, func(i, j int) bool {
+		return plan.Archivals[i].ContractID.String() < plan.Archivals[j].ContractID.String()
+	})
+	return plan, nil
+}
+
+// setPlan turns the change of a contract set into a plan.
+func (pb *planBus) setPlan(name string, change contractSetChange) api.ContractSetPlan {
+	plan := api.ContractSetPlan{
+		Name: name,
+		Size: len(change.newSet),
+	}
+
+	inNewSet := make(map[types.FileContractID]struct{})
+	for _, c := range change.newSet {
+		pc, ok := pb.planned[c.ID]
+		if !ok {
+			inNewSet[c.ID] = struct{}{}
+			continue
+		} else if pc.formation != nil {
+			plan.Formations = append(plan.Formations, *pc.formation)
+			continue
+		}
+
+		// renewals and refreshes replace the contract they were renewed from
+		inNewSet[c.RenewedFrom] = struct{}{}
+		renewal := *pc.renewal
+		renewal.Reason = change.churnReasons[c.RenewedFrom]
+		if pc.refresh {
+			plan.Refreshes = append(plan.Refreshes, renewal)
+		} else {
+			plan.Renewals = append(plan.Renewals, renewal)
+		}
+	}
+
+	for _, c := range change.oldSet {
+		if _, ok := inNewSet[c.ID]; ok {
+			continue
+		}
+		plan.Removals = append(plan.Removals, api.PlannedRemoval{
+			ContractID: c.ID,
+			HostKey:    c.HostKey,
+			Size:       c.Size,
+			Reason:     change.churnReasons[c.ID],
+		})
+	}
+	return plan
+}
+
+// estimatedCost estimates the cost of forming or renewing a contract with the
+// given renter funds and host collateral, which includes the siafund tax on
+// the contract's payout.
+func (pb *planBus) estimatedCost(ctx context.Context, hk types.PublicKey, renterFunds, hostCollateral types.Currency) (types.Currency, error) {
+	host, err := pb.Bus.Host(ctx, hk)
+	if err != nil {
+		return types.Currency{}, err
+	}
+	tax, err := pb.Bus.FileContractTax(ctx, renterFunds.Add(hostCollateral))
+	if err != nil {
+		return types.Currency{}, fmt.Errorf("failed to fetch file contract tax: %w", err)
+	}
+	return renterFunds.Add(contractOverhead(host, pb.fee)).Add(tax), nil
+}
+
+// applyCheck applies the host check of the dry run to the given host.
+func (pb *planBus) applyCheck(h api.Host) api.Host {
+	hc, ok := pb.checks[h.PublicKey]
+	if !ok {
+		return h
+	}
+	checks := maps.Clone(h.Checks)
+	if checks == nil {
+		checks = make(map[string]api.HostCheck)
+	}
+	checks[pb.autopilotID] = hc
+	h.Checks = checks
+	return h
+}
+
+func (pb *planBus) ArchiveContracts(ctx context.Context, toArchive map[types.FileContractID]string) error {
+	for fcid, reason := range toArchive {
+		c, err := pb.Bus.Contract(ctx, fcid)
+		if err != nil {
+			return err
+		}
+		pb.archivals[fcid] = api.PlannedArchival{
+			ContractID: fcid,
+			HostKey:    c.HostKey,
+			Reason:     reason,
+		}
+	}
+	return nil
+}
+
+func (pb *planBus) FormContract(ctx context.Context, renterAddress types.Address, renterFunds types.Currency, hostKey types.PublicKey, hostIP string, hostCollateral types.Currency, endHeight uint64) (api.ContractMetadata, error) {
+	cost, err := pb.estimatedCost(ctx, hostKey, renterFunds, hostCollateral)
+	if err != nil {
+		return api.ContractMetadata{}, err
+	}
+	c := api.ContractMetadata{
+		ID:          types.FileContractID(frand.Entropy256()),
+		HostKey:     hostKey,
+		HostIP:      hostIP,
+		State:       api.ContractStatePending,
+		TotalCost:   cost,
+		WindowStart: endHeight,
+	}
+	pb.planned[c.ID] = plannedContract{
+		formation: &api.PlannedFormation{
+			HostKey:        hostKey,
+			NetAddress:     hostIP,
+			EndHeight:      endHeight,
+			RenterFunds:    renterFunds,
+			HostCollateral: hostCollateral,
+			EstimatedCost:  cost,
+		},
+	}
+	pb.formed = append(pb.formed, c)
+	return c, nil
+}
+
+func (pb *planBus) RenewContract(ctx context.Context, fcid types.FileContractID, endHeight uint64, renterFunds, minNewCollateral, maxFundAmount types.Currency, expectedNewStorage uint64) (api.ContractMetadata, error) {
+	// contracts that are part of multiple sets are only renewed once
+	if renewal, ok := pb.renewed[fcid]; ok {
+		return renewal, nil
+	}
+
+	c, err := pb.Bus.Contract(ctx, fcid)
+	if err != nil {
+		return api.ContractMetadata{}, err
+	}
+	cost, err := pb.estimatedCost(ctx, c.HostKey, renterFunds, minNewCollateral)
+	if err != nil {
+		return api.ContractMetadata{}, err
+	}
+
+	// refreshes keep the end height of the contract
+	refresh := endHeight == c.WindowStart
+
+	renewal := c
+	renewal.ID = types.FileContractID(frand.Entropy256())
+	renewal.RenewedFrom = fcid
+	renewal.State = api.ContractStatePending
+	renewal.TotalCost = cost
+	renewal.WindowStart = endHeight
+	pb.planned[renewal.ID] = plannedContract{
+		renewal: &api.PlannedRenewal{
+			ContractID:    fcid,
+			HostKey:       c.HostKey,
+			EndHeight:     endHeight,
+			RenterFunds:   renterFunds,
+			EstimatedCost: cost,
+		},
+		refresh: refresh,
+	}
+	pb.renewed[fcid] = renewal
+	return renewal, nil
+}
+
+// Contracts adds the contracts that would be formed to the contracts returned
+// by the bus, that way hosts aren't contracted with by multiple sets.
+func (pb *planBus) Contracts(ctx context.Context, opts api.ContractsOpts) ([]api.ContractMetadata, error) {
+	contracts, err := pb.Bus.Contracts(ctx, opts)
+	if err != nil {
+		return nil, err
+	} else if opts.ContractSet == "" {
+		contracts = append(contracts, pb.formed...)
+	}
+	return contracts, nil
+}
+
+func (pb *planBus) Host(ctx context.Context, hostKey types.PublicKey) (api.Host, error) {
+	h, err := pb.Bus.Host(ctx, hostKey)
+	if err != nil {
+		return api.Host{}, err
+	}
+	return pb.applyCheck(h), nil
+}
+
+func (pb *planBus) RecordContractSetChurnMetric(ctx context.Context, metrics ...api.ContractSetChurnMetric) error {
+	return nil
+}
+
+// SearchHosts applies the host checks of the dry run to the hosts returned by
+// the bus, filtering them by usability if necessary. The filtering happens
+// after the hosts were fetched so the offset and limit are only respected
+// when all hosts are requested.
+func (pb *planBus) SearchHosts(ctx context.Context, opts api.SearchHostOptions) ([]api.Host, error) {
+	usabilityMode := opts.UsabilityMode
+	if usabilityMode == api.UsabilityFilterModeUsable || usabilityMode == api.UsabilityFilterModeUnusable {
+		opts.UsabilityMode = api.UsabilityFilterModeAll
+	}
+	hosts, err := pb.Bus.SearchHosts(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := hosts[:0]
+	for _, h := range hosts {
+		h = pb.applyCheck(h)
+		switch usabilityMode {
+		case api.UsabilityFilterModeUsable, api.UsabilityFilterModeUnusable:
+			hc, ok := h.Checks[pb.autopilotID]
+			usable := ok && hc.Usability.IsUsable()
+			if usable != (usabilityMode == api.UsabilityFilterModeUsable) {
+				continue
+			}
+		}
+		filtered = append(filtered, h)
+	}
+	return filtered, nil
+}
+
+func (pb *planBus) UpdateContractSet(ctx context.Context, set string, toAdd, toRemove []types.FileContractID) error {
+	return nil
+}
+
+func (pb *planBus) UpdateHostCheck(ctx context.Context, autopilotID string, hostKey types.PublicKey, hostCheck api.HostCheck) error {
+	pb.checks[hostKey] = hostCheck
+	return nil
+}
+
+func (pw *planWorker) RHPPriceTable(ctx context.Context, hostKey types.PublicKey, _ string, _ time.Duration) (api.HostPriceTable, error) {
+	host, err := pw.bus.Host(ctx, hostKey)
+	if err != nil {
+		return api.HostPriceTable{}, err
+	}
+	return host.PriceTable, nil
+}
+
+func (pw *planWorker) RHPScan(ctx context.Context, hostKey types.PublicKey, _ string, _ time.Duration) (api.RHPScanResponse, error) {
+	host, err := pw.bus.Host(ctx, hostKey)
+	if err != nil {
+		return api.RHPScanResponse{}, err
+	} else if !host.Scanned {
+		return api.RHPScanResponse{}, fmt.Errorf("host %v hasn't been scanned", hostKey)
+	}
+	return api.RHPScanResponse{
+		Settings:   host.Settings,
+		PriceTable: host.PriceTable.HostPriceTable,
+	}, nil
+}
@@ -0,0 +1,183 @@
+package contractor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.thebigfile.com/core/types"
+	"go.thebigfile.com/renterd/api"
+)
+
+type planTestBus struct {
+	Bus
+
+	contracts map[types.FileContractID]api.ContractMetadata
+	hosts     []api.Host
+}
+
+func (b *planTestBus) Contract(_ context.Context, id types.FileContractID) (api.ContractMetadata, error) {
+	c, ok := b.contracts[id]
+	if !ok {
+		return api.ContractMetadata{}, api.ErrContractNotFound
+	}
+	return c, nil
+}
+
+func (b *planTestBus) Contracts(_ context.Context, opts api.ContractsOpts) (contracts []api.ContractMetadata, _ error) {
+	for _, c := range b.contracts {
+		contracts = append(contracts, c)
+	}
+	return
+}
+
+// FileContractTax charges a tax of 10% to keep the numbers simple.
+func (b *planTestBus) FileContractTax(_ context.Context, payout types.Currency) (types.Currency, error) {
+	return payout.Div64(10), nil
+}
+
+func (b *planTestBus) Host(_ context.Context, hk types.PublicKey) (api.Host, error) {
+	for _, h := range b.hosts {
+		if h.PublicKey == hk {
+			return h, nil
+		}
+	}
+	return api.Host{}, api.ErrHostNotFound
+}
+
+func (b *planTestBus) SearchHosts(_ context.Context, opts api.SearchHostOptions) ([]api.Host, error) {
+	if opts.UsabilityMode != "" && opts.UsabilityMode != api.UsabilityFilterModeAll {
+		panic("plan bus should filter by usability itself")
+	}
+	return append([]api.Host(nil), b.hosts...), nil
+}
+
+func TestPlanBus(t *testing.T) {
+	hk1, hk2 := types.PublicKey{1}, types.PublicKey{2}
+	fcid := types.FileContractID{1}
+	bus := &planTestBus{
+		contracts: map[types.FileContractID]api.ContractMetadata{
+			fcid: {ID: fcid, HostKey: hk1, WindowStart: 100, Size: 10},
+		},
+		hosts: []api.Host{
+			{PublicKey: hk1},
+			{PublicKey: hk2},
+		},
+	}
+	bus.hosts[0].Settings.ContractPrice = types.Siacoins(1)
+
+	pb := newPlanBus(bus, &MaintenanceState{
+		AP:  api.Autopilot{ID: api.DefaultAutopilotID},
+		Fee: types.ZeroCurrency,
+	})
+	ctx := context.Background()
+
+	// host checks are kept in memory and applied to the hosts
+	usable := api.HostCheck{}
+	unusable := api.HostCheck{Usability: api.HostUsabilityBreakdown{Offline: true}}
+	if err := pb.UpdateHostCheck(ctx, api.DefaultAutopilotID, hk1, usable); err != nil {
+		t.Fatal(err)
+	} else if err := pb.UpdateHostCheck(ctx, api.DefaultAutopilotID, hk2, unusable); err != nil {
+		t.Fatal(err)
+	}
+	if h, err := pb.Host(ctx, hk2); err != nil {
+		t.Fatal(err)
+	} else if !h.Checks[api.DefaultAutopilotID].Usability.Offline {
+		t.Fatal("expected host check to be applied")
+	}
+	hosts, err := pb.SearchHosts(ctx, api.SearchHostOptions{UsabilityMode: api.UsabilityFilterModeUsable})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(hosts) != 1 || hosts[0].PublicKey != hk1 {
+		t.Fatalf("expected only the usable host, got %v", hosts)
+	}
+
+	// refreshes keep the end height, renewals don't, the estimated cost
+	// includes the renter funds, the contract price and the tax
+	refresh, err := pb.RenewContract(ctx, fcid, 100, types.Siacoins(2), types.ZeroCurrency, types.ZeroCurrency, 0)
+	if err != nil {
+		t.Fatal(err)
+	} else if refresh.RenewedFrom != fcid || !pb.planned[refresh.ID].refresh {
+		t.Fatal("expected contract to be refreshed")
+	} else if !refresh.TotalCost.Equals(types.Siacoins(32).Div64(10)) {
+		t.Fatalf("unexpected estimated cost %v", refresh.TotalCost)
+	}
+
+	// contracts are only renewed once
+	if again, err := pb.RenewContract(ctx, fcid, 200, types.Siacoins(2), types.ZeroCurrency, types.ZeroCurrency, 0); err != nil {
+		t.Fatal(err)
+	} else if again.ID != refresh.ID || len(pb.planned) != 1 {
+		t.Fatal("expected contract to be renewed only once")
+	}
+
+	// formed contracts are returned by the bus
+	formed, err := pb.FormContract(ctx, types.Address{}, types.Siacoins(1), hk2, "host", types.Siacoins(1), 200)
+	if err != nil {
+		t.Fatal(err)
+	} else if contracts, err := pb.Contracts(ctx, api.ContractsOpts{}); err != nil {
+		t.Fatal(err)
+	} else if len(contracts) != 2 {
+		t.Fatalf("expected 2 contracts, got %d", len(contracts))
+	}
+
+	// the tax is computed on the renter funds and the host collateral
+	if !formed.TotalCost.Equals(types.Siacoins(12).Div64(10)) {
+		t.Fatalf("unexpected estimated cost %v", formed.TotalCost)
+	}
+
+	// archivals are recorded
+	if err := pb.ArchiveContracts(ctx, map[types.FileContractID]string{fcid: "expired"}); err != nil {
+		t.Fatal(err)
+	} else if pb.archivals[fcid].HostKey != hk1 {
+		t.Fatal("expected archival to be recorded")
+	}
+
+	// assert the plan of the set
+	plan := pb.setPlan("set", contractSetChange{
+		oldSet:       []api.ContractMetadata{bus.contracts[fcid], {ID: types.FileContractID{2}, HostKey: hk2}},
+		newSet:       []api.ContractMetadata{refresh, formed},
+		churnReasons: map[types.FileContractID]string{fcid: "out of funds", {2}: "host is offline"},
+	})
+	if plan.Size != 2 {
+		t.Fatalf("unexpected size %d", plan.Size)
+	} else if len(plan.Refreshes) != 1 || plan.Refreshes[0].ContractID != fcid || plan.Refreshes[0].Reason != "out of funds" {
+		t.Fatalf("unexpected refreshes %+v", plan.Refreshes)
+	} else if len(plan.Renewals) != 0 {
+		t.Fatalf("unexpected renewals %+v", plan.Renewals)
+	} else if len(plan.Formations) != 1 || plan.Formations[0].HostKey != hk2 {
+		t.Fatalf("unexpected formations %+v", plan.Formations)
+	} else if len(plan.Removals) != 1 || plan.Removals[0].Reason != "host is offline" {
+		t.Fatalf("unexpected removals %+v", plan.Removals)
+	}
+}
+
+func TestPlanWorker(t *testing.T) {
+	hk1, hk2 := types.PublicKey{1}, types.PublicKey{2}
+	bus := &planTestBus{
+		hosts: []api.Host{
+			{PublicKey: hk1, Scanned: true},
+			{PublicKey: hk2},
+		},
+	}
+	bus.hosts[0].Settings.ContractPrice = types.Siacoins(1)
+	bus.hosts[0].PriceTable.Expiry = time.Now().Add(time.Hour)
+	pw := &planWorker{bus: bus}
+	ctx := context.Background()
+
+	// the settings and price table the bus knows are returned instead of
+	// scanning the host
+	if scan, err := pw.RHPScan(ctx, hk1, "", 0); err != nil {
+		t.Fatal(err)
+	} else if !scan.Settings.ContractPrice.Equals(types.Siacoins(1)) {
+		t.Fatalf("unexpected scan %+v", scan)
+	} else if pt, err := pw.RHPPriceTable(ctx, hk1, "", 0); err != nil {
+		t.Fatal(err)
+	} else if !pt.Expiry.Equal(bus.hosts[0].PriceTable.Expiry) {
+		t.Fatalf("unexpected price table %+v", pt)
+	}
+
+	// hosts that haven't been scanned fail
+	if _, err := pw.RHPScan(ctx, hk2, "", 0); err == nil {
+		t.Fatal("expected error")
+	}
+}
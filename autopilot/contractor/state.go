@@ -2,7 +2,6 @@ package contractor
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"go.thebigfile.com/core/types"
@@ -19,6 +18,18 @@ type (
 		RS api.RedundancySettings
 		AP api.Autopilot
 
+		// BucketRS contains the redundancy settings of the buckets that
+		// override the default redundancy settings.
+		BucketRS []api.RedundancySettings
+
+		// GeoIP is used to enforce the diversity limits of the hosts
+		// config, it's nil if no GeoIP database was configured.
+		GeoIP *GeoIPDatabase
+
+		// HostScorer is used to adjust the score of hosts, it's nil if no
+		// custom host scorer was configured.
+		HostScorer HostScorer
+
 		Address                types.Address
 		Fee                    types.Currency
 		SkipContractFormations bool
@@ -27,6 +38,10 @@ type (
 	mCtx struct {
 		ctx   context.Context
 		state *MaintenanceState
+
+		// set is the additional contract set that is being maintained, if
+		// nil the default set is maintained
+		set *api.ContractSetConfig
 	}
 )
 
@@ -37,6 +52,22 @@ func newMaintenanceCtx(ctx context.Context, state *MaintenanceState) *mCtx {
 	}
 }
 
+// forSet returns a copy of the context that maintains the given additional
+// contract set instead of the default set.
+func (ctx *mCtx) forSet(set api.ContractSetConfig) *mCtx {
+	return &mCtx{
+		ctx:   ctx.ctx,
+		state: ctx.state,
+		set:   &set,
+	}
+}
+
+// AllowsHost returns true if the host passes the filter of the contract set
+// that is being maintained.
+func (ctx *mCtx) AllowsHost(hk types.PublicKey, score float64) bool {
+	return ctx.set == nil || ctx.set.AllowsHost(hk, score)
+}
+
 func (ctx *mCtx) ApID() string {
 	return ctx.state.AP.ID
 }
@@ -54,6 +85,9 @@ func (ctx *mCtx) ContractsConfig() api.ContractsConfig {
 }
 
 func (ctx *mCtx) ContractSet() string {
+	if ctx.set != nil {
+		return ctx.set.Name
+	}
 	return ctx.state.AP.Config.Contracts.Set
 }
 
@@ -78,14 +112,16 @@ func (ctx *mCtx) GougingChecker(cs api.ConsensusState) gouging.Checker {
 	return gouging.NewChecker(ctx.state.GS, cs, ctx.state.Fee, &period, &renewWindow)
 }
 
-func (ctx *mCtx) HostScore(h api.Host) (sb api.HostScoreBreakdown, err error) {
-	// host settings that cause a panic should result in a score of 0
-	defer func() {
-		if r := recover(); r != nil {
-			err = errors.New("panic while scoring host")
+// MaxTotalShards returns the highest number of shards slabs are uploaded with,
+// taking into account the buckets that override the redundancy settings.
+func (ctx *mCtx) MaxTotalShards() int {
+	maxShards := ctx.state.RS.TotalShards
+	for _, rs := range ctx.state.BucketRS {
+		if rs.TotalShards > maxShards {
+			maxShards = rs.TotalShards
 		}
-	}()
-	return hostScore(ctx.state.AP.Config, h, ctx.state.RS.Redundancy()), nil
+	}
+	return maxShards
 }
 
 func (ctx *mCtx) Period() uint64 {
@@ -105,15 +141,26 @@ func (ctx *mCtx) Value(key interface{}) interface{} {
 }
 
 func (ctx *mCtx) WantedContracts() uint64 {
+	if ctx.set != nil {
+		return ctx.set.Amount
+	}
 	return ctx.state.AP.Config.Contracts.Amount
 }
 
+// DiversityLimits returns the diversity limits for the set that's being
+// maintained.
+func (ctx *mCtx) DiversityLimits() diversityLimits {
+	return newDiversityLimits(ctx.state.AP.Config.Hosts, ctx.WantedContracts())
+}
+
 func (ctx *mCtx) Set() string {
-	return ctx.state.ContractsConfig().Set
+	return ctx.ContractSet()
 }
 
 func (ctx *mCtx) SortContractsForMaintenance(contracts []api.Contract) {
-	ctx.state.ContractsConfig().SortContractsForMaintenance(contracts)
+	cfg := ctx.state.ContractsConfig()
+	cfg.Set = ctx.ContractSet()
+	cfg.SortContractsForMaintenance(contracts)
 }
 
 func (state *MaintenanceState) Allowance() types.Currency {
@@ -215,20 +215,37 @@ func (m *migrator) performMigrations(p *workerPool) {
 		m.logger.Errorf("failed to fetch autopilot config: %w", err)
 		return
 	}
-	set := autopilot.Config.Contracts.Set
-	if set == "" {
+	if autopilot.Config.Contracts.Set == "" {
 		m.logger.Error("could not perform migrations, no contract set configured")
 		return
 	}
 
+	// warn about buckets whose slabs can't be fully repaired
+	m.checkBucketRedundancy(m.ap.shutdownCtx, autopilot.Config.Contracts)
+
+	// keep track of the contract set every slab is migrated within
+	slabSets := make(map[object.EncryptionKey]string)
+
 	// helper to update 'toMigrate'
 	updateToMigrate := func() {
-		// fetch slabs for migration
-		toMigrateNew, err := b.SlabsForMigration(m.ap.shutdownCtx, m.healthCutoff, set, migratorBatchSize)
-		if err != nil {
-			m.logger.Errorf("failed to fetch slabs for migration, err: %v", err)
-			return
+		// fetch slabs for migration from all maintained sets
+		var toMigrateNew []api.UnhealthySlab
+		slabSetsNew := make(map[object.EncryptionKey]string)
+		for _, set := range autopilot.Config.Contracts.SetNames() {
+			slabs, err := b.SlabsForMigration(m.ap.shutdownCtx, m.healthCutoff, set, migratorBatchSize)
+			if err != nil {
+				m.logger.Errorf("failed to fetch slabs for migration in set '%s', err: %v", set, err)
+				return
+			}
+			for _, slab := range slabs {
+				slabSetsNew[slab.Key] = set
+			}
+			toMigrateNew = append(toMigrateNew, slabs...)
 		}
+
+		// only slabs that still require migration remain in 'toMigrate' after
+		// merging, so the sets of all other slabs can be forgotten
+		slabSets = slabSetsNew
 		m.logger.Infof("%d potential slabs fetched for migration", len(toMigrateNew))
 
 		// merge toMigrateNew with toMigrate
@@ -304,7 +321,7 @@ OUTER:
 			case <-m.signalMaintenanceFinished:
 				m.logger.Info("migrations interrupted - updating slabs for migration")
 				continue OUTER
-			case jobs <- job{slab, i, len(toMigrate), set, b}:
+			case jobs <- job{slab, i, len(toMigrate), slabSets[slab.Key], b}:
 			}
 		}
 
@@ -313,6 +330,61 @@ OUTER:
 	}
 }
 
+// checkBucketRedundancy registers an alert for every bucket whose contract set
+// contains fewer contracts than the total shards of the bucket's redundancy.
+// Slabs are migrated with the redundancy of the bucket they were uploaded to,
+// which might exceed the default redundancy settings, so the slabs of such a
+// bucket never become fully healthy again. Buckets that aren't bound to a
+// contract set are checked against the autopilot's contract set.
+func (m *migrator) checkBucketRedundancy(ctx context.Context, cfg api.ContractsConfig) {
+	rs, err := m.ap.bus.RedundancySettings(ctx)
+	if err != nil {
+		m.logger.Errorf("failed to fetch redundancy settings, err: %v", err)
+		return
+	}
+	buckets, err := m.ap.bus.ListBuckets(ctx)
+	if err != nil {
+		m.logger.Errorf("failed to fetch buckets, err: %v", err)
+		return
+	}
+
+	maintained := make(map[string]bool)
+	for _, set := range cfg.SetNames() {
+		maintained[set] = true
+	}
+
+	setSizes := make(map[string]int)
+	for _, b := range buckets {
+		set, bucketRS := cfg.Set, rs
+		if b.Policy.ContractSet != "" {
+			set = b.Policy.ContractSet
+		}
+		if b.Policy.Redundancy != nil {
+			bucketRS = *b.Policy.Redundancy
+		}
+		if !maintained[set] {
+			continue
+		}
+
+		size, ok := setSizes[set]
+		if !ok {
+			contracts, err := m.ap.bus.Contracts(ctx, api.ContractsOpts{ContractSet: set})
+			if err != nil {
+				m.logger.Errorf("failed to fetch contracts of set '%s', err: %v", set, err)
+				return
+			}
+			size = len(contracts)
+			setSizes[set] = size
+		}
+
+		if size < bucketRS.TotalShards {
+			m.ap.RegisterAlert(ctx, newBucketRedundancyAlert(b.Name, set, bucketRS, size))
+		} else {
+			m.ap.DismissAlert(ctx, alerts.IDForBucket(alertBucketRedundancyID, b.Name))
+		}
+	}
+}
+
 func (m *migrator) objectIDsForSlabKey(ctx context.Context, key object.EncryptionKey) (map[string][]string, error) {
 	// fetch all buckets
 	//
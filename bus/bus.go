@@ -41,6 +41,7 @@ import (
 
 const (
 	defaultWalletRecordMetricInterval = 5 * time.Minute
+	defaultLifecycleSweepInterval     = time.Hour
 	defaultPinUpdateInterval          = 5 * time.Minute
 	defaultPinRateWindow              = 6 * time.Hour
 
@@ -238,22 +239,31 @@ type (
 		UpdateBucketPolicy(ctx context.Context, bucketName string, policy api.BucketPolicy) error
 
 		CopyObject(ctx context.Context, srcBucket, dstBucket, srcPath, dstPath, mimeType string, metadata api.ObjectUserMetadata) (api.ObjectMetadata, error)
-		ListObjects(ctx context.Context, bucketName, prefix, sortBy, sortDir, marker string, limit int) (api.ObjectsListResponse, error)
+		ListObjects(ctx context.Context, bucketName, prefix, sortBy, sortDir, marker string, tags api.ObjectTags, limit int) (api.ObjectsListResponse, error)
 		Object(ctx context.Context, bucketName, path string) (api.Object, error)
+		ObjectLock(ctx context.Context, bucketName, path string) (api.ObjectLock, error)
 		ObjectMetadata(ctx context.Context, bucketName, path string) (api.Object, error)
+		ObjectTags(ctx context.Context, bucketName, path string) (api.ObjectTags, error)
 		ObjectEntries(ctx context.Context, bucketName, path, prefix, sortBy, sortDir, marker string, offset, limit int) ([]api.ObjectMetadata, bool, error)
 		ObjectsBySlabKey(ctx context.Context, bucketName string, slabKey object.EncryptionKey) ([]api.ObjectMetadata, error)
 		ObjectsStats(ctx context.Context, opts api.ObjectsStatsOpts) (api.ObjectsStatsResponse, error)
-		RemoveObject(ctx context.Context, bucketName, path string) error
+		ObjectVersion(ctx context.Context, bucketName, path, versionID string) (api.Object, error)
+		ObjectVersions(ctx context.Context, bucketName, prefix, keyMarker, versionIDMarker string, limit int) (api.ObjectVersionsResponse, error)
+		RemoveObject(ctx context.Context, bucketName, path string) (api.DeleteObjectResponse, error)
+		RemoveObjectVersion(ctx context.Context, bucketName, path, versionID string) error
 		RemoveObjects(ctx context.Context, bucketName, prefix string) error
 		RenameObject(ctx context.Context, bucketName, from, to string, force bool) error
 		RenameObjects(ctx context.Context, bucketName, from, to string, force bool) error
-		SearchObjects(ctx context.Context, bucketName, substring string, offset, limit int) ([]api.ObjectMetadata, error)
-		UpdateObject(ctx context.Context, bucketName, path, contractSet, ETag, mimeType string, metadata api.ObjectUserMetadata, o object.Object) error
+		SearchObjects(ctx context.Context, bucketName, substring string, tags api.ObjectTags, offset, limit int) ([]api.ObjectMetadata, error)
+		UpdateObject(ctx context.Context, bucketName, path, contractSet string, o object.Object, opts api.AddObjectOptions) error
+		UpdateObjectLegalHold(ctx context.Context, bucketName, path string, enabled bool) error
+		UpdateObjectRetention(ctx context.Context, bucketName, path string, retention api.ObjectRetention, bypassGovernance bool) error
+		UpdateObjectTags(ctx context.Context, bucketName, path string, tags api.ObjectTags) error
 
 		AbortMultipartUpload(ctx context.Context, bucketName, path string, uploadID string) (err error)
-		AddMultipartPart(ctx context.Context, bucketName, path, contractSet, eTag, uploadID string, partNumber int, slices []object.SlabSlice) (err error)
+		AddMultipartPart(ctx context.Context, bucketName, path, contractSet, eTag, uploadID string, partNumber int, slices []object.SlabSlice, opts api.AddMultipartPartOptions) (err error)
 		CompleteMultipartUpload(ctx context.Context, bucketName, path, uploadID string, parts []api.MultipartCompletedPart, opts api.CompleteMultipartOptions) (_ api.MultipartCompleteResponse, err error)
+		CopyMultipartPart(ctx context.Context, srcBucket, srcPath, bucketName, path, contractSet, uploadID string, partNumber int, offset, length int64) (api.MultipartCopyPartResponse, error)
 		CreateMultipartUpload(ctx context.Context, bucketName, path string, ec object.EncryptionKey, mimeType string, metadata api.ObjectUserMetadata) (api.MultipartCreateResponse, error)
 		MultipartUpload(ctx context.Context, uploadID string) (resp api.MultipartUpload, _ error)
 		MultipartUploads(ctx context.Context, bucketName, prefix, keyMarker, uploadIDMarker string, maxUploads int) (resp api.MultipartListUploadsResponse, _ error)
@@ -300,6 +310,10 @@ type (
 	WalletMetricsRecorder interface {
 		Shutdown(context.Context) error
 	}
+
+	LifecycleSweeper interface {
+		Shutdown(context.Context) error
+	}
 )
 
 type Bus struct {
@@ -326,6 +340,7 @@ type Bus struct {
 	rhp3 *rhp3.Client
 
 	contractLocker        ContractLocker
+	lifecycleSweeper      LifecycleSweeper
 	sectors               UploadingSectorsCache
 	walletMetricsRecorder WalletMetricsRecorder
 
@@ -379,6 +394,9 @@ func New(ctx context.Context, masterKey [32]byte, am AlertManager, wm WebhooksMa
 	// create wallet metrics recorder
 	b.walletMetricsRecorder = ibus.NewWalletMetricRecorder(store, w, defaultWalletRecordMetricInterval, l)
 
+	// create lifecycle sweeper
+	b.lifecycleSweeper = ibus.NewLifecycleSweeper(wm, store, defaultLifecycleSweepInterval, l)
+
 	return b, nil
 }
 
@@ -446,6 +464,9 @@ func (b *Bus) Handler() http.Handler {
 		"GET    /host/:hostkey":                  b.hostsPubkeyHandlerGET,
 		"POST   /host/:hostkey/resetlostsectors": b.hostsResetLostSectorsPOST,
 
+		"GET    /legalhold/*path": b.objectLegalHoldHandlerGET,
+		"PUT    /legalhold/*path": b.objectLegalHoldHandlerPUT,
+
 		"PUT    /metric/:key": b.metricsHandlerPUT,
 		"GET    /metric/:key": b.metricsHandlerGET,
 		"DELETE /metric/:key": b.metricsHandlerDELETE,
@@ -454,16 +475,18 @@ func (b *Bus) Handler() http.Handler {
 		"POST   /multipart/abort":       b.multipartHandlerAbortPOST,
 		"POST   /multipart/complete":    b.multipartHandlerCompletePOST,
 		"PUT    /multipart/part":        b.multipartHandlerUploadPartPUT,
+		"POST   /multipart/part/copy":   b.multipartHandlerCopyPartPOST,
 		"GET    /multipart/upload/:id":  b.multipartHandlerUploadGET,
 		"POST   /multipart/listuploads": b.multipartHandlerListUploadsPOST,
 		"POST   /multipart/listparts":   b.multipartHandlerListPartsPOST,
 
-		"GET    /objects/*path":  b.objectsHandlerGET,
-		"PUT    /objects/*path":  b.objectsHandlerPUT,
-		"DELETE /objects/*path":  b.objectsHandlerDELETE,
-		"POST   /objects/copy":   b.objectsCopyHandlerPOST,
-		"POST   /objects/rename": b.objectsRenameHandlerPOST,
-		"POST   /objects/list":   b.objectsListHandlerPOST,
+		"GET    /objects/*path":    b.objectsHandlerGET,
+		"PUT    /objects/*path":    b.objectsHandlerPUT,
+		"DELETE /objects/*path":    b.objectsHandlerDELETE,
+		"POST   /objects/copy":     b.objectsCopyHandlerPOST,
+		"POST   /objects/rename":   b.objectsRenameHandlerPOST,
+		"POST   /objects/list":     b.objectsListHandlerPOST,
+		"POST   /objects/versions": b.objectsVersionsHandlerPOST,
 
 		"GET    /params/gouging": b.paramsHandlerGougingGET,
 		"GET    /params/upload":  b.paramsHandlerUploadGET,
@@ -472,11 +495,19 @@ func (b *Bus) Handler() http.Handler {
 		"POST   /slabbuffer/done":  b.packedSlabsHandlerDonePOST,
 		"POST   /slabbuffer/fetch": b.packedSlabsHandlerFetchPOST,
 
+		"GET    /retention/*path": b.objectRetentionHandlerGET,
+		"PUT    /retention/*path": b.objectRetentionHandlerPUT,
+
 		"POST   /search/hosts":   b.searchHostsHandlerPOST,
 		"GET    /search/objects": b.searchObjectsHandlerGET,
 
 		"DELETE /sectors/:hk/:root": b.sectorsHostRootHandlerDELETE,
 
+		"GET    /s3/policies":    b.s3PoliciesHandlerGET,
+		"GET    /s3/policy/:key": b.s3PolicyHandlerGET,
+		"PUT    /s3/policy/:key": b.s3PolicyHandlerPUT,
+		"DELETE /s3/policy/:key": b.s3PolicyHandlerDELETE,
+
 		"GET    /settings":     b.settingsHandlerGET,
 		"GET    /setting/:key": b.settingKeyHandlerGET,
 		"PUT    /setting/:key": b.settingKeyHandlerPUT,
@@ -497,6 +528,10 @@ func (b *Bus) Handler() http.Handler {
 		"POST   /syncer/connect": b.syncerConnectHandler,
 		"GET    /syncer/peers":   b.syncerPeersHandler,
 
+		"GET    /tags/*path": b.objectTagsHandlerGET,
+		"PUT    /tags/*path": b.objectTagsHandlerPUT,
+		"DELETE /tags/*path": b.objectTagsHandlerDELETE,
+
 		"GET    /txpool/recommendedfee": b.txpoolFeeHandler,
 		"GET    /txpool/transactions":   b.txpoolTransactionsHandler,
 		"POST   /txpool/broadcast":      b.txpoolBroadcastHandler,
@@ -525,6 +560,7 @@ func (b *Bus) Handler() http.Handler {
 // Shutdown shuts down the bus.
 func (b *Bus) Shutdown(ctx context.Context) error {
 	return errors.Join(
+		b.lifecycleSweeper.Shutdown(ctx),
 		b.walletMetricsRecorder.Shutdown(ctx),
 		b.webhooksMgr.Shutdown(ctx),
 		b.pinMgr.Shutdown(ctx),
@@ -680,6 +716,7 @@ func (b *Bus) initSettings(ctx context.Context) error {
 
 	// load default settings if the setting is not already set
 	for key, value := range map[string]interface{}{
+		api.SettingBandwidth:     api.BandwidthSettings{},
 		api.SettingGouging:       api.DefaultGougingSettings,
 		api.SettingPricePinning:  api.DefaultPricePinSettings,
 		api.SettingRedundancy:    defaultRedundancySettings,
@@ -853,6 +890,40 @@ func (b *Bus) prepareRenew(cs consensus.State, revision types.FileContractRevisi
 	}
 }
 
+func (b *Bus) s3AuthenticationSettings(ctx context.Context) (as api.S3AuthenticationSettings, _ error) {
+	value, err := b.ss.Setting(ctx, api.SettingS3Authentication)
+	if errors.Is(err, api.ErrSettingNotFound) {
+		return api.S3AuthenticationSettings{}, nil
+	} else if err != nil {
+		return api.S3AuthenticationSettings{}, err
+	} else if err := json.Unmarshal([]byte(value), &as); err != nil {
+		return api.S3AuthenticationSettings{}, fmt.Errorf("failed to unmarshal s3 authentication settings: %w", err)
+	}
+	return as, nil
+}
+
+func (b *Bus) updateS3AuthenticationSettings(ctx context.Context, as api.S3AuthenticationSettings) error {
+	if err := as.Validate(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(as)
+	if err != nil {
+		return fmt.Errorf("failed to marshal s3 authentication settings: %w", err)
+	} else if err := b.ss.UpdateSetting(ctx, api.SettingS3Authentication, string(data)); err != nil {
+		return err
+	}
+	b.broadcastAction(webhooks.Event{
+		Module: api.ModuleSetting,
+		Event:  api.EventUpdate,
+		Payload: api.EventSettingUpdate{
+			Key:       api.SettingS3Authentication,
+			Update:    as,
+			Timestamp: time.Now().UTC(),
+		},
+	})
+	return nil
+}
+
 func (b *Bus) renewContract(ctx context.Context, cs consensus.State, gp api.GougingParams, c api.ContractMetadata, hs rhpv2.HostSettings, renterFunds, minNewCollateral, maxFundAmount types.Currency, endHeight, expectedNewStorage uint64) (rhpv2.ContractRevision, types.Currency, types.Currency, error) {
 	// acquire contract lock indefinitely and defer the release
 	lockID, err := b.contractLocker.Acquire(ctx, lockingPriorityRenew, c.ID, time.Duration(math.MaxInt64))
@@ -19,7 +19,7 @@ func (c *Client) AbortMultipartUpload(ctx context.Context, bucket, path string,
 }
 
 // AddMultipartPart adds a part to a multipart upload.
-func (c *Client) AddMultipartPart(ctx context.Context, bucket, path, contractSet, eTag, uploadID string, partNumber int, slices []object.SlabSlice) (err error) {
+func (c *Client) AddMultipartPart(ctx context.Context, bucket, path, contractSet, eTag, uploadID string, partNumber int, slices []object.SlabSlice, opts api.AddMultipartPartOptions) (err error) {
 	err = c.c.WithContext(ctx).PUT("/multipart/part", api.MultipartAddPartRequest{
 		Bucket:      bucket,
 		ETag:        eTag,
@@ -27,11 +27,33 @@ func (c *Client) AddMultipartPart(ctx context.Context, bucket, path, contractSet
 		ContractSet: contractSet,
 		UploadID:    uploadID,
 		PartNumber:  partNumber,
+		Offset:      opts.Offset,
 		Slices:      slices,
 	})
 	return
 }
 
+// CopyMultipartPart adds a part to a multipart upload that references the data
+// of an existing object.
+func (c *Client) CopyMultipartPart(ctx context.Context, srcBucket, srcPath, bucket, path, contractSet, uploadID string, partNumber int, opts api.CopyMultipartPartOptions) (resp api.MultipartCopyPartResponse, err error) {
+	req := api.MultipartCopyPartRequest{
+		Bucket:       bucket,
+		Path:         path,
+		ContractSet:  contractSet,
+		UploadID:     uploadID,
+		PartNumber:   partNumber,
+		SourceBucket: srcBucket,
+		SourcePath:   srcPath,
+		Offset:       0,
+		Length:       -1,
+	}
+	if opts.Range != nil {
+		req.Offset, req.Length = opts.Range.Offset, opts.Range.Length
+	}
+	err = c.c.WithContext(ctx).POST("/multipart/part/copy", req, &resp)
+	return
+}
+
 // CompleteMultipartUpload completes a multipart upload.
 func (c *Client) CompleteMultipartUpload(ctx context.Context, bucket, path, uploadID string, parts []api.MultipartCompletedPart, opts api.CompleteMultipartOptions) (resp api.MultipartCompleteResponse, err error) {
 	err = c.c.WithContext(ctx).POST("/multipart/complete", api.MultipartCompleteRequest{
@@ -40,6 +62,12 @@ func (c *Client) CompleteMultipartUpload(ctx context.Context, bucket, path, uplo
 		Metadata: opts.Metadata,
 		UploadID: uploadID,
 		Parts:    parts,
+
+		ChecksumAlgorithm:      opts.ChecksumAlgorithm,
+		Checksum:               opts.Checksum,
+		CustomerKeyFingerprint: opts.CustomerKeyFingerprint,
+		Conditions:             opts.Conditions,
+		Lock:                   opts.Lock,
 	}, &resp)
 	return
 }
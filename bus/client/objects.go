@@ -3,9 +3,11 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 
 	"go.thebigfile.com/renterd/api"
+	"go.thebigfile.com/renterd/internal/utils"
 	"go.thebigfile.com/renterd/object"
 )
 
@@ -19,6 +21,14 @@ func (c *Client) AddObject(ctx context.Context, bucket, path, contractSet string
 		ETag:        opts.ETag,
 		MimeType:    opts.MimeType,
 		Metadata:    opts.Metadata,
+
+		ChecksumAlgorithm: opts.ChecksumAlgorithm,
+		Checksum:          opts.Checksum,
+
+		CustomerKeyFingerprint: opts.CustomerKeyFingerprint,
+
+		Conditions: opts.Conditions,
+		Lock:       opts.Lock,
 	})
 	return
 }
@@ -39,13 +49,25 @@ func (c *Client) CopyObject(ctx context.Context, srcBucket, dstBucket, srcPath,
 
 // DeleteObject either deletes the object at the given path or if batch=true
 // deletes all objects that start with the given path.
-func (c *Client) DeleteObject(ctx context.Context, bucket, path string, opts api.DeleteObjectOptions) (err error) {
+func (c *Client) DeleteObject(ctx context.Context, bucket, path string, opts api.DeleteObjectOptions) (resp api.DeleteObjectResponse, err error) {
 	values := url.Values{}
 	values.Set("bucket", bucket)
 	opts.Apply(values)
 
 	path = api.ObjectPathEscape(path)
-	err = c.c.WithContext(ctx).DELETE(fmt.Sprintf("/objects/%s?"+values.Encode(), path))
+	c.c.Custom("DELETE", fmt.Sprintf("/objects/%s?"+values.Encode(), path), nil, &resp)
+
+	u, err := url.Parse(fmt.Sprintf("%s/objects/%s", c.c.BaseURL, path))
+	if err != nil {
+		panic(err)
+	}
+	u.RawQuery = values.Encode()
+	req, err := http.NewRequestWithContext(ctx, "DELETE", u.String(), http.NoBody)
+	if err != nil {
+		panic(err)
+	}
+	req.SetBasicAuth("", c.c.WithContext(ctx).Password)
+	_, _, err = utils.DoRequest(req, &resp)
 	return
 }
 
@@ -58,6 +80,7 @@ func (c *Client) ListObjects(ctx context.Context, bucket string, opts api.ListOb
 		Marker:  opts.Marker,
 		SortBy:  opts.SortBy,
 		SortDir: opts.SortDir,
+		Tags:    opts.Tags,
 	}, &resp)
 	return
 }
@@ -75,6 +98,94 @@ func (c *Client) Object(ctx context.Context, bucket, path string, opts api.GetOb
 	return
 }
 
+// ObjectLegalHold returns the legal hold of the object at the given path.
+func (c *Client) ObjectLegalHold(ctx context.Context, bucket, path string) (lh api.ObjectLegalHold, err error) {
+	values := url.Values{}
+	values.Set("bucket", bucket)
+
+	path = api.ObjectPathEscape(path)
+	err = c.c.WithContext(ctx).GET(fmt.Sprintf("/legalhold/%s?"+values.Encode(), path), &lh)
+	return
+}
+
+// UpdateObjectLegalHold enables or disables the legal hold of the object at
+// the given path.
+func (c *Client) UpdateObjectLegalHold(ctx context.Context, bucket, path string, enabled bool) (err error) {
+	values := url.Values{}
+	values.Set("bucket", bucket)
+
+	path = api.ObjectPathEscape(path)
+	err = c.c.WithContext(ctx).PUT(fmt.Sprintf("/legalhold/%s?"+values.Encode(), path), api.ObjectLegalHold{Enabled: enabled})
+	return
+}
+
+// ObjectRetention returns the retention of the object at the given path.
+func (c *Client) ObjectRetention(ctx context.Context, bucket, path string) (retention api.ObjectRetention, err error) {
+	values := url.Values{}
+	values.Set("bucket", bucket)
+
+	path = api.ObjectPathEscape(path)
+	err = c.c.WithContext(ctx).GET(fmt.Sprintf("/retention/%s?"+values.Encode(), path), &retention)
+	return
+}
+
+// UpdateObjectRetention replaces the retention of the object at the given
+// path. Active retentions in governance mode can only be shortened or removed
+// when bypassGovernance is set.
+func (c *Client) UpdateObjectRetention(ctx context.Context, bucket, path string, retention api.ObjectRetention, bypassGovernance bool) (err error) {
+	values := url.Values{}
+	values.Set("bucket", bucket)
+
+	path = api.ObjectPathEscape(path)
+	err = c.c.WithContext(ctx).PUT(fmt.Sprintf("/retention/%s?"+values.Encode(), path), api.ObjectRetentionRequest{
+		Retention:        retention,
+		BypassGovernance: bypassGovernance,
+	})
+	return
+}
+
+// ObjectTags returns the tags of the object at the given path.
+func (c *Client) ObjectTags(ctx context.Context, bucket, path string) (tags api.ObjectTags, err error) {
+	values := url.Values{}
+	values.Set("bucket", bucket)
+
+	path = api.ObjectPathEscape(path)
+	err = c.c.WithContext(ctx).GET(fmt.Sprintf("/tags/%s?"+values.Encode(), path), &tags)
+	return
+}
+
+// UpdateObjectTags replaces the tags of the object at the given path.
+func (c *Client) UpdateObjectTags(ctx context.Context, bucket, path string, tags api.ObjectTags) (err error) {
+	values := url.Values{}
+	values.Set("bucket", bucket)
+
+	path = api.ObjectPathEscape(path)
+	err = c.c.WithContext(ctx).PUT(fmt.Sprintf("/tags/%s?"+values.Encode(), path), tags)
+	return
+}
+
+// DeleteObjectTags removes all tags from the object at the given path.
+func (c *Client) DeleteObjectTags(ctx context.Context, bucket, path string) (err error) {
+	values := url.Values{}
+	values.Set("bucket", bucket)
+
+	path = api.ObjectPathEscape(path)
+	err = c.c.WithContext(ctx).DELETE(fmt.Sprintf("/tags/%s?"+values.Encode(), path))
+	return
+}
+
+// ObjectVersions lists all versions of the objects in the given bucket.
+func (c *Client) ObjectVersions(ctx context.Context, bucket string, opts api.ListObjectVersionsOptions) (resp api.ObjectVersionsResponse, err error) {
+	err = c.c.WithContext(ctx).POST("/objects/versions", api.ObjectVersionsRequest{
+		Bucket:          bucket,
+		Limit:           opts.Limit,
+		Prefix:          opts.Prefix,
+		KeyMarker:       opts.KeyMarker,
+		VersionIDMarker: opts.VersionIDMarker,
+	}, &resp)
+	return
+}
+
 // ObjectsBySlabKey returns all objects that reference a given slab.
 func (c *Client) ObjectsBySlabKey(ctx context.Context, bucket string, key object.EncryptionKey) (objects []api.ObjectMetadata, err error) {
 	values := url.Values{}
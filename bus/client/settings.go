@@ -7,12 +7,24 @@ import (
 	"go.thebigfile.com/renterd/api"
 )
 
+// BandwidthSettings returns the bandwidth settings.
+func (c *Client) BandwidthSettings(ctx context.Context) (bs api.BandwidthSettings, err error) {
+	err = c.Setting(ctx, api.SettingBandwidth, &bs)
+	return
+}
+
 // ContractSetSettings returns the contract set settings.
 func (c *Client) ContractSetSettings(ctx context.Context) (gs api.ContractSetSetting, err error) {
 	err = c.Setting(ctx, api.SettingContractSet, &gs)
 	return
 }
 
+// DeleteS3Policy removes the policy of the given access key, granting it full
+// access again.
+func (c *Client) DeleteS3Policy(ctx context.Context, accessKeyID string) error {
+	return c.c.WithContext(ctx).DELETE(fmt.Sprintf("/s3/policy/%s", accessKeyID))
+}
+
 // DeleteSetting will delete the setting with given key.
 func (c *Client) DeleteSetting(ctx context.Context, key string) error {
 	return c.c.WithContext(ctx).DELETE(fmt.Sprintf("/setting/%s", key))
@@ -36,6 +48,18 @@ func (c *Client) RedundancySettings(ctx context.Context) (rs api.RedundancySetti
 	return
 }
 
+// S3Policies returns the policies of all access keys that have one.
+func (c *Client) S3Policies(ctx context.Context) (policies map[string]api.S3AccessKeyPolicy, err error) {
+	err = c.c.WithContext(ctx).GET("/s3/policies", &policies)
+	return
+}
+
+// S3Policy returns the policy of the given access key.
+func (c *Client) S3Policy(ctx context.Context, accessKeyID string) (policy api.S3AccessKeyPolicy, err error) {
+	err = c.c.WithContext(ctx).GET(fmt.Sprintf("/s3/policy/%s", accessKeyID), &policy)
+	return
+}
+
 // S3AuthenticationSettings returns the S3 authentication settings.
 func (c *Client) S3AuthenticationSettings(ctx context.Context) (as api.S3AuthenticationSettings, err error) {
 	err = c.Setting(ctx, api.SettingS3Authentication, &as)
@@ -64,3 +88,8 @@ func (c *Client) UploadPackingSettings(ctx context.Context) (ups api.UploadPacki
 	err = c.Setting(ctx, api.SettingUploadPacking, &ups)
 	return
 }
+
+// UpdateS3Policy sets the policy of the given access key.
+func (c *Client) UpdateS3Policy(ctx context.Context, accessKeyID string, policy api.S3AccessKeyPolicy) error {
+	return c.c.WithContext(ctx).PUT(fmt.Sprintf("/s3/policy/%s", accessKeyID), policy)
+}
@@ -218,6 +218,9 @@ func (b *Bus) bucketsHandlerPOST(jc jape.Context) {
 	} else if bucket.Name == "" {
 		jc.Error(errors.New("no name provided"), http.StatusBadRequest)
 		return
+	} else if err := bucket.Policy.Validate(); err != nil {
+		jc.Error(err, http.StatusBadRequest)
+		return
 	} else if jc.Check("failed to create bucket", b.ms.CreateBucket(jc.Request.Context(), bucket.Name, bucket.Policy)) != nil {
 		return
 	}
@@ -230,6 +233,9 @@ func (b *Bus) bucketsHandlerPolicyPUT(jc jape.Context) {
 	} else if bucket := jc.PathParam("name"); bucket == "" {
 		jc.Error(errors.New("no bucket name provided"), http.StatusBadRequest)
 		return
+	} else if err := req.Policy.Validate(); err != nil {
+		jc.Error(err, http.StatusBadRequest)
+		return
 	} else if jc.Check("failed to create bucket", b.ms.UpdateBucketPolicy(jc.Request.Context(), bucket, req.Policy)) != nil {
 		return
 	}
@@ -1236,7 +1242,12 @@ func (b *Bus) searchObjectsHandlerGET(jc jape.Context) {
 	if jc.DecodeForm("bucket", &bucket) != nil {
 		return
 	}
-	keys, err := b.ms.SearchObjects(jc.Request.Context(), bucket, key, offset, limit)
+	tags, err := api.ParseObjectTagQuery(jc.Request.URL.Query()["tag"])
+	if err != nil {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	}
+	keys, err := b.ms.SearchObjects(jc.Request.Context(), bucket, key, tags, offset, limit)
 	if jc.Check("couldn't list objects", err) != nil {
 		return
 	}
@@ -1261,15 +1272,26 @@ func (b *Bus) objectsHandlerGET(jc jape.Context) {
 	if jc.DecodeForm("onlymetadata", &onlymetadata) != nil {
 		return
 	}
+	var versionID string
+	if jc.DecodeForm("versionid", &versionID) != nil {
+		return
+	}
 
 	var o api.Object
 	var err error
-	if onlymetadata {
+	if versionID != "" {
+		o, err = b.ms.ObjectVersion(jc.Request.Context(), bucket, path, versionID)
+		if onlymetadata {
+			o.Object = nil
+		}
+	} else if onlymetadata {
 		o, err = b.ms.ObjectMetadata(jc.Request.Context(), bucket, path)
 	} else {
 		o, err = b.ms.Object(jc.Request.Context(), bucket, path)
 	}
-	if errors.Is(err, api.ErrObjectNotFound) {
+	if errors.Is(err, api.ErrObjectNotFound) ||
+		errors.Is(err, api.ErrObjectVersionNotFound) ||
+		errors.Is(err, api.ErrObjectVersionIsDeleteMarker) {
 		jc.Error(err, http.StatusNotFound)
 		return
 	} else if jc.Check("couldn't load object", err) != nil {
@@ -1329,7 +1351,34 @@ func (b *Bus) objectsHandlerPUT(jc jape.Context) {
 	} else if aor.Bucket == "" {
 		aor.Bucket = api.DefaultBucketName
 	}
-	jc.Check("couldn't store object", b.ms.UpdateObject(jc.Request.Context(), aor.Bucket, jc.PathParam("path"), aor.ContractSet, aor.ETag, aor.MimeType, aor.Metadata, aor.Object))
+	if err := aor.Lock.Retention.Validate(); err != nil {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	}
+	err := b.ms.UpdateObject(jc.Request.Context(), aor.Bucket, jc.PathParam("path"), aor.ContractSet, aor.Object, api.AddObjectOptions{
+		ETag:                   aor.ETag,
+		MimeType:               aor.MimeType,
+		Metadata:               aor.Metadata,
+		ChecksumAlgorithm:      aor.ChecksumAlgorithm,
+		Checksum:               aor.Checksum,
+		CustomerKeyFingerprint: aor.CustomerKeyFingerprint,
+		Conditions:             aor.Conditions,
+		Lock:                   aor.Lock,
+	})
+	if errors.Is(err, api.ErrPreconditionFailed) {
+		jc.Error(err, http.StatusPreconditionFailed)
+		return
+	} else if errors.Is(err, api.ErrObjectLocked) {
+		jc.Error(err, http.StatusForbidden)
+		return
+	} else if jc.Check("couldn't store object", err) != nil {
+		return
+	}
+	b.broadcastObjectCreated(aor.Bucket, jc.PathParam("path"), api.ObjectMetadata{
+		ETag:     aor.ETag,
+		MimeType: aor.MimeType,
+		Size:     aor.Object.TotalSize(),
+	})
 }
 
 func (b *Bus) objectsCopyHandlerPOST(jc jape.Context) {
@@ -1338,9 +1387,13 @@ func (b *Bus) objectsCopyHandlerPOST(jc jape.Context) {
 		return
 	}
 	om, err := b.ms.CopyObject(jc.Request.Context(), orr.SourceBucket, orr.DestinationBucket, orr.SourcePath, orr.DestinationPath, orr.MimeType, orr.Metadata)
-	if jc.Check("couldn't copy object", err) != nil {
+	if errors.Is(err, api.ErrObjectLocked) {
+		jc.Error(err, http.StatusForbidden)
+		return
+	} else if jc.Check("couldn't copy object", err) != nil {
 		return
 	}
+	b.broadcastObjectCreated(orr.DestinationBucket, orr.DestinationPath, om)
 
 	jc.ResponseWriter.Header().Set("Last-Modified", om.ModTime.Std().Format(http.TimeFormat))
 	jc.ResponseWriter.Header().Set("ETag", api.FormatETag(om.ETag))
@@ -1355,7 +1408,7 @@ func (b *Bus) objectsListHandlerPOST(jc jape.Context) {
 	if req.Bucket == "" {
 		req.Bucket = api.DefaultBucketName
 	}
-	resp, err := b.ms.ListObjects(jc.Request.Context(), req.Bucket, req.Prefix, req.SortBy, req.SortDir, req.Marker, req.Limit)
+	resp, err := b.ms.ListObjects(jc.Request.Context(), req.Bucket, req.Prefix, req.SortBy, req.SortDir, req.Marker, req.Tags, req.Limit)
 	if errors.Is(err, api.ErrMarkerNotFound) {
 		jc.Error(err, http.StatusBadRequest)
 		return
@@ -1365,6 +1418,27 @@ func (b *Bus) objectsListHandlerPOST(jc jape.Context) {
 	jc.Encode(resp)
 }
 
+func (b *Bus) objectsVersionsHandlerPOST(jc jape.Context) {
+	var req api.ObjectVersionsRequest
+	if jc.Decode(&req) != nil {
+		return
+	}
+	if req.Bucket == "" {
+		req.Bucket = api.DefaultBucketName
+	}
+	resp, err := b.ms.ObjectVersions(jc.Request.Context(), req.Bucket, req.Prefix, req.KeyMarker, req.VersionIDMarker, req.Limit)
+	if errors.Is(err, api.ErrMarkerNotFound) {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	} else if errors.Is(err, api.ErrBucketNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	} else if jc.Check("couldn't list object versions", err) != nil {
+		return
+	}
+	jc.Encode(resp)
+}
+
 func (b *Bus) objectsRenameHandlerPOST(jc jape.Context) {
 	var orr api.ObjectsRenameRequest
 	if jc.Decode(&orr) != nil {
@@ -1378,7 +1452,13 @@ func (b *Bus) objectsRenameHandlerPOST(jc jape.Context) {
 			jc.Error(fmt.Errorf("can't rename dirs with mode %v", orr.Mode), http.StatusBadRequest)
 			return
 		}
-		jc.Check("couldn't rename object", b.ms.RenameObject(jc.Request.Context(), orr.Bucket, orr.From, orr.To, orr.Force))
+		err := b.ms.RenameObject(jc.Request.Context(), orr.Bucket, orr.From, orr.To, orr.Force)
+		if errors.Is(err, api.ErrObjectLocked) {
+			jc.Error(err, http.StatusForbidden)
+		} else if jc.Check("couldn't rename object", err) == nil {
+			b.broadcastObjectDeleted(orr.Bucket, orr.From, "", false)
+			b.broadcastStoredObjectCreated(jc.Request.Context(), orr.Bucket, orr.To)
+		}
 		return
 	} else if orr.Mode == api.ObjectsRenameModeMulti {
 		// Multi object rename.
@@ -1386,7 +1466,13 @@ func (b *Bus) objectsRenameHandlerPOST(jc jape.Context) {
 			jc.Error(fmt.Errorf("can't rename file with mode %v", orr.Mode), http.StatusBadRequest)
 			return
 		}
-		jc.Check("couldn't rename objects", b.ms.RenameObjects(jc.Request.Context(), orr.Bucket, orr.From, orr.To, orr.Force))
+		err := b.ms.RenameObjects(jc.Request.Context(), orr.Bucket, orr.From, orr.To, orr.Force)
+		if errors.Is(err, api.ErrObjectLocked) {
+			jc.Error(err, http.StatusForbidden)
+		} else if jc.Check("couldn't rename objects", err) == nil {
+			b.broadcastObjectDeleted(orr.Bucket, orr.From, "", true)
+			b.broadcastObjectsCreated(orr.Bucket, orr.To)
+		}
 		return
 	} else {
 		// Invalid mode.
@@ -1404,17 +1490,152 @@ func (b *Bus) objectsHandlerDELETE(jc jape.Context) {
 	if jc.DecodeForm("bucket", &bucket) != nil {
 		return
 	}
+	var versionID string
+	if jc.DecodeForm("versionid", &versionID) != nil {
+		return
+	} else if batch && versionID != "" {
+		jc.Error(errors.New("can't delete a specific version in batch mode"), http.StatusBadRequest)
+		return
+	}
+	var resp api.DeleteObjectResponse
 	var err error
 	if batch {
 		err = b.ms.RemoveObjects(jc.Request.Context(), bucket, jc.PathParam("path"))
+	} else if versionID != "" {
+		err = b.ms.RemoveObjectVersion(jc.Request.Context(), bucket, jc.PathParam("path"), versionID)
+		resp.VersionID = versionID
 	} else {
-		err = b.ms.RemoveObject(jc.Request.Context(), bucket, jc.PathParam("path"))
+		resp, err = b.ms.RemoveObject(jc.Request.Context(), bucket, jc.PathParam("path"))
+	}
+	if errors.Is(err, api.ErrObjectNotFound) || errors.Is(err, api.ErrObjectVersionNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	} else if errors.Is(err, api.ErrObjectLocked) {
+		jc.Error(err, http.StatusForbidden)
+		return
+	} else if jc.Check("couldn't delete object", err) != nil {
+		return
+	}
+	b.broadcastObjectDeleted(bucket, jc.PathParam("path"), versionID, batch)
+	jc.Encode(resp)
+}
+
+func (b *Bus) objectTagsHandlerGET(jc jape.Context) {
+	bucket := api.DefaultBucketName
+	if jc.DecodeForm("bucket", &bucket) != nil {
+		return
 	}
+	tags, err := b.ms.ObjectTags(jc.Request.Context(), bucket, jc.PathParam("path"))
 	if errors.Is(err, api.ErrObjectNotFound) {
 		jc.Error(err, http.StatusNotFound)
 		return
+	} else if jc.Check("couldn't fetch object tags", err) != nil {
+		return
+	}
+	jc.Encode(tags)
+}
+
+func (b *Bus) objectTagsHandlerPUT(jc jape.Context) {
+	bucket := api.DefaultBucketName
+	if jc.DecodeForm("bucket", &bucket) != nil {
+		return
+	}
+	var tags api.ObjectTags
+	if jc.Decode(&tags) != nil {
+		return
+	} else if err := tags.Validate(); err != nil {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	}
+	err := b.ms.UpdateObjectTags(jc.Request.Context(), bucket, jc.PathParam("path"), tags)
+	if errors.Is(err, api.ErrObjectNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	}
+	jc.Check("couldn't update object tags", err)
+}
+
+func (b *Bus) objectTagsHandlerDELETE(jc jape.Context) {
+	bucket := api.DefaultBucketName
+	if jc.DecodeForm("bucket", &bucket) != nil {
+		return
 	}
-	jc.Check("couldn't delete object", err)
+	err := b.ms.UpdateObjectTags(jc.Request.Context(), bucket, jc.PathParam("path"), nil)
+	if errors.Is(err, api.ErrObjectNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	}
+	jc.Check("couldn't delete object tags", err)
+}
+
+func (b *Bus) objectLegalHoldHandlerGET(jc jape.Context) {
+	bucket := api.DefaultBucketName
+	if jc.DecodeForm("bucket", &bucket) != nil {
+		return
+	}
+	lock, err := b.ms.ObjectLock(jc.Request.Context(), bucket, jc.PathParam("path"))
+	if errors.Is(err, api.ErrObjectNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	} else if jc.Check("couldn't fetch object legal hold", err) != nil {
+		return
+	}
+	jc.Encode(api.ObjectLegalHold{Enabled: lock.LegalHold})
+}
+
+func (b *Bus) objectLegalHoldHandlerPUT(jc jape.Context) {
+	bucket := api.DefaultBucketName
+	if jc.DecodeForm("bucket", &bucket) != nil {
+		return
+	}
+	var lh api.ObjectLegalHold
+	if jc.Decode(&lh) != nil {
+		return
+	}
+	err := b.ms.UpdateObjectLegalHold(jc.Request.Context(), bucket, jc.PathParam("path"), lh.Enabled)
+	if errors.Is(err, api.ErrObjectNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	}
+	jc.Check("couldn't update object legal hold", err)
+}
+
+func (b *Bus) objectRetentionHandlerGET(jc jape.Context) {
+	bucket := api.DefaultBucketName
+	if jc.DecodeForm("bucket", &bucket) != nil {
+		return
+	}
+	lock, err := b.ms.ObjectLock(jc.Request.Context(), bucket, jc.PathParam("path"))
+	if errors.Is(err, api.ErrObjectNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	} else if jc.Check("couldn't fetch object retention", err) != nil {
+		return
+	}
+	jc.Encode(lock.Retention)
+}
+
+func (b *Bus) objectRetentionHandlerPUT(jc jape.Context) {
+	bucket := api.DefaultBucketName
+	if jc.DecodeForm("bucket", &bucket) != nil {
+		return
+	}
+	var req api.ObjectRetentionRequest
+	if jc.Decode(&req) != nil {
+		return
+	} else if err := req.Retention.Validate(); err != nil {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	}
+	err := b.ms.UpdateObjectRetention(jc.Request.Context(), bucket, jc.PathParam("path"), req.Retention, req.BypassGovernance)
+	if errors.Is(err, api.ErrObjectNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	} else if errors.Is(err, api.ErrObjectLocked) {
+		jc.Error(err, http.StatusForbidden)
+		return
+	}
+	jc.Check("couldn't update object retention", err)
 }
 
 func (b *Bus) slabbuffersHandlerGET(jc jape.Context) {
@@ -1686,6 +1907,15 @@ func (b *Bus) settingKeyHandlerPUT(jc jape.Context) {
 	}
 
 	switch key {
+	case api.SettingBandwidth:
+		var bs api.BandwidthSettings
+		if err := json.Unmarshal(data, &bs); err != nil {
+			jc.Error(fmt.Errorf("couldn't update bandwidth settings, invalid request body"), http.StatusBadRequest)
+			return
+		} else if err := bs.Validate(); err != nil {
+			jc.Error(fmt.Errorf("couldn't update bandwidth settings, error: %v", err), http.StatusBadRequest)
+			return
+		}
 	case api.SettingGouging:
 		var gs api.GougingSettings
 		if err := json.Unmarshal(data, &gs); err != nil {
@@ -1763,6 +1993,67 @@ func (b *Bus) settingKeyHandlerDELETE(jc jape.Context) {
 	}
 }
 
+func (b *Bus) s3PoliciesHandlerGET(jc jape.Context) {
+	as, err := b.s3AuthenticationSettings(jc.Request.Context())
+	if jc.Check("failed to fetch s3 authentication settings", err) != nil {
+		return
+	} else if as.Policies == nil {
+		as.Policies = make(map[string]api.S3AccessKeyPolicy)
+	}
+	jc.Encode(as.Policies)
+}
+
+func (b *Bus) s3PolicyHandlerGET(jc jape.Context) {
+	as, err := b.s3AuthenticationSettings(jc.Request.Context())
+	if jc.Check("failed to fetch s3 authentication settings", err) != nil {
+		return
+	}
+	policy, exists := as.Policies[jc.PathParam("key")]
+	if !exists {
+		jc.Error(api.ErrS3PolicyNotFound, http.StatusNotFound)
+		return
+	}
+	jc.Encode(policy)
+}
+
+func (b *Bus) s3PolicyHandlerPUT(jc jape.Context) {
+	var policy api.S3AccessKeyPolicy
+	if jc.Decode(&policy) != nil {
+		return
+	} else if err := policy.Validate(); err != nil {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	}
+
+	as, err := b.s3AuthenticationSettings(jc.Request.Context())
+	if jc.Check("failed to fetch s3 authentication settings", err) != nil {
+		return
+	}
+	key := jc.PathParam("key")
+	if _, exists := as.V4Keypairs[key]; !exists {
+		jc.Error(api.ErrS3AccessKeyNotFound, http.StatusNotFound)
+		return
+	} else if as.Policies == nil {
+		as.Policies = make(map[string]api.S3AccessKeyPolicy)
+	}
+	as.Policies[key] = policy
+	jc.Check("failed to update s3 authentication settings", b.updateS3AuthenticationSettings(jc.Request.Context(), as))
+}
+
+func (b *Bus) s3PolicyHandlerDELETE(jc jape.Context) {
+	as, err := b.s3AuthenticationSettings(jc.Request.Context())
+	if jc.Check("failed to fetch s3 authentication settings", err) != nil {
+		return
+	}
+	key := jc.PathParam("key")
+	if _, exists := as.Policies[key]; !exists {
+		jc.Error(api.ErrS3PolicyNotFound, http.StatusNotFound)
+		return
+	}
+	delete(as.Policies, key)
+	jc.Check("failed to update s3 authentication settings", b.updateS3AuthenticationSettings(jc.Request.Context(), as))
+}
+
 func (b *Bus) contractIDAncestorsHandler(jc jape.Context) {
 	var fcid types.FileContractID
 	if jc.DecodeParam("id", &fcid) != nil {
@@ -2026,6 +2317,76 @@ func (b *Bus) autopilotHostCheckHandlerPUT(jc jape.Context) {
 	}
 }
 
+func (b *Bus) broadcastObjectDeleted(bucket, path, versionID string, batch bool) {
+	b.broadcastAction(webhooks.Event{
+		Module: api.ModuleObject,
+		Event:  api.EventDeleted,
+		Payload: api.EventObjectDeleted{
+			Bucket:    bucket,
+			Path:      path,
+			Batch:     batch,
+			VersionID: versionID,
+			Timestamp: time.Now().UTC(),
+		},
+	})
+}
+
+func (b *Bus) broadcastObjectCreated(bucket, path string, om api.ObjectMetadata) {
+	b.broadcastAction(webhooks.Event{
+		Module: api.ModuleObject,
+		Event:  api.EventCreated,
+		Payload: api.EventObjectCreated{
+			Bucket:    bucket,
+			Path:      path,
+			ETag:      om.ETag,
+			MimeType:  om.MimeType,
+			Size:      om.Size,
+			Timestamp: time.Now().UTC(),
+		},
+	})
+}
+
+// broadcastStoredObjectCreated broadcasts the creation of an object whose
+// metadata isn't known to the caller, it's fetched from the store. The event
+// is broadcast without metadata if that fails since subscribers rely on it to
+// invalidate their caches.
+func (b *Bus) broadcastStoredObjectCreated(ctx context.Context, bucket, path string) {
+	var om api.ObjectMetadata
+	if obj, err := b.ms.ObjectMetadata(ctx, bucket, path); err != nil {
+		b.logger.Errorw("failed to fetch metadata of updated object", "bucket", bucket, "path", path, zap.Error(err))
+	} else {
+		om = obj.ObjectMetadata
+	}
+	b.broadcastObjectCreated(bucket, path, om)
+}
+
+func (b *Bus) broadcastObjectsCreated(bucket, prefix string) {
+	b.broadcastAction(webhooks.Event{
+		Module: api.ModuleObject,
+		Event:  api.EventCreated,
+		Payload: api.EventObjectCreated{
+			Bucket:    bucket,
+			Path:      prefix,
+			Batch:     true,
+			Timestamp: time.Now().UTC(),
+		},
+	})
+}
+
+func (b *Bus) broadcastMultipartCompleted(bucket, path, uploadID, eTag string) {
+	b.broadcastAction(webhooks.Event{
+		Module: api.ModuleMultipart,
+		Event:  api.EventCompleted,
+		Payload: api.EventMultipartCompleted{
+			Bucket:    bucket,
+			Path:      path,
+			UploadID:  uploadID,
+			ETag:      eTag,
+			Timestamp: time.Now().UTC(),
+		},
+	})
+}
+
 func (b *Bus) broadcastAction(e webhooks.Event) {
 	log := b.logger.With("event", e.Event).With("module", e.Module)
 	err := b.webhooksMgr.BroadcastAction(context.Background(), e)
@@ -2118,6 +2479,9 @@ func (b *Bus) webhookHandlerPost(jc jape.Context) {
 	var req webhooks.Webhook
 	if jc.Decode(&req) != nil {
 		return
+	} else if (req.Bucket != "" || req.Prefix != "") && req.Module != api.ModuleObject {
+		jc.Error(fmt.Errorf("bucket and prefix filters are only supported for module '%s'", api.ModuleObject), http.StatusBadRequest)
+		return
 	}
 
 	err := b.webhooksMgr.Register(jc.Request.Context(), webhooks.Webhook{
@@ -2125,6 +2489,8 @@ func (b *Bus) webhookHandlerPost(jc jape.Context) {
 		Module:  req.Module,
 		URL:     req.URL,
 		Headers: req.Headers,
+		Bucket:  req.Bucket,
+		Prefix:  req.Prefix,
 	})
 	if err != nil {
 		jc.Error(fmt.Errorf("failed to add Webhook: %w", err), http.StatusInternalServerError)
@@ -2321,12 +2687,29 @@ func (b *Bus) multipartHandlerCompletePOST(jc jape.Context) {
 	if jc.Decode(&req) != nil {
 		return
 	}
+	if err := req.Lock.Retention.Validate(); err != nil {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	}
 	resp, err := b.ms.CompleteMultipartUpload(jc.Request.Context(), req.Bucket, req.Path, req.UploadID, req.Parts, api.CompleteMultipartOptions{
-		Metadata: req.Metadata,
+		Metadata:               req.Metadata,
+		ChecksumAlgorithm:      req.ChecksumAlgorithm,
+		Checksum:               req.Checksum,
+		CustomerKeyFingerprint: req.CustomerKeyFingerprint,
+		Conditions:             req.Conditions,
+		Lock:                   req.Lock,
 	})
-	if jc.Check("failed to complete multipart upload", err) != nil {
+	if errors.Is(err, api.ErrPreconditionFailed) {
+		jc.Error(err, http.StatusPreconditionFailed)
+		return
+	} else if errors.Is(err, api.ErrObjectLocked) {
+		jc.Error(err, http.StatusForbidden)
+		return
+	} else if jc.Check("failed to complete multipart upload", err) != nil {
 		return
 	}
+	b.broadcastMultipartCompleted(req.Bucket, req.Path, req.UploadID, resp.ETag)
+	b.broadcastStoredObjectCreated(jc.Request.Context(), req.Bucket, req.Path)
 	jc.Encode(resp)
 }
 
@@ -2349,11 +2732,62 @@ func (b *Bus) multipartHandlerUploadPartPUT(jc jape.Context) {
 	} else if req.UploadID == "" {
 		jc.Error(errors.New("upload_id must be non-empty"), http.StatusBadRequest)
 		return
+	} else if req.Offset != nil && *req.Offset < 0 {
+		jc.Error(errors.New("offset must be positive"), http.StatusBadRequest)
+		return
+	}
+	err := b.ms.AddMultipartPart(jc.Request.Context(), req.Bucket, req.Path, req.ContractSet, req.ETag, req.UploadID, req.PartNumber, req.Slices, api.AddMultipartPartOptions{
+		Offset: req.Offset,
+	})
+	if errors.Is(err, api.ErrUploadOffsetMismatch) {
+		jc.Error(err, http.StatusConflict)
+		return
+	} else if jc.Check("failed to upload part", err) != nil {
+		return
+	}
+}
+
+func (b *Bus) multipartHandlerCopyPartPOST(jc jape.Context) {
+	var req api.MultipartCopyPartRequest
+	if jc.Decode(&req) != nil {
+		return
+	}
+	if req.Bucket == "" {
+		req.Bucket = api.DefaultBucketName
+	}
+	if req.SourceBucket == "" {
+		req.SourceBucket = api.DefaultBucketName
+	}
+	if req.ContractSet == "" {
+		jc.Error(errors.New("contract_set must be non-empty"), http.StatusBadRequest)
+		return
+	} else if req.PartNumber <= 0 || req.PartNumber > gofakes3.MaxUploadPartNumber {
+		jc.Error(fmt.Errorf("part_number must be between 1 and %d", gofakes3.MaxUploadPartNumber), http.StatusBadRequest)
+		return
+	} else if req.UploadID == "" {
+		jc.Error(errors.New("upload_id must be non-empty"), http.StatusBadRequest)
+		return
+	} else if req.SourcePath == "" {
+		jc.Error(errors.New("source_path must be non-empty"), http.StatusBadRequest)
+		return
+	} else if req.Offset < 0 || req.Length < -1 {
+		jc.Error(api.ErrInvalidPartCopyRange, http.StatusBadRequest)
+		return
 	}
-	err := b.ms.AddMultipartPart(jc.Request.Context(), req.Bucket, req.Path, req.ContractSet, req.ETag, req.UploadID, req.PartNumber, req.Slices)
-	if jc.Check("failed to upload part", err) != nil {
+	resp, err := b.ms.CopyMultipartPart(jc.Request.Context(), req.SourceBucket, req.SourcePath, req.Bucket, req.Path, req.ContractSet, req.UploadID, req.PartNumber, req.Offset, req.Length)
+	if errors.Is(err, api.ErrObjectNotFound) || errors.Is(err, api.ErrMultipartUploadNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	} else if errors.Is(err, api.ErrInvalidPartCopyRange) {
+		jc.Error(err, http.StatusRequestedRangeNotSatisfiable)
+		return
+	} else if errors.Is(err, api.ErrPartCopyNotSupported) {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	} else if jc.Check("failed to copy part", err) != nil {
 		return
 	}
+	jc.Encode(resp)
 }
 
 func (b *Bus) multipartHandlerUploadGET(jc jape.Context) {
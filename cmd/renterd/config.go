@@ -321,6 +321,8 @@ func parseCLIFlags(cfg *config.Config) {
 	flag.BoolVar(&cfg.Worker.Enabled, "worker.enabled", cfg.Worker.Enabled, "Enables/disables worker (overrides with RENTERD_WORKER_ENABLED)")
 	flag.BoolVar(&cfg.Worker.AllowUnauthenticatedDownloads, "worker.unauthenticatedDownloads", cfg.Worker.AllowUnauthenticatedDownloads, "Allows unauthenticated downloads (overrides with RENTERD_WORKER_UNAUTHENTICATED_DOWNLOADS)")
 	flag.StringVar(&cfg.Worker.ExternalAddress, "worker.externalAddress", cfg.Worker.ExternalAddress, "Address of the worker on the network, only necessary when the bus is remote (overrides with RENTERD_WORKER_EXTERNAL_ADDR)")
+	flag.StringVar(&cfg.Worker.SlabCacheDir, "worker.slabCacheDir", cfg.Worker.SlabCacheDir, "Directory for the slab cache, defaults to a directory in the node's directory")
+	flag.Uint64Var(&cfg.Worker.SlabCacheMaxSize, "worker.slabCacheMaxSize", cfg.Worker.SlabCacheMaxSize, "Max size of the on-disk slab cache in bytes, 0 disables it (overrides with RENTERD_WORKER_SLAB_CACHE_MAX_SIZE)")
 
 	// autopilot
 	flag.DurationVar(&cfg.Autopilot.Heartbeat, "autopilot.heartbeat", cfg.Autopilot.Heartbeat, "Interval for autopilot loop execution")
@@ -330,6 +332,8 @@ func parseCLIFlags(cfg *config.Config) {
 	flag.DurationVar(&cfg.Autopilot.ScannerInterval, "autopilot.scannerInterval", cfg.Autopilot.ScannerInterval, "Interval for scanning hosts")
 	flag.Uint64Var(&cfg.Autopilot.ScannerNumThreads, "autopilot.scannerNumThreads", cfg.Autopilot.ScannerNumThreads, "Number of threads for scanning hosts")
 	flag.Uint64Var(&cfg.Autopilot.MigratorParallelSlabsPerWorker, "autopilot.migratorParallelSlabsPerWorker", cfg.Autopilot.MigratorParallelSlabsPerWorker, "Parallel slab migrations per worker (overrides with RENTERD_MIGRATOR_PARALLEL_SLABS_PER_WORKER)")
+	flag.StringVar(&cfg.Autopilot.GeoIPDatabase, "autopilot.geoIPDatabase", cfg.Autopilot.GeoIPDatabase, "Path to an IP-to-ASN database used to enforce host diversity limits")
+	flag.StringVar(&cfg.Autopilot.HostScorerURL, "autopilot.hostScorerURL", cfg.Autopilot.HostScorerURL, "URL of an external service used to score hosts")
 	flag.BoolVar(&cfg.Autopilot.Enabled, "autopilot.enabled", cfg.Autopilot.Enabled, "Enables/disables autopilot (overrides with RENTERD_AUTOPILOT_ENABLED)")
 	flag.DurationVar(&cfg.ShutdownTimeout, "node.shutdownTimeout", cfg.ShutdownTimeout, "Timeout for node shutdown")
 
@@ -339,6 +343,7 @@ func parseCLIFlags(cfg *config.Config) {
 	flag.BoolVar(&cfg.S3.Enabled, "s3.enabled", cfg.S3.Enabled, "Enables/disables S3 API (requires worker.enabled to be 'true', overrides with RENTERD_S3_ENABLED)")
 	flag.StringVar(&hostBasesStr, "s3.hostBases", "", "Enables bucket rewriting in the router for specific hosts provided via comma-separated list (overrides with RENTERD_S3_HOST_BUCKET_BASES)")
 	flag.BoolVar(&cfg.S3.HostBucketEnabled, "s3.hostBucketEnabled", cfg.S3.HostBucketEnabled, "Enables bucket rewriting in the router for all hosts (overrides with RENTERD_S3_HOST_BUCKET_ENABLED)")
+	flag.BoolVar(&cfg.S3.AllowInsecureSSEC, "s3.allowInsecureSSEC", cfg.S3.AllowInsecureSSEC, "Accepts SSE-C requests that weren't made over TLS, e.g. when TLS is terminated by a reverse proxy (overrides with RENTERD_S3_ALLOW_INSECURE_SSEC)")
 
 	// custom usage
 	flag.Usage = func() {
@@ -384,6 +389,7 @@ func parseEnvironmentVariables(cfg *config.Config) {
 	parseEnvVar("RENTERD_WORKER_DOWNLOAD_MAX_MEMORY", &cfg.Worker.DownloadMaxMemory)
 	parseEnvVar("RENTERD_WORKER_UPLOAD_MAX_MEMORY", &cfg.Worker.UploadMaxMemory)
 	parseEnvVar("RENTERD_WORKER_EXTERNAL_ADDR", &cfg.Worker.ExternalAddress)
+	parseEnvVar("RENTERD_WORKER_SLAB_CACHE_MAX_SIZE", &cfg.Worker.SlabCacheMaxSize)
 
 	parseEnvVar("RENTERD_AUTOPILOT_ENABLED", &cfg.Autopilot.Enabled)
 	parseEnvVar("RENTERD_AUTOPILOT_REVISION_BROADCAST_INTERVAL", &cfg.Autopilot.RevisionBroadcastInterval)
@@ -394,6 +400,7 @@ func parseEnvironmentVariables(cfg *config.Config) {
 	parseEnvVar("RENTERD_S3_DISABLE_AUTH", &cfg.S3.DisableAuth)
 	parseEnvVar("RENTERD_S3_HOST_BUCKET_ENABLED", &cfg.S3.HostBucketEnabled)
 	parseEnvVar("RENTERD_S3_HOST_BUCKET_BASES", &cfg.S3.HostBucketBases)
+	parseEnvVar("RENTERD_S3_ALLOW_INSECURE_SSEC", &cfg.S3.AllowInsecureSSEC)
 
 	parseEnvVar("RENTERD_LOG_PATH", &cfg.Log.Path)
 	parseEnvVar("RENTERD_LOG_LEVEL", &cfg.Log.Level)
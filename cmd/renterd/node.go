@@ -172,6 +172,10 @@ func newNode(cfg config.Config, network *consensus.Network, genesis types.Block)
 				workerExternAddr = workerAddr
 			}
 
+			if cfg.Worker.SlabCacheMaxSize > 0 && cfg.Worker.SlabCacheDir == "" {
+				cfg.Worker.SlabCacheDir = filepath.Join(cfg.Directory, "slabcache")
+			}
+
 			workerKey := blake2b.Sum256(append([]byte("worker"), pk...))
 			w, err := worker.New(cfg.Worker, workerKey, bc, logger)
 			if err != nil {
@@ -197,6 +201,7 @@ func newNode(cfg config.Config, network *consensus.Network, genesis types.Block)
 					AuthDisabled:      cfg.S3.DisableAuth,
 					HostBucketBases:   cfg.S3.HostBucketBases,
 					HostBucketEnabled: cfg.S3.HostBucketEnabled,
+					AllowInsecureSSEC: cfg.S3.AllowInsecureSSEC,
 				})
 				if err != nil {
 					err = errors.Join(err, w.Shutdown(context.Background()))
@@ -427,6 +432,7 @@ func (n *node) Run() error {
 			if err := (api.S3AuthenticationSettings{V4Keypairs: map[string]string{k: v}}).Validate(); err != nil {
 				n.logger.Infof("removing invalid S3 keypair for AccessKeyID %s, reason: %v", k, err)
 				delete(as.V4Keypairs, k)
+				delete(as.Policies, k)
 			}
 		}
 
@@ -110,6 +110,7 @@ type (
 		KeypairsV4        map[string]string `yaml:"keypairsV4,omitempty"` // deprecated. included for compatibility.
 		HostBucketEnabled bool              `yaml:"hostBucketEnabled,omitempty"`
 		HostBucketBases   []string          `yaml:"hostBucketBases,omitempty"`
+		AllowInsecureSSEC bool              `yaml:"allowInsecureSSEC,omitempty"`
 	}
 
 	// Worker contains the configuration for a worker.
@@ -129,6 +130,8 @@ type (
 		UploadMaxOverdrive            uint64         `yaml:"uploadMaxOverdrive,omitempty"`
 		AllowUnauthenticatedDownloads bool           `yaml:"allowUnauthenticatedDownloads,omitempty"`
 		ExternalAddress               string         `yaml:"externalAddress,omitempty"`
+		SlabCacheDir                  string         `yaml:"slabCacheDir,omitempty"`
+		SlabCacheMaxSize              uint64         `yaml:"slabCacheMaxSize,omitempty"`
 	}
 
 	// Autopilot contains the configuration for an autopilot.
@@ -143,6 +146,8 @@ type (
 		ScannerBatchSize               uint64        `yaml:"scannerBatchSize,omitempty"`
 		ScannerNumThreads              uint64        `yaml:"scannerNumThreads,omitempty"`
 		MigratorParallelSlabsPerWorker uint64        `yaml:"migratorParallelSlabsPerWorker,omitempty"`
+		GeoIPDatabase                  string        `yaml:"geoIPDatabase,omitempty"`
+		HostScorerURL                  string        `yaml:"hostScorerURL,omitempty"`
 	}
 )
 
@@ -0,0 +1,268 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.thebigfile.com/renterd/api"
+	"go.thebigfile.com/renterd/webhooks"
+	"go.uber.org/zap"
+)
+
+const (
+	// lifecycleSweepBatchSize is the number of objects and multipart uploads
+	// that are fetched per request when applying lifecycle rules.
+	lifecycleSweepBatchSize = 1000
+
+	// lifecycleSweepTimeout is the maximum amount of time a single sweep is
+	// allowed to take.
+	lifecycleSweepTimeout = time.Hour
+)
+
+type (
+	LifecycleSweeper struct {
+		broadcaster webhooks.Broadcaster
+		store       LifecycleStore
+
+		shutdownChan chan struct{}
+		wg           sync.WaitGroup
+
+		logger *zap.SugaredLogger
+	}
+
+	LifecycleStore interface {
+		ListBuckets(ctx context.Context) ([]api.Bucket, error)
+
+		ListObjects(ctx context.Context, bucketName, prefix, sortBy, sortDir, marker string, tags api.ObjectTags, limit int) (api.ObjectsListResponse, error)
+		RemoveObject(ctx context.Context, bucketName, path string) (api.DeleteObjectResponse, error)
+
+		ObjectVersions(ctx context.Context, bucketName, prefix, keyMarker, versionIDMarker string, limit int) (api.ObjectVersionsResponse, error)
+		RemoveObjectVersion(ctx context.Context, bucketName, path, versionID string) error
+
+		AbortMultipartUpload(ctx context.Context, bucketName, path string, uploadID string) error
+		MultipartUploads(ctx context.Context, bucketName, prefix, keyMarker, uploadIDMarker string, maxUploads int) (api.MultipartListUploadsResponse, error)
+	}
+)
+
+// NewLifecycleSweeper returns a sweeper that periodically applies the
+// lifecycle rules of all buckets and broadcasts the deletion of every object
+// it expires. The sweeper is already running and can be stopped by calling
+// Shutdown.
+func NewLifecycleSweeper(broadcaster webhooks.Broadcaster, store LifecycleStore, interval time.Duration, logger *zap.Logger) *LifecycleSweeper {
+	logger = logger.Named("lifecyclesweeper")
+	sweeper := &LifecycleSweeper{
+		broadcaster:  broadcaster,
+		store:        store,
+		shutdownChan: make(chan struct{}),
+		logger:       logger.Sugar(),
+	}
+	sweeper.run(interval)
+	return sweeper
+}
+
+func (ls *LifecycleSweeper) Shutdown(ctx context.Context) error {
+	close(ls.shutdownChan)
+
+	waitChan := make(chan struct{})
+	go func() {
+		ls.wg.Wait()
+		close(waitChan)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-waitChan:
+		return nil
+	}
+}
+
+// Sweep applies the lifecycle rules of all buckets once and returns the number
+// of expired objects and noncurrent versions and the number of aborted
+// multipart uploads.
+func (ls *LifecycleSweeper) Sweep(ctx context.Context, now time.Time) (expired, aborted int, _ error) {
+	buckets, err := ls.store.ListBuckets(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list buckets: %w", err)
+	}
+
+	var errs []error
+	for _, bucket := range buckets {
+		for _, rule := range bucket.Policy.LifecycleRules {
+			if rule.ExpirationDays > 0 {
+				n, err := ls.expireObjects(ctx, bucket.Name, rule, now)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to expire objects in bucket '%s' for rule '%s': %w", bucket.Name, rule.ID, err))
+				}
+				expired += n
+			}
+			if rule.NoncurrentVersionExpirationDays > 0 {
+				n, err := ls.expireNoncurrentVersions(ctx, bucket.Name, rule, now)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to expire noncurrent versions in bucket '%s' for rule '%s': %w", bucket.Name, rule.ID, err))
+				}
+				expired += n
+			}
+			if rule.AbortIncompleteMultipartUploadDays > 0 {
+				n, err := ls.abortMultipartUploads(ctx, bucket.Name, rule, now)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to abort multipart uploads in bucket '%s' for rule '%s': %w", bucket.Name, rule.ID, err))
+				}
+				aborted += n
+			}
+		}
+	}
+	return expired, aborted, errors.Join(errs...)
+}
+
+func (ls *LifecycleSweeper) abortMultipartUploads(ctx context.Context, bucket string, rule api.LifecycleRule, now time.Time) (aborted int, _ error) {
+	var keyMarker, uploadIDMarker string
+	for {
+		resp, err := ls.store.MultipartUploads(ctx, bucket, rule.Prefix, keyMarker, uploadIDMarker, lifecycleSweepBatchSize)
+		if err != nil {
+			return aborted, err
+		}
+		for _, upload := range resp.Uploads {
+			if !strings.HasPrefix(upload.Path, rule.Prefix) || !rule.Abandoned(upload.CreatedAt.Std(), now) {
+				continue
+			}
+			err := ls.store.AbortMultipartUpload(ctx, bucket, upload.Path, upload.UploadID)
+			if errors.Is(err, api.ErrMultipartUploadNotFound) {
+				continue // completed or aborted in the meantime
+			} else if err != nil {
+				return aborted, fmt.Errorf("failed to abort multipart upload '%s': %w", upload.UploadID, err)
+			}
+			aborted++
+		}
+		if !resp.HasMore {
+			return aborted, nil
+		}
+		keyMarker, uploadIDMarker = resp.NextPathMarker, resp.NextUploadIDMarker
+	}
+}
+
+func (ls *LifecycleSweeper) expireObjects(ctx context.Context, bucket string, rule api.LifecycleRule, now time.Time) (expired int, _ error) {
+	var marker string
+	for {
+		resp, err := ls.store.ListObjects(ctx, bucket, rule.Prefix, api.ObjectSortByName, api.ObjectSortDirAsc, marker, nil, lifecycleSweepBatchSize)
+		if err != nil {
+			return expired, err
+		}
+		for _, obj := range resp.Objects {
+			if !rule.Expired(obj.ModTime.Std(), now) {
+				continue
+			}
+			_, err := ls.store.RemoveObject(ctx, bucket, obj.Name)
+			if errors.Is(err, api.ErrObjectLocked) {
+				continue // locked objects expire once their lock is lifted
+			} else if errors.Is(err, api.ErrObjectNotFound) {
+				continue // deleted in the meantime
+			} else if err != nil {
+				return expired, fmt.Errorf("failed to remove object '%s': %w", obj.Name, err)
+			}
+			ls.broadcastObjectDeleted(ctx, bucket, obj.Name)
+			expired++
+		}
+		if !resp.HasMore || len(resp.Objects) == 0 {
+			return expired, nil
+		}
+		marker = resp.Objects[len(resp.Objects)-1].Name
+	}
+}
+
+// expireNoncurrentVersions deletes the noncurrent versions that expired
+// according to the rule. A version becomes noncurrent when the next newer
+// version of its object is created, the versions of an object are listed from
+// newest to oldest so that's the version that precedes it.
+func (ls *LifecycleSweeper) expireNoncurrentVersions(ctx context.Context, bucket string, rule api.LifecycleRule, now time.Time) (expired int, _ error) {
+	var keyMarker, versionIDMarker string
+	var prev api.ObjectVersion
+	for {
+		resp, err := ls.store.ObjectVersions(ctx, bucket, rule.Prefix, keyMarker, versionIDMarker, lifecycleSweepBatchSize)
+		if err != nil {
+			return expired, err
+		}
+		for _, v := range resp.Versions {
+			noncurrentSince := prev.ModTime.Std()
+			isCurrent := v.IsLatest || v.Name != prev.Name
+			prev = v
+			if isCurrent || !rule.NoncurrentExpired(noncurrentSince, now) {
+				continue
+			}
+
+			versionID := v.VersionID
+			if versionID == "" {
+				versionID = api.ObjectVersionIDNull
+			}
+			err := ls.store.RemoveObjectVersion(ctx, bucket, v.Name, versionID)
+			if errors.Is(err, api.ErrObjectLocked) {
+				continue // locked versions expire once their lock is lifted
+			} else if errors.Is(err, api.ErrObjectVersionNotFound) {
+				continue // deleted in the meantime
+			} else if err != nil {
+				return expired, fmt.Errorf("failed to remove version '%s' of object '%s': %w", versionID, v.Name, err)
+			}
+			expired++
+		}
+		if !resp.HasMore || len(resp.Versions) == 0 {
+			return expired, nil
+		}
+		keyMarker, versionIDMarker = resp.NextKeyMarker, resp.NextVersionIDMarker
+	}
+}
+
+func (ls *LifecycleSweeper) broadcastObjectDeleted(ctx context.Context, bucket, path string) {
+	err := ls.broadcaster.BroadcastAction(ctx, webhooks.Event{
+		Module: api.ModuleObject,
+		Event:  api.EventDeleted,
+		Payload: api.EventObjectDeleted{
+			Bucket:    bucket,
+			Path:      path,
+			Timestamp: time.Now().UTC(),
+		},
+	})
+	if err != nil {
+		ls.logger.Errorw("failed to broadcast expired object", "bucket", bucket, "path", path, zap.Error(err))
+	}
+}
+
+func (ls *LifecycleSweeper) run(interval time.Duration) {
+	ls.wg.Add(1)
+	go func() {
+		defer ls.wg.Done()
+
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ls.shutdownChan:
+				return
+			case <-t.C:
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), lifecycleSweepTimeout)
+			go func() {
+				select {
+				case <-ls.shutdownChan:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			expired, aborted, err := ls.Sweep(ctx, time.Now())
+			if err != nil {
+				ls.logger.Errorw("failed to apply lifecycle rules", zap.Error(err))
+			} else if expired > 0 || aborted > 0 {
+				ls.logger.Infow("applied lifecycle rules",
+					"expired", expired,
+					"aborted", aborted)
+			}
+			cancel()
+		}
+	}()
+}
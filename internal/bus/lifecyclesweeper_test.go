@@ -0,0 +1,233 @@
+package bus
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"go.thebigfile.com/renterd/api"
+	"go.uber.org/zap"
+)
+
+type mockLifecycleStore struct {
+	buckets []api.Bucket
+	objects map[string][]api.ObjectMetadata
+	uploads map[string][]api.MultipartUpload
+
+	// versions are ordered like the store lists them, newest version of
+	// every object first
+	versions map[string][]api.ObjectVersion
+	stale    map[string]struct{}
+}
+
+func (s *mockLifecycleStore) ListBuckets(ctx context.Context) ([]api.Bucket, error) {
+	return s.buckets, nil
+}
+
+func (s *mockLifecycleStore) ListObjects(ctx context.Context, bucket, prefix, sortBy, sortDir, marker string, tags api.ObjectTags, limit int) (resp api.ObjectsListResponse, _ error) {
+	for _, obj := range s.objects[bucket] {
+		if strings.HasPrefix(obj.Name, prefix) && obj.Name > marker {
+			resp.Objects = append(resp.Objects, obj)
+		}
+	}
+	sort.Slice(resp.Objects, func(i, j int) bool { return resp.Objects[i].Name < resp.Objects[j].Name })
+	if len(resp.Objects) > limit {
+		resp.Objects = resp.Objects[:limit]
+		resp.HasMore = true
+	}
+	return
+}
+
+func (s *mockLifecycleStore) RemoveObject(ctx context.Context, bucket, path string) (api.DeleteObjectResponse, error) {
+	for i, obj := range s.objects[bucket] {
+		if obj.Name == path {
+			s.objects[bucket] = append(s.objects[bucket][:i], s.objects[bucket][i+1:]...)
+			return api.DeleteObjectResponse{}, nil
+		}
+	}
+	return api.DeleteObjectResponse{}, api.ErrObjectNotFound
+}
+
+func (s *mockLifecycleStore) ObjectVersions(ctx context.Context, bucket, prefix, keyMarker, versionIDMarker string, limit int) (resp api.ObjectVersionsResponse, _ error) {
+	for _, v := range s.versions[bucket] {
+		if strings.HasPrefix(v.Name, prefix) {
+			resp.Versions = append(resp.Versions, v)
+		}
+	}
+	return
+}
+
+func (s *mockLifecycleStore) RemoveObjectVersion(ctx context.Context, bucket, path, versionID string) error {
+	if _, ok := s.stale[versionID]; ok {
+		return api.ErrObjectVersionNotFound
+	}
+	for i, v := range s.versions[bucket] {
+		if v.Name == path && v.VersionID == versionID {
+			s.versions[bucket] = append(s.versions[bucket][:i], s.versions[bucket][i+1:]...)
+			return nil
+		}
+	}
+	return api.ErrObjectVersionNotFound
+}
+
+func (s *mockLifecycleStore) AbortMultipartUpload(ctx context.Context, bucket, path string, uploadID string) error {
+	for i, upload := range s.uploads[bucket] {
+		if upload.UploadID == uploadID {
+			s.uploads[bucket] = append(s.uploads[bucket][:i], s.uploads[bucket][i+1:]...)
+			return nil
+		}
+	}
+	return api.ErrMultipartUploadNotFound
+}
+
+func (s *mockLifecycleStore) MultipartUploads(ctx context.Context, bucket, prefix, keyMarker, uploadIDMarker string, maxUploads int) (resp api.MultipartListUploadsResponse, _ error) {
+	for _, upload := range s.uploads[bucket] {
+		if strings.HasPrefix(upload.Path, prefix) {
+			resp.Uploads = append(resp.Uploads, upload)
+		}
+	}
+	return
+}
+
+func TestLifecycleSweeper(t *testing.T) {
+	now := time.Now()
+	daysAgo := func(days int) api.TimeRFC3339 {
+		return api.TimeRFC3339(now.Add(-time.Duration(days) * 24 * time.Hour))
+	}
+
+	store := &mockLifecycleStore{
+		buckets: []api.Bucket{
+			{
+				Name: "logs",
+				Policy: api.BucketPolicy{
+					LifecycleRules: []api.LifecycleRule{
+						{ID: "expire", Prefix: "/tmp/", ExpirationDays: 7},
+						{ID: "abort", AbortIncompleteMultipartUploadDays: 2},
+					},
+				},
+			},
+			{Name: "norules"},
+		},
+		objects: map[string][]api.ObjectMetadata{
+			"logs": {
+				{Name: "/tmp/old", ModTime: daysAgo(8)},
+				{Name: "/tmp/new", ModTime: daysAgo(1)},
+				{Name: "/keep/old", ModTime: daysAgo(30)},
+			},
+			"norules": {
+				{Name: "/tmp/old", ModTime: daysAgo(30)},
+			},
+		},
+		uploads: map[string][]api.MultipartUpload{
+			"logs": {
+				{Path: "/foo", UploadID: "old", CreatedAt: daysAgo(3)},
+				{Path: "/bar", UploadID: "new", CreatedAt: daysAgo(1)},
+			},
+			"norules": {
+				{Path: "/foo", UploadID: "old", CreatedAt: daysAgo(30)},
+			},
+		},
+	}
+
+	eb := &mockBroadcaster{}
+	ls := &LifecycleSweeper{broadcaster: eb, store: store, logger: zap.NewNop().Sugar()}
+	expired, aborted, err := ls.Sweep(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	} else if expired != 1 || aborted != 1 {
+		t.Fatalf("unexpected sweep result, expired %d aborted %d", expired, aborted)
+	}
+
+	// assert only the expired object and the abandoned upload were removed
+	if len(store.objects["logs"]) != 2 || len(store.objects["norules"]) != 1 {
+		t.Fatal("unexpected objects", store.objects)
+	}
+	for _, obj := range store.objects["logs"] {
+		if obj.Name == "/tmp/old" {
+			t.Fatal("expected object to be expired")
+		}
+	}
+	if len(store.uploads["logs"]) != 1 || store.uploads["logs"][0].UploadID != "new" {
+		t.Fatal("unexpected uploads", store.uploads["logs"])
+	} else if len(store.uploads["norules"]) != 1 {
+		t.Fatal("unexpected uploads", store.uploads["norules"])
+	}
+
+	// assert the deletion of the expired object was broadcast
+	if len(eb.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(eb.events))
+	} else if e, ok := eb.events[0].Payload.(api.EventObjectDeleted); !ok || e.Bucket != "logs" || e.Path != "/tmp/old" {
+		t.Fatal("unexpected event", eb.events[0])
+	}
+
+	// sweeping again should be a no-op
+	if expired, aborted, err := ls.Sweep(context.Background(), now); err != nil {
+		t.Fatal(err)
+	} else if expired != 0 || aborted != 0 {
+		t.Fatalf("unexpected sweep result, expired %d aborted %d", expired, aborted)
+	}
+}
+
+func TestLifecycleSweeperNoncurrentVersions(t *testing.T) {
+	now := time.Now()
+	version := func(name, id string, days int, latest bool) api.ObjectVersion {
+		return api.ObjectVersion{
+			ObjectMetadata: api.ObjectMetadata{
+				Name:      name,
+				VersionID: id,
+				ModTime:   api.TimeRFC3339(now.Add(-time.Duration(days) * 24 * time.Hour)),
+			},
+			IsLatest: latest,
+		}
+	}
+
+	store := &mockLifecycleStore{
+		buckets: []api.Bucket{
+			{
+				Name: "versioned",
+				Policy: api.BucketPolicy{
+					Versioning: api.BucketVersioningEnabled,
+					LifecycleRules: []api.LifecycleRule{
+						{ID: "noncurrent", NoncurrentVersionExpirationDays: 7},
+					},
+				},
+			},
+		},
+		versions: map[string][]api.ObjectVersion{
+			"versioned": {
+				// old current version, never expires through this rule
+				version("/current", "c1", 30, true),
+
+				// v2 replaced v1 8 days ago, v3 replaced v2 a day ago
+				version("/foo", "v3", 1, true),
+				version("/foo", "v2", 8, false),
+				version("/foo", "v1", 20, false),
+
+				// b2 replaced b1 10 days ago but b1 is deleted concurrently
+				version("/bar", "b2", 10, true),
+				version("/bar", "b1", 20, false),
+			},
+		},
+		stale: map[string]struct{}{"b1": {}},
+	}
+
+	ls := &LifecycleSweeper{broadcaster: &mockBroadcaster{}, store: store, logger: zap.NewNop().Sugar()}
+	expired, aborted, err := ls.Sweep(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	} else if expired != 1 || aborted != 0 {
+		t.Fatalf("unexpected sweep result, expired %d aborted %d", expired, aborted)
+	}
+
+	// assert only the version that has been noncurrent for longer than 7
+	// days was removed, the concurrently deleted version isn't counted
+	var remaining []string
+	for _, v := range store.versions["versioned"] {
+		remaining = append(remaining, v.VersionID)
+	}
+	if strings.Join(remaining, ",") != "c1,v3,v2,b2,b1" {
+		t.Fatal("unexpected versions", remaining)
+	}
+}
@@ -300,6 +300,42 @@ var (
 					return performMigration(ctx, tx, migrationsFs, dbIdentifier, "00020_remove_directories", log)
 				},
 			},
+			{
+				ID: "00021_object_versions",
+				Migrate: func(tx Tx) error {
+					return performMigration(ctx, tx, migrationsFs, dbIdentifier, "00021_object_versions", log)
+				},
+			},
+			{
+				ID: "00022_object_checksums",
+				Migrate: func(tx Tx) error {
+					return performMigration(ctx, tx, migrationsFs, dbIdentifier, "00022_object_checksums", log)
+				},
+			},
+			{
+				ID: "00023_object_tags",
+				Migrate: func(tx Tx) error {
+					return performMigration(ctx, tx, migrationsFs, dbIdentifier, "00023_object_tags", log)
+				},
+			},
+			{
+				ID: "00024_customer_key_fingerprints",
+				Migrate: func(tx Tx) error {
+					return performMigration(ctx, tx, migrationsFs, dbIdentifier, "00024_customer_key_fingerprints", log)
+				},
+			},
+			{
+				ID: "00025_object_lock",
+				Migrate: func(tx Tx) error {
+					return performMigration(ctx, tx, migrationsFs, dbIdentifier, "00025_object_lock", log)
+				},
+			},
+			{
+				ID: "00026_webhook_filters",
+				Migrate: func(tx Tx) error {
+					return performMigration(ctx, tx, migrationsFs, dbIdentifier, "00026_webhook_filters", log)
+				},
+			},
 		}
 	}
 	MetricsMigrations = func(ctx context.Context, migrationsFs embed.FS, log *zap.SugaredLogger) []Migration {
@@ -9,7 +9,9 @@ import (
 
 	"go.thebigfile.com/core/types"
 	"go.thebigfile.com/renterd/api"
+	"go.thebigfile.com/renterd/autopilot/contractor"
 	"go.thebigfile.com/renterd/internal/test"
+	"go.thebigfile.com/renterd/internal/utils"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -96,3 +98,60 @@ func TestFormContract(t *testing.T) {
 		return nil
 	})
 }
+
+func TestMaintenancePlan(t *testing.T) {
+	// configure the autopilot not to form any contracts
+	apSettings := test.AutopilotConfig
+	apSettings.Contracts.Amount = 0
+
+	// create cluster
+	opts := clusterOptsDefault
+	opts.autopilotSettings = &apSettings
+	cluster := newTestCluster(t, opts)
+	defer cluster.Shutdown()
+
+	// convenience variables
+	b := cluster.Bus
+	a := cluster.Autopilot
+	tt := cluster.tt
+
+	// add hosts
+	cluster.AddHosts(2)
+
+	// assert maintenance can't be planned for the current config
+	_, err := a.MaintenancePlan(context.Background(), nil)
+	if !utils.IsErr(err, contractor.ErrMaintenanceSkipped) {
+		t.Fatalf("expected ErrMaintenanceSkipped, got %v", err)
+	}
+
+	// plan maintenance for a config that wants 2 contracts
+	cfg := test.AutopilotConfig
+	cfg.Contracts.Amount = 2
+	tt.Retry(100, 100*time.Millisecond, func() error {
+		plan, err := a.MaintenancePlan(context.Background(), &cfg)
+		if err != nil {
+			return err
+		} else if len(plan.Sets) != 1 {
+			return fmt.Errorf("unexpected number of sets %d != 1", len(plan.Sets))
+		} else if len(plan.Sets[0].Formations) != 2 {
+			return fmt.Errorf("unexpected number of formations %d != 2", len(plan.Sets[0].Formations))
+		} else if plan.ProjectedSpending.IsZero() {
+			return errors.New("expected projected spending to be non-zero")
+		}
+		return nil
+	})
+
+	// assert no contracts were formed
+	contracts, err := b.Contracts(context.Background(), api.ContractsOpts{})
+	tt.OK(err)
+	if len(contracts) != 0 {
+		t.Fatalf("expected no contracts, got %d", len(contracts))
+	}
+
+	// assert the config wasn't updated
+	ap, err := b.Autopilot(context.Background(), api.DefaultAutopilotID)
+	tt.OK(err)
+	if ap.Config.Contracts.Amount != 0 {
+		t.Fatalf("expected config to be unchanged, got amount %d", ap.Config.Contracts.Amount)
+	}
+}
@@ -46,6 +46,9 @@ func Auth(password string, unauthenticatedDownloads bool) func(http.Handler) htt
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			if unauthenticatedDownloads && req.Method == http.MethodGet && strings.HasPrefix(req.URL.Path, "/objects/") {
 				h.ServeHTTP(w, req)
+			} else if (req.Method == http.MethodGet || req.Method == http.MethodHead) && strings.HasPrefix(req.URL.Path, "/website/") {
+				// websites are only served for publicly readable buckets
+				h.ServeHTTP(w, req)
 			} else {
 				jape.BasicAuth(password)(h).ServeHTTP(w, req)
 			}
@@ -13,10 +13,12 @@ import (
 	"go.uber.org/zap"
 
 	"go.thebigfile.com/renterd/api"
+	"go.thebigfile.com/renterd/internal/utils"
 	"go.thebigfile.com/renterd/webhooks"
 )
 
 const (
+	cacheKeyBandwidthSettings = "bandwidthsettings"
 	cacheKeyDownloadContracts = "downloadcontracts"
 	cacheKeyGougingParams     = "gougingparams"
 
@@ -73,11 +75,13 @@ func (c *memoryCache) Invalidate(key string) {
 
 type (
 	Bus interface {
+		BandwidthSettings(ctx context.Context) (api.BandwidthSettings, error)
 		Contracts(ctx context.Context, opts api.ContractsOpts) ([]api.ContractMetadata, error)
 		GougingParams(ctx context.Context) (api.GougingParams, error)
 	}
 
 	WorkerCache interface {
+		BandwidthSettings(ctx context.Context) (api.BandwidthSettings, error)
 		DownloadContracts(ctx context.Context) ([]api.ContractMetadata, error)
 		GougingParams(ctx context.Context) (api.GougingParams, error)
 		HandleEvent(event webhooks.Event) error
@@ -105,6 +109,27 @@ func NewCache(b Bus, logger *zap.Logger) WorkerCache {
 	}
 }
 
+func (c *cache) BandwidthSettings(ctx context.Context) (bs api.BandwidthSettings, err error) {
+	// fetch directly from bus if the cache is not ready
+	if !c.isReady() {
+		c.logger.Warn(errCacheNotReady)
+		bs, err = c.fetchBandwidthSettings(ctx)
+		return
+	}
+
+	// fetch from bus if it's not cached or expired
+	value, found, expired := c.cache.Get(cacheKeyBandwidthSettings)
+	if !found || expired {
+		bs, err = c.fetchBandwidthSettings(ctx)
+		if err == nil {
+			c.cache.Set(cacheKeyBandwidthSettings, bs)
+		}
+		return
+	}
+
+	return value.(api.BandwidthSettings), nil
+}
+
 func (c *cache) DownloadContracts(ctx context.Context) (contracts []api.ContractMetadata, err error) {
 	// fetch directly from bus if the cache is not ready
 	if !c.isReady() {
@@ -313,10 +338,17 @@ func (c *cache) handleHostUpdate(e api.EventHostUpdate) {
 func (c *cache) handleSettingDelete(e api.EventSettingDelete) {
 	if e.Key == api.SettingGouging || e.Key == api.SettingRedundancy {
 		c.cache.Invalidate(cacheKeyGougingParams)
+	} else if e.Key == api.SettingBandwidth {
+		c.cache.Invalidate(cacheKeyBandwidthSettings)
 	}
 }
 
 func (c *cache) handleSettingUpdate(e api.EventSettingUpdate) (err error) {
+	// the bandwidth settings are replaced as a whole
+	if e.Key == api.SettingBandwidth {
+		return c.handleBandwidthSettingsUpdate(e)
+	}
+
 	// return early if the cache doesn't have gouging params to update
 	value, found, _ := c.cache.Get(cacheKeyGougingParams)
 	if !found {
@@ -358,6 +390,32 @@ func (c *cache) handleSettingUpdate(e api.EventSettingUpdate) (err error) {
 	return nil
 }
 
+// fetchBandwidthSettings fetches the bandwidth settings from the bus, if the
+// setting was deleted the bandwidth is not limited.
+func (c *cache) fetchBandwidthSettings(ctx context.Context) (api.BandwidthSettings, error) {
+	bs, err := c.b.BandwidthSettings(ctx)
+	if utils.IsErr(err, api.ErrSettingNotFound) {
+		return api.BandwidthSettings{}, nil
+	}
+	return bs, err
+}
+
+func (c *cache) handleBandwidthSettingsUpdate(e api.EventSettingUpdate) error {
+	data, err := json.Marshal(e.Update)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal the given value, error: %v", err)
+	}
+
+	var bs api.BandwidthSettings
+	if err := json.Unmarshal(data, &bs); err != nil {
+		return fmt.Errorf("couldn't update bandwidth settings, invalid request body, %t", e.Update)
+	} else if err := bs.Validate(); err != nil {
+		return fmt.Errorf("couldn't update bandwidth settings, error: %v", err)
+	}
+	c.cache.Set(cacheKeyBandwidthSettings, bs)
+	return nil
+}
+
 func contractsEqual(x, y []api.ContractMetadata) bool {
 	if len(x) != len(y) {
 		return false
@@ -16,8 +16,13 @@ import (
 )
 
 type mockBus struct {
-	contracts     []api.ContractMetadata
-	gougingParams api.GougingParams
+	bandwidthSettings api.BandwidthSettings
+	contracts         []api.ContractMetadata
+	gougingParams     api.GougingParams
+}
+
+func (m *mockBus) BandwidthSettings(ctx context.Context) (api.BandwidthSettings, error) {
+	return m.bandwidthSettings, nil
 }
 
 func (m *mockBus) Contracts(ctx context.Context, opts api.ContractsOpts) ([]api.ContractMetadata, error) {
@@ -42,6 +42,7 @@ type (
 		webhooks WebhookManager
 		logger   *zap.SugaredLogger
 
+		objectEvents     bool
 		registerInterval time.Duration
 
 		mu             sync.Mutex
@@ -51,7 +52,10 @@ type (
 	}
 )
 
-func NewEventSubscriber(a alerts.Alerter, w WebhookManager, l *zap.Logger, registerInterval time.Duration) EventSubscriber {
+// NewEventSubscriber returns a new event subscriber, the webhooks for object
+// events are only registered if objectEvents is set since they are only of
+// interest to the slab cache.
+func NewEventSubscriber(a alerts.Alerter, w WebhookManager, l *zap.Logger, registerInterval time.Duration, objectEvents bool) EventSubscriber {
 	return &eventSubscriber{
 		alerts:   a,
 		webhooks: w,
@@ -60,6 +64,7 @@ func NewEventSubscriber(a alerts.Alerter, w WebhookManager, l *zap.Logger, regis
 		registeredChan: make(chan struct{}),
 
 		handlers:         make(map[string]EventHandler),
+		objectEvents:     objectEvents,
 		registerInterval: registerInterval,
 	}
 }
@@ -118,6 +123,12 @@ func (e *eventSubscriber) Register(ctx context.Context, eventsURL string, opts .
 		api.WebhookHostUpdate(eventsURL, headers),
 		api.WebhookSettingUpdate(eventsURL, headers),
 	}
+	if e.objectEvents {
+		webhooks = append(webhooks,
+			api.WebhookObjectCreated(eventsURL, headers),
+			api.WebhookObjectDeleted(eventsURL, headers),
+		)
+	}
 
 	// try and register the webhooks in a loop
 	for {
@@ -109,7 +109,7 @@ func TestEventSubscriber(t *testing.T) {
 	h := &mockEventHandler{id: t.Name()}
 
 	// create event subscriber
-	s := NewEventSubscriber(a, w, zap.New(observedZapCore), testRegisterInterval)
+	s := NewEventSubscriber(a, w, zap.New(observedZapCore), testRegisterInterval, true)
 
 	// subscribe the event handler
 	if err := h.Subscribe(s); err != nil {
@@ -156,8 +156,8 @@ func TestEventSubscriber(t *testing.T) {
 	time.Sleep(testRegisterInterval)
 
 	// assert webhook was registered
-	if webhooks := w.Webhooks(); len(webhooks) != 6 {
-		t.Fatal("expected 6 webhooks, got", len(webhooks))
+	if webhooks := w.Webhooks(); len(webhooks) != 8 {
+		t.Fatal("expected 8 webhooks, got", len(webhooks))
 	}
 
 	// send the same event again
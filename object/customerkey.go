@@ -0,0 +1,52 @@
+package object
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"go.thebigfile.com/core/types"
+)
+
+// customerKeyFingerprintSpecifier is prepended to a customer key before it is
+// hashed to compute its fingerprint, this makes sure the fingerprint can't be
+// confused with a key that is derived from the customer key.
+var customerKeyFingerprintSpecifier = types.NewSpecifier("customerkeyfp")
+
+// A CustomerKey is an encryption key that is provided by the client with every
+// request for an object, renterd only stores a fingerprint of the key.
+type CustomerKey [32]byte
+
+// ParseCustomerKey parses a base64 encoded customer key.
+func ParseCustomerKey(s string) (ck CustomerKey, err error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return CustomerKey{}, fmt.Errorf("failed to decode customer key: %w", err)
+	} else if len(b) != len(ck) {
+		return CustomerKey{}, fmt.Errorf("wrong customer key length: expected %v, got %v", len(ck), len(b))
+	}
+	copy(ck[:], b)
+	return ck, nil
+}
+
+// Fingerprint returns a hex encoded hash of the key that can be stored
+// alongside the object to check whether a key matches the one the object was
+// uploaded with.
+func (ck CustomerKey) Fingerprint() string {
+	h := types.HashBytes(append(customerKeyFingerprintSpecifier[:], ck[:]...))
+	return hex.EncodeToString(h[:])
+}
+
+// String returns the base64 encoded key.
+func (ck CustomerKey) String() string {
+	return base64.StdEncoding.EncodeToString(ck[:])
+}
+
+// WithCustomerKey returns the key that encrypts the data of an object that was
+// uploaded with a customer key. The object's key is mixed with the customer
+// key, so neither the stored key nor the customer key suffice to decrypt the
+// object, and objects that share a customer key don't share a keystream.
+func (k EncryptionKey) WithCustomerKey(ck CustomerKey) EncryptionKey {
+	entropy := [32]byte(types.HashBytes(append(append([]byte{}, k.entropy[:]...), ck[:]...)))
+	return EncryptionKey{entropy: &entropy}
+}
@@ -0,0 +1,67 @@
+package object
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"lukechampine.com/frand"
+)
+
+func TestCustomerKey(t *testing.T) {
+	var ck CustomerKey
+	frand.Read(ck[:])
+
+	// assert the key survives an encoding roundtrip
+	if parsed, err := ParseCustomerKey(ck.String()); err != nil {
+		t.Fatal(err)
+	} else if parsed != ck {
+		t.Fatal("key mismatch")
+	} else if _, err := ParseCustomerKey("Zm9v"); err == nil {
+		t.Fatal("expected error for short key")
+	}
+
+	// assert the fingerprint is deterministic and unique
+	var other CustomerKey
+	frand.Read(other[:])
+	if ck.Fingerprint() != ck.Fingerprint() {
+		t.Fatal("fingerprint should be deterministic")
+	} else if ck.Fingerprint() == other.Fingerprint() {
+		t.Fatal("fingerprints should differ")
+	}
+
+	// assert the derived key differs from both the object key and the
+	// customer key and is different for every object
+	key1, key2 := GenerateEncryptionKey(), GenerateEncryptionKey()
+	derived := key1.WithCustomerKey(ck)
+	if derived.String() == key1.String() || bytes.Equal(derived.entropy[:], ck[:]) {
+		t.Fatal("derived key should differ")
+	} else if derived.String() != key1.WithCustomerKey(ck).String() {
+		t.Fatal("derived key should be deterministic")
+	} else if derived.String() == key2.WithCustomerKey(ck).String() {
+		t.Fatal("derived keys of different objects should differ")
+	}
+
+	// assert data encrypted with the derived key can only be decrypted with it
+	data := frand.Bytes(128)
+	sr, err := derived.Encrypt(bytes.NewReader(data), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := io.ReadAll(sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypt := func(key EncryptionKey) []byte {
+		var buf bytes.Buffer
+		if _, err := key.Decrypt(&buf, 0).Write(ct); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	if !bytes.Equal(decrypt(key1.WithCustomerKey(ck)), data) {
+		t.Fatal("failed to decrypt with derived key")
+	} else if bytes.Equal(decrypt(key1), data) {
+		t.Fatal("object key shouldn't decrypt the data")
+	}
+}
@@ -222,6 +222,26 @@ func (ss SlabSlices) Contracts() []types.FileContractID {
 	return usedContracts
 }
 
+// Range returns the slices that reference the given range of the data that is
+// referenced by ss. The range must not exceed the total length of ss.
+func (ss SlabSlices) Range(offset, length uint64) SlabSlices {
+	var slices SlabSlices
+	for _, s := range ss {
+		if length == 0 {
+			break
+		} else if offset >= uint64(s.Length) {
+			offset -= uint64(s.Length)
+			continue
+		}
+		n := min(uint64(s.Length)-offset, length)
+		s.Offset += uint32(offset)
+		s.Length = uint32(n)
+		slices = append(slices, s)
+		offset, length = 0, length-n
+	}
+	return slices
+}
+
 // stripedSplit splits data into striped data shards, which must have sufficient
 // capacity.
 func stripedSplit(data []byte, dataShards [][]byte) {
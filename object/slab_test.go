@@ -70,6 +70,37 @@ func TestReedSolomon(t *testing.T) {
 	}
 }
 
+func TestSlabSlicesRange(t *testing.T) {
+	ss := SlabSlices{
+		{Offset: 0, Length: 10},
+		{Offset: 100, Length: 20},
+		{Offset: 5, Length: 5},
+	}
+
+	for _, test := range []struct {
+		offset, length uint64
+		want           SlabSlices
+	}{
+		{0, 35, ss},
+		{0, 10, SlabSlices{{Offset: 0, Length: 10}}},
+		{5, 10, SlabSlices{{Offset: 5, Length: 5}, {Offset: 100, Length: 5}}},
+		{10, 20, SlabSlices{{Offset: 100, Length: 20}}},
+		{15, 20, SlabSlices{{Offset: 105, Length: 15}, {Offset: 5, Length: 5}}},
+		{32, 3, SlabSlices{{Offset: 7, Length: 3}}},
+		{35, 0, nil},
+	} {
+		got := ss.Range(test.offset, test.length)
+		if len(got) != len(test.want) {
+			t.Fatalf("range %v-%v: expected %v slices, got %v", test.offset, test.length, len(test.want), len(got))
+		}
+		for i := range got {
+			if got[i].Offset != test.want[i].Offset || got[i].Length != test.want[i].Length {
+				t.Fatalf("range %v-%v: unexpected slice %v: %v-%v", test.offset, test.length, i, got[i].Offset, got[i].Length)
+			}
+		}
+	}
+}
+
 func BenchmarkReedSolomon(b *testing.B) {
 	makeSlab := func(m, n uint8) (Slab, []byte, [][]byte) {
 		return Slab{Key: GenerateEncryptionKey(), MinShards: m, Shards: make([]Sector, n)},
@@ -313,9 +313,9 @@ func (s *SQLStore) RenewedContract(ctx context.Context, renewedFrom types.FileCo
 	return
 }
 
-func (s *SQLStore) SearchObjects(ctx context.Context, bucket, substring string, offset, limit int) (objects []api.ObjectMetadata, err error) {
+func (s *SQLStore) SearchObjects(ctx context.Context, bucket, substring string, tags api.ObjectTags, offset, limit int) (objects []api.ObjectMetadata, err error) {
 	err = s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
-		objects, err = tx.SearchObjects(ctx, bucket, substring, offset, limit)
+		objects, err = tx.SearchObjects(ctx, bucket, substring, tags, offset, limit)
 		return err
 	})
 	return
@@ -337,6 +337,22 @@ func (s *SQLStore) Object(ctx context.Context, bucket, path string) (obj api.Obj
 	return
 }
 
+func (s *SQLStore) ObjectVersion(ctx context.Context, bucket, path, versionID string) (obj api.Object, err error) {
+	err = s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
+		obj, err = tx.ObjectVersion(ctx, bucket, path, versionID)
+		return err
+	})
+	return
+}
+
+func (s *SQLStore) ObjectVersions(ctx context.Context, bucket, prefix, keyMarker, versionIDMarker string, limit int) (resp api.ObjectVersionsResponse, err error) {
+	err = s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
+		resp, err = tx.ObjectVersions(ctx, bucket, prefix, keyMarker, versionIDMarker, limit)
+		return err
+	})
+	return
+}
+
 func (s *SQLStore) RecordContractSpending(ctx context.Context, records []api.ContractSpendingRecord) error {
 	if len(records) == 0 {
 		return nil // nothing to do
@@ -420,9 +436,27 @@ func (s *SQLStore) RecordContractSpending(ctx context.Context, records []api.Con
 
 func (s *SQLStore) RenameObject(ctx context.Context, bucket, keyOld, keyNew string, force bool) error {
 	return s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
+		versioned, err := bucketVersioned(ctx, tx, bucket)
+		if err != nil {
+			return err
+		}
+
+		// if versioning is enabled, the object that is overwritten is archived
+		if versioned && force && keyOld != keyNew {
+			if _, err := tx.ArchiveObject(ctx, bucket, keyNew); err != nil {
+				return fmt.Errorf("RenameObject: failed to archive object: %w", err)
+			}
+		}
 		if err := tx.RenameObject(ctx, bucket, keyOld, keyNew, force); err != nil {
 			return err
 		}
+
+		// the old key is marked as deleted
+		if versioned && keyOld != keyNew {
+			if _, _, err := tx.InsertDeleteMarker(ctx, bucket, keyOld); err != nil {
+				return fmt.Errorf("RenameObject: failed to insert delete marker: %w", err)
+			}
+		}
 		s.triggerSlabPruning()
 		return nil
 	})
@@ -430,9 +464,43 @@ func (s *SQLStore) RenameObject(ctx context.Context, bucket, keyOld, keyNew stri
 
 func (s *SQLStore) RenameObjects(ctx context.Context, bucket, prefixOld, prefixNew string, force bool) error {
 	return s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
+		versioned, err := bucketVersioned(ctx, tx, bucket)
+		if err != nil {
+			return err
+		}
+
+		// if versioning is enabled, the objects that are overwritten are
+		// archived
+		var keysOld []string
+		keysNew := make(map[string]struct{})
+		if versioned && prefixOld != prefixNew {
+			keysOld, err = tx.ObjectKeys(ctx, bucket, prefixOld, -1)
+			if err != nil {
+				return fmt.Errorf("RenameObjects: failed to fetch object keys: %w", err)
+			}
+			for _, key := range keysOld {
+				keyNew := prefixNew + strings.TrimPrefix(key, prefixOld)
+				keysNew[keyNew] = struct{}{}
+				if !force {
+					continue
+				} else if _, err := tx.ArchiveObject(ctx, bucket, keyNew); err != nil {
+					return fmt.Errorf("RenameObjects: failed to archive object: %w", err)
+				}
+			}
+		}
 		if err := tx.RenameObjects(ctx, bucket, prefixOld, prefixNew, force); err != nil {
 			return err
 		}
+
+		// the old keys that weren't replaced by a renamed object are marked
+		// as deleted
+		for _, key := range keysOld {
+			if _, replaced := keysNew[key]; replaced {
+				continue
+			} else if _, _, err := tx.InsertDeleteMarker(ctx, bucket, key); err != nil {
+				return fmt.Errorf("RenameObjects: failed to insert delete marker: %w", err)
+			}
+		}
 		s.triggerSlabPruning()
 		return nil
 	})
@@ -449,6 +517,10 @@ func (s *SQLStore) AddPartialSlab(ctx context.Context, data []byte, minShards, t
 func (s *SQLStore) CopyObject(ctx context.Context, srcBucket, dstBucket, srcPath, dstPath, mimeType string, metadata api.ObjectUserMetadata) (om api.ObjectMetadata, err error) {
 	err = s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
 		if srcBucket != dstBucket || srcPath != dstPath {
+			_, err = tx.ArchiveObject(ctx, dstBucket, dstPath)
+			if err != nil {
+				return fmt.Errorf("CopyObject: failed to archive object: %w", err)
+			}
 			_, err = tx.DeleteObject(ctx, dstBucket, dstPath)
 			if err != nil {
 				return fmt.Errorf("CopyObject: failed to delete object: %w", err)
@@ -468,7 +540,7 @@ func (s *SQLStore) DeleteHostSector(ctx context.Context, hk types.PublicKey, roo
 	return
 }
 
-func (s *SQLStore) UpdateObject(ctx context.Context, bucket, path, contractSet, eTag, mimeType string, metadata api.ObjectUserMetadata, o object.Object) error {
+func (s *SQLStore) UpdateObject(ctx context.Context, bucket, path, contractSet string, o object.Object, opts api.AddObjectOptions) error {
 	// Sanity check input.
 	for _, s := range o.Slabs {
 		for i, shard := range s.Shards {
@@ -482,6 +554,18 @@ func (s *SQLStore) UpdateObject(ctx context.Context, bucket, path, contractSet,
 	// UpdateObject is ACID.
 	var prune bool
 	err := s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
+		// Evaluate the preconditions against the current object, the object
+		// is locked to ensure it isn't overwritten before we do.
+		if opts.Conditions.HasWriteConditions() {
+			currentETag, err := tx.ObjectETag(ctx, bucket, path)
+			exists := err == nil
+			if err != nil && !errors.Is(err, api.ErrObjectNotFound) {
+				return fmt.Errorf("UpdateObject: failed to fetch object etag: %w", err)
+			} else if err := opts.Conditions.CheckWrite(currentETag, exists); err != nil {
+				return err
+			}
+		}
+
 		// Try to delete. We want to get rid of the object and its slices if it
 		// exists.
 		//
@@ -492,14 +576,21 @@ func (s *SQLStore) UpdateObject(ctx context.Context, bucket, path, contractSet,
 		// NOTE: the metadata is not deleted because this delete will cascade,
 		// if we stop recreating the object we have to make sure to delete the
 		// object's metadata before trying to recreate it
-		var err error
+		//
+		// NOTE: if versioning is enabled, the object is archived first, which
+		// moves its slices and metadata to the archived version
+		archived, err := tx.ArchiveObject(ctx, bucket, path)
+		if err != nil {
+			return fmt.Errorf("UpdateObject: failed to archive object: %w", err)
+		}
 		prune, err = tx.DeleteObject(ctx, bucket, path)
 		if err != nil {
 			return fmt.Errorf("UpdateObject: failed to delete object: %w", err)
 		}
+		prune = prune || archived
 
 		// Insert a new object.
-		err = tx.InsertObject(ctx, bucket, path, contractSet, o, mimeType, eTag, metadata)
+		err = tx.InsertObject(ctx, bucket, path, contractSet, o, opts)
 		if err != nil {
 			return fmt.Errorf("failed to insert object: %w", err)
 		}
@@ -514,16 +605,43 @@ func (s *SQLStore) UpdateObject(ctx context.Context, bucket, path, contractSet,
 	return nil
 }
 
-func (s *SQLStore) RemoveObject(ctx context.Context, bucket, path string) error {
+func (s *SQLStore) RemoveObject(ctx context.Context, bucket, path string) (resp api.DeleteObjectResponse, _ error) {
 	var prune bool
 	err := s.db.Transaction(ctx, func(tx sql.DatabaseTx) (err error) {
+		// if versioning is enabled, the object is archived and a delete marker
+		// is inserted in its place
+		archived, err := tx.ArchiveObject(ctx, bucket, path)
+		if err != nil {
+			return err
+		}
 		prune, err = tx.DeleteObject(ctx, bucket, path)
+		if err != nil {
+			return err
+		}
+		prune = prune || archived
+		resp.VersionID, resp.DeleteMarker, err = tx.InsertDeleteMarker(ctx, bucket, path)
 		return
 	})
 	if err != nil {
-		return fmt.Errorf("RemoveObject: failed to delete object: %w", err)
-	} else if !prune {
-		return fmt.Errorf("%w: key: %s", api.ErrObjectNotFound, path)
+		return api.DeleteObjectResponse{}, fmt.Errorf("RemoveObject: failed to delete object: %w", err)
+	} else if !prune && !resp.DeleteMarker {
+		return api.DeleteObjectResponse{}, fmt.Errorf("%w: key: %s", api.ErrObjectNotFound, path)
+	} else if prune {
+		s.triggerSlabPruning()
+	}
+	return resp, nil
+}
+
+func (s *SQLStore) RemoveObjectVersion(ctx context.Context, bucket, path, versionID string) error {
+	var deleted bool
+	err := s.db.Transaction(ctx, func(tx sql.DatabaseTx) (err error) {
+		deleted, err = tx.DeleteObjectVersion(ctx, bucket, path, versionID)
+		return
+	})
+	if err != nil {
+		return fmt.Errorf("RemoveObjectVersion: failed to delete object version: %w", err)
+	} else if !deleted {
+		return fmt.Errorf("%w: key: %s, version: %s", api.ErrObjectVersionNotFound, path, versionID)
 	}
 	s.triggerSlabPruning()
 	return nil
@@ -537,7 +655,17 @@ func (s *SQLStore) RemoveObjects(ctx context.Context, bucket, prefix string) err
 		var done bool
 		var duration time.Duration
 		if err := s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
-			deleted, err := tx.DeleteObjects(ctx, bucket, prefix, objectDeleteBatchSizes[batchSizeIdx])
+			versioned, err := bucketVersioned(ctx, tx, bucket)
+			if err != nil {
+				return err
+			}
+
+			var deleted bool
+			if versioned {
+				deleted, err = archiveObjects(ctx, tx, bucket, prefix, objectDeleteBatchSizes[batchSizeIdx])
+			} else {
+				deleted, err = tx.DeleteObjects(ctx, bucket, prefix, objectDeleteBatchSizes[batchSizeIdx])
+			}
 			if err != nil {
 				return err
 			}
@@ -563,6 +691,37 @@ func (s *SQLStore) RemoveObjects(ctx context.Context, bucket, prefix string) err
 	return nil
 }
 
+// archiveObjects archives a batch of objects starting with the given prefix
+// and inserts delete markers in their place. It returns 'true' if any object
+// was archived.
+func archiveObjects(ctx context.Context, tx sql.DatabaseTx, bucket, prefix string, limit int64) (bool, error) {
+	keys, err := tx.ObjectKeys(ctx, bucket, prefix, limit)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range keys {
+		if _, err := tx.ArchiveObject(ctx, bucket, key); err != nil {
+			return false, fmt.Errorf("failed to archive object: %w", err)
+		} else if _, err := tx.DeleteObject(ctx, bucket, key); err != nil {
+			return false, fmt.Errorf("failed to delete object: %w", err)
+		} else if _, _, err := tx.InsertDeleteMarker(ctx, bucket, key); err != nil {
+			return false, fmt.Errorf("failed to insert delete marker: %w", err)
+		}
+	}
+	return len(keys) > 0, nil
+}
+
+// bucketVersioned returns true if versioning was ever enabled for the bucket.
+func bucketVersioned(ctx context.Context, tx sql.DatabaseTx, bucket string) (bool, error) {
+	b, err := tx.Bucket(ctx, bucket)
+	if errors.Is(err, api.ErrBucketNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to fetch bucket: %w", err)
+	}
+	return b.Policy.Versioning != "", nil
+}
+
 func (s *SQLStore) Slab(ctx context.Context, key object.EncryptionKey) (slab object.Slab, err error) {
 	err = s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
 		slab, err = tx.Slab(ctx, key)
@@ -638,6 +797,40 @@ func (s *SQLStore) ObjectMetadata(ctx context.Context, bucket, path string) (obj
 	return
 }
 
+func (s *SQLStore) ObjectLock(ctx context.Context, bucket, path string) (lock api.ObjectLock, err error) {
+	err = s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
+		lock, err = tx.ObjectLock(ctx, bucket, path)
+		return err
+	})
+	return
+}
+
+func (s *SQLStore) UpdateObjectLegalHold(ctx context.Context, bucket, path string, enabled bool) error {
+	return s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
+		return tx.UpdateObjectLegalHold(ctx, bucket, path, enabled)
+	})
+}
+
+func (s *SQLStore) UpdateObjectRetention(ctx context.Context, bucket, path string, retention api.ObjectRetention, bypassGovernance bool) error {
+	return s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
+		return tx.UpdateObjectRetention(ctx, bucket, path, retention, bypassGovernance)
+	})
+}
+
+func (s *SQLStore) ObjectTags(ctx context.Context, bucket, path string) (tags api.ObjectTags, err error) {
+	err = s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
+		tags, err = tx.ObjectTags(ctx, bucket, path)
+		return err
+	})
+	return
+}
+
+func (s *SQLStore) UpdateObjectTags(ctx context.Context, bucket, path string, tags api.ObjectTags) error {
+	return s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
+		return tx.UpdateObjectTags(ctx, bucket, path, tags)
+	})
+}
+
 // PackedSlabsForUpload returns up to 'limit' packed slabs that are ready for
 // uploading. They are locked for 'lockingDuration' time before being handed out
 // again.
@@ -768,9 +961,9 @@ func (s *SQLStore) invalidateSlabHealthByFCID(ctx context.Context, fcids []types
 // TODO: we can use ObjectEntries instead of ListObject if we want to use '/' as
 // a delimiter for now (see backend.go) but it would be interesting to have
 // arbitrary 'delim' support in ListObjects.
-func (s *SQLStore) ListObjects(ctx context.Context, bucket, prefix, sortBy, sortDir, marker string, limit int) (resp api.ObjectsListResponse, err error) {
+func (s *SQLStore) ListObjects(ctx context.Context, bucket, prefix, sortBy, sortDir, marker string, tags api.ObjectTags, limit int) (resp api.ObjectsListResponse, err error) {
 	err = s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
-		resp, err = tx.ListObjects(ctx, bucket, prefix, sortBy, sortDir, marker, limit)
+		resp, err = tx.ListObjects(ctx, bucket, prefix, sortBy, sortDir, marker, tags, limit)
 		return err
 	})
 	return
@@ -11,6 +11,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -36,7 +37,7 @@ func (s *testSQLStore) InsertSlab(slab object.Slab) {
 			},
 		},
 	}
-	err := s.UpdateObject(context.Background(), api.DefaultBucketName, "/"+hex.EncodeToString(frand.Bytes(16)), testContractSet, "", "", api.ObjectUserMetadata{}, obj)
+	err := s.UpdateObject(context.Background(), api.DefaultBucketName, "/"+hex.EncodeToString(frand.Bytes(16)), testContractSet, obj, api.AddObjectOptions{})
 	if err != nil {
 		s.t.Fatal(err)
 	}
@@ -45,7 +46,7 @@ func (s *testSQLStore) InsertSlab(slab object.Slab) {
 func (s *SQLStore) RemoveObjectBlocking(ctx context.Context, bucket, path string) error {
 	ts := time.Now()
 	time.Sleep(time.Millisecond)
-	if err := s.RemoveObject(ctx, bucket, path); err != nil {
+	if _, err := s.RemoveObject(ctx, bucket, path); err != nil {
 		return err
 	}
 	return s.waitForPruneLoop(ts)
@@ -85,7 +86,7 @@ func (s *SQLStore) UpdateObjectBlocking(ctx context.Context, bucket, path, contr
 		ts = time.Now()
 		time.Sleep(time.Millisecond)
 	}
-	if err := s.UpdateObject(ctx, bucket, path, contractSet, eTag, mimeType, metadata, o); err != nil {
+	if err := s.UpdateObject(ctx, bucket, path, contractSet, o, api.AddObjectOptions{ETag: eTag, MimeType: mimeType, Metadata: metadata}); err != nil {
 		return err
 	}
 	return s.waitForPruneLoop(ts)
@@ -202,6 +203,82 @@ func TestPrunableContractRoots(t *testing.T) {
 	}
 }
 
+func TestPrunableContractRootsLockedObjects(t *testing.T) {
+	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
+	defer ss.Close()
+
+	// add a contract
+	hks, err := ss.addTestHosts(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcids, _, err := ss.addTestContracts(hks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newObject := func(root byte) object.Object {
+		return object.Object{
+			Key: object.GenerateEncryptionKey(),
+			Slabs: []object.SlabSlice{{
+				Slab: object.Slab{
+					Key:       object.GenerateEncryptionKey(),
+					MinShards: 1,
+					Shards:    newTestShards(hks[0], fcids[0], types.Hash256{root}),
+				},
+			}},
+		}
+	}
+
+	// add an object under a legal hold, one under retention and an unlocked
+	// one, all of them are stored with their lock
+	ctx := context.Background()
+	retention := api.ObjectRetention{Mode: api.ObjectLockModeCompliance, RetainUntil: api.TimeRFC3339(time.Now().Add(time.Hour))}
+	for i, lock := range []api.ObjectLock{{LegalHold: true}, {Retention: retention}, {}} {
+		if err := ss.UpdateObject(ctx, api.DefaultBucketName, fmt.Sprintf("/%d", i+1), testContractSet, newObject(byte(i+1)), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Lock: lock}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// overwrite an object under a legal hold in a versioned bucket, the
+	// locked version is archived
+	if err := ss.CreateBucket(ctx, "versioned", api.BucketPolicy{Versioning: api.BucketVersioningEnabled}); err != nil {
+		t.Fatal(err)
+	} else if err := ss.UpdateObject(ctx, "versioned", "/4", testContractSet, newObject(4), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Lock: api.ObjectLock{LegalHold: true}}); err != nil {
+		t.Fatal(err)
+	} else if err := ss.UpdateObject(ctx, "versioned", "/4", testContractSet, newObject(5), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType}); err != nil {
+		t.Fatal(err)
+	}
+	roots, err := ss.ContractRoots(ctx, fcids[0])
+	if err != nil {
+		t.Fatal(err)
+	} else if len(roots) != 5 {
+		t.Fatal("unexpected number of roots", len(roots))
+	}
+
+	// try to delete all objects, only the unlocked object can be deleted
+	if err := ss.RemoveObjectsBlocking(ctx, api.DefaultBucketName, "/"); !errors.Is(err, api.ErrObjectLocked) {
+		t.Fatal("expected ErrObjectLocked", err)
+	} else if err := ss.RemoveObjectBlocking(ctx, api.DefaultBucketName, "/3"); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the sectors of the unlocked objects are prunable
+	prunable := make(map[types.Hash256]struct{})
+	indices, err := ss.PrunableContractRoots(ctx, fcids[0], roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, idx := range indices {
+		prunable[roots[idx]] = struct{}{}
+	}
+	for root := byte(1); root <= 5; root++ {
+		_, ok := prunable[types.Hash256{root}]
+		if wantPrunable := root == 3; ok != wantPrunable {
+			t.Fatalf("root %d: expected prunable %v, got %v", root, wantPrunable, ok)
+		}
+	}
+}
+
 // TestObjectBasic tests the hydration of raw objects works when we fetch
 // objects from the metadata store.
 func TestObjectBasic(t *testing.T) {
@@ -1465,7 +1542,7 @@ func TestObjectHealth(t *testing.T) {
 	}
 
 	// assert health is returned correctly by SearchObject
-	entries, err = ss.SearchObjects(context.Background(), api.DefaultBucketName, "foo", 0, -1)
+	entries, err = ss.SearchObjects(context.Background(), api.DefaultBucketName, "foo", nil, 0, -1)
 	if err != nil {
 		t.Fatal(err)
 	} else if len(entries) != 1 {
@@ -1770,13 +1847,13 @@ func TestSearchObjects(t *testing.T) {
 		{"uu", []api.ObjectMetadata{{Name: "/foo/baz/quux", Size: 3, Health: 1}, {Name: "/foo/baz/quuz", Size: 4, Health: 1}, {Name: "/gab/guub", Size: 5, Health: 1}}},
 	}
 	for _, test := range tests {
-		got, err := ss.SearchObjects(ctx, api.DefaultBucketName, test.path, 0, -1)
+		got, err := ss.SearchObjects(ctx, api.DefaultBucketName, test.path, nil, 0, -1)
 		if err != nil {
 			t.Fatal(err)
 		}
 		assertEqual(got, test.want)
 		for offset := 0; offset < len(test.want); offset++ {
-			if got, err := ss.SearchObjects(ctx, api.DefaultBucketName, test.path, offset, 1); err != nil {
+			if got, err := ss.SearchObjects(ctx, api.DefaultBucketName, test.path, nil, offset, 1); err != nil {
 				t.Fatal(err)
 			} else if len(got) != 1 {
 				t.Errorf("\nkey: %v unexpected number of objects, %d != 1", test.path, len(got))
@@ -2167,6 +2244,85 @@ func TestUnhealthySlabsNoRedundancy(t *testing.T) {
 	}
 }
 
+// TestUnhealthySlabsBucketRedundancy asserts the health of a slab is computed
+// against the redundancy it was uploaded with, which differs from the default
+// redundancy for buckets that override it.
+func TestUnhealthySlabsBucketRedundancy(t *testing.T) {
+	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
+	defer ss.Close()
+
+	// add 4 hosts with a contract each, the last one is not in the set
+	hks, err := ss.addTestHosts(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcids, _, err := ss.addTestContracts(hks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ss.UpdateContractSet(context.Background(), testContractSet, fcids[:3], nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// create a bucket that overrides the redundancy
+	cold := api.RedundancySettings{MinShards: 1, TotalShards: 2}
+	if err := ss.CreateBucket(context.Background(), "cold", api.BucketPolicy{Redundancy: &cold}); err != nil {
+		t.Fatal(err)
+	}
+
+	// helper to create an object with a single slab stored on the given hosts
+	newObject := func(minShards uint8, hosts ...int) object.Object {
+		slab := object.Slab{
+			Key:       object.GenerateEncryptionKey(),
+			MinShards: minShards,
+		}
+		for _, i := range hosts {
+			slab.Shards = append(slab.Shards, newTestShard(hks[i], fcids[i], frand.Entropy256()))
+		}
+		return object.Object{
+			Key:   object.GenerateEncryptionKey(),
+			Slabs: []object.SlabSlice{{Slab: slab}},
+		}
+	}
+
+	// add an object with the default redundancy of 1-of-3 with one bad shard,
+	// an object in the cold bucket with all of its 1-of-2 shards on good hosts
+	// and one with a bad shard
+	hot := newObject(1, 0, 1, 3)
+	healthy := newObject(uint8(cold.MinShards), 0, 1)
+	unhealthy := newObject(uint8(cold.MinShards), 0, 3)
+	if err := ss.UpdateObjectBlocking(context.Background(), api.DefaultBucketName, "/hot", testContractSet, testETag, testMimeType, testMetadata, hot); err != nil {
+		t.Fatal(err)
+	} else if err := ss.UpdateObjectBlocking(context.Background(), "cold", "/healthy", testContractSet, testETag, testMimeType, testMetadata, healthy); err != nil {
+		t.Fatal(err)
+	} else if err := ss.UpdateObjectBlocking(context.Background(), "cold", "/unhealthy", testContractSet, testETag, testMimeType, testMetadata, unhealthy); err != nil {
+		t.Fatal(err)
+	}
+
+	// refresh the health and assert every slab's health is computed against
+	// its own redundancy, 2 out of 3 shards of the default slab are good
+	// which is half its redundancy, 2 out of 2 shards of the healthy cold slab
+	// are good and only 1 out of 2 shards of the unhealthy one
+	if err := ss.RefreshHealth(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	slabs, err := ss.UnhealthySlabs(context.Background(), 0.99, testContractSet, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	health := make(map[object.EncryptionKey]float64)
+	for _, slab := range slabs {
+		health[slab.Key] = slab.Health
+	}
+	if len(health) != 2 {
+		t.Fatalf("unexpected amount of slabs to migrate, %v!=2", len(health))
+	} else if h, ok := health[hot.Slabs[0].Key]; !ok || h != 0.5 {
+		t.Fatal("unexpected health of the default slab", ok, h)
+	} else if h, ok := health[unhealthy.Slabs[0].Key]; !ok || h != 0 {
+		t.Fatal("unexpected health of the unhealthy cold slab", ok, h)
+	}
+}
+
 // TestContractSectors is a test for the contract_sectors join table. It
 // verifies that deleting contracts or sectors also cleans up the join table.
 func TestContractSectors(t *testing.T) {
@@ -2626,7 +2782,7 @@ func TestRenameObjects(t *testing.T) {
 	}
 
 	// Assert that number of objects matches.
-	objs, err := ss.SearchObjects(ctx, api.DefaultBucketName, "/", 0, 100)
+	objs, err := ss.SearchObjects(ctx, api.DefaultBucketName, "/", nil, 0, 100)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -2672,7 +2828,7 @@ func TestRenameObjectsRegression(t *testing.T) {
 		if strings.HasSuffix(path, "/") {
 			objects, _, err = ss.ObjectEntries(ctx, testBucket, path, "", "", "", "", 0, -1)
 		} else {
-			objects, err = ss.SearchObjects(ctx, testBucket, path, 0, -1)
+			objects, err = ss.SearchObjects(ctx, testBucket, path, nil, 0, -1)
 		}
 
 		if err != nil {
@@ -3415,7 +3571,7 @@ func TestBucketObjects(t *testing.T) {
 
 	// Adding an object to a bucket that doesn't exist shouldn't work.
 	obj := newTestObject(1)
-	err := ss.UpdateObject(context.Background(), "unknown-bucket", "/foo", testContractSet, testETag, testMimeType, testMetadata, obj)
+	err := ss.UpdateObject(context.Background(), "unknown-bucket", "/foo", testContractSet, obj, api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata})
 	if !errors.Is(err, api.ErrBucketNotFound) {
 		t.Fatal("expected ErrBucketNotFound", err)
 	}
@@ -3446,7 +3602,7 @@ func TestBucketObjects(t *testing.T) {
 		obj := newTestObject(frand.Intn(9) + 1)
 		obj.Slabs = obj.Slabs[:1]
 		obj.Slabs[0].Length = uint32(o.size)
-		err := ss.UpdateObject(ctx, o.bucket, o.path, testContractSet, testETag, testMimeType, testMetadata, obj)
+		err := ss.UpdateObject(ctx, o.bucket, o.path, testContractSet, obj, api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -3473,13 +3629,13 @@ func TestBucketObjects(t *testing.T) {
 	}
 
 	// Search the objects in the buckets.
-	if objects, err := ss.SearchObjects(context.Background(), b1, "", 0, -1); err != nil {
+	if objects, err := ss.SearchObjects(context.Background(), b1, "", nil, 0, -1); err != nil {
 		t.Fatal(err)
 	} else if len(objects) != 2 {
 		t.Fatal("expected 2 objects", len(objects))
 	} else if objects[0].Size != 3 || objects[1].Size != 1 {
 		t.Fatal("unexpected size", objects[0].Size, objects[1].Size)
-	} else if objects, err := ss.SearchObjects(context.Background(), b2, "", 0, -1); err != nil {
+	} else if objects, err := ss.SearchObjects(context.Background(), b2, "", nil, 0, -1); err != nil {
 		t.Fatal(err)
 	} else if len(objects) != 2 {
 		t.Fatal("expected 2 objects", len(objects))
@@ -3590,7 +3746,7 @@ func TestCopyObject(t *testing.T) {
 
 	// Create one object.
 	obj := newTestObject(1)
-	err := ss.UpdateObject(ctx, "src", "/foo", testContractSet, testETag, testMimeType, testMetadata, obj)
+	err := ss.UpdateObject(ctx, "src", "/foo", testContractSet, obj, api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -3622,6 +3778,579 @@ func TestCopyObject(t *testing.T) {
 	}
 }
 
+func TestObjectChecksum(t *testing.T) {
+	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
+	defer ss.Close()
+
+	// upload an object with a checksum
+	ctx := context.Background()
+	checksum := "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg="
+	if err := ss.UpdateObject(ctx, api.DefaultBucketName, "/foo", testContractSet, newTestObject(1), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata, ChecksumAlgorithm: api.ChecksumAlgorithmSHA256, Checksum: checksum}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertChecksum := func(om api.ObjectMetadata) {
+		t.Helper()
+		if om.ChecksumAlgorithm != api.ChecksumAlgorithmSHA256 || om.Checksum != checksum {
+			t.Fatalf("unexpected checksum %v %v", om.ChecksumAlgorithm, om.Checksum)
+		}
+	}
+
+	// assert the checksum is returned for the object and its metadata
+	if obj, err := ss.Object(ctx, api.DefaultBucketName, "/foo"); err != nil {
+		t.Fatal(err)
+	} else {
+		assertChecksum(obj.ObjectMetadata)
+	}
+	if obj, err := ss.ObjectMetadata(ctx, api.DefaultBucketName, "/foo"); err != nil {
+		t.Fatal(err)
+	} else {
+		assertChecksum(obj.ObjectMetadata)
+	}
+
+	// assert the checksum is copied along with the object
+	if om, err := ss.CopyObject(ctx, api.DefaultBucketName, api.DefaultBucketName, "/foo", "/bar", "", nil); err != nil {
+		t.Fatal(err)
+	} else {
+		assertChecksum(om)
+	}
+}
+
+func TestObjectCustomerKeyFingerprint(t *testing.T) {
+	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
+	defer ss.Close()
+
+	// create a bucket with versioning enabled
+	ctx := context.Background()
+	if err := ss.CreateBucket(ctx, "versioned", api.BucketPolicy{Versioning: api.BucketVersioningEnabled}); err != nil {
+		t.Fatal(err)
+	}
+
+	// upload an object with a customer key
+	fp := object.CustomerKey(frand.Entropy256()).Fingerprint()
+	if err := ss.UpdateObject(ctx, "versioned", "/foo", testContractSet, newTestObject(1), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata, CustomerKeyFingerprint: fp}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFingerprint := func(om api.ObjectMetadata, expected string) {
+		t.Helper()
+		if om.CustomerKeyFingerprint != expected {
+			t.Fatalf("unexpected fingerprint '%v', expected '%v'", om.CustomerKeyFingerprint, expected)
+		}
+	}
+
+	// assert the fingerprint is returned for the object and its metadata
+	if obj, err := ss.Object(ctx, "versioned", "/foo"); err != nil {
+		t.Fatal(err)
+	} else {
+		assertFingerprint(obj.ObjectMetadata, fp)
+	}
+	if obj, err := ss.ObjectMetadata(ctx, "versioned", "/foo"); err != nil {
+		t.Fatal(err)
+	} else {
+		assertFingerprint(obj.ObjectMetadata, fp)
+	}
+
+	// assert the fingerprint is copied along with the object
+	if om, err := ss.CopyObject(ctx, "versioned", "versioned", "/foo", "/bar", "", nil); err != nil {
+		t.Fatal(err)
+	} else {
+		assertFingerprint(om, fp)
+	}
+
+	// overwrite the object without a customer key and assert the noncurrent
+	// version keeps its fingerprint
+	if err := ss.UpdateObject(ctx, "versioned", "/foo", testContractSet, newTestObject(1), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata}); err != nil {
+		t.Fatal(err)
+	}
+	if obj, err := ss.Object(ctx, "versioned", "/foo"); err != nil {
+		t.Fatal(err)
+	} else {
+		assertFingerprint(obj.ObjectMetadata, "")
+	}
+	resp, err := ss.ObjectVersions(ctx, "versioned", "/foo", "", "", -1)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(resp.Versions) != 2 {
+		t.Fatal("expected 2 versions", len(resp.Versions))
+	}
+	if obj, err := ss.ObjectVersion(ctx, "versioned", "/foo", resp.Versions[1].VersionID); err != nil {
+		t.Fatal(err)
+	} else {
+		assertFingerprint(obj.ObjectMetadata, fp)
+	}
+}
+
+func TestUpdateObjectConditions(t *testing.T) {
+	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
+	defer ss.Close()
+
+	ctx := context.Background()
+	update := func(eTag string, conditions api.ObjectConditions) error {
+		t.Helper()
+		return ss.UpdateObject(ctx, api.DefaultBucketName, "/foo", testContractSet, newTestObject(1), api.AddObjectOptions{ETag: eTag, MimeType: testMimeType, Metadata: testMetadata, Conditions: conditions})
+	}
+
+	// assert If-Match fails if the object doesn't exist
+	if err := update("etag1", api.ObjectConditions{IfMatch: "*"}); !errors.Is(err, api.ErrPreconditionFailed) {
+		t.Fatal("unexpected error", err)
+	}
+
+	// assert a create-only upload succeeds if the object doesn't exist
+	if err := update("etag1", api.ObjectConditions{IfNoneMatch: "*"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert a create-only upload fails once it does
+	if err := update("etag2", api.ObjectConditions{IfNoneMatch: "*"}); !errors.Is(err, api.ErrPreconditionFailed) {
+		t.Fatal("unexpected error", err)
+	}
+
+	// assert overwriting the object fails if the etag doesn't match
+	if err := update("etag2", api.ObjectConditions{IfMatch: `"etag2"`}); !errors.Is(err, api.ErrPreconditionFailed) {
+		t.Fatal("unexpected error", err)
+	}
+
+	// assert overwriting the object succeeds if it does
+	if err := update("etag2", api.ObjectConditions{IfMatch: `"etag1"`}); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert the object was only overwritten by the successful update
+	if obj, err := ss.ObjectMetadata(ctx, api.DefaultBucketName, "/foo"); err != nil {
+		t.Fatal(err)
+	} else if obj.ETag != "etag2" {
+		t.Fatal("unexpected etag", obj.ETag)
+	}
+
+	// assert only one of many concurrent create-only uploads succeeds
+	var wg sync.WaitGroup
+	var succeeded, failed atomic.Uint64
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := ss.UpdateObject(ctx, api.DefaultBucketName, "/bar", testContractSet, newTestObject(1), api.AddObjectOptions{ETag: fmt.Sprint(i), MimeType: testMimeType, Conditions: api.ObjectConditions{IfNoneMatch: "*"}})
+			if err == nil {
+				succeeded.Add(1)
+			} else if errors.Is(err, api.ErrPreconditionFailed) {
+				failed.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if succeeded.Load() != 1 || failed.Load() != 9 {
+		t.Fatalf("expected 1 upload to succeed and 9 to fail, got %d and %d", succeeded.Load(), failed.Load())
+	}
+}
+
+func TestObjectTags(t *testing.T) {
+	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
+	defer ss.Close()
+
+	// upload two objects
+	ctx := context.Background()
+	for _, path := range []string{"/foo", "/bar"} {
+		if err := ss.UpdateObject(ctx, api.DefaultBucketName, path, testContractSet, newTestObject(1), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// tag one of them
+	tags := api.ObjectTags{"env": "prod", "team": "storage"}
+	if err := ss.UpdateObjectTags(ctx, api.DefaultBucketName, "/foo", tags); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert the tags are returned
+	if got, err := ss.ObjectTags(ctx, api.DefaultBucketName, "/foo"); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(got, tags) {
+		t.Fatal("unexpected tags", got)
+	}
+	if obj, err := ss.Object(ctx, api.DefaultBucketName, "/foo"); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(obj.Tags, tags) {
+		t.Fatal("unexpected tags", obj.Tags)
+	}
+
+	// assert we can filter by tags when listing and searching
+	resp, err := ss.ListObjects(ctx, api.DefaultBucketName, "/", "", "", "", api.ObjectTags{"env": "prod"}, -1)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(resp.Objects) != 1 || resp.Objects[0].Name != "/foo" {
+		t.Fatal("unexpected objects", resp.Objects)
+	}
+	if objs, err := ss.SearchObjects(ctx, api.DefaultBucketName, "", api.ObjectTags{"env": "prod", "team": "storage"}, 0, -1); err != nil {
+		t.Fatal(err)
+	} else if len(objs) != 1 || objs[0].Name != "/foo" {
+		t.Fatal("unexpected objects", objs)
+	}
+	if objs, err := ss.SearchObjects(ctx, api.DefaultBucketName, "", api.ObjectTags{"env": "dev"}, 0, -1); err != nil {
+		t.Fatal(err)
+	} else if len(objs) != 0 {
+		t.Fatal("unexpected objects", objs)
+	}
+
+	// assert the tags are copied along with the object
+	if _, err := ss.CopyObject(ctx, api.DefaultBucketName, api.DefaultBucketName, "/foo", "/baz", "", nil); err != nil {
+		t.Fatal(err)
+	} else if got, err := ss.ObjectTags(ctx, api.DefaultBucketName, "/baz"); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(got, tags) {
+		t.Fatal("unexpected tags", got)
+	}
+
+	// replace the tags and assert the old ones are gone
+	if err := ss.UpdateObjectTags(ctx, api.DefaultBucketName, "/foo", api.ObjectTags{"env": "dev"}); err != nil {
+		t.Fatal(err)
+	} else if got, err := ss.ObjectTags(ctx, api.DefaultBucketName, "/foo"); err != nil {
+		t.Fatal(err)
+	} else if len(got) != 1 || got["env"] != "dev" {
+		t.Fatal("unexpected tags", got)
+	}
+
+	// delete the tags
+	if err := ss.UpdateObjectTags(ctx, api.DefaultBucketName, "/foo", nil); err != nil {
+		t.Fatal(err)
+	} else if got, err := ss.ObjectTags(ctx, api.DefaultBucketName, "/foo"); err != nil {
+		t.Fatal(err)
+	} else if len(got) != 0 {
+		t.Fatal("unexpected tags", got)
+	}
+
+	// assert tagging an unknown object fails
+	if err := ss.UpdateObjectTags(ctx, api.DefaultBucketName, "/unknown", tags); !errors.Is(err, api.ErrObjectNotFound) {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func TestObjectVersioning(t *testing.T) {
+	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
+	defer ss.Close()
+
+	// create a bucket with versioning enabled
+	ctx := context.Background()
+	if err := ss.CreateBucket(ctx, "versioned", api.BucketPolicy{Versioning: api.BucketVersioningEnabled}); err != nil {
+		t.Fatal(err)
+	}
+
+	// upload the same object twice
+	obj1, obj2 := newTestObject(1), newTestObject(1)
+	if err := ss.UpdateObject(ctx, "versioned", "/foo", testContractSet, obj1, api.AddObjectOptions{ETag: "etag1", MimeType: testMimeType, Metadata: testMetadata}); err != nil {
+		t.Fatal(err)
+	} else if err := ss.UpdateObject(ctx, "versioned", "/foo", testContractSet, obj2, api.AddObjectOptions{ETag: "etag2", MimeType: testMimeType, Metadata: testMetadata}); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert the noncurrent version is accounted for in the stats
+	if stats, err := ss.ObjectsStats(ctx, api.ObjectsStatsOpts{Bucket: "versioned"}); err != nil {
+		t.Fatal(err)
+	} else if stats.NumObjects != 1 || stats.TotalObjectsSize != uint64(obj2.TotalSize()) {
+		t.Fatal("unexpected stats", stats)
+	} else if stats.NumNoncurrentVersions != 1 || stats.TotalNoncurrentVersionsSize != uint64(obj1.TotalSize()) {
+		t.Fatal("unexpected noncurrent version stats", stats)
+	}
+
+	// assert both versions are listed, newest first
+	resp, err := ss.ObjectVersions(ctx, "versioned", "", "", "", -1)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(resp.Versions) != 2 {
+		t.Fatal("expected 2 versions", len(resp.Versions))
+	} else if v := resp.Versions[0]; v.ETag != "etag2" || !v.IsLatest || v.VersionID == "" {
+		t.Fatal("unexpected version", v)
+	} else if v := resp.Versions[1]; v.ETag != "etag1" || v.IsLatest || v.VersionID == "" {
+		t.Fatal("unexpected version", v)
+	}
+	v1, v2 := resp.Versions[1].VersionID, resp.Versions[0].VersionID
+
+	// assert the noncurrent version can be fetched
+	if obj, err := ss.ObjectVersion(ctx, "versioned", "/foo", v1); err != nil {
+		t.Fatal(err)
+	} else if obj.ETag != "etag1" || obj.VersionID != v1 || obj.Object == nil || len(obj.Object.Slabs) != 1 {
+		t.Fatal("unexpected object", obj)
+	} else if !reflect.DeepEqual(obj.Metadata, testMetadata) {
+		t.Fatal("unexpected metadata", obj.Metadata)
+	}
+
+	// assert pagination works using the markers
+	resp, err = ss.ObjectVersions(ctx, "versioned", "", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	} else if !resp.HasMore || resp.NextKeyMarker != "/foo" || resp.NextVersionIDMarker != v2 {
+		t.Fatal("unexpected response", resp)
+	}
+	resp, err = ss.ObjectVersions(ctx, "versioned", "", resp.NextKeyMarker, resp.NextVersionIDMarker, 1)
+	if err != nil {
+		t.Fatal(err)
+	} else if resp.HasMore || len(resp.Versions) != 1 || resp.Versions[0].VersionID != v1 || resp.Versions[0].IsLatest {
+		t.Fatal("unexpected response", resp)
+	}
+
+	// delete the object, this should insert a delete marker
+	if _, err := ss.RemoveObject(ctx, "versioned", "/foo"); err != nil {
+		t.Fatal(err)
+	} else if _, err := ss.Object(ctx, "versioned", "/foo"); !errors.Is(err, api.ErrObjectNotFound) {
+		t.Fatal("expected ErrObjectNotFound", err)
+	}
+	resp, err = ss.ObjectVersions(ctx, "versioned", "", "", "", -1)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(resp.Versions) != 3 {
+		t.Fatal("expected 3 versions", len(resp.Versions))
+	} else if v := resp.Versions[0]; !v.IsDeleteMarker || !v.IsLatest {
+		t.Fatal("expected latest version to be a delete marker", v)
+	} else if _, err := ss.ObjectVersion(ctx, "versioned", "/foo", v.VersionID); !errors.Is(err, api.ErrObjectVersionIsDeleteMarker) {
+		t.Fatal("expected ErrObjectVersionIsDeleteMarker", err)
+	}
+
+	// assert delete markers don't count towards the noncurrent versions
+	if stats, err := ss.ObjectsStats(ctx, api.ObjectsStatsOpts{Bucket: "versioned"}); err != nil {
+		t.Fatal(err)
+	} else if stats.NumObjects != 0 || stats.NumNoncurrentVersions != 2 || stats.TotalNoncurrentVersionsSize != uint64(obj1.TotalSize()+obj2.TotalSize()) {
+		t.Fatal("unexpected stats", stats)
+	}
+
+	// remove the delete marker, the previous version should be restored
+	if err := ss.RemoveObjectVersion(ctx, "versioned", "/foo", resp.Versions[0].VersionID); err != nil {
+		t.Fatal(err)
+	} else if obj, err := ss.Object(ctx, "versioned", "/foo"); err != nil {
+		t.Fatal(err)
+	} else if obj.ETag != "etag2" || obj.VersionID != v2 {
+		t.Fatal("unexpected object", obj)
+	}
+
+	// remove the current version, the oldest version should be restored
+	if err := ss.RemoveObjectVersion(ctx, "versioned", "/foo", v2); err != nil {
+		t.Fatal(err)
+	} else if obj, err := ss.Object(ctx, "versioned", "/foo"); err != nil {
+		t.Fatal(err)
+	} else if obj.ETag != "etag1" || obj.VersionID != v1 || len(obj.Object.Slabs) != 1 {
+		t.Fatal("unexpected object", obj)
+	} else if err := ss.RemoveObjectVersion(ctx, "versioned", "/foo", v2); !errors.Is(err, api.ErrObjectVersionNotFound) {
+		t.Fatal("expected ErrObjectVersionNotFound", err)
+	}
+
+	// remove the last version, the bucket should be empty
+	if err := ss.RemoveObjectVersion(ctx, "versioned", "/foo", v1); err != nil {
+		t.Fatal(err)
+	} else if err := ss.DeleteBucket(ctx, "versioned"); err != nil {
+		t.Fatal(err)
+	}
+
+	// suspend versioning, overwriting an object should not create versions
+	if err := ss.CreateBucket(ctx, "suspended", api.BucketPolicy{Versioning: api.BucketVersioningSuspended}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := ss.UpdateObject(ctx, "suspended", "/foo", testContractSet, newTestObject(1), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp, err = ss.ObjectVersions(ctx, "suspended", "", "", "", -1)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(resp.Versions) != 1 || resp.Versions[0].VersionID != "" {
+		t.Fatal("unexpected versions", resp.Versions)
+	} else if _, err := ss.ObjectVersion(ctx, "suspended", "/foo", api.ObjectVersionIDNull); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObjectVersioningRemoveRename(t *testing.T) {
+	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
+	defer ss.Close()
+
+	// create a bucket with versioning enabled
+	ctx := context.Background()
+	if err := ss.CreateBucket(ctx, "versioned", api.BucketPolicy{Versioning: api.BucketVersioningEnabled}); err != nil {
+		t.Fatal(err)
+	}
+
+	// upload a couple of objects
+	for _, path := range []string{"/dir/foo", "/dir/bar", "/baz", "/qux"} {
+		if err := ss.UpdateObject(ctx, "versioned", path, testContractSet, newTestObject(1), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// assertVersions is a helper that asserts the versions of a key, newest
+	// first, where 'true' denotes a delete marker
+	assertVersions := func(key string, markers ...bool) {
+		t.Helper()
+		resp, err := ss.ObjectVersions(ctx, "versioned", key, "", "", -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var versions []api.ObjectVersion
+		for _, v := range resp.Versions {
+			if v.Name == key {
+				versions = append(versions, v)
+			}
+		}
+		if len(versions) != len(markers) {
+			t.Fatalf("expected %d versions of %v, got %d", len(markers), key, len(versions))
+		}
+		for i, v := range versions {
+			if v.IsDeleteMarker != markers[i] {
+				t.Fatalf("unexpected version %d of %v: %+v", i, key, v)
+			} else if v.IsLatest != (i == 0) {
+				t.Fatalf("unexpected latest version %d of %v: %+v", i, key, v)
+			}
+		}
+	}
+
+	// delete the objects in the directory, they should be archived
+	if err := ss.RemoveObjects(ctx, "versioned", "/dir/"); err != nil {
+		t.Fatal(err)
+	} else if _, err := ss.Object(ctx, "versioned", "/dir/foo"); !errors.Is(err, api.ErrObjectNotFound) {
+		t.Fatal("expected ErrObjectNotFound", err)
+	} else if err := ss.RemoveObjects(ctx, "versioned", "/dir/"); !errors.Is(err, api.ErrObjectNotFound) {
+		t.Fatal("expected ErrObjectNotFound", err)
+	}
+	assertVersions("/dir/foo", true, false)
+	assertVersions("/dir/bar", true, false)
+
+	// force rename /baz to /qux, the overwritten object should be archived
+	// and /baz should be marked as deleted
+	if err := ss.RenameObject(ctx, "versioned", "/baz", "/qux", true); err != nil {
+		t.Fatal(err)
+	} else if _, err := ss.Object(ctx, "versioned", "/qux"); err != nil {
+		t.Fatal(err)
+	}
+	assertVersions("/baz", true)
+	assertVersions("/qux", false, false)
+
+	// force rename the objects with prefix /q to /b
+	if err := ss.RenameObjects(ctx, "versioned", "/q", "/b", true); err != nil {
+		t.Fatal(err)
+	} else if _, err := ss.Object(ctx, "versioned", "/bux"); err != nil {
+		t.Fatal(err)
+	}
+	assertVersions("/qux", true, false)
+	assertVersions("/bux", false)
+}
+
+func TestObjectLock(t *testing.T) {
+	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
+	defer ss.Close()
+
+	// upload two objects
+	ctx := context.Background()
+	for _, path := range []string{"/foo", "/bar"} {
+		if err := ss.UpdateObject(ctx, api.DefaultBucketName, path, testContractSet, newTestObject(1), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// assert objects are not locked by default
+	if lock, err := ss.ObjectLock(ctx, api.DefaultBucketName, "/foo"); err != nil {
+		t.Fatal(err)
+	} else if lock.Locked(time.Now()) {
+		t.Fatal("unexpected lock", lock)
+	} else if _, err := ss.ObjectLock(ctx, api.DefaultBucketName, "/baz"); !errors.Is(err, api.ErrObjectNotFound) {
+		t.Fatal("expected ErrObjectNotFound", err)
+	}
+
+	// put /foo under governance retention
+	retainUntil := api.TimeRFC3339(time.Now().Add(time.Hour).Round(time.Millisecond))
+	governance := api.ObjectRetention{Mode: api.ObjectLockModeGovernance, RetainUntil: retainUntil}
+	if err := ss.UpdateObjectRetention(ctx, api.DefaultBucketName, "/foo", governance, false); err != nil {
+		t.Fatal(err)
+	} else if lock, err := ss.ObjectLock(ctx, api.DefaultBucketName, "/foo"); err != nil {
+		t.Fatal(err)
+	} else if lock.Retention.Mode != governance.Mode || !lock.Retention.RetainUntil.Std().Equal(retainUntil.Std()) {
+		t.Fatal("unexpected retention", lock.Retention)
+	}
+
+	// assert the object can't be deleted, overwritten or renamed
+	assertLocked := func(path string) {
+		t.Helper()
+		if _, err := ss.RemoveObject(ctx, api.DefaultBucketName, path); !errors.Is(err, api.ErrObjectLocked) {
+			t.Fatal("expected ErrObjectLocked", err)
+		} else if err := ss.RemoveObjects(ctx, api.DefaultBucketName, path); !errors.Is(err, api.ErrObjectLocked) {
+			t.Fatal("expected ErrObjectLocked", err)
+		} else if err := ss.UpdateObject(ctx, api.DefaultBucketName, path, testContractSet, newTestObject(1), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata}); !errors.Is(err, api.ErrObjectLocked) {
+			t.Fatal("expected ErrObjectLocked", err)
+		} else if err := ss.RenameObject(ctx, api.DefaultBucketName, path, "/baz", false); !errors.Is(err, api.ErrObjectLocked) {
+			t.Fatal("expected ErrObjectLocked", err)
+		} else if err := ss.RenameObject(ctx, api.DefaultBucketName, "/bar", path, true); !errors.Is(err, api.ErrObjectLocked) {
+			t.Fatal("expected ErrObjectLocked", err)
+		}
+	}
+	assertLocked("/foo")
+
+	// assert the retention can't be shortened without bypassing governance
+	shorter := api.ObjectRetention{Mode: api.ObjectLockModeGovernance, RetainUntil: api.TimeRFC3339(time.Now().Add(time.Minute))}
+	if err := ss.UpdateObjectRetention(ctx, api.DefaultBucketName, "/foo", shorter, false); !errors.Is(err, api.ErrObjectLocked) {
+		t.Fatal("expected ErrObjectLocked", err)
+	} else if err := ss.UpdateObjectRetention(ctx, api.DefaultBucketName, "/foo", shorter, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// switch to compliance mode, it can't be bypassed
+	compliance := api.ObjectRetention{Mode: api.ObjectLockModeCompliance, RetainUntil: retainUntil}
+	if err := ss.UpdateObjectRetention(ctx, api.DefaultBucketName, "/foo", compliance, false); err != nil {
+		t.Fatal(err)
+	} else if err := ss.UpdateObjectRetention(ctx, api.DefaultBucketName, "/foo", api.ObjectRetention{}, true); !errors.Is(err, api.ErrObjectLocked) {
+		t.Fatal("expected ErrObjectLocked", err)
+	}
+	assertLocked("/foo")
+
+	// put /bar under a legal hold
+	if err := ss.UpdateObjectLegalHold(ctx, api.DefaultBucketName, "/bar", true); err != nil {
+		t.Fatal(err)
+	} else if lock, err := ss.ObjectLock(ctx, api.DefaultBucketName, "/bar"); err != nil {
+		t.Fatal(err)
+	} else if !lock.LegalHold || !lock.Locked(time.Now()) {
+		t.Fatal("unexpected lock", lock)
+	} else if _, err := ss.RemoveObject(ctx, api.DefaultBucketName, "/bar"); !errors.Is(err, api.ErrObjectLocked) {
+		t.Fatal("expected ErrObjectLocked", err)
+	}
+
+	// lift the legal hold, the object can be deleted again
+	if err := ss.UpdateObjectLegalHold(ctx, api.DefaultBucketName, "/bar", false); err != nil {
+		t.Fatal(err)
+	} else if _, err := ss.RemoveObject(ctx, api.DefaultBucketName, "/bar"); err != nil {
+		t.Fatal(err)
+	}
+
+	// in a versioned bucket, overwriting a locked object archives it with its
+	// lock, the locked version can't be deleted
+	if err := ss.CreateBucket(ctx, "versioned", api.BucketPolicy{Versioning: api.BucketVersioningEnabled}); err != nil {
+		t.Fatal(err)
+	} else if err := ss.UpdateObject(ctx, "versioned", "/foo", testContractSet, newTestObject(1), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata}); err != nil {
+		t.Fatal(err)
+	} else if err := ss.UpdateObjectLegalHold(ctx, "versioned", "/foo", true); err != nil {
+		t.Fatal(err)
+	} else if err := ss.UpdateObject(ctx, "versioned", "/foo", testContractSet, newTestObject(1), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := ss.ObjectVersions(ctx, "versioned", "", "", "", -1)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(resp.Versions) != 2 {
+		t.Fatal("expected 2 versions", len(resp.Versions))
+	} else if err := ss.RemoveObjectVersion(ctx, "versioned", "/foo", resp.Versions[1].VersionID); !errors.Is(err, api.ErrObjectLocked) {
+		t.Fatal("expected ErrObjectLocked", err)
+	} else if err := ss.RemoveObjectVersion(ctx, "versioned", "/foo", resp.Versions[0].VersionID); err != nil {
+		t.Fatal(err)
+	}
+
+	// an object can be locked when it's stored
+	lock := api.ObjectLock{Retention: governance, LegalHold: true}
+	if err := ss.UpdateObject(ctx, api.DefaultBucketName, "/baz", testContractSet, newTestObject(1), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Lock: lock}); err != nil {
+		t.Fatal(err)
+	} else if got, err := ss.ObjectLock(ctx, api.DefaultBucketName, "/baz"); err != nil {
+		t.Fatal(err)
+	} else if got.Retention.Mode != lock.Retention.Mode || !got.Retention.RetainUntil.Std().Equal(lock.Retention.RetainUntil.Std()) || !got.LegalHold {
+		t.Fatal("unexpected lock", got)
+	} else if _, err := ss.RemoveObject(ctx, api.DefaultBucketName, "/baz"); !errors.Is(err, api.ErrObjectLocked) {
+		t.Fatal("expected ErrObjectLocked", err)
+	}
+}
+
 func TestMarkSlabUploadedAfterRenew(t *testing.T) {
 	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
 	defer ss.Close()
@@ -3771,7 +4500,7 @@ func TestListObjects(t *testing.T) {
 		}
 	}
 	for _, test := range tests {
-		res, err := ss.ListObjects(ctx, api.DefaultBucketName, test.prefix, test.sortBy, test.sortDir, "", -1)
+		res, err := ss.ListObjects(ctx, api.DefaultBucketName, test.prefix, test.sortBy, test.sortDir, "", nil, -1)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -3786,7 +4515,7 @@ func TestListObjects(t *testing.T) {
 		if len(res.Objects) > 0 {
 			marker := ""
 			for offset := 0; offset < len(test.want); offset++ {
-				res, err := ss.ListObjects(ctx, api.DefaultBucketName, test.prefix, test.sortBy, test.sortDir, marker, 1)
+				res, err := ss.ListObjects(ctx, api.DefaultBucketName, test.prefix, test.sortBy, test.sortDir, marker, nil, 1)
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -4098,7 +4827,7 @@ func TestSlabHealthInvalidation(t *testing.T) {
 
 	// prepare a slab with pieces on h3 and h4
 	s2 := object.GenerateEncryptionKey()
-	err = ss.UpdateObject(context.Background(), api.DefaultBucketName, "/o2", testContractSet, testETag, testMimeType, testMetadata, object.Object{
+	err = ss.UpdateObject(context.Background(), api.DefaultBucketName, "/o2", testContractSet, object.Object{
 		Key: object.GenerateEncryptionKey(),
 		Slabs: []object.SlabSlice{{Slab: object.Slab{
 			Key: s2,
@@ -4107,7 +4836,7 @@ func TestSlabHealthInvalidation(t *testing.T) {
 				newTestShard(hks[3], fcids[3], types.Hash256{3}),
 			},
 		}}},
-	})
+	}, api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -4828,7 +5557,7 @@ func TestUpdateObjectParallel(t *testing.T) {
 			}
 
 			// update the object
-			if err := ss.UpdateObject(context.Background(), api.DefaultBucketName, name, testContractSet, testETag, testMimeType, testMetadata, obj); err != nil {
+			if err := ss.UpdateObject(context.Background(), api.DefaultBucketName, name, testContractSet, obj, api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata}); err != nil {
 				t.Error(err)
 				return
 			}
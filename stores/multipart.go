@@ -2,8 +2,12 @@ package stores
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"sort"
+	"time"
 
 	"go.thebigfile.com/renterd/api"
 	"go.thebigfile.com/renterd/object"
@@ -24,12 +28,62 @@ func (s *SQLStore) CreateMultipartUpload(ctx context.Context, bucket, path strin
 	}, err
 }
 
-func (s *SQLStore) AddMultipartPart(ctx context.Context, bucket, path, contractSet, eTag, uploadID string, partNumber int, slices []object.SlabSlice) (err error) {
+func (s *SQLStore) AddMultipartPart(ctx context.Context, bucket, path, contractSet, eTag, uploadID string, partNumber int, slices []object.SlabSlice, opts api.AddMultipartPartOptions) (err error) {
 	return s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
-		return tx.AddMultipartPart(ctx, bucket, path, contractSet, eTag, uploadID, partNumber, slices)
+		return tx.AddMultipartPart(ctx, bucket, path, contractSet, eTag, uploadID, partNumber, slices, opts)
 	})
 }
 
+// CopyMultipartPart adds a part to a multipart upload that references the given
+// range of the source object's slabs, the data itself is not copied. A length
+// of -1 copies everything from the offset onwards.
+func (s *SQLStore) CopyMultipartPart(ctx context.Context, srcBucket, srcPath, bucket, path, contractSet, uploadID string, partNumber int, offset, length int64) (resp api.MultipartCopyPartResponse, err error) {
+	err = s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
+		src, err := tx.Object(ctx, srcBucket, srcPath)
+		if err != nil {
+			return err
+		}
+		upload, err := tx.MultipartUpload(ctx, uploadID)
+		if err != nil {
+			return err
+		}
+
+		// the data of an object is encrypted using its key and the offset
+		// within the object, so the slabs can only be referenced if neither
+		// the source nor the upload use object encryption
+		if !src.Object.Key.IsNoopKey() || !upload.Key.IsNoopKey() {
+			return api.ErrPartCopyNotSupported
+		}
+
+		// adjust the range
+		if length == -1 {
+			length = src.Size - offset
+		}
+		if offset < 0 || length < 0 || offset+length > src.Size {
+			return fmt.Errorf("%w: range %d-%d exceeds object size %d", api.ErrInvalidPartCopyRange, offset, offset+length, src.Size)
+		}
+
+		// parts that copy the whole object have the same etag as the object,
+		// otherwise the etag is derived from the object's etag and the range
+		eTag := src.ETag
+		if offset != 0 || length != src.Size {
+			h := md5.Sum([]byte(fmt.Sprintf("%s-%d-%d", src.ETag, offset, length)))
+			eTag = hex.EncodeToString(h[:])
+		}
+
+		slices := src.Object.Slabs.Range(uint64(offset), uint64(length))
+		if err := tx.AddMultipartPart(ctx, bucket, path, contractSet, eTag, uploadID, partNumber, slices, api.AddMultipartPartOptions{}); err != nil {
+			return fmt.Errorf("failed to add part: %w", err)
+		}
+		resp = api.MultipartCopyPartResponse{
+			ETag:         eTag,
+			LastModified: api.TimeRFC3339(time.Now().UTC()),
+		}
+		return nil
+	})
+	return
+}
+
 func (s *SQLStore) MultipartUpload(ctx context.Context, uploadID string) (resp api.MultipartUpload, err error) {
 	err = s.db.Transaction(ctx, func(tx sql.DatabaseTx) (err error) {
 		resp, err = tx.MultipartUpload(ctx, uploadID)
@@ -81,11 +135,28 @@ func (s *SQLStore) CompleteMultipartUpload(ctx context.Context, bucket, path str
 	var eTag string
 	var prune bool
 	err = s.db.Transaction(ctx, func(tx sql.DatabaseTx) error {
-		// Delete potentially existing object.
+		// Evaluate the preconditions against the current object, like
+		// UpdateObject does.
+		if opts.Conditions.HasWriteConditions() {
+			currentETag, err := tx.ObjectETag(ctx, bucket, path)
+			exists := err == nil
+			if err != nil && !errors.Is(err, api.ErrObjectNotFound) {
+				return fmt.Errorf("failed to fetch object etag: %w", err)
+			} else if err := opts.Conditions.CheckWrite(currentETag, exists); err != nil {
+				return err
+			}
+		}
+
+		// Archive and delete potentially existing object.
+		archived, err := tx.ArchiveObject(ctx, bucket, path)
+		if err != nil {
+			return fmt.Errorf("failed to archive object: %w", err)
+		}
 		prune, err = tx.DeleteObject(ctx, bucket, path)
 		if err != nil {
 			return fmt.Errorf("failed to delete object: %w", err)
 		}
+		prune = prune || archived
 
 		// Complete upload
 		eTag, err = tx.CompleteMultipartUpload(ctx, bucket, path, uploadID, parts, opts)
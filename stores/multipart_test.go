@@ -3,6 +3,7 @@ package stores
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"reflect"
 	"sort"
 	"strings"
@@ -61,7 +62,7 @@ func TestMultipartUploadWithUploadPackingRegression(t *testing.T) {
 			t.Fatal(err)
 		}
 		etag := hex.EncodeToString(frand.Bytes(16))
-		err = ss.AddMultipartPart(ctx, api.DefaultBucketName, objName, testContractSet, etag, resp.UploadID, i, partialSlabs)
+		err = ss.AddMultipartPart(ctx, api.DefaultBucketName, objName, testContractSet, etag, resp.UploadID, i, partialSlabs, api.AddMultipartPartOptions{})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -182,6 +183,129 @@ func TestMultipartUploadWithUploadPackingRegression(t *testing.T) {
 	}
 }
 
+func TestCopyMultipartPart(t *testing.T) {
+	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
+	defer ss.Close()
+
+	// add an unencrypted source object, like the ones created by S3
+	// multipart uploads
+	ctx := context.Background()
+	src := newTestObject(2)
+	src.Key = object.NoOpKey
+	if err := ss.UpdateObject(ctx, api.DefaultBucketName, "/src", testContractSet, src, api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata}); err != nil {
+		t.Fatal(err)
+	}
+	size := src.TotalSize()
+
+	// create a multipart upload
+	resp, err := ss.CreateMultipartUpload(ctx, api.DefaultBucketName, "/dst", object.NoOpKey, testMimeType, testMetadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// copy a range of the source object and the whole source object
+	part1, err := ss.CopyMultipartPart(ctx, api.DefaultBucketName, "/src", api.DefaultBucketName, "/dst", testContractSet, resp.UploadID, 1, 10, 100)
+	if err != nil {
+		t.Fatal(err)
+	} else if part1.ETag == "" || part1.ETag == testETag {
+		t.Fatal("unexpected etag", part1.ETag)
+	}
+	part2, err := ss.CopyMultipartPart(ctx, api.DefaultBucketName, "/src", api.DefaultBucketName, "/dst", testContractSet, resp.UploadID, 2, 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	} else if part2.ETag != testETag {
+		t.Fatal("unexpected etag", part2.ETag)
+	}
+
+	// assert invalid ranges and unknown objects are rejected
+	if _, err := ss.CopyMultipartPart(ctx, api.DefaultBucketName, "/src", api.DefaultBucketName, "/dst", testContractSet, resp.UploadID, 3, size, 1); !errors.Is(err, api.ErrInvalidPartCopyRange) {
+		t.Fatal("unexpected error", err)
+	} else if _, err := ss.CopyMultipartPart(ctx, api.DefaultBucketName, "/unknown", api.DefaultBucketName, "/dst", testContractSet, resp.UploadID, 3, 0, -1); !errors.Is(err, api.ErrObjectNotFound) {
+		t.Fatal("unexpected error", err)
+	}
+
+	// assert encrypted objects can't be copied by reference
+	if err := ss.UpdateObject(ctx, api.DefaultBucketName, "/encrypted", testContractSet, newTestObject(1), api.AddObjectOptions{ETag: testETag, MimeType: testMimeType, Metadata: testMetadata}); err != nil {
+		t.Fatal(err)
+	} else if _, err := ss.CopyMultipartPart(ctx, api.DefaultBucketName, "/encrypted", api.DefaultBucketName, "/dst", testContractSet, resp.UploadID, 3, 0, -1); !errors.Is(err, api.ErrPartCopyNotSupported) {
+		t.Fatal("unexpected error", err)
+	}
+
+	// complete the upload
+	_, err = ss.CompleteMultipartUpload(ctx, api.DefaultBucketName, "/dst", resp.UploadID, []api.MultipartCompletedPart{
+		{PartNumber: 1, ETag: part1.ETag},
+		{PartNumber: 2, ETag: part2.ETag},
+	}, api.CompleteMultipartOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert the object references the copied slices
+	obj, err := ss.Object(ctx, api.DefaultBucketName, "/dst")
+	if err != nil {
+		t.Fatal(err)
+	} else if obj.Size != size+100 {
+		t.Fatalf("unexpected size %v, expected %v", obj.Size, size+100)
+	}
+	expected := append(src.Slabs.Range(10, 100), src.Slabs...)
+	if len(obj.Slabs) != len(expected) {
+		t.Fatalf("expected %v slices, got %v", len(expected), len(obj.Slabs))
+	}
+	for i := range expected {
+		if obj.Slabs[i].Key.String() != expected[i].Key.String() ||
+			obj.Slabs[i].Offset != expected[i].Offset ||
+			obj.Slabs[i].Length != expected[i].Length {
+			t.Fatalf("unexpected slice %v", i)
+		}
+	}
+}
+
+func TestAddMultipartPartOffset(t *testing.T) {
+	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
+	defer ss.Close()
+
+	// create a multipart upload
+	ctx := context.Background()
+	resp, err := ss.CreateMultipartUpload(ctx, api.DefaultBucketName, "/foo", object.GenerateEncryptionKey(), testMimeType, testMetadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addPart := func(partNumber int, offset int64) (int64, error) {
+		t.Helper()
+		o := newTestObject(1)
+		return int64(o.TotalSize()), ss.AddMultipartPart(ctx, api.DefaultBucketName, "/foo", testContractSet, testETag, resp.UploadID, partNumber, o.Slabs, api.AddMultipartPartOptions{
+			Offset: &offset,
+		})
+	}
+
+	// add the first part
+	size, err := addPart(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert parts that don't start at the upload's offset are rejected
+	if _, err := addPart(1, 0); !errors.Is(err, api.ErrUploadOffsetMismatch) {
+		t.Fatal("expected offset mismatch for existing part", err)
+	} else if _, err := addPart(3, size); !errors.Is(err, api.ErrUploadOffsetMismatch) {
+		t.Fatal("expected offset mismatch for skipped part", err)
+	} else if _, err := addPart(2, size-1); !errors.Is(err, api.ErrUploadOffsetMismatch) {
+		t.Fatal("expected offset mismatch for wrong offset", err)
+	}
+
+	// add the next part
+	if _, err := addPart(2, size); err != nil {
+		t.Fatal(err)
+	}
+	parts, err := ss.MultipartUploadParts(ctx, api.DefaultBucketName, "/foo", resp.UploadID, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(parts.Parts) != 2 {
+		t.Fatalf("expected 2 parts, got %v", len(parts.Parts))
+	}
+}
+
 func TestMultipartUploads(t *testing.T) {
 	if testing.Short() {
 		t.SkipNow()
@@ -318,3 +442,49 @@ func TestMultipartUploadEmptyObjects(t *testing.T) {
 		t.Fatal("unexpected etag")
 	}
 }
+
+func TestCompleteMultipartUploadOptions(t *testing.T) {
+	ss := newTestSQLStore(t, defaultTestSQLStoreConfig)
+	defer ss.Close()
+
+	complete := func(path string, opts api.CompleteMultipartOptions) error {
+		t.Helper()
+		resp, err := ss.CreateMultipartUpload(context.Background(), api.DefaultBucketName, path, object.NoOpKey, testMimeType, testMetadata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = ss.CompleteMultipartUpload(context.Background(), api.DefaultBucketName, path, resp.UploadID, []api.MultipartCompletedPart{}, opts)
+		return err
+	}
+
+	// assert the object is stored with the same options as a single-part
+	// upload
+	if err := complete("/foo", api.CompleteMultipartOptions{
+		ChecksumAlgorithm:      api.ChecksumAlgorithmSHA256,
+		Checksum:               "checksum",
+		CustomerKeyFingerprint: "fingerprint",
+		Lock:                   api.ObjectLock{LegalHold: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	obj, err := ss.Object(context.Background(), api.DefaultBucketName, "/foo")
+	if err != nil {
+		t.Fatal(err)
+	} else if obj.ChecksumAlgorithm != api.ChecksumAlgorithmSHA256 || obj.Checksum != "checksum" {
+		t.Fatal("unexpected checksum", obj.ChecksumAlgorithm, obj.Checksum)
+	} else if obj.CustomerKeyFingerprint != "fingerprint" {
+		t.Fatal("unexpected fingerprint", obj.CustomerKeyFingerprint)
+	}
+	if lock, err := ss.ObjectLock(context.Background(), api.DefaultBucketName, "/foo"); err != nil {
+		t.Fatal(err)
+	} else if !lock.LegalHold {
+		t.Fatal("expected legal hold")
+	}
+
+	// assert the conditions are evaluated against the existing object
+	if err := complete("/bar", api.CompleteMultipartOptions{Conditions: api.ObjectConditions{IfNoneMatch: "*"}}); err != nil {
+		t.Fatal(err)
+	} else if err := complete("/bar", api.CompleteMultipartOptions{Conditions: api.ObjectConditions{IfNoneMatch: "*"}}); !errors.Is(err, api.ErrPreconditionFailed) {
+		t.Fatal("unexpected error", err)
+	}
+}
@@ -54,8 +54,10 @@ type (
 		// Accounts returns all accounts from the db.
 		Accounts(ctx context.Context, owner string) ([]api.Account, error)
 
-		// AddMultipartPart adds a part to an unfinished multipart upload.
-		AddMultipartPart(ctx context.Context, bucket, key, contractSet, eTag, uploadID string, partNumber int, slices object.SlabSlices) error
+		// AddMultipartPart adds a part to an unfinished multipart upload. If
+		// an offset is given, the part is only added if it starts at that
+		// offset of the upload.
+		AddMultipartPart(ctx context.Context, bucket, key, contractSet, eTag, uploadID string, partNumber int, slices object.SlabSlices, opts api.AddMultipartPartOptions) error
 
 		// AddPeer adds a peer to the store.
 		AddPeer(ctx context.Context, addr string) error
@@ -72,6 +74,11 @@ type (
 		// archived ones.
 		ArchiveContract(ctx context.Context, fcid types.FileContractID, reason string) error
 
+		// ArchiveObject moves the current version of an object to the object
+		// versions if versioning is enabled for the bucket. It returns true if
+		// a noncurrent version was deleted in the process.
+		ArchiveObject(ctx context.Context, bucket, key string) (bool, error)
+
 		// Autopilot returns the autopilot with the given ID. Returns
 		// api.ErrAutopilotNotFound if the autopilot doesn't exist.
 		Autopilot(ctx context.Context, id string) (api.Autopilot, error)
@@ -143,13 +150,21 @@ type (
 		DeleteHostSector(ctx context.Context, hk types.PublicKey, root types.Hash256) (int, error)
 
 		// DeleteObject deletes an object from the database and returns true if
-		// the requested object was actually deleted.
+		// the requested object was actually deleted. Locked objects are not
+		// deleted, api.ErrObjectLocked is returned instead.
 		DeleteObject(ctx context.Context, bucket, key string) (bool, error)
 
 		// DeleteObjects deletes a batch of objects starting with the given
-		// prefix and returns 'true' if any object was deleted.
+		// prefix and returns 'true' if any object was deleted. If any of the
+		// objects is locked, nothing is deleted and api.ErrObjectLocked is
+		// returned.
 		DeleteObjects(ctx context.Context, bucket, prefix string, limit int64) (bool, error)
 
+		// DeleteObjectVersion permanently deletes the version of an object with
+		// the given version id and returns true if a version was deleted.
+		// Locked versions can't be deleted.
+		DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) (bool, error)
+
 		// DeleteSettings deletes the settings with the given key.
 		DeleteSettings(ctx context.Context, key string) error
 
@@ -167,6 +182,11 @@ type (
 		// InsertContract inserts a new contract into the database.
 		InsertContract(ctx context.Context, rev rhpv2.ContractRevision, contractPrice, totalCost types.Currency, startHeight uint64, renewedFrom types.FileContractID, state string) (api.ContractMetadata, error)
 
+		// InsertDeleteMarker inserts a delete marker for the given object if
+		// versioning was ever enabled for the bucket. It returns the version id
+		// of the marker and whether a marker was inserted.
+		InsertDeleteMarker(ctx context.Context, bucket, key string) (string, bool, error)
+
 		// InsertMultipartUpload creates a new multipart upload and returns a
 		// unique upload ID.
 		InsertMultipartUpload(ctx context.Context, bucket, path string, ec object.EncryptionKey, mimeType string, metadata api.ObjectUserMetadata) (string, error)
@@ -183,7 +203,7 @@ type (
 		HostBlocklist(ctx context.Context) ([]string, error)
 
 		// InsertObject inserts a new object into the database.
-		InsertObject(ctx context.Context, bucket, key, contractSet string, o object.Object, mimeType, eTag string, md api.ObjectUserMetadata) error
+		InsertObject(ctx context.Context, bucket, key, contractSet string, o object.Object, opts api.AddObjectOptions) error
 
 		// HostsForScanning returns a list of hosts to scan which haven't been
 		// scanned since at least maxLastScan.
@@ -192,8 +212,9 @@ type (
 		// ListBuckets returns a list of all buckets in the database.
 		ListBuckets(ctx context.Context) ([]api.Bucket, error)
 
-		// ListObjects returns a list of objects from the given bucket.
-		ListObjects(ctx context.Context, bucket, prefix, sortBy, sortDir, marker string, limit int) (api.ObjectsListResponse, error)
+		// ListObjects returns a list of objects from the given bucket. If tags
+		// are provided, only objects that have all of the tags are returned.
+		ListObjects(ctx context.Context, bucket, prefix, sortBy, sortDir, marker string, tags api.ObjectTags, limit int) (api.ObjectsListResponse, error)
 
 		// MakeDirsForPathDeprecated creates all directories for a given
 		// object's path. This method is deprecated and should not be used, it's
@@ -223,9 +244,31 @@ type (
 		// ObjectEntries queries the database for objects in a given dir.
 		ObjectEntries(ctx context.Context, bucket, key, prefix, sortBy, sortDir, marker string, offset, limit int) ([]api.ObjectMetadata, bool, error)
 
+		// ObjectETag returns the etag of an object and locks the object's row
+		// for the remainder of the transaction if the database supports it.
+		ObjectETag(ctx context.Context, bucket, key string) (string, error)
+
+		// ObjectKeys returns the keys of up to 'limit' objects starting with
+		// the given prefix, a negative limit returns all of them.
+		ObjectKeys(ctx context.Context, bucket, prefix string, limit int64) ([]string, error)
+
 		// ObjectMetadata returns an object's metadata.
 		ObjectMetadata(ctx context.Context, bucket, key string) (api.Object, error)
 
+		// ObjectLock returns the retention and legal hold of an object.
+		ObjectLock(ctx context.Context, bucket, key string) (api.ObjectLock, error)
+
+		// ObjectTags returns the tags of an object.
+		ObjectTags(ctx context.Context, bucket, key string) (api.ObjectTags, error)
+
+		// ObjectVersion returns the version of an object with the given version
+		// id.
+		ObjectVersion(ctx context.Context, bucket, key, versionID string) (api.Object, error)
+
+		// ObjectVersions returns all versions of the objects in a bucket,
+		// including delete markers.
+		ObjectVersions(ctx context.Context, bucket, prefix, keyMarker, versionIDMarker string, limit int) (api.ObjectVersionsResponse, error)
+
 		// ObjectsBySlabKey returns all objects that contain a reference to the
 		// slab with the given slabKey.
 		ObjectsBySlabKey(ctx context.Context, bucket string, slabKey object.EncryptionKey) (metadata []api.ObjectMetadata, err error)
@@ -247,7 +290,9 @@ type (
 		ProcessChainUpdate(ctx context.Context, applyFn func(ChainUpdateTx) error) error
 
 		// PrunableContractRoots returns the indices of roots that are not in
-		// the contract.
+		// the contract. Roots of sectors that are still part of a slab are
+		// never prunable, which protects the sectors of locked objects and
+		// object versions since their slices can't be deleted.
 		PrunableContractRoots(ctx context.Context, fcid types.FileContractID, roots []types.Hash256) (indices []uint64, err error)
 
 		// PruneSlabs deletes slabs that are no longer referenced by any slice
@@ -283,7 +328,7 @@ type (
 		// object already exists at the target location or api.ErrObjectNotFound
 		// if the object at keyOld doesn't exist. If force is true, the instead
 		// of returning api.ErrObjectExists, the existing object will be
-		// deleted.
+		// deleted. Locked objects can neither be renamed nor overwritten.
 		RenameObject(ctx context.Context, bucket, keyOld, keyNew string, force bool) error
 
 		// RenameObjects renames all objects in the database with the given
@@ -291,7 +336,7 @@ type (
 		// existing objects with the new prefix. If no object can be renamed,
 		// `api.ErrOBjectNotFound` is returned. If 'force' is false and an
 		// object already exists with the new prefix, `api.ErrObjectExists` is
-		// returned.
+		// returned. Locked objects can neither be renamed nor overwritten.
 		RenameObjects(ctx context.Context, bucket, prefixOld, prefixNew string, force bool) error
 
 		// RenewContract renews the contract in the database. That means the
@@ -318,8 +363,8 @@ type (
 		SearchHosts(ctx context.Context, autopilotID, filterMode, usabilityMode, addressContains string, keyIn []types.PublicKey, offset, limit int) ([]api.Host, error)
 
 		// SearchObjects returns a list of objects that contain the provided
-		// substring.
-		SearchObjects(ctx context.Context, bucket, substring string, offset, limit int) ([]api.ObjectMetadata, error)
+		// substring and have all of the provided tags.
+		SearchObjects(ctx context.Context, bucket, substring string, tags api.ObjectTags, offset, limit int) ([]api.ObjectMetadata, error)
 
 		// UpdateContractSet adds/removes the provided contract ids to/from
 		// the contract set. The contract set is created in the process if
@@ -366,6 +411,17 @@ type (
 		// UpdateHostCheck updates the host check for the given host.
 		UpdateHostCheck(ctx context.Context, autopilot string, hk types.PublicKey, hc api.HostCheck) error
 
+		// UpdateObjectLegalHold places or removes a legal hold on an object.
+		UpdateObjectLegalHold(ctx context.Context, bucket, key string, enabled bool) error
+
+		// UpdateObjectRetention replaces the retention of an object, an
+		// active retention can only be shortened or removed if it's in
+		// governance mode and the governance mode is bypassed.
+		UpdateObjectRetention(ctx context.Context, bucket, key string, retention api.ObjectRetention, bypassGovernance bool) error
+
+		// UpdateObjectTags replaces the tags of an object with the given ones.
+		UpdateObjectTags(ctx context.Context, bucket, key string, tags api.ObjectTags) error
+
 		// UpdatePeerInfo updates the metadata for the specified peer.
 		UpdatePeerInfo(ctx context.Context, addr string, fn func(*syncer.PeerInfo)) error
 
@@ -9,6 +9,7 @@ import (
 	"math"
 	"math/big"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -180,6 +181,82 @@ func ArchiveContract(ctx context.Context, tx sql.Tx, fcid types.FileContractID,
 	return nil
 }
 
+// ArchiveObject moves the current version of an object to the
+// object_versions table if versioning is enabled for the bucket. When
+// versioning is suspended, only versioned objects are archived and any
+// noncurrent null version is deleted since it's about to be replaced. The
+// returned boolean indicates whether a noncurrent version was deleted.
+func ArchiveObject(ctx context.Context, tx sql.Tx, bucket, key string) (bool, error) {
+	bucketID, versioning, err := bucketVersioning(ctx, tx, bucket)
+	if err != nil {
+		return false, err
+	} else if versioning == "" {
+		return false, nil // versioning was never enabled
+	}
+
+	// when versioning is suspended the null version gets replaced
+	var deleted bool
+	if versioning == api.BucketVersioningSuspended {
+		var locked bool
+		err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM object_versions WHERE db_bucket_id = ? AND object_id = ? AND version_id = '' AND "+lockedObjectExpr("")+")", bucketID, key, time.Now().UnixMilli()).
+			Scan(&locked)
+		if err != nil {
+			return false, fmt.Errorf("failed to check null version lock: %w", err)
+		} else if locked {
+			return false, fmt.Errorf("%w: null version of key %v", api.ErrObjectLocked, key)
+		}
+		res, err := tx.Exec(ctx, "DELETE FROM object_versions WHERE db_bucket_id = ? AND object_id = ? AND version_id = ''", bucketID, key)
+		if err != nil {
+			return false, fmt.Errorf("failed to delete null version: %w", err)
+		} else if n, err := res.RowsAffected(); err != nil {
+			return false, fmt.Errorf("failed to get rows affected: %w", err)
+		} else {
+			deleted = n > 0
+		}
+	}
+
+	// fetch the current version
+	var objID int64
+	var versionID string
+	err = tx.QueryRow(ctx, "SELECT id, version_id FROM objects WHERE db_bucket_id = ? AND object_id = ?", bucketID, key).
+		Scan(&objID, &versionID)
+	if errors.Is(err, dsql.ErrNoRows) {
+		return deleted, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to fetch object: %w", err)
+	} else if versioning == api.BucketVersioningSuspended && versionID == "" {
+		return deleted, nil
+	}
+
+	// copy the object to the versions table
+	res, err := tx.Exec(ctx, `INSERT INTO object_versions (created_at, db_bucket_id, object_id, version_id, delete_marker, `+"`key`"+`, health, size, mime_type, etag, checksum_algorithm, checksum, customer_key_fingerprint, lock_mode, lock_retain_until, legal_hold)
+		SELECT created_at, db_bucket_id, object_id, version_id, ?, `+"`key`"+`, health, size, mime_type, etag, checksum_algorithm, checksum, customer_key_fingerprint, lock_mode, lock_retain_until, legal_hold
+		FROM objects
+		WHERE id = ?`, false, objID)
+	if err != nil {
+		return false, fmt.Errorf("failed to archive object: %w", err)
+	}
+	ovID, err := res.LastInsertId()
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch object version id: %w", err)
+	}
+
+	// move over the slices and metadata
+	if _, err := tx.Exec(ctx, "UPDATE slices SET db_object_id = NULL, db_object_version_id = ? WHERE db_object_id = ?", ovID, objID); err != nil {
+		return false, fmt.Errorf("failed to move slices: %w", err)
+	} else if _, err := tx.Exec(ctx, "UPDATE object_user_metadata SET db_object_id = NULL, db_object_version_id = ? WHERE db_object_id = ?", ovID, objID); err != nil {
+		return false, fmt.Errorf("failed to move user metadata: %w", err)
+	} else if _, err := tx.Exec(ctx, "UPDATE object_tags SET db_object_id = NULL, db_object_version_id = ? WHERE db_object_id = ?", ovID, objID); err != nil {
+		return false, fmt.Errorf("failed to move tags: %w", err)
+	}
+
+	// delete the object
+	if _, err := tx.Exec(ctx, "DELETE FROM objects WHERE id = ?", objID); err != nil {
+		return false, fmt.Errorf("failed to delete archived object: %w", err)
+	}
+	return deleted, nil
+}
+
 func Autopilot(ctx context.Context, tx sql.Tx, id string) (api.Autopilot, error) {
 	row := tx.QueryRow(ctx, "SELECT identifier, config, current_period FROM autopilots WHERE identifier = ?", id)
 	ap, err := scanAutopilot(row)
@@ -391,8 +468,8 @@ func CopyObject(ctx context.Context, tx sql.Tx, srcBucket, dstBucket, srcKey, ds
 
 	// helper to fetch metadata
 	fetchMetadata := func(objID int64) (om api.ObjectMetadata, err error) {
-		err = tx.QueryRow(ctx, "SELECT etag, health, created_at, object_id, size, mime_type FROM objects WHERE id = ?", objID).
-			Scan(&om.ETag, &om.Health, (*time.Time)(&om.ModTime), &om.Name, &om.Size, &om.MimeType)
+		err = tx.QueryRow(ctx, "SELECT etag, health, created_at, object_id, size, mime_type, version_id, checksum_algorithm, checksum, customer_key_fingerprint FROM objects WHERE id = ?", objID).
+			Scan(&om.ETag, &om.Health, (*time.Time)(&om.ModTime), &om.Name, &om.Size, &om.MimeType, &om.VersionID, &om.ChecksumAlgorithm, &om.Checksum, &om.CustomerKeyFingerprint)
 		if err != nil {
 			return api.ObjectMetadata{}, fmt.Errorf("failed to fetch new object: %w", err)
 		}
@@ -401,8 +478,10 @@ func CopyObject(ctx context.Context, tx sql.Tx, srcBucket, dstBucket, srcKey, ds
 
 	if srcBucket == dstBucket && srcKey == dstKey {
 		// No copying is happening. We just update the metadata on the src
-		// object.
-		if _, err := tx.Exec(ctx, "UPDATE objects SET mime_type = ? WHERE id = ?", mimeType, srcObjID); err != nil {
+		// object, unless it's locked.
+		if err := CheckObjectLock(ctx, tx, srcBucket, srcKey); err != nil {
+			return api.ObjectMetadata{}, err
+		} else if _, err := tx.Exec(ctx, "UPDATE objects SET mime_type = ? WHERE id = ?", mimeType, srcObjID); err != nil {
 			return api.ObjectMetadata{}, fmt.Errorf("failed to update mime type: %w", err)
 		} else if err := UpdateMetadata(ctx, tx, srcObjID, metadata); err != nil {
 			return api.ObjectMetadata{}, fmt.Errorf("failed to update metadata: %w", err)
@@ -419,11 +498,17 @@ func CopyObject(ctx context.Context, tx sql.Tx, srcBucket, dstBucket, srcKey, ds
 		return api.ObjectMetadata{}, fmt.Errorf("failed to fetch dest bucket id: %w", err)
 	}
 
+	// generate a version id for the copy
+	versionID, err := newObjectVersionID(ctx, tx, dstBID)
+	if err != nil {
+		return api.ObjectMetadata{}, err
+	}
+
 	// copy object
-	res, err := tx.Exec(ctx, `INSERT INTO objects (created_at, object_id, db_bucket_id,`+"`key`"+`, size, mime_type, etag)
-						SELECT ?, ?, ?, `+"`key`"+`, size, ?, etag
+	res, err := tx.Exec(ctx, `INSERT INTO objects (created_at, object_id, db_bucket_id,`+"`key`"+`, size, mime_type, etag, version_id, checksum_algorithm, checksum, customer_key_fingerprint)
+						SELECT ?, ?, ?, `+"`key`"+`, size, ?, etag, ?, checksum_algorithm, checksum, customer_key_fingerprint
 						FROM objects
-						WHERE id = ?`, time.Now(), dstKey, dstBID, mimeType, srcObjID)
+						WHERE id = ?`, time.Now(), dstKey, dstBID, mimeType, versionID, srcObjID)
 	if err != nil {
 		return api.ObjectMetadata{}, fmt.Errorf("failed to insert object: %w", err)
 	}
@@ -446,6 +531,12 @@ func CopyObject(ctx context.Context, tx sql.Tx, srcBucket, dstBucket, srcKey, ds
 		return api.ObjectMetadata{}, fmt.Errorf("failed to insert metadata: %w", err)
 	}
 
+	// copy tags
+	_, err = tx.Exec(ctx, "INSERT INTO object_tags (created_at, db_object_id, `key`, value) SELECT ?, ?, `key`, value FROM object_tags WHERE db_object_id = ?", time.Now(), dstObjID, srcObjID)
+	if err != nil {
+		return api.ObjectMetadata{}, fmt.Errorf("failed to copy tags: %w", err)
+	}
+
 	// fetch copied object
 	return fetchMetadata(dstObjID)
 }
@@ -459,7 +550,7 @@ func DeleteBucket(ctx context.Context, tx sql.Tx, bucket string) error {
 		return fmt.Errorf("failed to fetch bucket id: %w", err)
 	}
 	var empty bool
-	err = tx.QueryRow(ctx, "SELECT NOT EXISTS(SELECT 1 FROM objects WHERE db_bucket_id = ?)", id).Scan(&empty)
+	err = tx.QueryRow(ctx, "SELECT NOT EXISTS(SELECT 1 FROM objects WHERE db_bucket_id = ?) AND NOT EXISTS(SELECT 1 FROM object_versions WHERE db_bucket_id = ?)", id, id).Scan(&empty)
 	if err != nil {
 		return fmt.Errorf("failed to check if bucket is empty: %w", err)
 	} else if !empty {
@@ -548,6 +639,164 @@ func DeleteMetadata(ctx context.Context, tx sql.Tx, objID int64) error {
 	return err
 }
 
+func ObjectTags(ctx context.Context, tx sql.Tx, bucket, key string) (api.ObjectTags, error) {
+	objID, err := objectID(ctx, tx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return objectTags(ctx, tx, "db_object_id", objID)
+}
+
+// ObjectLock returns the retention and legal hold of the object with the given
+// key.
+func ObjectLock(ctx context.Context, tx sql.Tx, bucket, key string) (api.ObjectLock, error) {
+	_, lock, err := objectLock(ctx, tx, bucket, key)
+	return lock, err
+}
+
+// CheckMultipartPartOffset returns api.ErrUploadOffsetMismatch unless the part
+// with the given number is the next part of the multipart upload and the parts
+// before it add up to the given offset.
+func CheckMultipartPartOffset(ctx context.Context, tx sql.Tx, muID int64, partNumber int, offset int64) error {
+	var n, size int64
+	err := tx.QueryRow(ctx, "SELECT COUNT(*), COALESCE(SUM(size), 0) FROM multipart_parts WHERE db_multipart_upload_id = ?", muID).
+		Scan(&n, &size)
+	if err != nil {
+		return fmt.Errorf("failed to fetch multipart parts: %w", err)
+	} else if n != int64(partNumber-1) || size != offset {
+		return fmt.Errorf("%w: expected part %d at offset %d, got part %d at offset %d", api.ErrUploadOffsetMismatch, n+1, size, partNumber, offset)
+	}
+	return nil
+}
+
+// CheckObjectLock returns api.ErrObjectLocked if the current version of the
+// object with the given key is locked.
+func CheckObjectLock(ctx context.Context, tx sql.Tx, bucket, key string) error {
+	var locked bool
+	err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM objects o
+			INNER JOIN buckets b ON b.id = o.db_bucket_id
+			WHERE o.object_id = ? AND b.name = ? AND %s
+		)
+	`, lockedObjectExpr("o")), key, bucket, time.Now().UnixMilli()).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("failed to check object lock: %w", err)
+	} else if locked {
+		return fmt.Errorf("%w: key %v", api.ErrObjectLocked, key)
+	}
+	return nil
+}
+
+// CheckObjectsLock returns api.ErrObjectLocked if any of the objects with the
+// given prefix is locked.
+func CheckObjectsLock(ctx context.Context, tx sql.Tx, bucket, prefix string) error {
+	var locked bool
+	err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM objects o
+			INNER JOIN buckets b ON b.id = o.db_bucket_id
+			WHERE o.object_id LIKE ? AND SUBSTR(o.object_id, 1, ?) = ? AND b.name = ? AND %s
+		)
+	`, lockedObjectExpr("o")), prefix+"%", utf8.RuneCountInString(prefix), prefix, bucket, time.Now().UnixMilli()).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("failed to check objects lock: %w", err)
+	} else if locked {
+		return fmt.Errorf("%w: prefix %v", api.ErrObjectLocked, prefix)
+	}
+	return nil
+}
+
+// CheckRenameObjectsLock returns api.ErrObjectLocked if renaming the objects
+// with the old prefix to the new prefix would overwrite a locked object.
+func CheckRenameObjectsLock(ctx context.Context, tx sql.Tx, bucket, prefixOld, prefixNew string) error {
+	var locked bool
+	err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM objects dst
+			INNER JOIN buckets b ON b.id = dst.db_bucket_id
+			INNER JOIN objects src ON src.db_bucket_id = dst.db_bucket_id AND SUBSTR(src.object_id, ?) = SUBSTR(dst.object_id, ?)
+			WHERE
+				b.name = ? AND
+				src.object_id LIKE ? AND SUBSTR(src.object_id, 1, ?) = ? AND
+				dst.object_id LIKE ? AND SUBSTR(dst.object_id, 1, ?) = ? AND
+				%s
+		)
+	`, lockedObjectExpr("dst")),
+		utf8.RuneCountInString(prefixOld)+1, utf8.RuneCountInString(prefixNew)+1,
+		bucket,
+		prefixOld+"%", utf8.RuneCountInString(prefixOld), prefixOld,
+		prefixNew+"%", utf8.RuneCountInString(prefixNew), prefixNew,
+		time.Now().UnixMilli(),
+	).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("failed to check objects lock: %w", err)
+	} else if locked {
+		return fmt.Errorf("%w: prefix %v", api.ErrObjectLocked, prefixNew)
+	}
+	return nil
+}
+
+// DeleteObjectVersion permanently deletes the object version with the given
+// id. If the current version was deleted, the most recent noncurrent version
+// becomes the current one. The returned boolean indicates whether a version
+// was deleted.
+func DeleteObjectVersion(ctx context.Context, tx sql.Tx, bucket, key, versionID string) (bool, error) {
+	if versionID == api.ObjectVersionIDNull {
+		versionID = ""
+	}
+
+	bucketID, _, err := bucketVersioning(ctx, tx, bucket)
+	if err != nil {
+		return false, err
+	}
+
+	// locked versions can't be deleted
+	var locked bool
+	now := time.Now().UnixMilli()
+	err = tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT
+			EXISTS (SELECT 1 FROM objects WHERE db_bucket_id = ? AND object_id = ? AND version_id = ? AND %s) OR
+			EXISTS (SELECT 1 FROM object_versions WHERE db_bucket_id = ? AND object_id = ? AND version_id = ? AND %s)
+	`, lockedObjectExpr(""), lockedObjectExpr("")), bucketID, key, versionID, now, bucketID, key, versionID, now).Scan(&locked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check object version lock: %w", err)
+	} else if locked {
+		return false, fmt.Errorf("%w: key %v, version %v", api.ErrObjectLocked, key, versionID)
+	}
+
+	// try deleting the current version first
+	res, err := tx.Exec(ctx, "DELETE FROM objects WHERE db_bucket_id = ? AND object_id = ? AND version_id = ?", bucketID, key, versionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete object: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	// fall back to deleting a noncurrent version
+	if n == 0 {
+		res, err = tx.Exec(ctx, "DELETE FROM object_versions WHERE db_bucket_id = ? AND object_id = ? AND version_id = ?", bucketID, key, versionID)
+		if err != nil {
+			return false, fmt.Errorf("failed to delete object version: %w", err)
+		} else if n, err = res.RowsAffected(); err != nil {
+			return false, fmt.Errorf("failed to get rows affected: %w", err)
+		} else if n == 0 {
+			return false, nil
+		}
+	}
+
+	// restore the latest noncurrent version if necessary
+	if err := promoteObjectVersion(ctx, tx, bucketID, key); err != nil {
+		return false, fmt.Errorf("failed to promote object version: %w", err)
+	}
+	return true, nil
+}
+
 func DeleteSettings(ctx context.Context, tx sql.Tx, key string) error {
 	if _, err := tx.Exec(ctx, "DELETE FROM settings WHERE `key` = ?", key); err != nil {
 		return fmt.Errorf("failed to delete setting '%s': %w", key, err)
@@ -556,7 +805,7 @@ func DeleteSettings(ctx context.Context, tx sql.Tx, key string) error {
 }
 
 func DeleteWebhook(ctx context.Context, tx sql.Tx, wh webhooks.Webhook) error {
-	res, err := tx.Exec(ctx, "DELETE FROM webhooks WHERE module = ? AND event = ? AND url = ?", wh.Module, wh.Event, wh.URL)
+	res, err := tx.Exec(ctx, "DELETE FROM webhooks WHERE module = ? AND event = ? AND url = ? AND bucket = ? AND prefix = ?", wh.Module, wh.Event, wh.URL, wh.Bucket, wh.Prefix)
 	if err != nil {
 		return fmt.Errorf("failed to delete webhook: %w", err)
 	} else if n, err := res.RowsAffected(); err != nil {
@@ -745,6 +994,29 @@ func InsertContract(ctx context.Context, tx sql.Tx, rev rhpv2.ContractRevision,
 	return contracts[0], nil
 }
 
+// InsertDeleteMarker inserts a delete marker for the given key if versioning
+// was ever enabled for the bucket. It returns the version id of the marker and
+// whether a marker was inserted.
+func InsertDeleteMarker(ctx context.Context, tx sql.Tx, bucket, key string) (string, bool, error) {
+	bucketID, versioning, err := bucketVersioning(ctx, tx, bucket)
+	if err != nil {
+		return "", false, err
+	} else if versioning == "" {
+		return "", false, nil
+	}
+
+	versionID, err := newObjectVersionID(ctx, tx, bucketID)
+	if err != nil {
+		return "", false, err
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO object_versions (created_at, db_bucket_id, object_id, version_id, delete_marker, size, mime_type, etag)
+		VALUES (?, ?, ?, ?, ?, 0, '', '')`, time.Now(), bucketID, key, versionID, true)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to insert delete marker: %w", err)
+	}
+	return versionID, true, nil
+}
+
 func InsertMetadata(ctx context.Context, tx sql.Tx, objID, muID *int64, md api.ObjectUserMetadata) error {
 	if len(md) == 0 {
 		return nil
@@ -797,16 +1069,31 @@ func InsertMultipartUpload(ctx context.Context, tx sql.Tx, bucket, key string, e
 	return uploadID, nil
 }
 
-func InsertObject(ctx context.Context, tx sql.Tx, key string, bucketID, size int64, ec object.EncryptionKey, mimeType, eTag string) (int64, error) {
-	res, err := tx.Exec(ctx, `INSERT INTO objects (created_at, object_id, db_bucket_id, `+"`key`"+`, size, mime_type, etag)
-						VALUES (?, ?, ?, ?, ?, ?, ?)`,
+func InsertObject(ctx context.Context, tx sql.Tx, key string, bucketID, size int64, ec object.EncryptionKey, opts api.AddObjectOptions) (int64, error) {
+	versionID, err := newObjectVersionID(ctx, tx, bucketID)
+	if err != nil {
+		return 0, err
+	}
+	var retainUntil int64
+	if !opts.Lock.Retention.RetainUntil.IsZero() {
+		retainUntil = opts.Lock.Retention.RetainUntil.Std().UnixMilli()
+	}
+	res, err := tx.Exec(ctx, `INSERT INTO objects (created_at, object_id, db_bucket_id, `+"`key`"+`, size, mime_type, etag, version_id, checksum_algorithm, checksum, customer_key_fingerprint, lock_mode, lock_retain_until, legal_hold)
+						VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		time.Now(),
 		key,
 		bucketID,
 		EncryptionKey(ec),
 		size,
-		mimeType,
-		eTag)
+		opts.MimeType,
+		opts.ETag,
+		versionID,
+		opts.ChecksumAlgorithm,
+		opts.Checksum,
+		opts.CustomerKeyFingerprint,
+		opts.Lock.Retention.Mode,
+		retainUntil,
+		opts.Lock.LegalHold)
 	if err != nil {
 		return 0, err
 	}
@@ -877,6 +1164,66 @@ func LoadSlabBuffers(ctx context.Context, db *sql.DB) (bufferedSlabs []LoadedSla
 	return
 }
 
+// UpdateObjectTags replaces the tags of the object with the given key.
+func UpdateObjectTags(ctx context.Context, tx sql.Tx, bucket, key string, tags api.ObjectTags) error {
+	objID, err := objectID(ctx, tx, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM object_tags WHERE db_object_id = ?", objID); err != nil {
+		return fmt.Errorf("failed to delete tags: %w", err)
+	} else if len(tags) == 0 {
+		return nil
+	}
+
+	insertTagStmt, err := tx.Prepare(ctx, "INSERT INTO object_tags (created_at, db_object_id, `key`, value) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement to insert tags: %w", err)
+	}
+	defer insertTagStmt.Close()
+
+	for k, v := range tags {
+		if _, err := insertTagStmt.Exec(ctx, time.Now(), objID, k, v); err != nil {
+			return fmt.Errorf("failed to insert tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// UpdateObjectLegalHold places or removes a legal hold on the object with the
+// given key.
+func UpdateObjectLegalHold(ctx context.Context, tx sql.Tx, bucket, key string, enabled bool) error {
+	objID, err := objectID(ctx, tx, bucket, key)
+	if err != nil {
+		return err
+	} else if _, err := tx.Exec(ctx, "UPDATE objects SET legal_hold = ? WHERE id = ?", enabled, objID); err != nil {
+		return fmt.Errorf("failed to update legal hold: %w", err)
+	}
+	return nil
+}
+
+// UpdateObjectRetention replaces the retention of the object with the given
+// key, an active retention can only be shortened or removed if it's in
+// governance mode and the governance mode is bypassed.
+func UpdateObjectRetention(ctx context.Context, tx sql.Tx, bucket, key string, retention api.ObjectRetention, bypassGovernance bool) error {
+	objID, lock, err := objectLock(ctx, tx, bucket, key)
+	if err != nil {
+		return err
+	} else if err := lock.Retention.CheckUpdate(retention, bypassGovernance, time.Now()); err != nil {
+		return err
+	}
+
+	var retainUntil int64
+	if !retention.RetainUntil.IsZero() {
+		retainUntil = retention.RetainUntil.Std().UnixMilli()
+	}
+	if _, err := tx.Exec(ctx, "UPDATE objects SET lock_mode = ?, lock_retain_until = ? WHERE id = ?", retention.Mode, retainUntil, objID); err != nil {
+		return fmt.Errorf("failed to update retention: %w", err)
+	}
+	return nil
+}
+
 func UpdateMetadata(ctx context.Context, tx sql.Tx, objID int64, md api.ObjectUserMetadata) error {
 	if err := DeleteMetadata(ctx, tx, objID); err != nil {
 		return err
@@ -886,6 +1233,10 @@ func UpdateMetadata(ctx context.Context, tx sql.Tx, objID int64, md api.ObjectUs
 	return nil
 }
 
+// PrepareSlabHealth computes the health of up to 'limit' slabs whose health
+// expired and stores it in a temporary table. A slab's health is computed
+// against the min and total shards stored with the slab, i.e. the redundancy
+// of the bucket it was uploaded to rather than the default redundancy.
 func PrepareSlabHealth(ctx context.Context, tx sql.Tx, limit int64, now time.Time) error {
 	_, err := tx.Exec(ctx, "DROP TABLE IF EXISTS slabs_health")
 	if err != nil {
@@ -1012,7 +1363,7 @@ func orderByObject(sortBy, sortDir string) (orderByExprs []string, _ error) {
 	return orderByExprs, nil
 }
 
-func ListObjects(ctx context.Context, tx Tx, bucket, prefix, sortBy, sortDir, marker string, limit int) (api.ObjectsListResponse, error) {
+func ListObjects(ctx context.Context, tx Tx, bucket, prefix, sortBy, sortDir, marker string, tags api.ObjectTags, limit int) (api.ObjectsListResponse, error) {
 	// fetch one more to see if there are more entries
 	if limit <= -1 {
 		limit = math.MaxInt
@@ -1038,6 +1389,11 @@ func ListObjects(ctx context.Context, tx Tx, bucket, prefix, sortBy, sortDir, ma
 		whereArgs = append(whereArgs, prefix+"%", utf8.RuneCountInString(prefix), prefix)
 	}
 
+	// apply tags
+	tagExprs, tagArgs := whereObjectTags(tags)
+	whereExprs = append(whereExprs, tagExprs...)
+	whereArgs = append(whereArgs, tagArgs...)
+
 	// apply sorting
 	orderByExprs, err := orderByObject(sortBy, sortDir)
 	if err != nil {
@@ -1514,29 +1870,81 @@ func ObjectEntries(ctx context.Context, tx Tx, bucket, path, prefix, sortBy, sor
 	return objects, hasMore, nil
 }
 
-func ObjectMetadata(ctx context.Context, tx Tx, bucket, key string) (api.Object, error) {
-	// fetch object id
-	var objID int64
-	if err := tx.QueryRow(ctx, `
-		SELECT o.id
+// ObjectETag returns the etag of the object with the given key. If forUpdate
+// is set, the object's row is locked until the end of the transaction which
+// allows for evaluating preconditions before the object is overwritten.
+func ObjectETag(ctx context.Context, tx sql.Tx, bucket, key string, forUpdate bool) (string, error) {
+	query := `
+		SELECT COALESCE(o.etag, '')
 		FROM objects o
 		INNER JOIN buckets b ON b.id = o.db_bucket_id
-		WHERE o.object_id = ? AND b.name = ?
-	`, key, bucket).Scan(&objID); errors.Is(err, dsql.ErrNoRows) {
-		return api.Object{}, api.ErrObjectNotFound
+		WHERE o.object_id = ? AND b.name = ?`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	var eTag string
+	if err := tx.QueryRow(ctx, query, key, bucket).Scan(&eTag); errors.Is(err, dsql.ErrNoRows) {
+		return "", api.ErrObjectNotFound
 	} else if err != nil {
-		return api.Object{}, fmt.Errorf("failed to fetch object id: %w", err)
+		return "", fmt.Errorf("failed to fetch object etag: %w", err)
+	}
+	return eTag, nil
+}
+
+// ObjectKeys returns the keys of up to 'limit' objects starting with the given
+// prefix, a negative limit returns all of them.
+func ObjectKeys(ctx context.Context, tx sql.Tx, bucket, prefix string, limit int64) ([]string, error) {
+	query := `
+		SELECT o.object_id
+		FROM objects o
+		INNER JOIN buckets b ON b.id = o.db_bucket_id
+		WHERE o.object_id LIKE ? AND SUBSTR(o.object_id, 1, ?) = ? AND b.name = ?
+		ORDER BY o.object_id`
+	args := []any{prefix + "%", utf8.RuneCountInString(prefix), prefix, bucket}
+	if limit >= 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch object keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan object key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func ObjectMetadata(ctx context.Context, tx Tx, bucket, key string) (api.Object, error) {
+	// fetch object id
+	objID, err := objectID(ctx, tx, bucket, key)
+	if err != nil {
+		return api.Object{}, err
 	}
 
 	// fetch metadata
+	var versionID, checksumAlgorithm, checksum, fingerprint string
 	om, err := tx.ScanObjectMetadata(tx.QueryRow(ctx, fmt.Sprintf(`
-		SELECT %s
+		SELECT %s, o.version_id, o.checksum_algorithm, o.checksum, o.customer_key_fingerprint
 		FROM objects o
 		WHERE o.id = ?
-	`, tx.SelectObjectMetadataExpr()), objID))
+	`, tx.SelectObjectMetadataExpr()), objID), &versionID, &checksumAlgorithm, &checksum, &fingerprint)
 	if err != nil {
 		return api.Object{}, fmt.Errorf("failed to fetch object metadata: %w", err)
 	}
+	om.VersionID = versionID
+	om.ChecksumAlgorithm = checksumAlgorithm
+	om.Checksum = checksum
+	om.CustomerKeyFingerprint = fingerprint
 
 	// fetch user metadata
 	rows, err := tx.Query(ctx, `
@@ -1560,13 +1968,211 @@ func ObjectMetadata(ctx context.Context, tx Tx, bucket, key string) (api.Object,
 		metadata[key] = value
 	}
 
+	// fetch tags
+	tags, err := objectTags(ctx, tx, "db_object_id", objID)
+	if err != nil {
+		return api.Object{}, err
+	}
+
 	return api.Object{
 		Metadata:       metadata,
+		Tags:           tags,
 		ObjectMetadata: om,
 		Object:         nil, // only return metadata
 	}, nil
 }
 
+// ObjectVersion returns the object version with the given id, which can be
+// either the current version or a noncurrent one.
+func ObjectVersion(ctx context.Context, tx Tx, bucket, key, versionID string) (api.Object, error) {
+	if versionID == api.ObjectVersionIDNull {
+		versionID = ""
+	}
+
+	// check whether the requested version is the current one
+	var isCurrent bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM objects o
+			INNER JOIN buckets b ON o.db_bucket_id = b.id
+			WHERE o.object_id = ? AND b.name = ? AND o.version_id = ?
+		)
+	`, key, bucket, versionID).Scan(&isCurrent)
+	if err != nil {
+		return api.Object{}, fmt.Errorf("failed to check for current version: %w", err)
+	} else if isCurrent {
+		return Object(ctx, tx, bucket, key)
+	}
+
+	// fetch the noncurrent version
+	var ovID int64
+	var deleteMarker bool
+	err = tx.QueryRow(ctx, `
+		SELECT ov.id, ov.delete_marker
+		FROM object_versions ov
+		INNER JOIN buckets b ON ov.db_bucket_id = b.id
+		WHERE ov.object_id = ? AND b.name = ? AND ov.version_id = ?
+	`, key, bucket, versionID).Scan(&ovID, &deleteMarker)
+	if errors.Is(err, dsql.ErrNoRows) {
+		return api.Object{}, api.ErrObjectVersionNotFound
+	} else if err != nil {
+		return api.Object{}, fmt.Errorf("failed to fetch object version: %w", err)
+	} else if deleteMarker {
+		return api.Object{}, api.ErrObjectVersionIsDeleteMarker
+	}
+
+	var ec object.EncryptionKey
+	var checksumAlgorithm, checksum, fingerprint string
+	om, err := tx.ScanObjectMetadata(tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT %s, o.key, o.checksum_algorithm, o.checksum, o.customer_key_fingerprint
+		FROM object_versions o
+		WHERE o.id = ?
+	`, tx.SelectObjectMetadataExpr()), ovID), (*EncryptionKey)(&ec), &checksumAlgorithm, &checksum, &fingerprint)
+	if err != nil {
+		return api.Object{}, fmt.Errorf("failed to fetch object version metadata: %w", err)
+	}
+	om.VersionID = versionID
+	om.ChecksumAlgorithm = checksumAlgorithm
+	om.Checksum = checksum
+	om.CustomerKeyFingerprint = fingerprint
+
+	oum, err := objectUserMetadata(ctx, tx, "db_object_version_id", ovID)
+	if err != nil {
+		return api.Object{}, err
+	}
+	tags, err := objectTags(ctx, tx, "db_object_version_id", ovID)
+	if err != nil {
+		return api.Object{}, err
+	}
+	slabSlices, err := objectSlabSlices(ctx, tx, "db_object_version_id", ovID)
+	if err != nil {
+		return api.Object{}, err
+	}
+	return api.Object{
+		Metadata:       oum,
+		Tags:           tags,
+		ObjectMetadata: om,
+		Object: &object.Object{
+			Key:   ec,
+			Slabs: slabSlices,
+		},
+	}, nil
+}
+
+// ObjectVersions lists all versions of objects within a bucket, including
+// delete markers. Versions are sorted by key and within a key from newest to
+// oldest.
+func ObjectVersions(ctx context.Context, tx Tx, bucket, prefix, keyMarker, versionIDMarker string, limit int) (api.ObjectVersionsResponse, error) {
+	// fetch one more to see if there are more entries
+	if limit <= -1 {
+		limit = math.MaxInt
+	} else if limit != math.MaxInt {
+		limit++
+	}
+
+	// fetch bucket id
+	var bucketID int64
+	err := tx.QueryRow(ctx, "SELECT id FROM buckets WHERE name = ?", bucket).Scan(&bucketID)
+	if errors.Is(err, dsql.ErrNoRows) {
+		return api.ObjectVersionsResponse{}, api.ErrBucketNotFound
+	} else if err != nil {
+		return api.ObjectVersionsResponse{}, fmt.Errorf("failed to fetch bucket id: %w", err)
+	}
+
+	// apply prefix
+	var prefixExpr string
+	var prefixArgs []any
+	if prefix != "" {
+		prefixExpr = "AND object_id LIKE ? AND SUBSTR(object_id, 1, ?) = ?"
+		prefixArgs = []any{prefix + "%", utf8.RuneCountInString(prefix), prefix}
+	}
+	args := []any{bucketID}
+	args = append(args, prefixArgs...)
+	args = append(args, bucketID)
+	args = append(args, prefixArgs...)
+
+	// apply marker, the current version of a key always comes first, followed
+	// by the noncurrent versions in reverse order of their creation
+	var markerExpr string
+	if keyMarker != "" && versionIDMarker == "" {
+		markerExpr = "WHERE o.object_id > ?"
+		args = append(args, keyMarker)
+	} else if keyMarker != "" {
+		if versionIDMarker == api.ObjectVersionIDNull {
+			versionIDMarker = ""
+		}
+		var latest, seq int64
+		err := tx.QueryRow(ctx, "SELECT 1, 0 FROM objects WHERE db_bucket_id = ? AND object_id = ? AND version_id = ?", bucketID, keyMarker, versionIDMarker).
+			Scan(&latest, &seq)
+		if errors.Is(err, dsql.ErrNoRows) {
+			err = tx.QueryRow(ctx, "SELECT 0, id FROM object_versions WHERE db_bucket_id = ? AND object_id = ? AND version_id = ?", bucketID, keyMarker, versionIDMarker).
+				Scan(&latest, &seq)
+		}
+		if errors.Is(err, dsql.ErrNoRows) {
+			return api.ObjectVersionsResponse{}, api.ErrMarkerNotFound
+		} else if err != nil {
+			return api.ObjectVersionsResponse{}, fmt.Errorf("failed to fetch marker: %w", err)
+		}
+		markerExpr = "WHERE o.object_id > ? OR (o.object_id = ? AND (o.latest < ? OR (o.latest = ? AND o.seq < ?)))"
+		args = append(args, keyMarker, keyMarker, latest, latest, seq)
+	}
+	args = append(args, limit)
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT %s, o.version_id, o.delete_marker
+		FROM (
+			SELECT object_id, size, health, mime_type, created_at, etag, version_id, 0 AS delete_marker, 1 AS latest, 0 AS seq
+			FROM objects
+			WHERE db_bucket_id = ? %s
+			UNION ALL
+			SELECT object_id, size, health, mime_type, created_at, etag, version_id, delete_marker, 0 AS latest, id AS seq
+			FROM object_versions
+			WHERE db_bucket_id = ? %s
+		) AS o
+		%s
+		ORDER BY o.object_id ASC, o.latest DESC, o.seq DESC
+		LIMIT ?
+	`, tx.SelectObjectMetadataExpr(), prefixExpr, prefixExpr, markerExpr), args...)
+	if err != nil {
+		return api.ObjectVersionsResponse{}, fmt.Errorf("failed to fetch object versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []api.ObjectVersion
+	for rows.Next() {
+		var v api.ObjectVersion
+		v.ObjectMetadata, err = tx.ScanObjectMetadata(rows, &v.VersionID, &v.IsDeleteMarker)
+		if err != nil {
+			return api.ObjectVersionsResponse{}, fmt.Errorf("failed to scan object version: %w", err)
+		}
+
+		// the first version of every key is the latest one, unless we are
+		// continuing a key from the previous page
+		if len(versions) == 0 {
+			v.IsLatest = v.Name != keyMarker
+		} else {
+			v.IsLatest = versions[len(versions)-1].Name != v.Name
+		}
+		versions = append(versions, v)
+	}
+
+	var resp api.ObjectVersionsResponse
+	if len(versions) == limit {
+		versions = versions[:len(versions)-1]
+		if len(versions) > 0 {
+			resp.HasMore = true
+			resp.NextKeyMarker = versions[len(versions)-1].Name
+			resp.NextVersionIDMarker = versions[len(versions)-1].VersionID
+			if resp.NextVersionIDMarker == "" {
+				resp.NextVersionIDMarker = api.ObjectVersionIDNull
+			}
+		}
+	}
+	resp.Versions = versions
+	return resp, nil
+}
+
 func ObjectsStats(ctx context.Context, tx sql.Tx, opts api.ObjectsStatsOpts) (api.ObjectsStatsResponse, error) {
 	var args []any
 	var bucketExpr string
@@ -1608,6 +2214,20 @@ func ObjectsStats(ctx context.Context, tx sql.Tx, opts api.ObjectsStatsOpts) (ap
 		return api.ObjectsStatsResponse{}, fmt.Errorf("failed to fetch multipart upload part stats: %w", err)
 	}
 
+	// noncurrent version stats
+	versionsExpr := "WHERE delete_marker = ?"
+	versionsArgs := []any{false}
+	if opts.Bucket != "" {
+		versionsExpr += " AND db_bucket_id = ?"
+		versionsArgs = append(versionsArgs, bucketID)
+	}
+	var numNoncurrentVersions, totalNoncurrentVersionsSize uint64
+	err = tx.QueryRow(ctx, "SELECT COUNT(*), COALESCE(SUM(size), 0) FROM object_versions "+versionsExpr, versionsArgs...).
+		Scan(&numNoncurrentVersions, &totalNoncurrentVersionsSize)
+	if err != nil {
+		return api.ObjectsStatsResponse{}, fmt.Errorf("failed to fetch noncurrent version stats: %w", err)
+	}
+
 	// total sectors
 	var whereExpr string
 	var whereArgs []any
@@ -1643,6 +2263,9 @@ func ObjectsStats(ctx context.Context, tx sql.Tx, opts api.ObjectsStatsOpts) (ap
 		TotalObjectsSize:           totalObjectsSize,
 		TotalSectorsSize:           totalSectors * rhpv2.SectorSize,
 		TotalUploadedSize:          totalUploaded,
+
+		NumNoncurrentVersions:       numNoncurrentVersions,
+		TotalNoncurrentVersionsSize: totalNoncurrentVersionsSize,
 	}, nil
 }
 
@@ -2420,7 +3043,7 @@ func UpdatePeerInfo(ctx context.Context, tx sql.Tx, addr string, fn func(*syncer
 }
 
 func Webhooks(ctx context.Context, tx sql.Tx) ([]webhooks.Webhook, error) {
-	rows, err := tx.Query(ctx, "SELECT module, event, url, headers FROM webhooks")
+	rows, err := tx.Query(ctx, "SELECT module, event, url, headers, bucket, prefix FROM webhooks")
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch webhooks: %w", err)
 	}
@@ -2430,7 +3053,7 @@ func Webhooks(ctx context.Context, tx sql.Tx) ([]webhooks.Webhook, error) {
 	for rows.Next() {
 		var webhook webhooks.Webhook
 		var headers string
-		if err := rows.Scan(&webhook.Module, &webhook.Event, &webhook.URL, &headers); err != nil {
+		if err := rows.Scan(&webhook.Module, &webhook.Event, &webhook.URL, &headers, &webhook.Bucket, &webhook.Prefix); err != nil {
 			return nil, fmt.Errorf("failed to scan webhook: %w", err)
 		} else if err := json.Unmarshal([]byte(headers), &webhook.Headers); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal headers: %w", err)
@@ -2503,6 +3126,90 @@ func copyContractToArchive(ctx context.Context, tx sql.Tx, fcid types.FileContra
 	return err
 }
 
+func bucketVersioning(ctx context.Context, tx sql.Tx, bucket string) (int64, string, error) {
+	var bucketID int64
+	var policy string
+	err := tx.QueryRow(ctx, "SELECT id, COALESCE(policy, '{}') FROM buckets WHERE name = ?", bucket).
+		Scan(&bucketID, &policy)
+	if errors.Is(err, dsql.ErrNoRows) {
+		return 0, "", api.ErrBucketNotFound
+	} else if err != nil {
+		return 0, "", fmt.Errorf("failed to fetch bucket: %w", err)
+	}
+	var bp api.BucketPolicy
+	if err := json.Unmarshal([]byte(policy), &bp); err != nil {
+		return 0, "", fmt.Errorf("failed to unmarshal bucket policy: %w", err)
+	}
+	return bucketID, bp.Versioning, nil
+}
+
+// newObjectVersionID returns the version id for a new object in the given
+// bucket, objects only receive a version id if versioning is enabled.
+func newObjectVersionID(ctx context.Context, tx sql.Tx, bucketID int64) (string, error) {
+	var policy string
+	err := tx.QueryRow(ctx, "SELECT COALESCE(policy, '{}') FROM buckets WHERE id = ?", bucketID).Scan(&policy)
+	if errors.Is(err, dsql.ErrNoRows) {
+		return "", api.ErrBucketNotFound
+	} else if err != nil {
+		return "", fmt.Errorf("failed to fetch bucket policy: %w", err)
+	}
+	var bp api.BucketPolicy
+	if err := json.Unmarshal([]byte(policy), &bp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal bucket policy: %w", err)
+	} else if !bp.VersioningEnabled() {
+		return "", nil
+	}
+	entropy := frand.Entropy128()
+	return hex.EncodeToString(entropy[:]), nil
+}
+
+// promoteObjectVersion turns the most recent noncurrent version of an object
+// into the current version if the object doesn't have a current version and
+// the most recent version isn't a delete marker.
+func promoteObjectVersion(ctx context.Context, tx sql.Tx, bucketID int64, key string) error {
+	var exists bool
+	err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM objects WHERE db_bucket_id = ? AND object_id = ?)", bucketID, key).
+		Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check for current version: %w", err)
+	} else if exists {
+		return nil
+	}
+
+	var ovID int64
+	var deleteMarker bool
+	err = tx.QueryRow(ctx, "SELECT id, delete_marker FROM object_versions WHERE db_bucket_id = ? AND object_id = ? ORDER BY id DESC LIMIT 1", bucketID, key).
+		Scan(&ovID, &deleteMarker)
+	if errors.Is(err, dsql.ErrNoRows) || (err == nil && deleteMarker) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch latest version: %w", err)
+	}
+
+	res, err := tx.Exec(ctx, `INSERT INTO objects (created_at, db_bucket_id, object_id, version_id, `+"`key`"+`, health, size, mime_type, etag, checksum_algorithm, checksum, customer_key_fingerprint, lock_mode, lock_retain_until, legal_hold)
+		SELECT created_at, db_bucket_id, object_id, version_id, `+"`key`"+`, health, size, mime_type, etag, checksum_algorithm, checksum, customer_key_fingerprint, lock_mode, lock_retain_until, legal_hold
+		FROM object_versions
+		WHERE id = ?`, ovID)
+	if err != nil {
+		return fmt.Errorf("failed to restore object: %w", err)
+	}
+	objID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to fetch object id: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE slices SET db_object_version_id = NULL, db_object_id = ? WHERE db_object_version_id = ?", objID, ovID); err != nil {
+		return fmt.Errorf("failed to move slices: %w", err)
+	} else if _, err := tx.Exec(ctx, "UPDATE object_user_metadata SET db_object_version_id = NULL, db_object_id = ? WHERE db_object_version_id = ?", objID, ovID); err != nil {
+		return fmt.Errorf("failed to move user metadata: %w", err)
+	} else if _, err := tx.Exec(ctx, "UPDATE object_tags SET db_object_version_id = NULL, db_object_id = ? WHERE db_object_version_id = ?", objID, ovID); err != nil {
+		return fmt.Errorf("failed to move tags: %w", err)
+	} else if _, err := tx.Exec(ctx, "DELETE FROM object_versions WHERE id = ?", ovID); err != nil {
+		return fmt.Errorf("failed to delete object version: %w", err)
+	}
+	return nil
+}
+
 func scanAutopilot(s Scanner) (api.Autopilot, error) {
 	var a api.Autopilot
 	if err := s.Scan(&a.ID, (*AutopilotConfig)(&a.Config), &a.CurrentPeriod); err != nil {
@@ -2619,19 +3326,27 @@ func scanStateElement(s Scanner) (stateElement, error) {
 	}, nil
 }
 
-func SearchObjects(ctx context.Context, tx Tx, bucket, substring string, offset, limit int) ([]api.ObjectMetadata, error) {
+func SearchObjects(ctx context.Context, tx Tx, bucket, substring string, tags api.ObjectTags, offset, limit int) ([]api.ObjectMetadata, error) {
 	if limit <= -1 {
 		limit = math.MaxInt
 	}
 
+	whereExprs := []string{"INSTR(o.object_id, ?) > 0", "b.name = ?"}
+	whereArgs := []any{substring, bucket}
+
+	// apply tags
+	tagExprs, tagArgs := whereObjectTags(tags)
+	whereExprs = append(whereExprs, tagExprs...)
+	whereArgs = append(whereArgs, tagArgs...)
+
 	rows, err := tx.Query(ctx, fmt.Sprintf(`
 		SELECT %s
 		FROM objects o
 		INNER JOIN buckets b ON o.db_bucket_id = b.id
-		WHERE INSTR(o.object_id, ?) > 0 AND b.name = ?
+		WHERE %s
 		ORDER BY o.object_id ASC
 		LIMIT ? OFFSET ?
-	`, tx.SelectObjectMetadataExpr()), substring, bucket, limit, offset)
+	`, tx.SelectObjectMetadataExpr(), strings.Join(whereExprs, " AND ")), append(whereArgs, limit, offset)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search objects: %w", err)
 	}
@@ -2792,7 +3507,7 @@ func RecordContractSpending(ctx context.Context, tx Tx, fcid types.FileContractI
 func Object(ctx context.Context, tx Tx, bucket, key string) (api.Object, error) {
 	/// fetch object metadata
 	row := tx.QueryRow(ctx, fmt.Sprintf(`
-		SELECT %s, o.id, o.key
+		SELECT %s, o.id, o.key, o.version_id, o.checksum_algorithm, o.checksum, o.customer_key_fingerprint
 		FROM objects o
 		INNER JOIN buckets b ON o.db_bucket_id = b.id
 		WHERE o.object_id = ? AND b.name = ?
@@ -2800,21 +3515,55 @@ func Object(ctx context.Context, tx Tx, bucket, key string) (api.Object, error)
 		tx.SelectObjectMetadataExpr()), key, bucket)
 	var objID int64
 	var ec object.EncryptionKey
-	om, err := tx.ScanObjectMetadata(row, &objID, (*EncryptionKey)(&ec))
+	var versionID, checksumAlgorithm, checksum, fingerprint string
+	om, err := tx.ScanObjectMetadata(row, &objID, (*EncryptionKey)(&ec), &versionID, &checksumAlgorithm, &checksum, &fingerprint)
 	if errors.Is(err, dsql.ErrNoRows) {
 		return api.Object{}, api.ErrObjectNotFound
 	} else if err != nil {
 		return api.Object{}, err
 	}
+	om.VersionID = versionID
+	om.ChecksumAlgorithm = checksumAlgorithm
+	om.Checksum = checksum
+	om.CustomerKeyFingerprint = fingerprint
 
 	// fetch user metadata
-	rows, err := tx.Query(ctx, `
+	oum, err := objectUserMetadata(ctx, tx, "db_object_id", objID)
+	if err != nil {
+		return api.Object{}, err
+	}
+
+	// fetch tags
+	tags, err := objectTags(ctx, tx, "db_object_id", objID)
+	if err != nil {
+		return api.Object{}, err
+	}
+
+	// fetch slab slices
+	slabSlices, err := objectSlabSlices(ctx, tx, "db_object_id", objID)
+	if err != nil {
+		return api.Object{}, err
+	}
+
+	return api.Object{
+		Metadata:       oum,
+		Tags:           tags,
+		ObjectMetadata: om,
+		Object: &object.Object{
+			Key:   ec,
+			Slabs: slabSlices,
+		},
+	}, nil
+}
+
+func objectUserMetadata(ctx context.Context, tx Tx, col string, id int64) (api.ObjectUserMetadata, error) {
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
 		SELECT oum.key, oum.value
 		FROM object_user_metadata oum
-		WHERE oum.db_object_id = ?
-	`, objID)
+		WHERE oum.%s = ?
+	`, col), id)
 	if err != nil {
-		return api.Object{}, fmt.Errorf("failed to fetch user metadata: %w", err)
+		return nil, fmt.Errorf("failed to fetch user metadata: %w", err)
 	}
 	defer rows.Close()
 
@@ -2822,13 +3571,96 @@ func Object(ctx context.Context, tx Tx, bucket, key string) (api.Object, error)
 	for rows.Next() {
 		var key, value string
 		if err := rows.Scan(&key, &value); err != nil {
-			return api.Object{}, fmt.Errorf("failed to scan user metadata: %w", err)
+			return nil, fmt.Errorf("failed to scan user metadata: %w", err)
 		}
 		oum[key] = value
 	}
+	return oum, nil
+}
 
-	// fetch slab slices
-	rows, err = tx.Query(ctx, `
+func objectID(ctx context.Context, tx sql.Tx, bucket, key string) (int64, error) {
+	var objID int64
+	if err := tx.QueryRow(ctx, `
+		SELECT o.id
+		FROM objects o
+		INNER JOIN buckets b ON b.id = o.db_bucket_id
+		WHERE o.object_id = ? AND b.name = ?
+	`, key, bucket).Scan(&objID); errors.Is(err, dsql.ErrNoRows) {
+		return 0, api.ErrObjectNotFound
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to fetch object id: %w", err)
+	}
+	return objID, nil
+}
+
+func objectLock(ctx context.Context, tx sql.Tx, bucket, key string) (int64, api.ObjectLock, error) {
+	var objID int64
+	var lock api.ObjectLock
+	var retainUntil UnixTimeMS
+	if err := tx.QueryRow(ctx, `
+		SELECT o.id, o.lock_mode, o.lock_retain_until, o.legal_hold
+		FROM objects o
+		INNER JOIN buckets b ON b.id = o.db_bucket_id
+		WHERE o.object_id = ? AND b.name = ?
+	`, key, bucket).Scan(&objID, &lock.Retention.Mode, &retainUntil, &lock.LegalHold); errors.Is(err, dsql.ErrNoRows) {
+		return 0, api.ObjectLock{}, api.ErrObjectNotFound
+	} else if err != nil {
+		return 0, api.ObjectLock{}, fmt.Errorf("failed to fetch object lock: %w", err)
+	}
+	lock.Retention.RetainUntil = api.TimeRFC3339(retainUntil)
+	return objID, lock, nil
+}
+
+// lockedObjectExpr returns an expression that matches objects and object
+// versions that are under retention or a legal hold, it takes the current time
+// in unix milliseconds as its only argument.
+func lockedObjectExpr(alias string) string {
+	if alias != "" {
+		alias += "."
+	}
+	return fmt.Sprintf("(%[1]slegal_hold = 1 OR (%[1]slock_mode <> '' AND %[1]slock_retain_until > ?))", alias)
+}
+
+func objectTags(ctx context.Context, tx sql.Tx, col string, id int64) (api.ObjectTags, error) {
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT ot.key, ot.value
+		FROM object_tags ot
+		WHERE ot.%s = ?
+	`, col), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(api.ObjectTags)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan tags: %w", err)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// whereObjectTags returns the expressions to filter objects by the given tags,
+// an object has to have all of the tags to match.
+func whereObjectTags(tags api.ObjectTags) (whereExprs []string, whereArgs []any) {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		whereExprs = append(whereExprs, "EXISTS (SELECT 1 FROM object_tags ot WHERE ot.db_object_id = o.id AND ot.key = ? AND ot.value = ?)")
+		whereArgs = append(whereArgs, k, tags[k])
+	}
+	return
+}
+
+func objectSlabSlices(ctx context.Context, tx Tx, col string, id int64) (object.SlabSlices, error) {
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
 		SELECT sla.db_buffered_slab_id IS NOT NULL, sli.object_index, sli.offset, sli.length, sla.health, sla.key, sla.min_shards, COALESCE(sec.slab_index, 0), COALESCE(sec.root, ?), COALESCE(sec.latest_host, ?), COALESCE(c.fcid, ?), COALESCE(h.public_key, ?)
 		FROM slices sli
 		INNER JOIN slabs sla ON sli.db_slab_id = sla.id
@@ -2836,11 +3668,11 @@ func Object(ctx context.Context, tx Tx, bucket, key string) (api.Object, error)
 		LEFT JOIN contract_sectors csec ON csec.db_sector_id = sec.id
 		LEFT JOIN contracts c ON c.id = csec.db_contract_id
 		LEFT JOIN hosts h ON h.id = c.host_id
-		WHERE sli.db_object_id = ?
+		WHERE sli.%s = ?
 		ORDER BY sli.object_index ASC, sec.slab_index ASC
-	`, Hash256{}, PublicKey{}, FileContractID{}, PublicKey{}, objID)
+	`, col), Hash256{}, PublicKey{}, FileContractID{}, PublicKey{}, id)
 	if err != nil {
-		return api.Object{}, fmt.Errorf("failed to fetch slabs: %w", err)
+		return nil, fmt.Errorf("failed to fetch slabs: %w", err)
 	}
 	defer rows.Close()
 
@@ -2862,7 +3694,7 @@ func Object(ctx context.Context, tx Tx, bucket, key string) (api.Object, error)
 			(*PublicKey)(&fcid), // contract info
 			(*PublicKey)(&hk),   // host info
 		); err != nil {
-			return api.Object{}, fmt.Errorf("failed to scan slab slice: %w", err)
+			return nil, fmt.Errorf("failed to scan slab slice: %w", err)
 		}
 
 		// sanity check object for corruption
@@ -2872,7 +3704,7 @@ func Object(ctx context.Context, tx Tx, bucket, key string) (api.Object, error)
 		isNewShard := isNewSlab || (objectIndex == currObjIdx && slabIndex == currSlaIdx+1)
 		isNewContract := isNewShard || (objectIndex == currObjIdx && slabIndex == currSlaIdx)
 		if !isFirst && !isBuffered && !isNewSlab && !isNewShard && !isNewContract {
-			return api.Object{}, fmt.Errorf("%w: object index %d, slab index %d, current object index %d, current slab index %d", api.ErrObjectCorrupted, objectIndex, slabIndex, currObjIdx, currSlaIdx)
+			return nil, fmt.Errorf("%w: object index %d, slab index %d, current object index %d, current slab index %d", api.ErrObjectCorrupted, objectIndex, slabIndex, currObjIdx, currSlaIdx)
 		}
 
 		// update indices
@@ -2906,13 +3738,5 @@ func Object(ctx context.Context, tx Tx, bucket, key string) (api.Object, error)
 	if current != nil {
 		slabSlices = append(slabSlices, *current)
 	}
-
-	return api.Object{
-		Metadata:       oum,
-		ObjectMetadata: om,
-		Object: &object.Object{
-			Key:   ec,
-			Slabs: slabSlices,
-		},
-	}, nil
+	return slabSlices, nil
 }
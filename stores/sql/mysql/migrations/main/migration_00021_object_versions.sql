@@ -0,0 +1,35 @@
+ALTER TABLE `objects` ADD COLUMN `version_id` varchar(64) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS `object_versions` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `db_bucket_id` bigint unsigned NOT NULL,
+  `object_id` varchar(766) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  `version_id` varchar(64) NOT NULL,
+  `delete_marker` tinyint(1) NOT NULL DEFAULT '0',
+  `key` longblob,
+  `health` double NOT NULL DEFAULT '1',
+  `size` bigint DEFAULT NULL,
+  `mime_type` longtext,
+  `etag` varchar(191) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_object_versions_db_bucket_id` (`db_bucket_id`),
+  KEY `idx_object_versions_object_id` (`object_id`),
+  KEY `idx_object_versions_version_id` (`version_id`),
+  KEY `idx_object_versions_bucket_object` (`db_bucket_id`,`object_id`),
+  CONSTRAINT `fk_object_versions_db_bucket` FOREIGN KEY (`db_bucket_id`) REFERENCES `buckets` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+ALTER TABLE `slices` ADD COLUMN `db_object_version_id` bigint unsigned DEFAULT NULL;
+ALTER TABLE `slices` ADD INDEX `idx_slices_db_object_version_id` (`db_object_version_id`);
+ALTER TABLE `slices` ADD CONSTRAINT `fk_object_versions_slabs` FOREIGN KEY (`db_object_version_id`) REFERENCES `object_versions` (`id`) ON DELETE CASCADE;
+
+ALTER TABLE `object_user_metadata` ADD COLUMN `db_object_version_id` bigint unsigned DEFAULT NULL;
+ALTER TABLE `object_user_metadata` ADD INDEX `idx_object_user_metadata_db_object_version_id` (`db_object_version_id`);
+ALTER TABLE `object_user_metadata` ADD CONSTRAINT `fk_object_version_user_metadata` FOREIGN KEY (`db_object_version_id`) REFERENCES `object_versions` (`id`) ON DELETE CASCADE;
+
+CREATE TRIGGER before_delete_on_object_versions_delete_slices
+BEFORE DELETE
+ON object_versions FOR EACH ROW
+DELETE FROM slices
+WHERE slices.db_object_version_id = OLD.id;
@@ -0,0 +1,4 @@
+ALTER TABLE `objects` ADD COLUMN `checksum_algorithm` varchar(16) NOT NULL DEFAULT '';
+ALTER TABLE `objects` ADD COLUMN `checksum` varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE `object_versions` ADD COLUMN `checksum_algorithm` varchar(16) NOT NULL DEFAULT '';
+ALTER TABLE `object_versions` ADD COLUMN `checksum` varchar(64) NOT NULL DEFAULT '';
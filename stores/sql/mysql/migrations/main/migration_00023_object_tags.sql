@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `object_tags` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `db_object_id` bigint unsigned DEFAULT NULL,
+  `db_object_version_id` bigint unsigned DEFAULT NULL,
+  `key` varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  `value` varchar(256) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_object_tags_key` (`db_object_id`, `key`),
+  KEY `idx_object_tags_db_object_version_id` (`db_object_version_id`),
+  KEY `idx_object_tags_key_value` (`key`, `value`),
+  CONSTRAINT `fk_object_tags` FOREIGN KEY (`db_object_id`) REFERENCES `objects` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_object_version_tags` FOREIGN KEY (`db_object_version_id`) REFERENCES `object_versions` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,2 @@
+ALTER TABLE `objects` ADD COLUMN `customer_key_fingerprint` varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE `object_versions` ADD COLUMN `customer_key_fingerprint` varchar(64) NOT NULL DEFAULT '';
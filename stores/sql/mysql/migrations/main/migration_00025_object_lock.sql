@@ -0,0 +1,6 @@
+ALTER TABLE `objects` ADD COLUMN `lock_mode` varchar(16) NOT NULL DEFAULT '';
+ALTER TABLE `objects` ADD COLUMN `lock_retain_until` bigint NOT NULL DEFAULT 0;
+ALTER TABLE `objects` ADD COLUMN `legal_hold` tinyint(1) NOT NULL DEFAULT 0;
+ALTER TABLE `object_versions` ADD COLUMN `lock_mode` varchar(16) NOT NULL DEFAULT '';
+ALTER TABLE `object_versions` ADD COLUMN `lock_retain_until` bigint NOT NULL DEFAULT 0;
+ALTER TABLE `object_versions` ADD COLUMN `legal_hold` tinyint(1) NOT NULL DEFAULT 0;
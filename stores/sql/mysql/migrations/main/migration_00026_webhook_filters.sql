@@ -0,0 +1,5 @@
+ALTER TABLE `webhooks` ADD COLUMN `bucket` varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE `webhooks` ADD COLUMN `prefix` varchar(766) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL DEFAULT '';
+ALTER TABLE `webhooks` ADD COLUMN `prefix_hash` binary(32) GENERATED ALWAYS AS (UNHEX(SHA2(`prefix`, 256))) STORED;
+ALTER TABLE `webhooks` DROP INDEX `idx_module_event_url`;
+ALTER TABLE `webhooks` ADD UNIQUE INDEX `idx_module_event_url_bucket_prefix` (`module`(64),`event`(64),`url`,`bucket`(63),`prefix_hash`);
@@ -326,6 +326,13 @@ CREATE TABLE `objects` (
   `size` bigint DEFAULT NULL,
   `mime_type` longtext,
   `etag` varchar(191) DEFAULT NULL,
+  `version_id` varchar(64) NOT NULL DEFAULT '',
+  `checksum_algorithm` varchar(16) NOT NULL DEFAULT '',
+  `checksum` varchar(64) NOT NULL DEFAULT '',
+  `customer_key_fingerprint` varchar(64) NOT NULL DEFAULT '',
+  `lock_mode` varchar(16) NOT NULL DEFAULT '',
+  `lock_retain_until` bigint NOT NULL DEFAULT 0,
+  `legal_hold` tinyint(1) NOT NULL DEFAULT 0,
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_object_bucket` (`db_bucket_id`,`object_id`),
   KEY `idx_objects_db_bucket_id` (`db_bucket_id`),
@@ -337,6 +344,33 @@ CREATE TABLE `objects` (
   CONSTRAINT `fk_objects_db_bucket` FOREIGN KEY (`db_bucket_id`) REFERENCES `buckets` (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 
+-- dbObjectVersion
+CREATE TABLE `object_versions` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `db_bucket_id` bigint unsigned NOT NULL,
+  `object_id` varchar(766) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  `version_id` varchar(64) NOT NULL,
+  `delete_marker` tinyint(1) NOT NULL DEFAULT '0',
+  `key` longblob,
+  `health` double NOT NULL DEFAULT '1',
+  `size` bigint DEFAULT NULL,
+  `mime_type` longtext,
+  `etag` varchar(191) DEFAULT NULL,
+  `checksum_algorithm` varchar(16) NOT NULL DEFAULT '',
+  `checksum` varchar(64) NOT NULL DEFAULT '',
+  `customer_key_fingerprint` varchar(64) NOT NULL DEFAULT '',
+  `lock_mode` varchar(16) NOT NULL DEFAULT '',
+  `lock_retain_until` bigint NOT NULL DEFAULT 0,
+  `legal_hold` tinyint(1) NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`),
+  KEY `idx_object_versions_db_bucket_id` (`db_bucket_id`),
+  KEY `idx_object_versions_object_id` (`object_id`),
+  KEY `idx_object_versions_version_id` (`version_id`),
+  KEY `idx_object_versions_bucket_object` (`db_bucket_id`,`object_id`),
+  CONSTRAINT `fk_object_versions_db_bucket` FOREIGN KEY (`db_bucket_id`) REFERENCES `buckets` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
 -- dbSetting
 CREATE TABLE `settings` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT,
@@ -358,13 +392,16 @@ CREATE TABLE `slices` (
   `db_slab_id` bigint unsigned DEFAULT NULL,
   `offset` int unsigned DEFAULT NULL,
   `length` int unsigned DEFAULT NULL,
+  `db_object_version_id` bigint unsigned DEFAULT NULL,
   PRIMARY KEY (`id`),
   KEY `idx_slices_db_object_id` (`db_object_id`),
   KEY `idx_slices_object_index` (`object_index`),
   KEY `idx_slices_db_multipart_part_id` (`db_multipart_part_id`),
   KEY `idx_slices_db_slab_id` (`db_slab_id`),
+  KEY `idx_slices_db_object_version_id` (`db_object_version_id`),
   CONSTRAINT `fk_multipart_parts_slabs` FOREIGN KEY (`db_multipart_part_id`) REFERENCES `multipart_parts` (`id`) ON DELETE CASCADE,
   CONSTRAINT `fk_objects_slabs` FOREIGN KEY (`db_object_id`) REFERENCES `objects` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_object_versions_slabs` FOREIGN KEY (`db_object_version_id`) REFERENCES `object_versions` (`id`) ON DELETE CASCADE,
   CONSTRAINT `fk_slabs_slices` FOREIGN KEY (`db_slab_id`) REFERENCES `slabs` (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 
@@ -376,8 +413,11 @@ CREATE TABLE `webhooks` (
   `event` varchar(255) NOT NULL,
   `url` varchar(255) NOT NULL,
   `headers` JSON DEFAULT ('{}'),
+  `bucket` varchar(255) NOT NULL DEFAULT '',
+  `prefix` varchar(766) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL DEFAULT '',
+  `prefix_hash` binary(32) GENERATED ALWAYS AS (UNHEX(SHA2(`prefix`, 256))) STORED,
   PRIMARY KEY (`id`),
-  UNIQUE KEY `idx_module_event_url` (`module`,`event`,`url`)
+  UNIQUE KEY `idx_module_event_url_bucket_prefix` (`module`(64),`event`(64),`url`,`bucket`(63),`prefix_hash`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 
 -- dbObjectUserMetadata
@@ -388,12 +428,31 @@ CREATE TABLE `object_user_metadata` (
   `db_multipart_upload_id` bigint unsigned DEFAULT NULL,
   `key` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL,
   `value` longtext,
+  `db_object_version_id` bigint unsigned DEFAULT NULL,
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_object_user_metadata_key` (`db_object_id`, `db_multipart_upload_id`, `key`),
+  KEY `idx_object_user_metadata_db_object_version_id` (`db_object_version_id`),
   CONSTRAINT `fk_object_user_metadata` FOREIGN KEY (`db_object_id`) REFERENCES `objects` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_object_version_user_metadata` FOREIGN KEY (`db_object_version_id`) REFERENCES `object_versions` (`id`) ON DELETE CASCADE,
   CONSTRAINT `fk_multipart_upload_user_metadata` FOREIGN KEY (`db_multipart_upload_id`) REFERENCES `multipart_uploads` (`id`) ON DELETE SET NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 
+-- dbObjectTag
+CREATE TABLE `object_tags` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `db_object_id` bigint unsigned DEFAULT NULL,
+  `db_object_version_id` bigint unsigned DEFAULT NULL,
+  `key` varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  `value` varchar(256) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_object_tags_key` (`db_object_id`, `key`),
+  KEY `idx_object_tags_db_object_version_id` (`db_object_version_id`),
+  KEY `idx_object_tags_key_value` (`key`, `value`),
+  CONSTRAINT `fk_object_tags` FOREIGN KEY (`db_object_id`) REFERENCES `objects` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_object_version_tags` FOREIGN KEY (`db_object_version_id`) REFERENCES `object_versions` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
 -- dbHostCheck
 CREATE TABLE `host_checks` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT,
@@ -454,6 +513,13 @@ ON objects FOR EACH ROW
 DELETE FROM slices
 WHERE slices.db_object_id = OLD.id;
 
+-- dbObjectVersion trigger to delete from slices
+CREATE TRIGGER before_delete_on_object_versions_delete_slices
+BEFORE DELETE
+ON object_versions FOR EACH ROW
+DELETE FROM slices
+WHERE slices.db_object_version_id = OLD.id;
+
 -- dbMultipartUpload trigger to delete from dbMultipartPart
 CREATE TRIGGER before_delete_on_multipart_uploads_delete_multipart_parts
 BEFORE DELETE
@@ -107,7 +107,7 @@ func (tx *MainDatabaseTx) AbortMultipartUpload(ctx context.Context, bucket, path
 	return ssql.AbortMultipartUpload(ctx, tx, bucket, path, uploadID)
 }
 
-func (tx *MainDatabaseTx) AddMultipartPart(ctx context.Context, bucket, path, contractSet, eTag, uploadID string, partNumber int, slices object.SlabSlices) error {
+func (tx *MainDatabaseTx) AddMultipartPart(ctx context.Context, bucket, path, contractSet, eTag, uploadID string, partNumber int, slices object.SlabSlices, opts api.AddMultipartPartOptions) error {
 	// fetch contract set
 	var csID int64
 	err := tx.QueryRow(ctx, "SELECT id FROM contract_sets WHERE name = ?", contractSet).
@@ -126,6 +126,13 @@ func (tx *MainDatabaseTx) AddMultipartPart(ctx context.Context, bucket, path, co
 		return fmt.Errorf("failed to fetch multipart upload: %w", err)
 	}
 
+	// check the part starts at the expected offset
+	if opts.Offset != nil {
+		if err := ssql.CheckMultipartPartOffset(ctx, tx, muID, partNumber, *opts.Offset); err != nil {
+			return err
+		}
+	}
+
 	// delete a potentially existing part
 	_, err = tx.Exec(ctx, "DELETE FROM multipart_parts WHERE db_multipart_upload_id = ? AND part_number = ?",
 		muID, partNumber)
@@ -172,8 +179,8 @@ func (tx *MainDatabaseTx) AddWebhook(ctx context.Context, wh webhooks.Webhook) e
 		}
 		headers = string(h)
 	}
-	_, err := tx.Exec(ctx, "INSERT INTO webhooks (created_at, module, event, url, headers) VALUES (?, ?, ?, ?, ?) ON CONFLICT DO UPDATE SET headers = EXCLUDED.headers",
-		time.Now(), wh.Module, wh.Event, wh.URL, headers)
+	_, err := tx.Exec(ctx, "INSERT INTO webhooks (created_at, module, event, url, headers, bucket, prefix) VALUES (?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO UPDATE SET headers = EXCLUDED.headers",
+		time.Now(), wh.Module, wh.Event, wh.URL, headers, wh.Bucket, wh.Prefix)
 	if err != nil {
 		return fmt.Errorf("failed to insert webhook: %w", err)
 	}
@@ -188,6 +195,10 @@ func (tx *MainDatabaseTx) ArchiveContract(ctx context.Context, fcid types.FileCo
 	return ssql.ArchiveContract(ctx, tx, fcid, reason)
 }
 
+func (tx *MainDatabaseTx) ArchiveObject(ctx context.Context, bucket, key string) (bool, error) {
+	return ssql.ArchiveObject(ctx, tx, bucket, key)
+}
+
 func (tx *MainDatabaseTx) Autopilot(ctx context.Context, id string) (api.Autopilot, error) {
 	return ssql.Autopilot(ctx, tx, id)
 }
@@ -227,7 +238,14 @@ func (tx *MainDatabaseTx) CompleteMultipartUpload(ctx context.Context, bucket, k
 	}
 
 	// create the object
-	objID, err := ssql.InsertObject(ctx, tx, key, mpu.BucketID, size, mpu.EC, mpu.MimeType, eTag)
+	objID, err := ssql.InsertObject(ctx, tx, key, mpu.BucketID, size, mpu.EC, api.AddObjectOptions{
+		MimeType:               mpu.MimeType,
+		ETag:                   eTag,
+		ChecksumAlgorithm:      opts.ChecksumAlgorithm,
+		Checksum:               opts.Checksum,
+		CustomerKeyFingerprint: opts.CustomerKeyFingerprint,
+		Lock:                   opts.Lock,
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to insert object: %w", err)
 	}
@@ -335,6 +353,10 @@ func (tx *MainDatabaseTx) DeleteHostSector(ctx context.Context, hk types.PublicK
 	return ssql.DeleteHostSector(ctx, tx, hk, root)
 }
 
+func (tx *MainDatabaseTx) DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) (bool, error) {
+	return ssql.DeleteObjectVersion(ctx, tx, bucket, key, versionID)
+}
+
 func (tx *MainDatabaseTx) DeleteSettings(ctx context.Context, key string) error {
 	return ssql.DeleteSettings(ctx, tx, key)
 }
@@ -348,6 +370,9 @@ func (tx *MainDatabaseTx) DeleteBucket(ctx context.Context, bucket string) error
 }
 
 func (tx *MainDatabaseTx) DeleteObject(ctx context.Context, bucket string, key string) (bool, error) {
+	if err := ssql.CheckObjectLock(ctx, tx, bucket, key); err != nil {
+		return false, err
+	}
 	resp, err := tx.Exec(ctx, "DELETE FROM objects WHERE object_id = ? AND db_bucket_id = (SELECT id FROM buckets WHERE buckets.name = ?)", key, bucket)
 	if err != nil {
 		return false, err
@@ -359,6 +384,9 @@ func (tx *MainDatabaseTx) DeleteObject(ctx context.Context, bucket string, key s
 }
 
 func (tx *MainDatabaseTx) DeleteObjects(ctx context.Context, bucket string, key string, limit int64) (bool, error) {
+	if err := ssql.CheckObjectsLock(ctx, tx, bucket, key); err != nil {
+		return false, err
+	}
 	resp, err := tx.Exec(ctx, `
 	DELETE FROM objects
 	WHERE id IN (
@@ -451,11 +479,15 @@ func (tx *MainDatabaseTx) InsertDirectoriesDeprecated(ctx context.Context, bucke
 	return *dirID, nil
 }
 
+func (tx *MainDatabaseTx) InsertDeleteMarker(ctx context.Context, bucket, key string) (string, bool, error) {
+	return ssql.InsertDeleteMarker(ctx, tx, bucket, key)
+}
+
 func (tx *MainDatabaseTx) InsertMultipartUpload(ctx context.Context, bucket, key string, ec object.EncryptionKey, mimeType string, metadata api.ObjectUserMetadata) (string, error) {
 	return ssql.InsertMultipartUpload(ctx, tx, bucket, key, ec, mimeType, metadata)
 }
 
-func (tx *MainDatabaseTx) InsertObject(ctx context.Context, bucket, key, contractSet string, o object.Object, mimeType, eTag string, md api.ObjectUserMetadata) error {
+func (tx *MainDatabaseTx) InsertObject(ctx context.Context, bucket, key, contractSet string, o object.Object, opts api.AddObjectOptions) error {
 	// get bucket id
 	var bucketID int64
 	err := tx.QueryRow(ctx, "SELECT id FROM buckets WHERE buckets.name = ?", bucket).Scan(&bucketID)
@@ -466,7 +498,7 @@ func (tx *MainDatabaseTx) InsertObject(ctx context.Context, bucket, key, contrac
 	}
 
 	// insert object
-	objID, err := ssql.InsertObject(ctx, tx, key, bucketID, o.TotalSize(), o.Key, mimeType, eTag)
+	objID, err := ssql.InsertObject(ctx, tx, key, bucketID, o.TotalSize(), o.Key, opts)
 	if err != nil {
 		return fmt.Errorf("failed to insert object: %w", err)
 	}
@@ -477,7 +509,7 @@ func (tx *MainDatabaseTx) InsertObject(ctx context.Context, bucket, key, contrac
 	}
 
 	// insert metadata
-	if err := ssql.InsertMetadata(ctx, tx, &objID, nil, md); err != nil {
+	if err := ssql.InsertMetadata(ctx, tx, &objID, nil, opts.Metadata); err != nil {
 		return fmt.Errorf("failed to insert object metadata: %w", err)
 	}
 	return nil
@@ -515,8 +547,8 @@ func (tx *MainDatabaseTx) ListBuckets(ctx context.Context) ([]api.Bucket, error)
 	return ssql.ListBuckets(ctx, tx)
 }
 
-func (tx *MainDatabaseTx) ListObjects(ctx context.Context, bucket, prefix, sortBy, sortDir, marker string, limit int) (api.ObjectsListResponse, error) {
-	return ssql.ListObjects(ctx, tx, bucket, prefix, sortBy, sortDir, marker, limit)
+func (tx *MainDatabaseTx) ListObjects(ctx context.Context, bucket, prefix, sortBy, sortDir, marker string, tags api.ObjectTags, limit int) (api.ObjectsListResponse, error) {
+	return ssql.ListObjects(ctx, tx, bucket, prefix, sortBy, sortDir, marker, tags, limit)
 }
 
 func (tx *MainDatabaseTx) MakeDirsForPathDeprecated(ctx context.Context, path string) (int64, error) {
@@ -589,14 +621,44 @@ func (tx *MainDatabaseTx) ObjectEntries(ctx context.Context, bucket, path, prefi
 	return ssql.ObjectEntries(ctx, tx, bucket, path, prefix, sortBy, sortDir, marker, offset, limit)
 }
 
+func (tx *MainDatabaseTx) ObjectETag(ctx context.Context, bucket, key string) (string, error) {
+	// SQLite doesn't support SELECT ... FOR UPDATE, a write that doesn't
+	// change anything acquires the database's write lock instead, that way
+	// the object can't be overwritten before the transaction is committed
+	if _, err := tx.Exec(ctx, "UPDATE objects SET id = id WHERE 1 = 0"); err != nil {
+		return "", fmt.Errorf("failed to lock objects: %w", err)
+	}
+	return ssql.ObjectETag(ctx, tx, bucket, key, false)
+}
+
+func (tx *MainDatabaseTx) ObjectKeys(ctx context.Context, bucket, prefix string, limit int64) ([]string, error) {
+	return ssql.ObjectKeys(ctx, tx, bucket, prefix, limit)
+}
+
 func (tx *MainDatabaseTx) ObjectMetadata(ctx context.Context, bucket, path string) (api.Object, error) {
 	return ssql.ObjectMetadata(ctx, tx, bucket, path)
 }
 
+func (tx *MainDatabaseTx) ObjectVersion(ctx context.Context, bucket, key, versionID string) (api.Object, error) {
+	return ssql.ObjectVersion(ctx, tx, bucket, key, versionID)
+}
+
+func (tx *MainDatabaseTx) ObjectVersions(ctx context.Context, bucket, prefix, keyMarker, versionIDMarker string, limit int) (api.ObjectVersionsResponse, error) {
+	return ssql.ObjectVersions(ctx, tx, bucket, prefix, keyMarker, versionIDMarker, limit)
+}
+
 func (tx *MainDatabaseTx) ObjectsBySlabKey(ctx context.Context, bucket string, slabKey object.EncryptionKey) (metadata []api.ObjectMetadata, err error) {
 	return ssql.ObjectsBySlabKey(ctx, tx, bucket, slabKey)
 }
 
+func (tx *MainDatabaseTx) ObjectLock(ctx context.Context, bucket, key string) (api.ObjectLock, error) {
+	return ssql.ObjectLock(ctx, tx, bucket, key)
+}
+
+func (tx *MainDatabaseTx) ObjectTags(ctx context.Context, bucket, key string) (api.ObjectTags, error) {
+	return ssql.ObjectTags(ctx, tx, bucket, key)
+}
+
 func (tx *MainDatabaseTx) ObjectsStats(ctx context.Context, opts api.ObjectsStatsOpts) (api.ObjectsStatsResponse, error) {
 	return ssql.ObjectsStats(ctx, tx, opts)
 }
@@ -733,6 +795,9 @@ func (tx *MainDatabaseTx) RemoveOfflineHosts(ctx context.Context, minRecentFailu
 }
 
 func (tx *MainDatabaseTx) RenameObject(ctx context.Context, bucket, keyOld, keyNew string, force bool) error {
+	if err := ssql.CheckObjectLock(ctx, tx, bucket, keyOld); err != nil {
+		return err
+	}
 	if force {
 		// delete potentially existing object at destination
 		if _, err := tx.DeleteObject(ctx, bucket, keyNew); err != nil {
@@ -758,7 +823,15 @@ func (tx *MainDatabaseTx) RenameObject(ctx context.Context, bucket, keyOld, keyN
 }
 
 func (tx *MainDatabaseTx) RenameObjects(ctx context.Context, bucket, prefixOld, prefixNew string, force bool) error {
+	if err := ssql.CheckObjectsLock(ctx, tx, bucket, prefixOld); err != nil {
+		return err
+	}
 	if force {
+		// locked objects can't be overwritten
+		if err := ssql.CheckRenameObjectsLock(ctx, tx, bucket, prefixOld, prefixNew); err != nil {
+			return err
+		}
+
 		// to avoid a conflict on update, we delete objects that would conflict
 		// with objects being renamed, within the scope of the bucket of course
 		query := `
@@ -874,8 +947,8 @@ func (tx *MainDatabaseTx) SearchHosts(ctx context.Context, autopilotID, filterMo
 	return ssql.SearchHosts(ctx, tx, autopilotID, filterMode, usabilityMode, addressContains, keyIn, offset, limit)
 }
 
-func (tx *MainDatabaseTx) SearchObjects(ctx context.Context, bucket, substring string, offset, limit int) ([]api.ObjectMetadata, error) {
-	return ssql.SearchObjects(ctx, tx, bucket, substring, offset, limit)
+func (tx *MainDatabaseTx) SearchObjects(ctx context.Context, bucket, substring string, tags api.ObjectTags, offset, limit int) ([]api.ObjectMetadata, error) {
+	return ssql.SearchObjects(ctx, tx, bucket, substring, tags, offset, limit)
 }
 
 func (tx *MainDatabaseTx) SelectObjectMetadataExpr() string {
@@ -1115,6 +1188,18 @@ func (tx *MainDatabaseTx) UpdateHostCheck(ctx context.Context, autopilot string,
 	return nil
 }
 
+func (tx *MainDatabaseTx) UpdateObjectLegalHold(ctx context.Context, bucket, key string, enabled bool) error {
+	return ssql.UpdateObjectLegalHold(ctx, tx, bucket, key, enabled)
+}
+
+func (tx *MainDatabaseTx) UpdateObjectRetention(ctx context.Context, bucket, key string, retention api.ObjectRetention, bypassGovernance bool) error {
+	return ssql.UpdateObjectRetention(ctx, tx, bucket, key, retention, bypassGovernance)
+}
+
+func (tx *MainDatabaseTx) UpdateObjectTags(ctx context.Context, bucket, key string, tags api.ObjectTags) error {
+	return ssql.UpdateObjectTags(ctx, tx, bucket, key, tags)
+}
+
 func (tx *MainDatabaseTx) UpdatePeerInfo(ctx context.Context, addr string, fn func(*syncer.PeerInfo)) error {
 	return ssql.UpdatePeerInfo(ctx, tx, addr, fn)
 }
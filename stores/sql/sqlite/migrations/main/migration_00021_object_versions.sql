@@ -0,0 +1,20 @@
+ALTER TABLE `objects` ADD COLUMN `version_id` text NOT NULL DEFAULT '';
+
+CREATE TABLE `object_versions` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`db_bucket_id` integer NOT NULL,`object_id` text NOT NULL,`version_id` text NOT NULL,`delete_marker` numeric NOT NULL DEFAULT false,`key` blob,`health` real NOT NULL DEFAULT 1,`size` integer,`mime_type` text,`etag` text,CONSTRAINT `fk_object_versions_db_bucket` FOREIGN KEY (`db_bucket_id`) REFERENCES `buckets`(`id`));
+CREATE INDEX `idx_object_versions_db_bucket_id` ON `object_versions`(`db_bucket_id`);
+CREATE INDEX `idx_object_versions_object_id` ON `object_versions`(`object_id`);
+CREATE INDEX `idx_object_versions_version_id` ON `object_versions`(`version_id`);
+CREATE INDEX `idx_object_versions_bucket_object` ON `object_versions`(`db_bucket_id`,`object_id`);
+
+ALTER TABLE `slices` ADD COLUMN `db_object_version_id` integer DEFAULT NULL REFERENCES `object_versions`(`id`) ON DELETE CASCADE;
+CREATE INDEX `idx_slices_db_object_version_id` ON `slices`(`db_object_version_id`);
+
+ALTER TABLE `object_user_metadata` ADD COLUMN `db_object_version_id` integer DEFAULT NULL REFERENCES `object_versions`(`id`) ON DELETE CASCADE;
+CREATE INDEX `idx_object_user_metadata_db_object_version_id` ON `object_user_metadata`(`db_object_version_id`);
+
+CREATE TRIGGER before_delete_on_object_versions_delete_slices
+BEFORE DELETE ON object_versions
+BEGIN
+    DELETE FROM slices
+    WHERE slices.db_object_version_id = OLD.id;
+END;
@@ -0,0 +1,4 @@
+ALTER TABLE `objects` ADD COLUMN `checksum_algorithm` text NOT NULL DEFAULT '';
+ALTER TABLE `objects` ADD COLUMN `checksum` text NOT NULL DEFAULT '';
+ALTER TABLE `object_versions` ADD COLUMN `checksum_algorithm` text NOT NULL DEFAULT '';
+ALTER TABLE `object_versions` ADD COLUMN `checksum` text NOT NULL DEFAULT '';
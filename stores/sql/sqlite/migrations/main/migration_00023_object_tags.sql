@@ -0,0 +1,4 @@
+CREATE TABLE `object_tags` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`db_object_id` integer DEFAULT NULL,`db_object_version_id` integer DEFAULT NULL,`key` text NOT NULL,`value` text NOT NULL, CONSTRAINT `fk_object_tags` FOREIGN KEY (`db_object_id`) REFERENCES `objects` (`id`) ON DELETE CASCADE, CONSTRAINT `fk_object_version_tags` FOREIGN KEY (`db_object_version_id`) REFERENCES `object_versions` (`id`) ON DELETE CASCADE);
+CREATE UNIQUE INDEX `idx_object_tags_key` ON `object_tags`(`db_object_id`,`key`);
+CREATE INDEX `idx_object_tags_db_object_version_id` ON `object_tags`(`db_object_version_id`);
+CREATE INDEX `idx_object_tags_key_value` ON `object_tags`(`key`,`value`);
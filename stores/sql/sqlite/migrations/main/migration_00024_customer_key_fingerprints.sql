@@ -0,0 +1,2 @@
+ALTER TABLE `objects` ADD COLUMN `customer_key_fingerprint` text NOT NULL DEFAULT '';
+ALTER TABLE `object_versions` ADD COLUMN `customer_key_fingerprint` text NOT NULL DEFAULT '';
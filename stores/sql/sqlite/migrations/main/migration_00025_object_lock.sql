@@ -0,0 +1,6 @@
+ALTER TABLE `objects` ADD COLUMN `lock_mode` text NOT NULL DEFAULT '';
+ALTER TABLE `objects` ADD COLUMN `lock_retain_until` integer NOT NULL DEFAULT 0;
+ALTER TABLE `objects` ADD COLUMN `legal_hold` numeric NOT NULL DEFAULT false;
+ALTER TABLE `object_versions` ADD COLUMN `lock_mode` text NOT NULL DEFAULT '';
+ALTER TABLE `object_versions` ADD COLUMN `lock_retain_until` integer NOT NULL DEFAULT 0;
+ALTER TABLE `object_versions` ADD COLUMN `legal_hold` numeric NOT NULL DEFAULT false;
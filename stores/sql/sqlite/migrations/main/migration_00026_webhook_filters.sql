@@ -0,0 +1,4 @@
+ALTER TABLE `webhooks` ADD COLUMN `bucket` text NOT NULL DEFAULT '';
+ALTER TABLE `webhooks` ADD COLUMN `prefix` text NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS `idx_module_event_url`;
+CREATE UNIQUE INDEX `idx_module_event_url_bucket_prefix` ON `webhooks`(`module`,`event`,`url`,`bucket`,`prefix`);
@@ -45,7 +45,7 @@ CREATE TABLE `buckets` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` date
 CREATE INDEX `idx_buckets_name` ON `buckets`(`name`);
 
 -- dbObject
-CREATE TABLE `objects` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`db_bucket_id` integer NOT NULL, `object_id` text,`key` blob,`health` real NOT NULL DEFAULT 1,`size` integer,`mime_type` text,`etag` text,CONSTRAINT `fk_objects_db_bucket` FOREIGN KEY (`db_bucket_id`) REFERENCES `buckets`(`id`));
+CREATE TABLE `objects` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`db_bucket_id` integer NOT NULL, `object_id` text,`key` blob,`health` real NOT NULL DEFAULT 1,`size` integer,`mime_type` text,`etag` text,`version_id` text NOT NULL DEFAULT '',`checksum_algorithm` text NOT NULL DEFAULT '',`checksum` text NOT NULL DEFAULT '',`customer_key_fingerprint` text NOT NULL DEFAULT '',`lock_mode` text NOT NULL DEFAULT '',`lock_retain_until` integer NOT NULL DEFAULT 0,`legal_hold` numeric NOT NULL DEFAULT false,CONSTRAINT `fk_objects_db_bucket` FOREIGN KEY (`db_bucket_id`) REFERENCES `buckets`(`id`));
 CREATE INDEX `idx_objects_db_bucket_id` ON `objects`(`db_bucket_id`);
 CREATE INDEX `idx_objects_etag` ON `objects`(`etag`);
 CREATE INDEX `idx_objects_health` ON `objects`(`health`);
@@ -54,6 +54,13 @@ CREATE INDEX `idx_objects_size` ON `objects`(`size`);
 CREATE UNIQUE INDEX `idx_object_bucket` ON `objects`(`db_bucket_id`,`object_id`);
 CREATE INDEX `idx_objects_created_at` ON `objects`(`created_at`);
 
+-- dbObjectVersion
+CREATE TABLE `object_versions` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`db_bucket_id` integer NOT NULL,`object_id` text NOT NULL,`version_id` text NOT NULL,`delete_marker` numeric NOT NULL DEFAULT false,`key` blob,`health` real NOT NULL DEFAULT 1,`size` integer,`mime_type` text,`etag` text,`checksum_algorithm` text NOT NULL DEFAULT '',`checksum` text NOT NULL DEFAULT '',`customer_key_fingerprint` text NOT NULL DEFAULT '',`lock_mode` text NOT NULL DEFAULT '',`lock_retain_until` integer NOT NULL DEFAULT 0,`legal_hold` numeric NOT NULL DEFAULT false,CONSTRAINT `fk_object_versions_db_bucket` FOREIGN KEY (`db_bucket_id`) REFERENCES `buckets`(`id`));
+CREATE INDEX `idx_object_versions_db_bucket_id` ON `object_versions`(`db_bucket_id`);
+CREATE INDEX `idx_object_versions_object_id` ON `object_versions`(`object_id`);
+CREATE INDEX `idx_object_versions_version_id` ON `object_versions`(`version_id`);
+CREATE INDEX `idx_object_versions_bucket_object` ON `object_versions`(`db_bucket_id`,`object_id`);
+
 -- dbMultipartUpload
 CREATE TABLE `multipart_uploads` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`key` blob,`upload_id` text NOT NULL,`object_id` text NOT NULL,`db_bucket_id` integer NOT NULL,`mime_type` text,CONSTRAINT `fk_multipart_uploads_db_bucket` FOREIGN KEY (`db_bucket_id`) REFERENCES `buckets`(`id`) ON DELETE CASCADE);
 CREATE INDEX `idx_multipart_uploads_mime_type` ON `multipart_uploads`(`mime_type`);
@@ -92,11 +99,12 @@ CREATE INDEX `idx_multipart_parts_part_number` ON `multipart_parts`(`part_number
 CREATE INDEX `idx_multipart_parts_etag` ON `multipart_parts`(`etag`);
 
 -- dbSlice
-CREATE TABLE `slices` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`db_object_id` integer,`object_index` integer,`db_multipart_part_id` integer,`db_slab_id` integer,`offset` integer,`length` integer,CONSTRAINT `fk_objects_slabs` FOREIGN KEY (`db_object_id`) REFERENCES `objects`(`id`) ON DELETE CASCADE,CONSTRAINT `fk_multipart_parts_slabs` FOREIGN KEY (`db_multipart_part_id`) REFERENCES `multipart_parts`(`id`) ON DELETE CASCADE,CONSTRAINT `fk_slabs_slices` FOREIGN KEY (`db_slab_id`) REFERENCES `slabs`(`id`));
+CREATE TABLE `slices` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`db_object_id` integer,`object_index` integer,`db_multipart_part_id` integer,`db_slab_id` integer,`offset` integer,`length` integer,`db_object_version_id` integer DEFAULT NULL,CONSTRAINT `fk_objects_slabs` FOREIGN KEY (`db_object_id`) REFERENCES `objects`(`id`) ON DELETE CASCADE,CONSTRAINT `fk_object_versions_slabs` FOREIGN KEY (`db_object_version_id`) REFERENCES `object_versions`(`id`) ON DELETE CASCADE,CONSTRAINT `fk_multipart_parts_slabs` FOREIGN KEY (`db_multipart_part_id`) REFERENCES `multipart_parts`(`id`) ON DELETE CASCADE,CONSTRAINT `fk_slabs_slices` FOREIGN KEY (`db_slab_id`) REFERENCES `slabs`(`id`));
 CREATE INDEX `idx_slices_object_index` ON `slices`(`object_index`);
 CREATE INDEX `idx_slices_db_object_id` ON `slices`(`db_object_id`);
 CREATE INDEX `idx_slices_db_slab_id` ON `slices`(`db_slab_id`);
 CREATE INDEX `idx_slices_db_multipart_part_id` ON `slices`(`db_multipart_part_id`);
+CREATE INDEX `idx_slices_db_object_version_id` ON `slices`(`db_object_version_id`);
 
 -- dbHostAnnouncement
 CREATE TABLE `host_announcements` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`host_key` blob NOT NULL,`block_height` integer,`block_id` text,`net_address` text);
@@ -133,12 +141,19 @@ CREATE INDEX `idx_ephemeral_accounts_owner` ON `ephemeral_accounts`(`owner`);
 CREATE TABLE `autopilots` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`identifier` text NOT NULL UNIQUE,`config` text,`current_period` integer DEFAULT 0);
 
 -- dbWebhook
-CREATE TABLE `webhooks` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`module` text NOT NULL,`event` text NOT NULL,`url` text NOT NULL,`headers` text DEFAULT ('{}'));
-CREATE UNIQUE INDEX `idx_module_event_url` ON `webhooks`(`module`,`event`,`url`);
+CREATE TABLE `webhooks` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`module` text NOT NULL,`event` text NOT NULL,`url` text NOT NULL,`headers` text DEFAULT ('{}'),`bucket` text NOT NULL DEFAULT '',`prefix` text NOT NULL DEFAULT '');
+CREATE UNIQUE INDEX `idx_module_event_url_bucket_prefix` ON `webhooks`(`module`,`event`,`url`,`bucket`,`prefix`);
 
 -- dbObjectUserMetadata
-CREATE TABLE `object_user_metadata` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`db_object_id` integer DEFAULT NULL,`db_multipart_upload_id` integer DEFAULT NULL,`key` text NOT NULL,`value` text, CONSTRAINT `fk_object_user_metadata` FOREIGN KEY (`db_object_id`) REFERENCES `objects` (`id`) ON DELETE CASCADE, CONSTRAINT `fk_multipart_upload_user_metadata` FOREIGN KEY (`db_multipart_upload_id`) REFERENCES `multipart_uploads` (`id`) ON DELETE SET NULL);
+CREATE TABLE `object_user_metadata` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`db_object_id` integer DEFAULT NULL,`db_multipart_upload_id` integer DEFAULT NULL,`key` text NOT NULL,`value` text,`db_object_version_id` integer DEFAULT NULL, CONSTRAINT `fk_object_user_metadata` FOREIGN KEY (`db_object_id`) REFERENCES `objects` (`id`) ON DELETE CASCADE, CONSTRAINT `fk_object_version_user_metadata` FOREIGN KEY (`db_object_version_id`) REFERENCES `object_versions` (`id`) ON DELETE CASCADE, CONSTRAINT `fk_multipart_upload_user_metadata` FOREIGN KEY (`db_multipart_upload_id`) REFERENCES `multipart_uploads` (`id`) ON DELETE SET NULL);
 CREATE UNIQUE INDEX `idx_object_user_metadata_key` ON `object_user_metadata`(`db_object_id`,`db_multipart_upload_id`,`key`);
+CREATE INDEX `idx_object_user_metadata_db_object_version_id` ON `object_user_metadata`(`db_object_version_id`);
+
+-- dbObjectTag
+CREATE TABLE `object_tags` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`db_object_id` integer DEFAULT NULL,`db_object_version_id` integer DEFAULT NULL,`key` text NOT NULL,`value` text NOT NULL, CONSTRAINT `fk_object_tags` FOREIGN KEY (`db_object_id`) REFERENCES `objects` (`id`) ON DELETE CASCADE, CONSTRAINT `fk_object_version_tags` FOREIGN KEY (`db_object_version_id`) REFERENCES `object_versions` (`id`) ON DELETE CASCADE);
+CREATE UNIQUE INDEX `idx_object_tags_key` ON `object_tags`(`db_object_id`,`key`);
+CREATE INDEX `idx_object_tags_db_object_version_id` ON `object_tags`(`db_object_version_id`);
+CREATE INDEX `idx_object_tags_key_value` ON `object_tags`(`key`,`value`);
 
 -- dbHostCheck
 CREATE TABLE `host_checks` (`id` INTEGER PRIMARY KEY AUTOINCREMENT, `created_at` datetime, `db_autopilot_id` INTEGER NOT NULL, `db_host_id` INTEGER NOT NULL, `usability_blocked` INTEGER NOT NULL DEFAULT 0, `usability_offline` INTEGER NOT NULL DEFAULT 0, `usability_low_score` INTEGER NOT NULL DEFAULT 0, `usability_redundant_ip` INTEGER NOT NULL DEFAULT 0, `usability_gouging` INTEGER NOT NULL DEFAULT 0, `usability_not_accepting_contracts` INTEGER NOT NULL DEFAULT 0, `usability_not_announced` INTEGER NOT NULL DEFAULT 0, `usability_not_completing_scan` INTEGER NOT NULL DEFAULT 0, `score_age` REAL NOT NULL, `score_collateral` REAL NOT NULL, `score_interactions` REAL NOT NULL, `score_storage_remaining` REAL NOT NULL, `score_uptime` REAL NOT NULL, `score_version` REAL NOT NULL, `score_prices` REAL NOT NULL, `gouging_contract_err` TEXT, `gouging_download_err` TEXT, `gouging_gouging_err` TEXT, `gouging_prune_err` TEXT, `gouging_upload_err` TEXT, FOREIGN KEY (`db_autopilot_id`) REFERENCES `autopilots` (`id`) ON DELETE CASCADE, FOREIGN KEY (`db_host_id`) REFERENCES `hosts` (`id`) ON DELETE CASCADE);
@@ -167,6 +182,14 @@ BEGIN
     WHERE slices.db_object_id = OLD.id;
 END;
 
+-- dbObjectVersion trigger to delete from slices
+CREATE TRIGGER before_delete_on_object_versions_delete_slices
+BEFORE DELETE ON object_versions
+BEGIN
+    DELETE FROM slices
+    WHERE slices.db_object_version_id = OLD.id;
+END;
+
 -- dbMultipartUpload trigger to delete from dbMultipartPart
 CREATE TRIGGER before_delete_on_multipart_uploads_delete_multipart_parts
 BEFORE DELETE ON multipart_uploads
@@ -2,6 +2,7 @@ package stores
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -27,6 +28,8 @@ func TestWebhooks(t *testing.T) {
 		Headers: map[string]string{
 			"foo2": "bar2",
 		},
+		Bucket: "bucket",
+		Prefix: "/foo/",
 	}
 
 	// Add hook.
@@ -82,4 +85,56 @@ func TestWebhooks(t *testing.T) {
 	} else if !cmp.Equal(whs[0], wh2) {
 		t.Fatal("unexpected webhook", cmp.Diff(whs[0], wh2))
 	}
+
+	// Add the same hook with a different prefix, it should not overwrite the
+	// existing one.
+	wh3 := wh2
+	wh3.Prefix = "/bar/"
+	if err := ss.AddWebhook(context.Background(), wh3); err != nil {
+		t.Fatal(err)
+	}
+	whs, err = ss.Webhooks(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	} else if len(whs) != 2 {
+		t.Fatal("expected 2 webhooks", len(whs))
+	} else if !cmp.Equal(whs[0], wh2) {
+		t.Fatal("unexpected webhook", cmp.Diff(whs[0], wh2))
+	} else if !cmp.Equal(whs[1], wh3) {
+		t.Fatal("unexpected webhook", cmp.Diff(whs[1], wh3))
+	}
+
+	// Remove it again, the original one should remain.
+	if err := ss.DeleteWebhook(context.Background(), wh3); err != nil {
+		t.Fatal(err)
+	}
+	whs, err = ss.Webhooks(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	} else if len(whs) != 1 {
+		t.Fatal("expected 1 webhook")
+	} else if !cmp.Equal(whs[0], wh2) {
+		t.Fatal("unexpected webhook", cmp.Diff(whs[0], wh2))
+	}
+
+	// Add two hooks whose long prefixes only differ at the end, they should
+	// not overwrite each other.
+	wh4, wh5 := wh2, wh2
+	wh4.Prefix = "/" + strings.Repeat("a", 500) + "/foo/"
+	wh5.Prefix = "/" + strings.Repeat("a", 500) + "/bar/"
+	if err := ss.AddWebhook(context.Background(), wh4); err != nil {
+		t.Fatal(err)
+	} else if err := ss.AddWebhook(context.Background(), wh5); err != nil {
+		t.Fatal(err)
+	}
+	whs, err = ss.Webhooks(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	} else if len(whs) != 3 {
+		t.Fatal("expected 3 webhooks", len(whs))
+	} else if !cmp.Equal(whs[1], wh4) {
+		t.Fatal("unexpected webhook", cmp.Diff(whs[1], wh4))
+	} else if !cmp.Equal(whs[2], wh5) {
+		t.Fatal("unexpected webhook", cmp.Diff(whs[2], wh5))
+	}
 }
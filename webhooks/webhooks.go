@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,6 +28,18 @@ type (
 	Broadcaster interface {
 		BroadcastAction(ctx context.Context, action Event) error
 	}
+
+	// ObjectPayload is implemented by the payloads of events that concern
+	// the objects in a bucket, webhooks can filter these events by bucket and
+	// prefix.
+	ObjectPayload interface {
+		// ObjectBucket returns the bucket of the object.
+		ObjectBucket() string
+
+		// ObjectPath returns the path of the object, if the event concerns
+		// all objects with a common prefix it returns the prefix and true.
+		ObjectPath() (path string, isPrefix bool)
+	}
 )
 
 type HeaderOption func(headers map[string]string)
@@ -52,6 +65,12 @@ type (
 		Event   string            `json:"event"`
 		URL     string            `json:"url"`
 		Headers map[string]string `json:"headers,omitempty"`
+
+		// Bucket and Prefix restrict the webhook to events concerning
+		// objects in the bucket whose path starts with the prefix, events
+		// that don't concern objects never match a filtered webhook.
+		Bucket string `json:"bucket,omitempty"`
+		Prefix string `json:"prefix,omitempty"`
 	}
 
 	WebhookQueueInfo struct {
@@ -146,6 +165,8 @@ func (m *Manager) Info() ([]Webhook, []WebhookQueueInfo) {
 			Event:  hook.Event,
 			Module: hook.Module,
 			URL:    hook.URL,
+			Bucket: hook.Bucket,
+			Prefix: hook.Prefix,
 		})
 	}
 	var queueInfos []WebhookQueueInfo
@@ -225,12 +246,24 @@ func (q *eventQueue) dequeue() {
 func (w Webhook) Matches(action Event) bool {
 	if w.Module != action.Module {
 		return false
+	} else if w.Event != "" && w.Event != action.Event {
+		return false
+	} else if w.Bucket == "" && w.Prefix == "" {
+		return true
+	}
+
+	op, ok := action.Payload.(ObjectPayload)
+	if !ok {
+		return false
+	} else if w.Bucket != "" && w.Bucket != op.ObjectBucket() {
+		return false
 	}
-	return w.Event == "" || w.Event == action.Event
+	path, isPrefix := op.ObjectPath()
+	return strings.HasPrefix(path, w.Prefix) || (isPrefix && strings.HasPrefix(w.Prefix, path))
 }
 
 func (w Webhook) String() string {
-	return fmt.Sprintf("%v.%v.%v", w.URL, w.Module, w.Event)
+	return fmt.Sprintf("%v.%v.%v.%v.%v", w.URL, w.Module, w.Event, w.Bucket, w.Prefix)
 }
 
 func NewManager(store WebhookStore, logger *zap.Logger) (*Manager, error) {
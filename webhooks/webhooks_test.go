@@ -0,0 +1,55 @@
+package webhooks
+
+import "testing"
+
+type testObjectPayload struct {
+	bucket string
+	path   string
+	batch  bool
+}
+
+func (p testObjectPayload) ObjectBucket() string       { return p.bucket }
+func (p testObjectPayload) ObjectPath() (string, bool) { return p.path, p.batch }
+
+func TestWebhookMatches(t *testing.T) {
+	event := func(bucket, path string, batch bool) Event {
+		return Event{
+			Module:  "object",
+			Event:   "created",
+			Payload: testObjectPayload{bucket, path, batch},
+		}
+	}
+
+	tests := []struct {
+		wh      Webhook
+		event   Event
+		matches bool
+	}{
+		// module and event have to match
+		{Webhook{Module: "object"}, event("default", "/foo", false), true},
+		{Webhook{Module: "object", Event: "created"}, event("default", "/foo", false), true},
+		{Webhook{Module: "object", Event: "deleted"}, event("default", "/foo", false), false},
+		{Webhook{Module: "setting"}, event("default", "/foo", false), false},
+
+		// bucket and prefix filters
+		{Webhook{Module: "object", Bucket: "default"}, event("default", "/foo", false), true},
+		{Webhook{Module: "object", Bucket: "other"}, event("default", "/foo", false), false},
+		{Webhook{Module: "object", Prefix: "/fo"}, event("default", "/foo", false), true},
+		{Webhook{Module: "object", Prefix: "/bar"}, event("default", "/foo", false), false},
+		{Webhook{Module: "object", Bucket: "default", Prefix: "/foo/"}, event("default", "/foo/bar", false), true},
+
+		// batch events match if the prefixes overlap
+		{Webhook{Module: "object", Prefix: "/foo/bar/"}, event("default", "/foo/", true), true},
+		{Webhook{Module: "object", Prefix: "/foo/bar/"}, event("default", "/foo/", false), false},
+		{Webhook{Module: "object", Prefix: "/foo/bar/"}, event("default", "/baz/", true), false},
+
+		// events without an object payload never match a filtered webhook
+		{Webhook{Module: "object", Prefix: "/"}, Event{Module: "object", Event: "created"}, false},
+		{Webhook{Module: "object"}, Event{Module: "object", Event: "created"}, true},
+	}
+	for i, test := range tests {
+		if test.wh.Matches(test.event) != test.matches {
+			t.Fatalf("%d: expected match to be %v", i, test.matches)
+		}
+	}
+}
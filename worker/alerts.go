@@ -6,9 +6,14 @@ import (
 
 	"go.thebigfile.com/core/types"
 	"go.thebigfile.com/renterd/alerts"
+	"go.thebigfile.com/renterd/api"
 	"lukechampine.com/frand"
 )
 
+var (
+	alertBucketQuotaID = alerts.RandomAlertID() // constant until restarted
+)
+
 func randomAlertID() types.Hash256 {
 	return frand.Entropy256()
 }
@@ -68,3 +73,26 @@ func newUploadFailedAlert(bucket, path, contractSet, mimeType string, minShards,
 		Timestamp: time.Now(),
 	}
 }
+
+func newBucketQuotaAlert(bucket string, quota api.BucketQuota, stats api.ObjectsStatsResponse, usage float64) alerts.Alert {
+	severity := alerts.SeverityWarning
+	if usage >= 1 {
+		severity = alerts.SeverityError
+	}
+	size, objects := quota.Used(stats)
+	return alerts.Alert{
+		ID:       alerts.IDForBucket(alertBucketQuotaID, bucket),
+		Severity: severity,
+		Message:  "Bucket is running out of quota",
+		Data: map[string]any{
+			"bucket":     bucket,
+			"usage":      usage,
+			"maxSize":    quota.MaxSize,
+			"maxObjects": quota.MaxObjects,
+			"size":       size,
+			"objects":    objects,
+			"hint":       "Uploads to the bucket are rejected once its quota is exceeded. Either increase the quota or remove objects from the bucket.",
+		},
+		Timestamp: time.Now(),
+	}
+}
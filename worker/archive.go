@@ -0,0 +1,226 @@
+package worker
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"go.thebigfile.com/renterd/api"
+	"go.thebigfile.com/renterd/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// archiveListBatchSize is the number of objects that are listed at once
+	// when building an archive.
+	archiveListBatchSize = 1000
+)
+
+type (
+	// archiveWriter writes the entries of an archive, entries must be written
+	// in full before the next one is created.
+	archiveWriter interface {
+		CreateEntry(name string, size int64, modTime time.Time) (io.Writer, error)
+		Close() error
+	}
+
+	tarArchiveWriter struct {
+		tw *tar.Writer
+	}
+
+	zipArchiveWriter struct {
+		zw *zip.Writer
+	}
+
+	// archiveResponseWriter sets the archive's headers right before the first
+	// byte is written, which allows for returning an error to the client if
+	// the archive fails before that
+	archiveResponseWriter struct {
+		rw      http.ResponseWriter
+		format  string
+		name    string
+		written bool
+	}
+)
+
+func (w *archiveResponseWriter) Write(p []byte) (int, error) {
+	if !w.written {
+		w.written = true
+		w.rw.Header().Set("Content-Type", archiveContentType(w.format))
+		w.rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", w.name+"."+w.format))
+	}
+	return w.rw.Write(p)
+}
+
+func newArchiveWriter(w io.Writer, format string) (archiveWriter, error) {
+	switch format {
+	case api.ArchiveFormatTar:
+		return &tarArchiveWriter{tw: tar.NewWriter(w)}, nil
+	case api.ArchiveFormatZip:
+		return &zipArchiveWriter{zw: zip.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: '%s'", api.ErrInvalidArchiveFormat, format)
+	}
+}
+
+// archiveContentType returns the MIME type of the archive format.
+func archiveContentType(format string) string {
+	if format == api.ArchiveFormatTar {
+		return "application/x-tar"
+	}
+	return "application/zip"
+}
+
+func (a *tarArchiveWriter) CreateEntry(name string, size int64, modTime time.Time) (io.Writer, error) {
+	hdr := &tar.Header{
+		Name:    name,
+		Size:    size,
+		Mode:    0644,
+		ModTime: modTime,
+	}
+	if strings.HasSuffix(name, "/") {
+		hdr.Typeflag = tar.TypeDir
+		hdr.Mode = 0755
+		hdr.Size = 0
+	}
+	if err := a.tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	return a.tw, nil
+}
+
+func (a *tarArchiveWriter) Close() error {
+	return a.tw.Close()
+}
+
+func (a *zipArchiveWriter) CreateEntry(name string, _ int64, modTime time.Time) (io.Writer, error) {
+	// the content is stored without compression, objects are often already
+	// compressed and compressing them would make the download CPU bound
+	return a.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: modTime,
+	})
+}
+
+func (a *zipArchiveWriter) Close() error {
+	return a.zw.Close()
+}
+
+// archiveEntryName returns the name of the archive entry for the object at the
+// given path. The name is cleaned, false is returned if it would be extracted
+// outside of the directory the archive is extracted to.
+func archiveEntryName(objectPath string) (string, bool) {
+	name := path.Clean(strings.TrimLeft(objectPath, "/"))
+	if name == "." {
+		return "", false
+	}
+
+	// extractors on Windows also treat backslashes as separators
+	for _, elem := range strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if elem == ".." {
+			return "", false
+		}
+	}
+	if strings.HasSuffix(objectPath, "/") {
+		name += "/"
+	}
+	return name, true
+}
+
+// DownloadArchive streams an archive that contains all objects in the bucket
+// whose path starts with the given prefix. The objects are downloaded one at a
+// time using the download manager, so the memory used is bounded by its memory
+// manager regardless of the number of objects. Objects whose path can't be
+// extracted safely and objects that can't be decrypted with the given customer
+// key are skipped.
+func (w *Worker) DownloadArchive(ctx context.Context, wr io.Writer, bucket, prefix string, opts api.DownloadArchiveOptions) error {
+	if opts.Format == "" {
+		opts.Format = api.ArchiveFormatZip
+	}
+	aw, err := newArchiveWriter(wr, opts.Format)
+	if err != nil {
+		return err
+	}
+
+	// fetch the first batch before writing anything to be able to return
+	// errors like a missing bucket to the caller
+	resp, err := w.bus.ListObjects(ctx, bucket, api.ListObjectOptions{
+		Prefix: prefix,
+		Limit:  archiveListBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't list objects: %w", err)
+	}
+	return w.writeArchive(ctx, aw, bucket, prefix, resp, opts)
+}
+
+func (w *Worker) writeArchive(ctx context.Context, aw archiveWriter, bucket, prefix string, resp api.ObjectsListResponse, opts api.DownloadArchiveOptions) error {
+	for {
+		for _, om := range resp.Objects {
+			if err := w.writeArchiveEntry(ctx, aw, bucket, om, opts); err != nil {
+				return fmt.Errorf("failed to add '%s' to archive: %w", om.Name, err)
+			}
+		}
+		if !resp.HasMore {
+			break
+		}
+
+		var err error
+		resp, err = w.bus.ListObjects(ctx, bucket, api.ListObjectOptions{
+			Prefix: prefix,
+			Marker: resp.NextMarker,
+			Limit:  archiveListBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't list objects: %w", err)
+		}
+	}
+	return aw.Close()
+}
+
+func (w *Worker) writeArchiveEntry(ctx context.Context, aw archiveWriter, bucket string, om api.ObjectMetadata, opts api.DownloadArchiveOptions) error {
+	name, ok := archiveEntryName(om.Name)
+	if !ok {
+		w.logger.Warnw("skipping object with unsafe path in archive", "bucket", bucket, "path", om.Name)
+		return nil
+	}
+
+	// directory markers don't have any content
+	if strings.HasSuffix(name, "/") {
+		_, err := aw.CreateEntry(name, 0, om.ModTime.Std())
+		return err
+	}
+
+	// the object is downloaded to get its content and the metadata that
+	// belongs to it in case it was updated after it was listed
+	gor, err := w.GetObject(ctx, bucket, om.Name, api.DownloadObjectOptions{
+		CustomerKey: opts.CustomerKey,
+	})
+	if utils.IsErr(err, api.ErrCustomerKeyRequired) || utils.IsErr(err, api.ErrCustomerKeyMismatch) {
+		// the prefix might contain objects that were uploaded with
+		// different customer keys, or without one
+		w.logger.Warnw("skipping object that can't be decrypted in archive", "bucket", bucket, "path", om.Name, zap.Error(err))
+		return nil
+	} else if utils.IsErr(err, api.ErrObjectNotFound) {
+		// the object might have been deleted after it was listed
+		w.logger.Debugw("skipping object that was deleted in archive", "bucket", bucket, "path", om.Name)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer gor.Content.Close()
+
+	ew, err := aw.CreateEntry(name, gor.Size, gor.LastModified.Std())
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(ew, gor.Content, gor.Size)
+	return err
+}
@@ -0,0 +1,166 @@
+package worker
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.thebigfile.com/renterd/api"
+	"lukechampine.com/frand"
+)
+
+func TestArchiveWriter(t *testing.T) {
+	modTime := time.Now().Truncate(time.Second).UTC()
+	entries := []struct {
+		name string
+		data []byte
+	}{
+		{"dir/", nil},
+		{"dir/foo", frand.Bytes(100)},
+		{"dir/bar", nil},
+		{"baz", frand.Bytes(10)},
+	}
+	writeArchive := func(format string) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		aw, err := newArchiveWriter(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			ew, err := aw.CreateEntry(e.name, int64(len(e.data)), modTime)
+			if err != nil {
+				t.Fatal(err)
+			} else if _, err := ew.Write(e.data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := aw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	// assert the tar archive contains all entries
+	tr := tar.NewReader(bytes.NewReader(writeArchive(api.ArchiveFormatTar)))
+	for _, e := range entries {
+		hdr, err := tr.Next()
+		if err != nil {
+			t.Fatal(err)
+		} else if hdr.Name != e.name || !hdr.ModTime.Equal(modTime) {
+			t.Fatal("unexpected header", hdr.Name, hdr.ModTime)
+		} else if isDir := hdr.Typeflag == tar.TypeDir; isDir != (e.name == "dir/") {
+			t.Fatal("unexpected type", hdr.Typeflag)
+		} else if data, err := io.ReadAll(tr); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(data, e.data) {
+			t.Fatal("data mismatch", e.name)
+		}
+	}
+	if _, err := tr.Next(); !errors.Is(err, io.EOF) {
+		t.Fatal("expected EOF", err)
+	}
+
+	// assert the zip archive contains all entries
+	b := writeArchive(api.ArchiveFormatZip)
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(zr.File) != len(entries) {
+		t.Fatal("unexpected number of files", len(zr.File))
+	}
+	for i, f := range zr.File {
+		if f.Name != entries[i].name || !f.Modified.Equal(modTime) {
+			t.Fatal("unexpected file", f.Name, f.Modified)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(data, entries[i].data) {
+			t.Fatal("data mismatch", f.Name)
+		}
+	}
+
+	// assert unknown formats are rejected
+	if _, err := newArchiveWriter(io.Discard, "rar"); !errors.Is(err, api.ErrInvalidArchiveFormat) {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func TestArchiveResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	aw := &archiveResponseWriter{rw: rec, format: api.ArchiveFormatTar, name: "foo"}
+
+	// assert no headers are set before the first write
+	if aw.written || rec.Header().Get("Content-Type") != "" {
+		t.Fatal("unexpected headers")
+	}
+	if _, err := aw.Write([]byte("bar")); err != nil {
+		t.Fatal(err)
+	} else if !aw.written {
+		t.Fatal("expected written to be set")
+	} else if ct := rec.Header().Get("Content-Type"); ct != "application/x-tar" {
+		t.Fatal("unexpected content type", ct)
+	} else if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="foo.tar"` {
+		t.Fatal("unexpected content disposition", cd)
+	}
+}
+
+func TestArchiveEntryName(t *testing.T) {
+	for _, test := range []struct {
+		path string
+		name string
+		ok   bool
+	}{
+		{"/foo", "foo", true},
+		{"/dir/", "dir/", true},
+		{"/dir/./foo", "dir/foo", true},
+		{"/dir/../foo", "foo", true},
+		{"//foo", "foo", true},
+		{"/../foo", "", false},
+		{"/dir/../../foo", "", false},
+		{"/dir/..\\..\\foo", "", false},
+		{"/..", "", false},
+		{"/", "", false},
+	} {
+		name, ok := archiveEntryName(test.path)
+		if ok != test.ok || name != test.name {
+			t.Fatalf("%v: expected '%v' (%v), got '%v' (%v)", test.path, test.name, test.ok, name, ok)
+		}
+	}
+}
+
+func TestWriteArchiveDeletedObject(t *testing.T) {
+	w := newTestWorker(t)
+
+	// objects that are deleted after they were listed are skipped
+	var buf bytes.Buffer
+	aw, err := newArchiveWriter(&buf, api.ArchiveFormatZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.writeArchive(context.Background(), aw, testBucket, "", api.ObjectsListResponse{
+		Objects: []api.ObjectMetadata{{Name: "/dir/"}, {Name: "/dir/deleted"}},
+	}, api.DownloadArchiveOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(zr.File) != 1 || zr.File[0].Name != "dir/" {
+		t.Fatal("unexpected entries", zr.File)
+	}
+}
@@ -0,0 +1,324 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"go.thebigfile.com/renterd/api"
+)
+
+const (
+	// bandwidthChunkSize is the maximum number of bytes that are read or
+	// written at once by a throttled stream, it keeps the streams from
+	// bursting when the limit is lower than the size of the buffers used by
+	// the callers.
+	bandwidthChunkSize = 1 << 16 // 64 KiB
+
+	// bandwidthMeterWindow is the number of seconds over which the throughput
+	// is averaged.
+	bandwidthMeterWindow = 10
+
+	// bandwidthBucketIdleTimeout is the amount of time after which the
+	// limiters of a bucket without any transfers are evicted.
+	bandwidthBucketIdleTimeout = 10 * time.Minute
+)
+
+type (
+	// bandwidthManager enforces the global and per-bucket bandwidth limits of
+	// the worker and keeps track of its current throughput.
+	bandwidthManager struct {
+		globalDownload *bandwidthLimiter
+		globalUpload   *bandwidthLimiter
+
+		downloads *bandwidthMeter
+		uploads   *bandwidthMeter
+
+		mu      sync.Mutex
+		buckets map[string]*bucketLimiters
+	}
+
+	bucketLimiters struct {
+		download *bandwidthLimiter
+		upload   *bandwidthLimiter
+	}
+
+	// bandwidthLimiter is a token bucket that allows for bursts of up to one
+	// second worth of bandwidth. A limit of 0 means the bandwidth is unlimited.
+	bandwidthLimiter struct {
+		mu     sync.Mutex
+		limit  uint64
+		tokens float64
+		last   time.Time
+	}
+
+	// bandwidthMeter keeps track of the number of bytes that were transferred
+	// during the last couple of seconds.
+	bandwidthMeter struct {
+		mu      sync.Mutex
+		buckets [bandwidthMeterWindow]uint64
+		last    int64 // unix timestamp of the most recent bucket
+	}
+
+	throttledReader struct {
+		ctx      context.Context
+		r        io.Reader
+		limiters []*bandwidthLimiter
+		meter    *bandwidthMeter
+	}
+
+	throttledWriter struct {
+		ctx      context.Context
+		w        io.Writer
+		limiters []*bandwidthLimiter
+		meter    *bandwidthMeter
+	}
+)
+
+func newBandwidthManager() *bandwidthManager {
+	return &bandwidthManager{
+		globalDownload: newBandwidthLimiter(0),
+		globalUpload:   newBandwidthLimiter(0),
+
+		downloads: &bandwidthMeter{},
+		uploads:   &bandwidthMeter{},
+
+		buckets: make(map[string]*bucketLimiters),
+	}
+}
+
+// DownloadWriter wraps the writer of a download from the given bucket so it
+// respects the bandwidth limits in the settings.
+func (m *bandwidthManager) DownloadWriter(ctx context.Context, w io.Writer, bucket string, bs api.BandwidthSettings) io.Writer {
+	m.globalDownload.SetLimit(bs.Global.Download)
+	limiters := []*bandwidthLimiter{m.globalDownload}
+	if bl, ok := m.bucketLimiters(bucket, bs); ok {
+		limiters = append(limiters, bl.download)
+	}
+	if bs.PerRequest.Download > 0 {
+		limiters = append(limiters, newBandwidthLimiter(bs.PerRequest.Download))
+	}
+	return &throttledWriter{
+		ctx:      ctx,
+		w:        w,
+		limiters: limiters,
+		meter:    m.downloads,
+	}
+}
+
+// UploadReader wraps the reader of an upload to the given bucket so it
+// respects the bandwidth limits in the settings.
+func (m *bandwidthManager) UploadReader(ctx context.Context, r io.Reader, bucket string, bs api.BandwidthSettings) io.Reader {
+	m.globalUpload.SetLimit(bs.Global.Upload)
+	limiters := []*bandwidthLimiter{m.globalUpload}
+	if bl, ok := m.bucketLimiters(bucket, bs); ok {
+		limiters = append(limiters, bl.upload)
+	}
+	if bs.PerRequest.Upload > 0 {
+		limiters = append(limiters, newBandwidthLimiter(bs.PerRequest.Upload))
+	}
+	return &throttledReader{
+		ctx:      ctx,
+		r:        r,
+		limiters: limiters,
+		meter:    m.uploads,
+	}
+}
+
+// DownloadThroughput returns the current download throughput in bytes per
+// second.
+func (m *bandwidthManager) DownloadThroughput() float64 {
+	return m.downloads.Throughput(time.Now())
+}
+
+// UploadThroughput returns the current upload throughput in bytes per second.
+func (m *bandwidthManager) UploadThroughput() float64 {
+	return m.uploads.Throughput(time.Now())
+}
+
+// bucketLimiters returns the limiters that are shared by all transfers of the
+// given bucket, limiters are only created for buckets that have limits
+// configured in the settings.
+func (m *bandwidthManager) bucketLimiters(bucket string, bs api.BandwidthSettings) (*bucketLimiters, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneBuckets(bs, time.Now())
+
+	limits, ok := bs.Buckets[bucket]
+	if !ok {
+		return nil, false
+	}
+	bl, exists := m.buckets[bucket]
+	if !exists {
+		bl = &bucketLimiters{
+			download: newBandwidthLimiter(limits.Download),
+			upload:   newBandwidthLimiter(limits.Upload),
+		}
+		m.buckets[bucket] = bl
+	}
+	bl.download.SetLimit(limits.Download)
+	bl.upload.SetLimit(limits.Upload)
+	return bl, true
+}
+
+// pruneBuckets evicts the limiters of buckets that no longer have limits
+// configured or that haven't seen any transfers in a while, the caller must
+// hold the lock.
+func (m *bandwidthManager) pruneBuckets(bs api.BandwidthSettings, now time.Time) {
+	for bucket, bl := range m.buckets {
+		_, limited := bs.Buckets[bucket]
+		idle := now.Sub(bl.download.lastUsed()) > bandwidthBucketIdleTimeout &&
+			now.Sub(bl.upload.lastUsed()) > bandwidthBucketIdleTimeout
+		if !limited || idle {
+			delete(m.buckets, bucket)
+		}
+	}
+}
+
+func newBandwidthLimiter(limit uint64) *bandwidthLimiter {
+	return &bandwidthLimiter{
+		limit:  limit,
+		tokens: float64(limit),
+		last:   time.Now(),
+	}
+}
+
+// SetLimit updates the limit of the limiter.
+func (l *bandwidthLimiter) SetLimit(limit uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit == limit {
+		return
+	}
+	l.refill(time.Now())
+	l.limit = limit
+	if l.tokens > float64(limit) {
+		l.tokens = float64(limit)
+	}
+}
+
+// lastUsed returns the last time the limiter was refilled, which happens
+// whenever a limited transfer takes tokens from it.
+func (l *bandwidthLimiter) lastUsed() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.last
+}
+
+// Wait blocks until n bytes can be transferred without exceeding the limit.
+func (l *bandwidthLimiter) Wait(ctx context.Context, n int) error {
+	d := l.reserve(n, time.Now())
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-t.C:
+		return nil
+	}
+}
+
+// reserve takes n tokens from the bucket and returns how long the caller has
+// to wait before the tokens are available. The tokens are reserved right away
+// so concurrent callers queue up behind each other.
+func (l *bandwidthLimiter) reserve(n int, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit == 0 {
+		return 0
+	}
+	l.refill(now)
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / float64(l.limit) * float64(time.Second))
+}
+
+// refill adds the tokens that accumulated since the last refill, the caller
+// must hold the lock.
+func (l *bandwidthLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * float64(l.limit)
+		if l.tokens > float64(l.limit) {
+			l.tokens = float64(l.limit)
+		}
+		l.last = now
+	}
+}
+
+// Record adds n transferred bytes to the meter.
+func (m *bandwidthMeter) Record(n int, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance(now.Unix())
+	m.buckets[m.last%bandwidthMeterWindow] += uint64(n)
+}
+
+// Throughput returns the average number of bytes that were transferred per
+// second over the window of the meter.
+func (m *bandwidthMeter) Throughput(now time.Time) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance(now.Unix())
+	var total uint64
+	for _, n := range m.buckets {
+		total += n
+	}
+	return float64(total) / bandwidthMeterWindow
+}
+
+// advance clears the buckets of the seconds that passed since the meter was
+// last used, the caller must hold the lock.
+func (m *bandwidthMeter) advance(now int64) {
+	if now <= m.last {
+		return
+	}
+	for i := m.last + 1; i <= now && i-m.last <= bandwidthMeterWindow; i++ {
+		m.buckets[i%bandwidthMeterWindow] = 0
+	}
+	m.last = now
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > bandwidthChunkSize {
+		p = p[:bandwidthChunkSize]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		for _, l := range r.limiters {
+			if err := l.Wait(r.ctx, n); err != nil {
+				return 0, err
+			}
+		}
+		r.meter.Record(n, time.Now())
+	}
+	return n, err
+}
+
+func (w *throttledWriter) Write(p []byte) (written int, _ error) {
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > bandwidthChunkSize {
+			chunk = chunk[:bandwidthChunkSize]
+		}
+		for _, l := range w.limiters {
+			if err := l.Wait(w.ctx, len(chunk)); err != nil {
+				return written, err
+			}
+		}
+		n, err := w.w.Write(chunk)
+		written += n
+		w.meter.Record(n, time.Now())
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
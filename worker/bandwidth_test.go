@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.thebigfile.com/renterd/api"
+)
+
+func TestBandwidthLimiter(t *testing.T) {
+	now := time.Now()
+	l := newBandwidthLimiter(100)
+	l.last = now
+
+	// the limiter allows a burst of one second worth of bandwidth
+	if d := l.reserve(100, now); d != 0 {
+		t.Fatal("unexpected wait", d)
+	}
+
+	// the next reservation has to wait for the tokens to refill
+	if d := l.reserve(50, now); d != 500*time.Millisecond {
+		t.Fatal("unexpected wait", d)
+	}
+
+	// concurrent reservations queue up behind each other
+	if d := l.reserve(50, now); d != time.Second {
+		t.Fatal("unexpected wait", d)
+	}
+
+	// after two seconds the debt is paid off
+	if d := l.reserve(0, now.Add(2*time.Second)); d != 0 {
+		t.Fatal("unexpected wait", d)
+	}
+
+	// the tokens don't accumulate beyond the burst
+	if d := l.reserve(150, now.Add(time.Hour)); d != 500*time.Millisecond {
+		t.Fatal("unexpected wait", d)
+	}
+
+	// removing the limit removes the wait
+	l.SetLimit(0)
+	if d := l.reserve(1<<30, now.Add(time.Hour)); d != 0 {
+		t.Fatal("unexpected wait", d)
+	}
+
+	// waiting respects the context
+	l = newBandwidthLimiter(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx, 10); !errors.Is(err, context.Canceled) {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func TestBandwidthMeter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	m := &bandwidthMeter{}
+
+	// record 100 bytes per second for 5 seconds
+	for i := 0; i < 5; i++ {
+		m.Record(100, now.Add(time.Duration(i)*time.Second))
+	}
+	if tp := m.Throughput(now.Add(4 * time.Second)); tp != 50 {
+		t.Fatal("unexpected throughput", tp)
+	}
+
+	// the oldest seconds fall out of the window
+	if tp := m.Throughput(now.Add(12 * time.Second)); tp != 20 {
+		t.Fatal("unexpected throughput", tp)
+	} else if tp := m.Throughput(now.Add(time.Minute)); tp != 0 {
+		t.Fatal("unexpected throughput", tp)
+	}
+}
+
+func TestBandwidthManager(t *testing.T) {
+	m := newBandwidthManager()
+	bs := api.BandwidthSettings{
+		Buckets: map[string]api.BandwidthLimits{
+			"limited": {Download: 1 << 20},
+		},
+	}
+
+	// unlimited buckets only use the global limiter
+	var buf bytes.Buffer
+	tw := m.DownloadWriter(context.Background(), &buf, "default", bs).(*throttledWriter)
+	if len(tw.limiters) != 1 {
+		t.Fatal("unexpected number of limiters", len(tw.limiters))
+	}
+
+	// writes are split into chunks and recorded
+	if n, err := tw.Write(make([]byte, 3*bandwidthChunkSize)); err != nil {
+		t.Fatal(err)
+	} else if n != 3*bandwidthChunkSize || buf.Len() != n {
+		t.Fatal("unexpected number of bytes written", n, buf.Len())
+	} else if tp := m.DownloadThroughput(); tp != float64(3*bandwidthChunkSize)/bandwidthMeterWindow {
+		t.Fatal("unexpected throughput", tp)
+	}
+
+	// limited buckets share a limiter, requests get their own
+	bs.PerRequest.Download = 1 << 10
+	tw1 := m.DownloadWriter(context.Background(), &buf, "limited", bs).(*throttledWriter)
+	tw2 := m.DownloadWriter(context.Background(), &buf, "limited", bs).(*throttledWriter)
+	if len(tw1.limiters) != 3 || len(tw2.limiters) != 3 {
+		t.Fatal("unexpected number of limiters", len(tw1.limiters), len(tw2.limiters))
+	} else if tw1.limiters[1] != tw2.limiters[1] {
+		t.Fatal("expected bucket limiter to be shared")
+	} else if tw1.limiters[2] == tw2.limiters[2] {
+		t.Fatal("expected request limiters to differ")
+	}
+
+	// idle buckets are evicted
+	m.mu.Lock()
+	m.pruneBuckets(bs, time.Now().Add(bandwidthBucketIdleTimeout/2))
+	if len(m.buckets) != 1 {
+		t.Fatal("expected bucket to be kept", len(m.buckets))
+	}
+	m.pruneBuckets(bs, time.Now().Add(2*bandwidthBucketIdleTimeout))
+	if len(m.buckets) != 0 {
+		t.Fatal("expected idle bucket to be evicted", len(m.buckets))
+	}
+	m.mu.Unlock()
+
+	// buckets without limits are evicted
+	m.DownloadWriter(context.Background(), &buf, "limited", bs)
+	delete(bs.Buckets, "limited")
+	m.DownloadWriter(context.Background(), &buf, "default", bs)
+	if len(m.buckets) != 0 {
+		t.Fatal("expected bucket without limits to be evicted", len(m.buckets))
+	}
+}
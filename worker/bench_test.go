@@ -45,7 +45,7 @@ func BenchmarkDownloaderSingleObject(b *testing.B) {
 	b.SetBytes(o.Object.Size)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		err = w.downloadManager.DownloadObject(context.Background(), io.Discard, *o.Object.Object, 0, uint64(o.Object.Size), w.Contracts())
+		err = w.downloadManager.DownloadObject(context.Background(), io.Discard, *o.Object.Object, 0, uint64(o.Object.Size), w.Contracts(), "")
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"go.thebigfile.com/renterd/api"
+	"go.uber.org/zap"
+)
+
+type (
+	// checksumVerifier wraps the content of a download and fails the read
+	// that completes the content if the content doesn't match the expected
+	// checksum. The final bytes are withheld in that case to make sure the
+	// caller never receives the full, corrupted content.
+	checksumVerifier struct {
+		io.ReadCloser
+		hasher    hash.Hash
+		expected  string
+		remaining int64
+		logger    *zap.SugaredLogger
+	}
+)
+
+func newChecksumHasher(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case api.ChecksumAlgorithmCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli)), nil
+	case api.ChecksumAlgorithmSHA256:
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("%w: '%s'", api.ErrInvalidChecksumAlgorithm, algorithm)
+	}
+}
+
+func newChecksumVerifier(rc io.ReadCloser, algorithm, expected string, size int64, logger *zap.SugaredLogger) (*checksumVerifier, error) {
+	hasher, err := newChecksumHasher(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &checksumVerifier{
+		ReadCloser: rc,
+		hasher:     hasher,
+		expected:   expected,
+		remaining:  size,
+		logger:     logger,
+	}, nil
+}
+
+func encodeChecksum(h hash.Hash) string {
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (cv *checksumVerifier) Read(p []byte) (int, error) {
+	if cv.remaining <= 0 {
+		return 0, io.EOF
+	} else if int64(len(p)) > cv.remaining {
+		p = p[:cv.remaining]
+	}
+
+	n, err := cv.ReadCloser.Read(p)
+	cv.hasher.Write(p[:n])
+	cv.remaining -= int64(n)
+	if cv.remaining == 0 {
+		if checksum := encodeChecksum(cv.hasher); checksum != cv.expected {
+			cv.logger.Errorw("checksum mismatch", "expected", cv.expected, "actual", checksum)
+			return 0, fmt.Errorf("%w: expected '%s', got '%s'", api.ErrChecksumMismatch, cv.expected, checksum)
+		} else if err == nil {
+			err = io.EOF
+		}
+	} else if errors.Is(err, io.EOF) {
+		// the content ended before its expected size, so it can't match
+		// the checksum
+		return n, fmt.Errorf("%w: content ended %d bytes early", io.ErrUnexpectedEOF, cv.remaining)
+	}
+	return n, err
+}
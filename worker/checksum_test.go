@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"testing"
+
+	"go.thebigfile.com/renterd/api"
+	"go.uber.org/zap"
+)
+
+func TestChecksumVerifier(t *testing.T) {
+	data := []byte("hello world")
+	sum := sha256.Sum256(data)
+	checksum := base64.StdEncoding.EncodeToString(sum[:])
+
+	// assert unknown algorithms are rejected
+	if _, err := newChecksumHasher("MD5"); !errors.Is(err, api.ErrInvalidChecksumAlgorithm) {
+		t.Fatal("unexpected error", err)
+	}
+
+	// assert the content is returned if the checksum matches
+	cv, err := newChecksumVerifier(io.NopCloser(bytes.NewReader(data)), api.ChecksumAlgorithmSHA256, checksum, int64(len(data)), zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	} else if b, err := io.ReadAll(cv); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(b, data) {
+		t.Fatal("unexpected data", string(b))
+	}
+
+	// assert the final bytes are withheld if the checksum doesn't match
+	corrupted := append([]byte(nil), data...)
+	corrupted[0] ^= 1
+	cv, err = newChecksumVerifier(io.NopCloser(bytes.NewReader(corrupted)), api.ChecksumAlgorithmSHA256, checksum, int64(len(data)), zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(cv)
+	if !errors.Is(err, api.ErrChecksumMismatch) {
+		t.Fatal("expected checksum mismatch", err)
+	} else if len(b) == len(data) {
+		t.Fatal("expected content to be truncated")
+	}
+
+	// assert a stream that ends early fails instead of returning the partial
+	// content as if it were complete
+	cv, err = newChecksumVerifier(io.NopCloser(bytes.NewReader(data[:5])), api.ChecksumAlgorithmSHA256, checksum, int64(len(data)), zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := io.ReadAll(cv); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("expected unexpected EOF", err)
+	}
+}
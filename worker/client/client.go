@@ -69,6 +69,35 @@ func (c *Client) DeleteObject(ctx context.Context, bucket, path string, opts api
 	return
 }
 
+// DownloadArchive downloads an archive that contains all objects with the
+// given prefix.
+func (c *Client) DownloadArchive(ctx context.Context, w io.Writer, bucket, prefix string, opts api.DownloadArchiveOptions) (err error) {
+	values := url.Values{}
+	values.Set("bucket", bucket)
+	opts.ApplyValues(values)
+	prefix = api.ObjectPathEscape(prefix)
+
+	c.c.Custom("GET", fmt.Sprintf("/archive/%s", prefix), nil, []byte{})
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/archive/%s?%s", c.c.BaseURL, prefix, values.Encode()), http.NoBody)
+	if err != nil {
+		panic(err)
+	}
+	req.SetBasicAuth("", c.c.WithContext(ctx).Password)
+	opts.ApplyHeaders(req.Header)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err, _ := io.ReadAll(resp.Body)
+		return errors.New(string(err))
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
 // DownloadObject downloads the object at the given path.
 func (c *Client) DownloadObject(ctx context.Context, w io.Writer, bucket, path string, opts api.DownloadObjectOptions) (err error) {
 	if strings.HasSuffix(path, "/") {
@@ -109,14 +138,25 @@ func (c *Client) HeadObject(ctx context.Context, bucket, path string, opts api.H
 	req.SetBasicAuth("", c.c.WithContext(ctx).Password)
 	opts.ApplyHeaders(req.Header)
 
-	headers, statusCode, err := utils.DoRequest(req, nil)
-	if err != nil && statusCode == http.StatusNotFound {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+	case http.StatusNotFound:
 		return nil, api.ErrObjectNotFound
-	} else if err != nil {
-		return nil, errors.New(http.StatusText(statusCode))
+	case http.StatusNotModified:
+		return nil, api.ErrNotModified
+	case http.StatusPreconditionFailed:
+		return nil, api.ErrPreconditionFailed
+	default:
+		return nil, errors.New(http.StatusText(resp.StatusCode))
 	}
 
-	head, err := parseObjectResponseHeaders(headers)
+	head, err := parseObjectResponseHeaders(resp.Header)
 	if err != nil {
 		return nil, err
 	}
@@ -187,6 +227,69 @@ func (c *Client) ObjectEntries(ctx context.Context, bucket, path string, opts ap
 	return
 }
 
+// AbortResumableUpload aborts a resumable upload.
+func (c *Client) AbortResumableUpload(ctx context.Context, uploadID string) (err error) {
+	err = c.c.WithContext(ctx).DELETE(fmt.Sprintf("/resumable/%s", uploadID))
+	return
+}
+
+// CompleteResumableUpload completes a resumable upload, creating an object
+// from the chunks that were uploaded.
+func (c *Client) CompleteResumableUpload(ctx context.Context, uploadID string) (resp api.MultipartCompleteResponse, err error) {
+	err = c.c.WithContext(ctx).POST(fmt.Sprintf("/resumable/%s", uploadID), nil, &resp)
+	return
+}
+
+// CreateResumableUpload creates a resumable upload for the object at the given
+// path.
+func (c *Client) CreateResumableUpload(ctx context.Context, bucket, path, mimeType string, metadata api.ObjectUserMetadata) (resp api.ResumableUpload, err error) {
+	err = c.c.WithContext(ctx).POST("/resumable", api.ResumableUploadCreateRequest{
+		Bucket:   bucket,
+		Path:     path,
+		MimeType: mimeType,
+		Metadata: metadata,
+	}, &resp)
+	return
+}
+
+// ResumableUpload returns the resumable upload with the given id, its offset is
+// the offset at which the next chunk has to be uploaded.
+func (c *Client) ResumableUpload(ctx context.Context, uploadID string) (resp api.ResumableUpload, err error) {
+	err = c.c.WithContext(ctx).GET(fmt.Sprintf("/resumable/%s", uploadID), &resp)
+	return
+}
+
+// UploadResumableChunk uploads the data in r as the next chunk of a resumable
+// upload, the offset has to match the upload's current offset.
+func (c *Client) UploadResumableChunk(ctx context.Context, r io.Reader, uploadID string, offset int64, opts api.UploadResumableChunkOptions) (*api.ResumableUpload, error) {
+	c.c.Custom("PATCH", fmt.Sprintf("/resumable/%s", uploadID), []byte{}, (*api.ResumableUpload)(nil))
+
+	values := make(url.Values)
+	opts.Apply(values)
+
+	u, err := url.Parse(fmt.Sprintf("%v/resumable/%v", c.c.BaseURL, uploadID))
+	if err != nil {
+		panic(err)
+	}
+	u.RawQuery = values.Encode()
+	req, err := http.NewRequestWithContext(ctx, "PATCH", u.String(), r)
+	if err != nil {
+		panic(err)
+	}
+	req.SetBasicAuth("", c.c.WithContext(ctx).Password)
+	req.Header.Set(api.ResumableUploadOffsetHeader, fmt.Sprint(offset))
+	if opts.ContentLength != 0 {
+		req.ContentLength = opts.ContentLength
+	} else if req.ContentLength, err = sizeFromSeeker(r); err != nil {
+		return nil, fmt.Errorf("failed to get content length from seeker: %w", err)
+	}
+	var resp api.ResumableUpload
+	if _, _, err := utils.DoRequest(req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // State returns the current state of the worker.
 func (c *Client) State() (state api.WorkerStateResponse, err error) {
 	err = c.c.GET("/state", &state)
@@ -287,7 +390,13 @@ func (c *Client) object(ctx context.Context, bucket, path string, opts api.Downl
 	if err != nil {
 		return nil, nil, err
 	}
-	if resp.StatusCode != 200 && resp.StatusCode != 206 {
+	if resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close()
+		return nil, nil, api.ErrNotModified
+	} else if resp.StatusCode == http.StatusPreconditionFailed {
+		_ = resp.Body.Close()
+		return nil, nil, api.ErrPreconditionFailed
+	} else if resp.StatusCode != 200 && resp.StatusCode != 206 {
 		err, _ := io.ReadAll(resp.Body)
 		_ = resp.Body.Close()
 		return nil, nil, errors.New(string(err))
@@ -337,6 +446,10 @@ func parseObjectResponseHeaders(header http.Header) (api.HeadObjectResponse, err
 		Range:        r,
 		Size:         size,
 		Metadata:     api.ExtractObjectUserMetadataFrom(headers),
+		VersionID:    header.Get(api.ObjectVersionIDHeader),
+
+		ChecksumAlgorithm: header.Get(api.ObjectChecksumAlgorithmHeader),
+		Checksum:          header.Get(api.ObjectChecksumHeader),
 	}, nil
 }
 
@@ -2,6 +2,7 @@ package worker
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -34,6 +35,7 @@ type (
 		hm     HostManager
 		mm     MemoryManager
 		os     ObjectStore
+		cache  *slabCache // optional
 		logger *zap.SugaredLogger
 
 		maxOverdrive     uint64
@@ -83,6 +85,7 @@ type (
 	slabDownloadResponse struct {
 		mem              Memory
 		surchargeApplied bool
+		cached           []byte
 		shards           [][]byte
 		index            int
 		err              error
@@ -127,19 +130,20 @@ type (
 	}
 )
 
-func (w *Worker) initDownloadManager(maxMemory, maxOverdrive uint64, overdriveTimeout time.Duration, logger *zap.Logger) {
+func (w *Worker) initDownloadManager(maxMemory, maxOverdrive uint64, overdriveTimeout time.Duration, cache *slabCache, logger *zap.Logger) {
 	if w.downloadManager != nil {
 		panic("download manager already initialized") // developer error
 	}
-	w.downloadManager = newDownloadManager(w.shutdownCtx, w, w.bus, maxMemory, maxOverdrive, overdriveTimeout, logger)
+	w.downloadManager = newDownloadManager(w.shutdownCtx, w, w.bus, maxMemory, maxOverdrive, overdriveTimeout, cache, logger)
 }
 
-func newDownloadManager(ctx context.Context, hm HostManager, os ObjectStore, maxMemory, maxOverdrive uint64, overdriveTimeout time.Duration, logger *zap.Logger) *downloadManager {
+func newDownloadManager(ctx context.Context, hm HostManager, os ObjectStore, maxMemory, maxOverdrive uint64, overdriveTimeout time.Duration, cache *slabCache, logger *zap.Logger) *downloadManager {
 	logger = logger.Named("downloadmanager")
 	return &downloadManager{
 		hm:     hm,
 		mm:     newMemoryManager(maxMemory, logger),
 		os:     os,
+		cache:  cache,
 		logger: logger.Sugar(),
 
 		maxOverdrive:     maxOverdrive,
@@ -154,7 +158,16 @@ func newDownloadManager(ctx context.Context, hm HostManager, os ObjectStore, max
 	}
 }
 
-func (mgr *downloadManager) DownloadObject(ctx context.Context, w io.Writer, o object.Object, offset, length uint64, contracts []api.ContractMetadata) (err error) {
+// DownloadObject downloads the given range of the object and writes it to w.
+// If the slab cache is enabled, the slabs are served from and added to the
+// cache, the cacheID is used to invalidate the entries when the object changes
+// and caching is skipped if it's empty.
+func (mgr *downloadManager) DownloadObject(ctx context.Context, w io.Writer, o object.Object, offset, length uint64, contracts []api.ContractMetadata, cacheID string) (err error) {
+	cache := mgr.cache
+	if cacheID == "" {
+		cache = nil
+	}
+
 	// calculate what slabs we need
 	var ss []slabSlice
 	for _, s := range o.Slabs {
@@ -242,6 +255,24 @@ func (mgr *downloadManager) DownloadObject(ctx context.Context, w io.Writer, o o
 				continue // handle partial slab separately
 			}
 
+			// check if the slab is cached
+			if cache != nil {
+				mem := mm.AcquireMemory(ctx, uint64(next.Length))
+				if mem == nil {
+					return // interrupted
+				}
+				if data, ok := cache.Get(slabCacheKey(next.SlabSlice)); ok {
+					select {
+					case responseChan <- &slabDownloadResponse{mem: mem, cached: data, index: slabIndex}:
+					case <-ctx.Done():
+						mem.Release()
+						return
+					}
+					continue
+				}
+				mem.Release()
+			}
+
 			// check if we have enough downloaders
 			var available uint8
 			for _, s := range next.Shards {
@@ -319,6 +350,28 @@ outer:
 							mgr.logger.Errorf("failed to send partial slab", respIndex, err)
 							return err
 						}
+					} else if next.cached != nil {
+						// Cached slab.
+						_, err = bw.Write(next.cached)
+						if err != nil {
+							mgr.logger.Errorf("failed to send cached slab %v: %v", respIndex, err)
+							return err
+						}
+					} else if cache != nil {
+						// Regular slab that is added to the cache.
+						var buf bytes.Buffer
+						slabs[respIndex].Decrypt(next.shards)
+						err := slabs[respIndex].Recover(&buf, next.shards)
+						if err != nil {
+							mgr.logger.Errorf("failed to recover slab %v: %v", respIndex, err)
+							return err
+						}
+						cache.Put(cacheID, slabCacheKey(slabs[respIndex].SlabSlice), buf.Bytes())
+						_, err = bw.Write(buf.Bytes())
+						if err != nil {
+							mgr.logger.Errorf("failed to send slab %v: %v", respIndex, err)
+							return err
+						}
 					} else {
 						// Regular slab.
 						slabs[respIndex].Decrypt(next.shards)
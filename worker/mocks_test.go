@@ -332,6 +332,7 @@ type (
 		mu                    sync.Mutex
 		objects               map[string]map[string]object.Object
 		partials              map[string]*packedSlabMock
+		policies              map[string]api.BucketPolicy
 		slabBufferMaxSizeSoft int
 		bufferIDCntr          uint // allows marking packed slabs as uploaded
 	}
@@ -349,13 +350,14 @@ func newObjectStoreMock(bucket string) *objectStoreMock {
 	os := &objectStoreMock{
 		objects:               make(map[string]map[string]object.Object),
 		partials:              make(map[string]*packedSlabMock),
+		policies:              make(map[string]api.BucketPolicy),
 		slabBufferMaxSizeSoft: math.MaxInt64,
 	}
 	os.objects[bucket] = make(map[string]object.Object)
 	return os
 }
 
-func (os *objectStoreMock) AddMultipartPart(ctx context.Context, bucket, path, contractSet, eTag, uploadID string, partNumber int, slices []object.SlabSlice) (err error) {
+func (os *objectStoreMock) AddMultipartPart(ctx context.Context, bucket, path, contractSet, eTag, uploadID string, partNumber int, slices []object.SlabSlice, opts api.AddMultipartPartOptions) (err error) {
 	return nil
 }
 
@@ -386,8 +388,8 @@ func (os *objectStoreMock) DeleteHostSector(ctx context.Context, hk types.Public
 	return nil
 }
 
-func (os *objectStoreMock) DeleteObject(ctx context.Context, bucket, path string, opts api.DeleteObjectOptions) error {
-	return nil
+func (os *objectStoreMock) DeleteObject(ctx context.Context, bucket, path string, opts api.DeleteObjectOptions) (api.DeleteObjectResponse, error) {
+	return api.DeleteObjectResponse{}, nil
 }
 
 func (os *objectStoreMock) AddObject(ctx context.Context, bucket, path, contractSet string, o object.Object, opts api.AddObjectOptions) error {
@@ -582,13 +584,19 @@ func (os *objectStoreMock) MarkPackedSlabsUploaded(ctx context.Context, slabs []
 }
 
 func (os *objectStoreMock) Bucket(_ context.Context, bucket string) (api.Bucket, error) {
-	return api.Bucket{}, nil
+	os.mu.Lock()
+	defer os.mu.Unlock()
+	return api.Bucket{Name: bucket, Policy: os.policies[bucket]}, nil
 }
 
 func (os *objectStoreMock) MultipartUpload(ctx context.Context, uploadID string) (resp api.MultipartUpload, err error) {
 	return api.MultipartUpload{}, nil
 }
 
+func (os *objectStoreMock) ObjectsStats(ctx context.Context, opts api.ObjectsStatsOpts) (api.ObjectsStatsResponse, error) {
+	return api.ObjectsStatsResponse{}, nil
+}
+
 func (os *objectStoreMock) totalSlabBufferSize() (total int) {
 	for _, p := range os.partials {
 		if time.Now().After(p.lockedUntil) {
@@ -634,6 +642,14 @@ func (*s3Mock) ListObjects(context.Context, string, api.ListObjectOptions) (resp
 	return api.ObjectsListResponse{}, nil
 }
 
+func (*s3Mock) ObjectVersions(context.Context, string, api.ListObjectVersionsOptions) (api.ObjectVersionsResponse, error) {
+	return api.ObjectVersionsResponse{}, nil
+}
+
+func (*s3Mock) UpdateBucketPolicy(context.Context, string, api.BucketPolicy) error {
+	return nil
+}
+
 func (*s3Mock) AbortMultipartUpload(context.Context, string, string, string) (err error) {
 	return nil
 }
@@ -642,6 +658,10 @@ func (*s3Mock) CompleteMultipartUpload(context.Context, string, string, string,
 	return api.MultipartCompleteResponse{}, nil
 }
 
+func (*s3Mock) CopyMultipartPart(context.Context, string, string, string, string, string, string, int, api.CopyMultipartPartOptions) (api.MultipartCopyPartResponse, error) {
+	return api.MultipartCopyPartResponse{}, nil
+}
+
 func (*s3Mock) CreateMultipartUpload(context.Context, string, string, api.CreateMultipartOptions) (api.MultipartCreateResponse, error) {
 	return api.MultipartCreateResponse{}, nil
 }
@@ -654,6 +674,34 @@ func (*s3Mock) MultipartUploadParts(ctx context.Context, bucket, object string,
 	return api.MultipartListPartsResponse{}, nil
 }
 
+func (*s3Mock) DeleteObjectTags(context.Context, string, string) error {
+	return nil
+}
+
+func (*s3Mock) ObjectTags(context.Context, string, string) (api.ObjectTags, error) {
+	return nil, nil
+}
+
+func (*s3Mock) UpdateObjectTags(context.Context, string, string, api.ObjectTags) error {
+	return nil
+}
+
+func (*s3Mock) ObjectLegalHold(context.Context, string, string) (api.ObjectLegalHold, error) {
+	return api.ObjectLegalHold{}, nil
+}
+
+func (*s3Mock) ObjectRetention(context.Context, string, string) (api.ObjectRetention, error) {
+	return api.ObjectRetention{}, nil
+}
+
+func (*s3Mock) UpdateObjectLegalHold(context.Context, string, string, bool) error {
+	return nil
+}
+
+func (*s3Mock) UpdateObjectRetention(context.Context, string, string, api.ObjectRetention, bool) error {
+	return nil
+}
+
 func (*s3Mock) S3AuthenticationSettings(context.Context) (as api.S3AuthenticationSettings, err error) {
 	return api.S3AuthenticationSettings{}, nil
 }
@@ -664,14 +712,20 @@ func (*s3Mock) UpdateSetting(context.Context, string, interface{}) error {
 
 var _ SettingStore = (*settingStoreMock)(nil)
 
-type settingStoreMock struct{}
+type settingStoreMock struct {
+	uploadParams api.UploadParams
+}
+
+func (*settingStoreMock) BandwidthSettings(context.Context) (api.BandwidthSettings, error) {
+	return api.BandwidthSettings{}, nil
+}
 
 func (*settingStoreMock) GougingParams(context.Context) (api.GougingParams, error) {
 	return api.GougingParams{}, nil
 }
 
-func (*settingStoreMock) UploadParams(context.Context) (api.UploadParams, error) {
-	return api.UploadParams{}, nil
+func (s *settingStoreMock) UploadParams(context.Context) (api.UploadParams, error) {
+	return s.uploadParams, nil
 }
 
 var _ Syncer = (*syncerMock)(nil)
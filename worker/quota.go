@@ -0,0 +1,195 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go.thebigfile.com/renterd/alerts"
+	"go.thebigfile.com/renterd/api"
+)
+
+const (
+	// bucketStatsRefreshInterval is the interval at which the stats of a
+	// bucket with a quota are fetched from the bus, uploads accepted in
+	// between are accounted for locally.
+	bucketStatsRefreshInterval = 10 * time.Second
+)
+
+type (
+	// bucketQuotaTracker caches the stats of buckets with a quota to avoid
+	// fetching them from the bus for every upload.
+	bucketQuotaTracker struct {
+		mu      sync.Mutex
+		buckets map[string]*bucketUsage
+	}
+
+	bucketUsage struct {
+		stats     api.ObjectsStatsResponse
+		fetchedAt time.Time
+
+		// alerted indicates whether the quota alert of the bucket is
+		// registered, it's nil until the alert was registered or dismissed
+		alerted *bool
+	}
+
+	// quotaReader fails an upload once more bytes were read than the bucket
+	// has left of its quota, it protects against uploads that declare a
+	// smaller size than they actually upload or don't declare a size at all.
+	quotaReader struct {
+		r         io.Reader
+		remaining int64
+		read      int64
+	}
+)
+
+func newBucketQuotaTracker() *bucketQuotaTracker {
+	return &bucketQuotaTracker{
+		buckets: make(map[string]*bucketUsage),
+	}
+}
+
+// stats returns the cached stats of the given bucket. If the stats are
+// outdated, the given function is used to fetch them and refreshed is true.
+func (t *bucketQuotaTracker) stats(bucket string, fetch func() (api.ObjectsStatsResponse, error)) (_ api.ObjectsStatsResponse, refreshed bool, _ error) {
+	t.mu.Lock()
+	u, ok := t.buckets[bucket]
+	if ok && time.Since(u.fetchedAt) < bucketStatsRefreshInterval {
+		stats := u.stats
+		t.mu.Unlock()
+		return stats, false, nil
+	}
+	t.mu.Unlock()
+
+	stats, err := fetch()
+	if err != nil {
+		return api.ObjectsStatsResponse{}, false, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u, ok = t.buckets[bucket]
+	if !ok {
+		u = &bucketUsage{}
+		t.buckets[bucket] = u
+	}
+	u.stats = stats
+	u.fetchedAt = time.Now()
+	return stats, true, nil
+}
+
+// track accounts for an accepted upload of the given size until the stats of
+// the bucket are refreshed.
+func (t *bucketQuotaTracker) track(bucket string, size int64, isPart bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u, ok := t.buckets[bucket]
+	if !ok {
+		return
+	}
+	if size > 0 {
+		u.stats.TotalObjectsSize += uint64(size)
+	}
+	if !isPart {
+		u.stats.NumObjects++
+	}
+}
+
+// setAlerted updates whether the quota alert of the given bucket is
+// registered and returns true if that changed.
+func (t *bucketQuotaTracker) setAlerted(bucket string, alerted bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u, ok := t.buckets[bucket]
+	if !ok {
+		return true
+	} else if u.alerted != nil && *u.alerted == alerted {
+		return false
+	}
+	u.alerted = &alerted
+	return true
+}
+
+// CheckBucketQuota returns an error if adding an object, or a part of a
+// multipart upload, of the given size to the bucket exceeds its quota. It's
+// used by uploads that don't stream data through the worker, e.g. copies that
+// reference the data of an existing object.
+func (w *Worker) CheckBucketQuota(ctx context.Context, b api.Bucket, size int64, isPart bool) error {
+	_, err := w.checkBucketQuota(ctx, b, size, isPart)
+	return err
+}
+
+// checkBucketQuota returns an error if uploading an object, or a part of a
+// multipart upload, of the given size exceeds the quota of the bucket. It
+// registers an alert when the bucket is close to reaching its quota and
+// dismisses it once that's no longer the case. The returned number of bytes
+// that can still be uploaded is negative if the size isn't limited.
+func (w *Worker) checkBucketQuota(ctx context.Context, b api.Bucket, size int64, isPart bool) (remaining int64, _ error) {
+	quota := b.Policy.Quota
+	if !quota.Enabled() {
+		return -1, nil
+	}
+
+	stats, refreshed, err := w.bucketQuotas.stats(b.Name, func() (api.ObjectsStatsResponse, error) {
+		return w.bus.ObjectsStats(ctx, api.ObjectsStatsOpts{Bucket: b.Name})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("couldn't fetch stats for bucket '%s': %w", b.Name, err)
+	}
+
+	// update the alert along with the stats, it's only dismissed if it was
+	// registered before
+	if refreshed {
+		if usage := quota.Usage(stats); usage >= api.BucketQuotaWarningThreshold {
+			w.bucketQuotas.setAlerted(b.Name, true)
+			w.registerAlert(newBucketQuotaAlert(b.Name, quota, stats, usage))
+		} else if w.bucketQuotas.setAlerted(b.Name, false) {
+			if err := w.alerts.DismissAlerts(ctx, alerts.IDForBucket(alertBucketQuotaID, b.Name)); err != nil {
+				w.logger.Errorf("failed to dismiss bucket quota alert, err: %v", err)
+			}
+		}
+	}
+
+	if err := quota.Check(stats, size, isPart); err != nil {
+		return 0, err
+	}
+	w.bucketQuotas.track(b.Name, size, isPart)
+	return quota.Remaining(stats), nil
+}
+
+// limitToBucketQuota wraps the reader of an upload to the given bucket so the
+// upload fails once it exceeds the remaining quota, the returned function
+// accounts for the bytes that were uploaded on top of the declared size. A
+// negative remaining size means the size of the bucket isn't limited.
+func (w *Worker) limitToBucketQuota(r io.Reader, bucket string, size, remaining int64) (io.Reader, func()) {
+	if remaining < 0 {
+		return r, func() {}
+	}
+	qr := &quotaReader{r: r, remaining: remaining}
+	return qr, func() {
+		if size < 0 {
+			size = 0
+		}
+		if qr.read > size {
+			w.bucketQuotas.track(bucket, qr.read-size, true)
+		}
+	}
+}
+
+func (r *quotaReader) Read(p []byte) (int, error) {
+	if r.read > r.remaining {
+		return 0, r.exceededErr()
+	}
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	if r.read > r.remaining {
+		return n, r.exceededErr()
+	}
+	return n, err
+}
+
+func (r *quotaReader) exceededErr() error {
+	return fmt.Errorf("%w: upload exceeds the %d bytes left of the bucket's quota", api.ErrBucketQuotaExceeded, r.remaining)
+}
@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"go.thebigfile.com/renterd/api"
+	"lukechampine.com/frand"
+)
+
+func TestBucketQuotaTracker(t *testing.T) {
+	tracker := newBucketQuotaTracker()
+
+	var fetches int
+	fetch := func() (api.ObjectsStatsResponse, error) {
+		fetches++
+		return api.ObjectsStatsResponse{NumObjects: 1, TotalObjectsSize: 10}, nil
+	}
+
+	// the first call fetches the stats
+	stats, refreshed, err := tracker.stats("bucket", fetch)
+	if err != nil {
+		t.Fatal(err)
+	} else if !refreshed || fetches != 1 || stats.NumObjects != 1 {
+		t.Fatal("expected stats to be fetched", refreshed, fetches, stats)
+	}
+
+	// accepted uploads are tracked, parts don't add objects
+	tracker.track("bucket", 5, false)
+	tracker.track("bucket", 5, true)
+
+	// the second call uses the cached stats
+	stats, refreshed, err = tracker.stats("bucket", fetch)
+	if err != nil {
+		t.Fatal(err)
+	} else if refreshed || fetches != 1 {
+		t.Fatal("expected cached stats", refreshed, fetches)
+	} else if stats.NumObjects != 2 || stats.TotalObjectsSize != 20 {
+		t.Fatal("unexpected stats", stats)
+	}
+
+	// outdated stats are refetched
+	tracker.buckets["bucket"].fetchedAt = tracker.buckets["bucket"].fetchedAt.Add(-bucketStatsRefreshInterval)
+	if stats, refreshed, err = tracker.stats("bucket", fetch); err != nil {
+		t.Fatal(err)
+	} else if !refreshed || fetches != 2 || stats.NumObjects != 1 {
+		t.Fatal("expected stats to be refetched", refreshed, fetches, stats)
+	}
+
+	// errors are returned and nothing is cached
+	if _, _, err := tracker.stats("other", func() (api.ObjectsStatsResponse, error) {
+		return api.ObjectsStatsResponse{}, errors.New("failed")
+	}); err == nil {
+		t.Fatal("expected error")
+	} else if _, ok := tracker.buckets["other"]; ok {
+		t.Fatal("expected no stats to be cached")
+	}
+
+	// the alert state is only reported as changed when it changes
+	if !tracker.setAlerted("bucket", false) {
+		t.Fatal("expected unknown state to change")
+	} else if tracker.setAlerted("bucket", false) {
+		t.Fatal("expected state to be unchanged")
+	} else if !tracker.setAlerted("bucket", true) {
+		t.Fatal("expected state to change")
+	}
+}
+
+func TestUploadBucketQuota(t *testing.T) {
+	w := newTestWorker(t)
+	b := w.bus.(*busMock)
+	b.uploadParams = api.UploadParams{
+		ContractSet:        testContractSet,
+		ConsensusState:     api.ConsensusState{Synced: true},
+		RedundancySettings: testRedundancySettings,
+	}
+	w.os.policies[testBucket] = api.BucketPolicy{Quota: api.BucketQuota{MaxSize: 100}}
+
+	// upload reads the given data the way an upload with the given content
+	// length does and returns the error of the read
+	upload := func(data []byte, contentLength int64) error {
+		t.Helper()
+		_, remaining, err := w.prepareUploadParams(context.Background(), testBucket, "", 0, 0, contentLength, false)
+		if err != nil {
+			return err
+		} else if remaining < 0 {
+			t.Fatal("expected the upload to be limited")
+		}
+		r, trackQuota := w.limitToBucketQuota(bytes.NewReader(data), testBucket, contentLength, remaining)
+		if _, err := io.ReadAll(r); err != nil {
+			return err
+		}
+		trackQuota()
+		return nil
+	}
+	resetTracker := func() { w.bucketQuotas = newBucketQuotaTracker() }
+
+	// a chunked upload without a length that exceeds the quota is rejected
+	if err := upload(frand.Bytes(101), -1); !errors.Is(err, api.ErrBucketQuotaExceeded) {
+		t.Fatal("expected quota error, got", err)
+	}
+	resetTracker()
+
+	// an upload that declares a smaller size than it uploads is rejected
+	if err := upload(frand.Bytes(101), 10); !errors.Is(err, api.ErrBucketQuotaExceeded) {
+		t.Fatal("expected quota error, got", err)
+	}
+	resetTracker()
+
+	// an upload without a length that fits is accepted and the uploaded
+	// bytes are tracked
+	if err := upload(frand.Bytes(100), -1); err != nil {
+		t.Fatal(err)
+	} else if size := w.bucketQuotas.buckets[testBucket].stats.TotalObjectsSize; size != 100 {
+		t.Fatal("expected uploaded bytes to be tracked, got", size)
+	}
+
+	// the next upload is rejected mid-stream even though it declares no size
+	if err := upload(frand.Bytes(1), 0); !errors.Is(err, api.ErrBucketQuotaExceeded) {
+		t.Fatal("expected quota error, got", err)
+	}
+}
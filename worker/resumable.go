@@ -0,0 +1,249 @@
+package worker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	rhpv2 "go.thebigfile.com/core/rhp/v2"
+	"go.thebigfile.com/renterd/api"
+)
+
+const (
+	// resumableUploadPartsBatchSize is the number of parts that are fetched
+	// at once when computing the offset of a resumable upload.
+	resumableUploadPartsBatchSize = 1000
+
+	// resumableUploadPartSize is the max size of the parts a chunk is split
+	// into, every part is persisted once it's uploaded so an interrupted
+	// chunk can be resumed from the end of the last part. It's a multiple of
+	// the slab size of the common redundancy settings.
+	resumableUploadPartSize = 40 * rhpv2.SectorSize
+)
+
+type (
+	// resumableUploadLocker serializes the chunks that are uploaded to the
+	// same resumable upload through this worker. The bus rejects parts that
+	// don't start at the upload's current offset, so chunks that are uploaded
+	// at the same offset through different workers can't both be added, the
+	// lock only prevents uploading data that would be rejected.
+	resumableUploadLocker struct {
+		mu    sync.Mutex
+		locks map[string]*resumableUploadLock
+	}
+
+	resumableUploadLock struct {
+		mu   sync.Mutex
+		refs int
+	}
+)
+
+func newResumableUploadLocker() *resumableUploadLocker {
+	return &resumableUploadLocker{
+		locks: make(map[string]*resumableUploadLock),
+	}
+}
+
+// Lock locks the upload with the given id and returns a function to unlock it.
+func (l *resumableUploadLocker) Lock(uploadID string) func() {
+	l.mu.Lock()
+	lock, ok := l.locks[uploadID]
+	if !ok {
+		lock = &resumableUploadLock{}
+		l.locks[uploadID] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		l.mu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(l.locks, uploadID)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// CreateResumableUpload creates a resumable upload for the object at the given
+// path. The upload's data is encrypted using the offsets of the chunks, so the
+// chunks can be of any size.
+func (w *Worker) CreateResumableUpload(ctx context.Context, bucket, path, mimeType string, metadata api.ObjectUserMetadata) (api.ResumableUpload, error) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	resp, err := w.bus.CreateMultipartUpload(ctx, bucket, path, api.CreateMultipartOptions{
+		GenerateKey: true,
+		MimeType:    mimeType,
+		Metadata:    metadata,
+	})
+	if err != nil {
+		return api.ResumableUpload{}, fmt.Errorf("couldn't create multipart upload: %w", err)
+	}
+	return api.ResumableUpload{
+		UploadID: resp.UploadID,
+		Bucket:   bucket,
+		Path:     path,
+	}, nil
+}
+
+// ResumableUpload returns the resumable upload with the given id alongside
+// its current offset.
+func (w *Worker) ResumableUpload(ctx context.Context, uploadID string) (api.ResumableUpload, error) {
+	ru, _, err := w.resumableUpload(ctx, uploadID)
+	return ru, err
+}
+
+// UploadResumableChunk uploads a chunk of a resumable upload, the offset has to
+// match the upload's current offset. The chunk is uploaded as one or more
+// parts, if uploading the chunk fails only the part that was being uploaded is
+// discarded, in which case the upload can be resumed from the offset that is
+// returned by ResumableUpload.
+func (w *Worker) UploadResumableChunk(ctx context.Context, r io.Reader, uploadID string, offset int64, opts api.UploadResumableChunkOptions) (api.ResumableUpload, error) {
+	unlock := w.resumableUploads.Lock(uploadID)
+	defer unlock()
+
+	ru, parts, err := w.resumableUpload(ctx, uploadID)
+	if err != nil {
+		return api.ResumableUpload{}, err
+	} else if offset != ru.Offset {
+		return api.ResumableUpload{}, fmt.Errorf("%w: expected offset %d, got %d", api.ErrUploadOffsetMismatch, ru.Offset, offset)
+	} else if opts.ContentLength == 0 {
+		return ru, nil
+	}
+
+	// upload the chunk as the next parts
+	_, err = uploadResumableParts(r, len(parts)+1, offset, resumableUploadPartSize, func(r io.Reader, partNumber int, partOffset int64) error {
+		contentLength := opts.ContentLength
+		if contentLength > 0 {
+			contentLength = min(contentLength-(partOffset-offset), resumableUploadPartSize)
+		}
+		encryptionOffset := int(partOffset)
+		_, err := w.uploadMultipartUploadPart(ctx, r, ru.Bucket, ru.Path, uploadID, partNumber, api.UploadMultipartUploadPartOptions{
+			ContractSet:      opts.ContractSet,
+			MinShards:        opts.MinShards,
+			TotalShards:      opts.TotalShards,
+			EncryptionOffset: &encryptionOffset,
+			ContentLength:    contentLength,
+		}, WithPartOffset(partOffset))
+		return err
+	})
+	if err != nil {
+		return api.ResumableUpload{}, err
+	}
+
+	// fetch the upload again to return the new offset
+	ru, _, err = w.resumableUpload(ctx, uploadID)
+	return ru, err
+}
+
+// CompleteResumableUpload completes the resumable upload, turning the chunks
+// that were uploaded into an object.
+func (w *Worker) CompleteResumableUpload(ctx context.Context, uploadID string) (api.MultipartCompleteResponse, error) {
+	unlock := w.resumableUploads.Lock(uploadID)
+	defer unlock()
+
+	ru, parts, err := w.resumableUpload(ctx, uploadID)
+	if err != nil {
+		return api.MultipartCompleteResponse{}, err
+	}
+	completed := make([]api.MultipartCompletedPart, len(parts))
+	for i, part := range parts {
+		completed[i] = api.MultipartCompletedPart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		}
+	}
+	return w.bus.CompleteMultipartUpload(ctx, ru.Bucket, ru.Path, uploadID, completed, api.CompleteMultipartOptions{})
+}
+
+// AbortResumableUpload aborts the resumable upload and discards the chunks that
+// were uploaded.
+func (w *Worker) AbortResumableUpload(ctx context.Context, uploadID string) error {
+	unlock := w.resumableUploads.Lock(uploadID)
+	defer unlock()
+
+	upload, err := w.bus.MultipartUpload(ctx, uploadID)
+	if err != nil {
+		return fmt.Errorf("couldn't fetch multipart upload: %w", err)
+	}
+	return w.bus.AbortMultipartUpload(ctx, upload.Bucket, upload.Path, uploadID)
+}
+
+// uploadResumableParts splits the data that is read from r into parts of at
+// most partSize bytes and uploads them using the given function, starting with
+// the given part number at the given offset. It returns the offset after the
+// last part that was uploaded.
+func uploadResumableParts(r io.Reader, partNumber int, offset, partSize int64, uploadPart func(r io.Reader, partNumber int, offset int64) error) (int64, error) {
+	br := bufio.NewReader(r)
+	for {
+		// stop once all data was read
+		if _, err := br.Peek(1); errors.Is(err, io.EOF) {
+			return offset, nil
+		} else if err != nil {
+			return offset, err
+		}
+
+		pr := &partReader{r: io.LimitReader(br, partSize)}
+		if err := uploadPart(pr, partNumber, offset); err != nil {
+			return offset, err
+		}
+		offset += pr.n
+		partNumber++
+	}
+}
+
+// partReader counts the bytes that are read from a part.
+type partReader struct {
+	r io.Reader
+	n int64
+}
+
+func (pr *partReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.n += int64(n)
+	return n, err
+}
+
+// resumableUpload fetches the multipart upload that backs the resumable upload
+// and its parts, the upload's offset is the sum of the size of its parts.
+func (w *Worker) resumableUpload(ctx context.Context, uploadID string) (api.ResumableUpload, []api.MultipartListPartItem, error) {
+	upload, err := w.bus.MultipartUpload(ctx, uploadID)
+	if err != nil {
+		return api.ResumableUpload{}, nil, fmt.Errorf("couldn't fetch multipart upload: %w", err)
+	}
+
+	var parts []api.MultipartListPartItem
+	var marker int
+	for {
+		resp, err := w.bus.MultipartUploadParts(ctx, upload.Bucket, upload.Path, uploadID, marker, resumableUploadPartsBatchSize)
+		if err != nil {
+			return api.ResumableUpload{}, nil, fmt.Errorf("couldn't fetch multipart upload parts: %w", err)
+		}
+		parts = append(parts, resp.Parts...)
+		if !resp.HasMore {
+			break
+		}
+		marker = resp.NextMarker
+	}
+
+	ru := api.ResumableUpload{
+		UploadID: uploadID,
+		Bucket:   upload.Bucket,
+		Path:     upload.Path,
+	}
+	for i, part := range parts {
+		if part.PartNumber != i+1 {
+			return api.ResumableUpload{}, nil, fmt.Errorf("multipart upload %v is not a resumable upload, part %d is missing", uploadID, i+1)
+		}
+		ru.Offset += part.Size
+	}
+	return ru, parts, nil
+}
@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"lukechampine.com/frand"
+)
+
+func TestResumableUploadLocker(t *testing.T) {
+	l := newResumableUploadLocker()
+
+	// lock the same upload concurrently and assert the critical section is
+	// never entered twice
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var active, maxActive int
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := l.Lock("upload")
+			defer unlock()
+
+			mu.Lock()
+			active++
+			if active > maxActive {
+				maxActive = active
+			}
+			mu.Unlock()
+
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if maxActive != 1 {
+		t.Fatalf("expected at most 1 active lock holder, got %d", maxActive)
+	}
+
+	// locking another upload shouldn't block
+	unlock := l.Lock("upload")
+	l.Lock("other")()
+	unlock()
+
+	// the locks should have been cleaned up
+	if len(l.locks) != 0 {
+		t.Fatalf("expected no locks, got %d", len(l.locks))
+	}
+}
+
+func TestUploadResumableParts(t *testing.T) {
+	type part struct {
+		number int
+		offset int64
+		data   []byte
+	}
+	var parts []part
+	var failPart int
+	uploadPart := func(r io.Reader, partNumber int, offset int64) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		} else if partNumber == failPart {
+			return errors.New("interrupted")
+		}
+		parts = append(parts, part{partNumber, offset, data})
+		return nil
+	}
+	assertParts := func(data []byte, numbers []int, offsets []int64) {
+		t.Helper()
+		if len(parts) != len(numbers) {
+			t.Fatalf("expected %v parts, got %v", len(numbers), len(parts))
+		}
+		var uploaded []byte
+		for i, p := range parts {
+			if p.number != numbers[i] || p.offset != offsets[i] {
+				t.Fatalf("unexpected part %v at offset %v, expected part %v at offset %v", p.number, p.offset, numbers[i], offsets[i])
+			}
+			uploaded = append(uploaded, p.data...)
+		}
+		if !bytes.Equal(uploaded, data) {
+			t.Fatal("data mismatch")
+		}
+	}
+
+	// empty chunks don't add parts
+	if offset, err := uploadResumableParts(bytes.NewReader(nil), 1, 0, 10, uploadPart); err != nil {
+		t.Fatal(err)
+	} else if offset != 0 || len(parts) != 0 {
+		t.Fatal("unexpected parts", offset, len(parts))
+	}
+
+	// a chunk is split into parts, the part numbers and offsets continue
+	// where the previous part left off
+	data := frand.Bytes(25)
+	if offset, err := uploadResumableParts(bytes.NewReader(data), 1, 0, 10, uploadPart); err != nil {
+		t.Fatal(err)
+	} else if offset != 25 {
+		t.Fatal("unexpected offset", offset)
+	}
+	assertParts(data, []int{1, 2, 3}, []int64{0, 10, 20})
+
+	// interrupt the next chunk while uploading its second part, the first part
+	// is kept
+	chunk := frand.Bytes(25)
+	failPart = 5
+	offset, err := uploadResumableParts(bytes.NewReader(chunk), 4, 25, 10, uploadPart)
+	if err == nil {
+		t.Fatal("expected error")
+	} else if offset != 35 {
+		t.Fatal("unexpected offset", offset)
+	}
+	assertParts(append(data, chunk[:10]...), []int{1, 2, 3, 4}, []int64{0, 10, 20, 25})
+
+	// resume the chunk from the offset of the upload
+	failPart = 0
+	if offset, err = uploadResumableParts(bytes.NewReader(chunk[offset-25:]), 5, offset, 10, uploadPart); err != nil {
+		t.Fatal(err)
+	} else if offset != 50 {
+		t.Fatal("unexpected offset", offset)
+	}
+	assertParts(append(data, chunk...), []int{1, 2, 3, 4, 5, 6}, []int64{0, 10, 20, 25, 35, 45})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"go.sia.tech/gofakes3"
 	"go.sia.tech/gofakes3/signature"
@@ -16,6 +17,8 @@ var (
 	_ gofakes3.AuthenticatedBackend = (*authenticatedBackend)(nil)
 	_ gofakes3.Backend              = (*authenticatedBackend)(nil)
 	_ gofakes3.MultipartBackend     = (*authenticatedBackend)(nil)
+	_ gofakes3.VersionedBackend     = (*authenticatedBackend)(nil)
+	_ extensionBackend              = (*authenticatedBackend)(nil)
 )
 
 type (
@@ -35,13 +38,26 @@ type (
 		DeleteObject            bool
 		PutObject               bool
 		DeleteMulti             bool
-		CopyObject              bool
 		CreateMultipartUpload   bool
 		UploadPart              bool
 		ListMultipartUpload     bool
 		ListParts               bool
 		AbortMultipartUpload    bool
 		CompleteMultipartUpload bool
+		GetBucketVersioning     bool
+		PutBucketVersioning     bool
+		GetObjectTagging        bool
+		PutObjectTagging        bool
+		GetObjectRetention      bool
+		PutObjectRetention      bool
+		GetObjectLegalHold      bool
+		PutObjectLegalHold      bool
+
+		BypassGovernanceRetention bool
+
+		// policy restricts the permissions above to specific buckets and
+		// prefixes, it is nil for keys that have full access
+		policy *api.S3AccessKeyPolicy
 	}
 
 	contextKey int
@@ -64,13 +80,22 @@ var (
 		DeleteObject:            true,
 		PutObject:               true,
 		DeleteMulti:             true,
-		CopyObject:              true,
 		CreateMultipartUpload:   true,
 		UploadPart:              true,
 		ListMultipartUpload:     true,
 		ListParts:               true,
 		AbortMultipartUpload:    true,
 		CompleteMultipartUpload: true,
+		GetBucketVersioning:     true,
+		PutBucketVersioning:     true,
+		GetObjectTagging:        true,
+		PutObjectTagging:        true,
+		GetObjectRetention:      true,
+		PutObjectRetention:      true,
+		GetObjectLegalHold:      true,
+		PutObjectLegalHold:      true,
+
+		BypassGovernanceRetention: true,
 	}
 
 	// noAccessPerms grant access to nothing.
@@ -83,6 +108,14 @@ func writeResponse(w http.ResponseWriter, err signature.APIError) {
 	_, _ = w.Write(signature.EncodeAPIErrorToResponse(err))
 }
 
+func reloadKeysError(err error) signature.APIError {
+	return signature.APIError{
+		Code:           string(gofakes3.ErrInternal),
+		Description:    fmt.Sprintf("failed to reload v4 keys: %v", err),
+		HTTPStatusCode: http.StatusInternalServerError,
+	}
+}
+
 func newAuthenticatedBackend(b *s3) *authenticatedBackend {
 	return &authenticatedBackend{
 		backend: b,
@@ -105,24 +138,81 @@ func (b *authenticatedBackend) applyBucketPolicy(ctx context.Context, bucketName
 	return nil
 }
 
-func (b *authenticatedBackend) permsFromCtx(ctx context.Context, bucket string) permissions {
+// permsFromCtx returns the permissions for an operation on the given bucket
+// and key. Bucket-level operations pass an empty key, list operations pass the
+// prefix that is listed.
+func (b *authenticatedBackend) permsFromCtx(ctx context.Context, bucket, key string) permissions {
 	perms := noAccessPerms
 	if p, ok := ctx.Value(permissionKey).(*permissions); ok {
 		perms = *p
 	}
+	if perms.policy != nil {
+		perms = scopedPerms(*perms.policy, bucket, key)
+	}
 	if bucket != "" {
 		b.applyBucketPolicy(ctx, bucket, &perms)
 	}
 	return perms
 }
 
-func (b *authenticatedBackend) reloadV4Keys(ctx context.Context) error {
+func (b *authenticatedBackend) reloadV4Keys(ctx context.Context) (api.S3AuthenticationSettings, error) {
 	as, err := b.backend.b.S3AuthenticationSettings(ctx)
 	if err != nil {
-		return err
+		return api.S3AuthenticationSettings{}, err
 	}
 	signature.ReloadKeys(as.V4Keypairs)
-	return nil
+	return as, nil
+}
+
+// keyPerms returns the permissions of a successfully authenticated access key,
+// keys with a policy are restricted to the buckets and prefixes it grants
+// access to.
+func keyPerms(accessKeyID string, policies map[string]api.S3AccessKeyPolicy) permissions {
+	perms := rootPerms
+	if policy, ok := policies[accessKeyID]; ok {
+		perms.policy = &policy
+	}
+	return perms
+}
+
+// scopedPerms returns the permissions of an access key with the given policy
+// for an operation on the given bucket and key.
+func scopedPerms(policy api.S3AccessKeyPolicy, bucket, key string) permissions {
+	read := policy.Allows(api.S3ActionRead, bucket, key)
+	write := policy.Allows(api.S3ActionWrite, bucket, key)
+	list := policy.Allows(api.S3ActionList, bucket, key)
+	del := policy.Allows(api.S3ActionDelete, bucket, key)
+	return permissions{
+		Authenticated:           true,
+		ListBuckets:             bucket == "" && len(policy.Grants) > 0,
+		ListBucket:              list,
+		CreateBucket:            write && key == "",
+		BucketExists:            policy.AllowsBucket(bucket),
+		DeleteBucket:            del && key == "",
+		GetObject:               read,
+		HeadObject:              read,
+		DeleteObject:            del,
+		PutObject:               write,
+		DeleteMulti:             del,
+		CreateMultipartUpload:   write,
+		UploadPart:              write,
+		ListMultipartUpload:     list,
+		ListParts:               write,
+		AbortMultipartUpload:    write,
+		CompleteMultipartUpload: write,
+		GetBucketVersioning:     policy.AllowsBucket(bucket),
+		PutBucketVersioning:     write && key == "",
+		GetObjectTagging:        read,
+		PutObjectTagging:        write,
+		GetObjectRetention:      read,
+		PutObjectRetention:      write,
+		GetObjectLegalHold:      read,
+		PutObjectLegalHold:      write,
+
+		BypassGovernanceRetention: policy.Allows(api.S3ActionBypassGovernance, bucket, key),
+
+		policy: &policy,
+	}
 }
 
 func (b *authenticatedBackend) AuthenticationMiddleware(h http.Handler) http.Handler {
@@ -132,18 +222,15 @@ func (b *authenticatedBackend) AuthenticationMiddleware(h http.Handler) http.Han
 
 		if rq.Header.Get("Authorization") != "" {
 			// auth header found, refresh keys
-			if err := b.reloadV4Keys(rq.Context()); err != nil {
-				writeResponse(w, signature.APIError{
-					Code:           string(gofakes3.ErrInternal),
-					Description:    fmt.Sprintf("failed to reload v4 keys: %v", err),
-					HTTPStatusCode: http.StatusInternalServerError,
-				})
+			as, err := b.reloadV4Keys(rq.Context())
+			if err != nil {
+				writeResponse(w, reloadKeysError(err))
 				return
 			}
 			// verify signature
 			if accessKeyID, result := signature.V4SignVerify(rq); result == signature.ErrNone {
 				// authenticated request successfully
-				perms = rootPerms
+				perms = keyPerms(accessKeyID, as.Policies)
 			} else if accessKeyID == "" {
 				// no access key provided; bucket policy might still permit access
 				// NOTE: this happens when the official aws sdk is used without
@@ -153,6 +240,20 @@ func (b *authenticatedBackend) AuthenticationMiddleware(h http.Handler) http.Han
 				writeResponse(w, signature.GetAPIError(result))
 				return
 			}
+		} else if isPresignedV4(rq) {
+			// presigned url found, refresh keys
+			as, err := b.reloadV4Keys(rq.Context())
+			if err != nil {
+				writeResponse(w, reloadKeysError(err))
+				return
+			}
+			// verify the query-string signature and its expiry
+			accessKeyID, apiErr := verifyPresignedV4(rq, as.V4Keypairs, time.Now())
+			if apiErr != nil {
+				writeResponse(w, *apiErr)
+				return
+			}
+			perms = keyPerms(accessKeyID, as.Policies)
 		}
 
 		// add permissions to context
@@ -181,122 +282,283 @@ func (b *authenticatedBackend) IsAuthenticated(w http.ResponseWriter, rq *http.R
 }
 
 func (b *authenticatedBackend) ListBuckets(ctx context.Context) ([]gofakes3.BucketInfo, error) {
-	if !b.permsFromCtx(ctx, "").ListBuckets {
+	perms := b.permsFromCtx(ctx, "", "")
+	if !perms.ListBuckets {
 		return nil, gofakes3.ErrAccessDenied
 	}
-	return b.backend.ListBuckets(ctx)
+	buckets, err := b.backend.ListBuckets(ctx)
+	if err != nil || perms.policy == nil {
+		return buckets, err
+	}
+
+	// only return the buckets the key has access to
+	filtered := buckets[:0]
+	for _, bucket := range buckets {
+		if perms.policy.AllowsBucket(bucket.Name) {
+			filtered = append(filtered, bucket)
+		}
+	}
+	return filtered, nil
 }
 
 func (b *authenticatedBackend) ListBucket(ctx context.Context, bucketName string, prefix *gofakes3.Prefix, page gofakes3.ListBucketPage) (*gofakes3.ObjectList, error) {
-	if !b.permsFromCtx(ctx, bucketName).ListBucket {
+	if !b.permsFromCtx(ctx, bucketName, prefixString(prefix)).ListBucket {
 		return nil, gofakes3.ErrAccessDenied
 	}
 	return b.backend.ListBucket(ctx, bucketName, prefix, page)
 }
 
 func (b *authenticatedBackend) CreateBucket(ctx context.Context, name string) error {
-	if !b.permsFromCtx(ctx, "").CreateBucket {
+	if !b.permsFromCtx(ctx, name, "").CreateBucket {
 		return gofakes3.ErrAccessDenied
 	}
 	return b.backend.CreateBucket(ctx, name)
 }
 
 func (b *authenticatedBackend) BucketExists(ctx context.Context, name string) (bool, error) {
-	if !b.permsFromCtx(ctx, name).BucketExists {
+	if !b.permsFromCtx(ctx, name, "").BucketExists {
 		return false, gofakes3.ErrAccessDenied
 	}
 	return b.backend.BucketExists(ctx, name)
 }
 
 func (b *authenticatedBackend) DeleteBucket(ctx context.Context, name string) error {
-	if !b.permsFromCtx(ctx, name).DeleteBucket {
+	if !b.permsFromCtx(ctx, name, "").DeleteBucket {
 		return gofakes3.ErrAccessDenied
 	}
 	return b.backend.DeleteBucket(ctx, name)
 }
 
 func (b *authenticatedBackend) GetObject(ctx context.Context, bucketName, objectName string, rangeRequest *gofakes3.ObjectRangeRequest) (*gofakes3.Object, error) {
-	if !b.permsFromCtx(ctx, bucketName).GetObject {
+	if !b.permsFromCtx(ctx, bucketName, objectName).GetObject {
 		return nil, gofakes3.ErrAccessDenied
 	}
 	return b.backend.GetObject(ctx, bucketName, objectName, rangeRequest)
 }
 
 func (b *authenticatedBackend) HeadObject(ctx context.Context, bucketName, objectName string) (*gofakes3.Object, error) {
-	if !b.permsFromCtx(ctx, bucketName).HeadObject {
+	if !b.permsFromCtx(ctx, bucketName, objectName).HeadObject {
 		return nil, gofakes3.ErrAccessDenied
 	}
 	return b.backend.HeadObject(ctx, bucketName, objectName)
 }
 
 func (b *authenticatedBackend) DeleteObject(ctx context.Context, bucketName, objectName string) (gofakes3.ObjectDeleteResult, error) {
-	if !b.permsFromCtx(ctx, bucketName).DeleteObject {
+	if !b.permsFromCtx(ctx, bucketName, objectName).DeleteObject {
 		return gofakes3.ObjectDeleteResult{}, gofakes3.ErrAccessDenied
 	}
 	return b.backend.DeleteObject(ctx, bucketName, objectName)
 }
 
 func (b *authenticatedBackend) PutObject(ctx context.Context, bucketName, key string, meta map[string]string, input io.Reader, size int64) (gofakes3.PutObjectResult, error) {
-	if !b.permsFromCtx(ctx, bucketName).PutObject {
+	perms := b.permsFromCtx(ctx, bucketName, key)
+	if !perms.PutObject {
+		return gofakes3.PutObjectResult{}, gofakes3.ErrAccessDenied
+	} else if meta[amazonObjectLockModeHeader] != "" && !perms.PutObjectRetention {
+		return gofakes3.PutObjectResult{}, gofakes3.ErrAccessDenied
+	} else if meta[amazonObjectLockLegalHoldHeader] != "" && !perms.PutObjectLegalHold {
 		return gofakes3.PutObjectResult{}, gofakes3.ErrAccessDenied
 	}
 	return b.backend.PutObject(ctx, bucketName, key, meta, input, size)
 }
 
 func (b *authenticatedBackend) DeleteMulti(ctx context.Context, bucketName string, objects ...string) (gofakes3.MultiDeleteResult, error) {
-	if !b.permsFromCtx(ctx, bucketName).DeleteMulti {
+	var allowed []string
+	var denied []gofakes3.ErrorResult
+	for _, object := range objects {
+		if b.permsFromCtx(ctx, bucketName, object).DeleteMulti {
+			allowed = append(allowed, object)
+		} else {
+			denied = append(denied, gofakes3.ErrorResult{Key: object, Code: gofakes3.ErrAccessDenied})
+		}
+	}
+	if len(allowed) == 0 && len(denied) > 0 {
 		return gofakes3.MultiDeleteResult{}, gofakes3.ErrAccessDenied
 	}
-	return b.backend.DeleteMulti(ctx, bucketName, objects...)
+	res, err := b.backend.DeleteMulti(ctx, bucketName, allowed...)
+	res.Error = append(res.Error, denied...)
+	return res, err
 }
 
 func (b *authenticatedBackend) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, meta map[string]string) (gofakes3.CopyObjectResult, error) {
-	if !b.permsFromCtx(ctx, srcBucket).CopyObject {
+	// copying an object reads the source, so it requires the same
+	// permission as downloading it
+	if !b.permsFromCtx(ctx, srcBucket, srcKey).GetObject {
 		return gofakes3.CopyObjectResult{}, gofakes3.ErrAccessDenied
-	} else if !b.permsFromCtx(ctx, dstBucket).PutObject {
+	} else if !b.permsFromCtx(ctx, dstBucket, dstKey).PutObject {
 		return gofakes3.CopyObjectResult{}, gofakes3.ErrAccessDenied
 	}
 	return b.backend.CopyObject(ctx, srcBucket, srcKey, dstBucket, dstKey, meta)
 }
 
 func (b *authenticatedBackend) CreateMultipartUpload(ctx context.Context, bucket, key string, meta map[string]string) (gofakes3.UploadID, error) {
-	if !b.permsFromCtx(ctx, bucket).CreateMultipartUpload {
+	if !b.permsFromCtx(ctx, bucket, key).CreateMultipartUpload {
 		return "", gofakes3.ErrAccessDenied
 	}
 	return b.backend.CreateMultipartUpload(ctx, bucket, key, meta)
 }
 
 func (b *authenticatedBackend) UploadPart(ctx context.Context, bucket, object string, id gofakes3.UploadID, partNumber int, contentLength int64, input io.Reader) (resp *gofakes3.UploadPartResult, err error) {
-	if !b.permsFromCtx(ctx, bucket).UploadPart {
+	if !b.permsFromCtx(ctx, bucket, object).UploadPart {
 		return nil, gofakes3.ErrAccessDenied
 	}
 	return b.backend.UploadPart(ctx, bucket, object, id, partNumber, contentLength, input)
 }
 
 func (b *authenticatedBackend) ListMultipartUploads(ctx context.Context, bucket string, marker *gofakes3.UploadListMarker, prefix gofakes3.Prefix, limit int64) (*gofakes3.ListMultipartUploadsResult, error) {
-	if !b.permsFromCtx(ctx, bucket).ListMultipartUpload {
+	if !b.permsFromCtx(ctx, bucket, prefix.Prefix).ListMultipartUpload {
 		return nil, gofakes3.ErrAccessDenied
 	}
 	return b.backend.ListMultipartUploads(ctx, bucket, marker, prefix, limit)
 }
 
 func (b *authenticatedBackend) ListParts(ctx context.Context, bucket, object string, uploadID gofakes3.UploadID, marker int, limit int64) (*gofakes3.ListMultipartUploadPartsResult, error) {
-	if !b.permsFromCtx(ctx, bucket).ListParts {
+	if !b.permsFromCtx(ctx, bucket, object).ListParts {
 		return nil, gofakes3.ErrAccessDenied
 	}
 	return b.backend.ListParts(ctx, bucket, object, uploadID, marker, limit)
 }
 
 func (b *authenticatedBackend) AbortMultipartUpload(ctx context.Context, bucket, object string, id gofakes3.UploadID) error {
-	if !b.permsFromCtx(ctx, bucket).AbortMultipartUpload {
+	if !b.permsFromCtx(ctx, bucket, object).AbortMultipartUpload {
 		return gofakes3.ErrAccessDenied
 	}
 	return b.backend.AbortMultipartUpload(ctx, bucket, object, id)
 }
 
 func (b *authenticatedBackend) CompleteMultipartUpload(ctx context.Context, bucket, object string, id gofakes3.UploadID, meta map[string]string, input *gofakes3.CompleteMultipartUploadRequest) (resp *gofakes3.CompleteMultipartUploadResult, err error) {
-	if !b.permsFromCtx(ctx, bucket).CompleteMultipartUpload {
+	if !b.permsFromCtx(ctx, bucket, object).CompleteMultipartUpload {
 		return nil, gofakes3.ErrAccessDenied
 	}
 	return b.backend.CompleteMultipartUpload(ctx, bucket, object, id, meta, input)
 }
+
+func (b *authenticatedBackend) VersioningConfiguration(ctx context.Context, bucket string) (gofakes3.VersioningConfiguration, error) {
+	if !b.permsFromCtx(ctx, bucket, "").GetBucketVersioning {
+		return gofakes3.VersioningConfiguration{}, gofakes3.ErrAccessDenied
+	}
+	return b.backend.VersioningConfiguration(ctx, bucket)
+}
+
+func (b *authenticatedBackend) SetVersioningConfiguration(ctx context.Context, bucket string, v gofakes3.VersioningConfiguration) error {
+	if !b.permsFromCtx(ctx, bucket, "").PutBucketVersioning {
+		return gofakes3.ErrAccessDenied
+	}
+	return b.backend.SetVersioningConfiguration(ctx, bucket, v)
+}
+
+func (b *authenticatedBackend) GetObjectVersion(ctx context.Context, bucket, object string, versionID gofakes3.VersionID, rangeRequest *gofakes3.ObjectRangeRequest) (*gofakes3.Object, error) {
+	if !b.permsFromCtx(ctx, bucket, object).GetObject {
+		return nil, gofakes3.ErrAccessDenied
+	}
+	return b.backend.GetObjectVersion(ctx, bucket, object, versionID, rangeRequest)
+}
+
+func (b *authenticatedBackend) HeadObjectVersion(ctx context.Context, bucket, object string, versionID gofakes3.VersionID) (*gofakes3.Object, error) {
+	if !b.permsFromCtx(ctx, bucket, object).HeadObject {
+		return nil, gofakes3.ErrAccessDenied
+	}
+	return b.backend.HeadObjectVersion(ctx, bucket, object, versionID)
+}
+
+func (b *authenticatedBackend) DeleteObjectVersion(ctx context.Context, bucket, object string, versionID gofakes3.VersionID) (gofakes3.ObjectDeleteResult, error) {
+	if !b.permsFromCtx(ctx, bucket, object).DeleteObject {
+		return gofakes3.ObjectDeleteResult{}, gofakes3.ErrAccessDenied
+	}
+	return b.backend.DeleteObjectVersion(ctx, bucket, object, versionID)
+}
+
+func (b *authenticatedBackend) DeleteMultiVersions(ctx context.Context, bucket string, objects ...gofakes3.ObjectID) (gofakes3.MultiDeleteResult, error) {
+	var allowed []gofakes3.ObjectID
+	var denied []gofakes3.ErrorResult
+	for _, object := range objects {
+		if b.permsFromCtx(ctx, bucket, object.Key).DeleteMulti {
+			allowed = append(allowed, object)
+		} else {
+			denied = append(denied, gofakes3.ErrorResult{Key: object.Key, Code: gofakes3.ErrAccessDenied})
+		}
+	}
+	if len(allowed) == 0 && len(denied) > 0 {
+		return gofakes3.MultiDeleteResult{}, gofakes3.ErrAccessDenied
+	}
+	res, err := b.backend.DeleteMultiVersions(ctx, bucket, allowed...)
+	res.Error = append(res.Error, denied...)
+	return res, err
+}
+
+func (b *authenticatedBackend) ListBucketVersions(ctx context.Context, bucket string, prefix *gofakes3.Prefix, page *gofakes3.ListBucketVersionsPage) (*gofakes3.ListBucketVersionsResult, error) {
+	if !b.permsFromCtx(ctx, bucket, prefixString(prefix)).ListBucket {
+		return nil, gofakes3.ErrAccessDenied
+	}
+	return b.backend.ListBucketVersions(ctx, bucket, prefix, page)
+}
+
+func (b *authenticatedBackend) GetObjectTagging(ctx context.Context, bucketName, objectName string) (api.ObjectTags, error) {
+	if !b.permsFromCtx(ctx, bucketName, objectName).GetObjectTagging {
+		return nil, gofakes3.ErrAccessDenied
+	}
+	return b.backend.GetObjectTagging(ctx, bucketName, objectName)
+}
+
+func (b *authenticatedBackend) PutObjectTagging(ctx context.Context, bucketName, objectName string, tags api.ObjectTags) error {
+	if !b.permsFromCtx(ctx, bucketName, objectName).PutObjectTagging {
+		return gofakes3.ErrAccessDenied
+	}
+	return b.backend.PutObjectTagging(ctx, bucketName, objectName, tags)
+}
+
+func (b *authenticatedBackend) DeleteObjectTagging(ctx context.Context, bucketName, objectName string) error {
+	if !b.permsFromCtx(ctx, bucketName, objectName).PutObjectTagging {
+		return gofakes3.ErrAccessDenied
+	}
+	return b.backend.DeleteObjectTagging(ctx, bucketName, objectName)
+}
+
+func (b *authenticatedBackend) GetObjectRetention(ctx context.Context, bucketName, objectName string) (api.ObjectRetention, error) {
+	if !b.permsFromCtx(ctx, bucketName, objectName).GetObjectRetention {
+		return api.ObjectRetention{}, gofakes3.ErrAccessDenied
+	}
+	return b.backend.GetObjectRetention(ctx, bucketName, objectName)
+}
+
+func (b *authenticatedBackend) PutObjectRetention(ctx context.Context, bucketName, objectName string, retention api.ObjectRetention, bypassGovernance bool) error {
+	perms := b.permsFromCtx(ctx, bucketName, objectName)
+	if !perms.PutObjectRetention {
+		return gofakes3.ErrAccessDenied
+	} else if bypassGovernance && !perms.BypassGovernanceRetention {
+		return gofakes3.ErrAccessDenied
+	}
+	return b.backend.PutObjectRetention(ctx, bucketName, objectName, retention, bypassGovernance)
+}
+
+func (b *authenticatedBackend) GetObjectLegalHold(ctx context.Context, bucketName, objectName string) (bool, error) {
+	if !b.permsFromCtx(ctx, bucketName, objectName).GetObjectLegalHold {
+		return false, gofakes3.ErrAccessDenied
+	}
+	return b.backend.GetObjectLegalHold(ctx, bucketName, objectName)
+}
+
+func (b *authenticatedBackend) PutObjectLegalHold(ctx context.Context, bucketName, objectName string, enabled bool) error {
+	if !b.permsFromCtx(ctx, bucketName, objectName).PutObjectLegalHold {
+		return gofakes3.ErrAccessDenied
+	}
+	return b.backend.PutObjectLegalHold(ctx, bucketName, objectName, enabled)
+}
+
+func (b *authenticatedBackend) UploadPartCopy(ctx context.Context, srcBucket, srcObject, bucket, object, uploadID string, partNumber int, rng *api.DownloadRange) (api.MultipartCopyPartResponse, error) {
+	if !b.permsFromCtx(ctx, srcBucket, srcObject).GetObject {
+		return api.MultipartCopyPartResponse{}, gofakes3.ErrAccessDenied
+	} else if !b.permsFromCtx(ctx, bucket, object).UploadPart {
+		return api.MultipartCopyPartResponse{}, gofakes3.ErrAccessDenied
+	}
+	return b.backend.UploadPartCopy(ctx, srcBucket, srcObject, bucket, object, uploadID, partNumber, rng)
+}
+
+// prefixString returns the prefix of a list request, a nil prefix is treated as
+// an empty prefix.
+func prefixString(prefix *gofakes3.Prefix) string {
+	if prefix == nil {
+		return ""
+	}
+	return prefix.Prefix
+}
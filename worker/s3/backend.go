@@ -8,7 +8,9 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/gotd/contrib/http_range"
 	"go.sia.tech/gofakes3"
 	"go.thebigfile.com/renterd/api"
 	"go.thebigfile.com/renterd/internal/utils"
@@ -20,6 +22,13 @@ const (
 	// amazonMetadataPrefix is a header prefix used by the AWS SDK.
 	amazonMetadataPrefix = "X-Amz-Meta-"
 
+	// amazon checksum headers, the checksum algorithm is sent by the AWS SDK
+	// alongside the checksum itself
+	amazonChecksumAlgorithmHeader    = "X-Amz-Checksum-Algorithm"
+	amazonSDKChecksumAlgorithmHeader = "X-Amz-Sdk-Checksum-Algorithm"
+	amazonChecksumCRC32CHeader       = "X-Amz-Checksum-Crc32c"
+	amazonChecksumSHA256Header       = "X-Amz-Checksum-Sha256"
+
 	// maxKeysDefault is the default maxKeys value used in the AWS SDK
 	maxKeysDefault = 1000
 )
@@ -27,6 +36,8 @@ const (
 var (
 	_ gofakes3.Backend          = (*s3)(nil)
 	_ gofakes3.MultipartBackend = (*s3)(nil)
+	_ gofakes3.VersionedBackend = (*s3)(nil)
+	_ extensionBackend          = (*s3)(nil)
 )
 
 type s3 struct {
@@ -240,11 +251,17 @@ func (s *s3) DeleteBucket(ctx context.Context, name string) error {
 // TODO: Range requests starting from the end are not supported yet. Backend
 // needs to be updated for that.
 func (s *s3) GetObject(ctx context.Context, bucketName, objectName string, rangeRequest *gofakes3.ObjectRangeRequest) (*gofakes3.Object, error) {
+	return s.getObject(ctx, bucketName, objectName, "", rangeRequest)
+}
+
+func (s *s3) getObject(ctx context.Context, bucketName, objectName, versionID string, rangeRequest *gofakes3.ObjectRangeRequest) (*gofakes3.Object, error) {
 	if rangeRequest != nil && rangeRequest.FromEnd {
 		return nil, gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "range request from end not supported")
 	}
 
 	opts := api.DownloadObjectOptions{}
+	opts.VersionID = versionID
+	opts.CustomerKey = sseCustomerKeysFromContext(ctx).key
 	if rangeRequest != nil {
 		length := int64(-1)
 		if rangeRequest.End >= 0 {
@@ -256,8 +273,14 @@ func (s *s3) GetObject(ctx context.Context, bucketName, objectName string, range
 	res, err := s.w.GetObject(ctx, bucketName, objectName, opts)
 	if utils.IsErr(err, api.ErrBucketNotFound) {
 		return nil, gofakes3.BucketNotFound(bucketName)
-	} else if utils.IsErr(err, api.ErrObjectNotFound) {
+	} else if utils.IsErr(err, api.ErrObjectNotFound) || utils.IsErr(err, api.ErrObjectVersionIsDeleteMarker) {
 		return nil, gofakes3.KeyNotFound(objectName)
+	} else if utils.IsErr(err, api.ErrObjectVersionNotFound) {
+		return nil, gofakes3.ErrNoSuchVersion
+	} else if utils.IsErr(err, api.ErrCustomerKeyRequired) {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, err.Error())
+	} else if utils.IsErr(err, api.ErrCustomerKeyMismatch) {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrAccessDenied, err.Error())
 	} else if err != nil {
 		return nil, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
 	}
@@ -280,6 +303,8 @@ func (s *s3) GetObject(ctx context.Context, bucketName, objectName string, range
 	// decorate metadata
 	metadata["Content-Type"] = res.ContentType
 	metadata["Last-Modified"] = res.LastModified.Std().Format(http.TimeFormat)
+	setChecksumMetadata(metadata, res.ChecksumAlgorithm, res.Checksum)
+	setSSECustomerKeyMetadata(metadata, opts.CustomerKey)
 
 	// etag to bytes
 	etag, err := hex.DecodeString(res.Etag)
@@ -288,12 +313,13 @@ func (s *s3) GetObject(ctx context.Context, bucketName, objectName string, range
 	}
 
 	return &gofakes3.Object{
-		Hash:     etag,
-		Name:     gofakes3.URLEncode(objectName),
-		Metadata: metadata,
-		Size:     res.Size,
-		Contents: res.Content,
-		Range:    objectRange,
+		Hash:      etag,
+		Name:      gofakes3.URLEncode(objectName),
+		Metadata:  metadata,
+		Size:      res.Size,
+		Contents:  res.Content,
+		Range:     objectRange,
+		VersionID: gofakes3.VersionID(res.VersionID),
 	}, nil
 }
 
@@ -307,11 +333,24 @@ func (s *s3) GetObject(ctx context.Context, bucketName, objectName string, range
 // HeadObject should return a NotFound() error if the object does not
 // exist.
 func (s *s3) HeadObject(ctx context.Context, bucketName, objectName string) (*gofakes3.Object, error) {
+	return s.headObject(ctx, bucketName, objectName, "")
+}
+
+func (s *s3) headObject(ctx context.Context, bucketName, objectName, versionID string) (*gofakes3.Object, error) {
+	ck := sseCustomerKeysFromContext(ctx).sourceKey()
 	res, err := s.w.HeadObject(ctx, bucketName, objectName, api.HeadObjectOptions{
 		IgnoreDelim: true,
+		VersionID:   versionID,
+		CustomerKey: ck,
 	})
-	if utils.IsErr(err, api.ErrObjectNotFound) {
+	if utils.IsErr(err, api.ErrObjectNotFound) || utils.IsErr(err, api.ErrObjectVersionIsDeleteMarker) {
 		return nil, gofakes3.KeyNotFound(objectName)
+	} else if utils.IsErr(err, api.ErrObjectVersionNotFound) {
+		return nil, gofakes3.ErrNoSuchVersion
+	} else if utils.IsErr(err, api.ErrCustomerKeyRequired) {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, err.Error())
+	} else if utils.IsErr(err, api.ErrCustomerKeyMismatch) {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrAccessDenied, err.Error())
 	} else if err != nil {
 		return nil, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
 	}
@@ -325,6 +364,8 @@ func (s *s3) HeadObject(ctx context.Context, bucketName, objectName string) (*go
 	// decorate metadata
 	metadata["Content-Type"] = res.ContentType
 	metadata["Last-Modified"] = res.LastModified.Std().Format(http.TimeFormat)
+	setChecksumMetadata(metadata, res.ChecksumAlgorithm, res.Checksum)
+	setSSECustomerKeyMetadata(metadata, ck)
 
 	// etag to bytes
 	hash, err := hex.DecodeString(res.Etag)
@@ -333,11 +374,12 @@ func (s *s3) HeadObject(ctx context.Context, bucketName, objectName string) (*go
 	}
 
 	return &gofakes3.Object{
-		Hash:     hash,
-		Name:     gofakes3.URLEncode(objectName),
-		Metadata: metadata,
-		Size:     res.Size,
-		Contents: io.NopCloser(bytes.NewReader(nil)),
+		Hash:      hash,
+		Name:      gofakes3.URLEncode(objectName),
+		Metadata:  metadata,
+		Size:      res.Size,
+		Contents:  io.NopCloser(bytes.NewReader(nil)),
+		VersionID: gofakes3.VersionID(res.VersionID),
 	}, nil
 }
 
@@ -357,16 +399,19 @@ func (s *s3) HeadObject(ctx context.Context, bucketName, objectName string) (*go
 //	delete marker, which becomes the latest version of the object. If there
 //	isn't a null version, Amazon S3 does not remove any objects.
 func (s *s3) DeleteObject(ctx context.Context, bucketName, objectName string) (gofakes3.ObjectDeleteResult, error) {
-	err := s.b.DeleteObject(ctx, bucketName, objectName, api.DeleteObjectOptions{})
+	resp, err := s.b.DeleteObject(ctx, bucketName, objectName, api.DeleteObjectOptions{})
 	if utils.IsErr(err, api.ErrBucketNotFound) {
 		return gofakes3.ObjectDeleteResult{}, gofakes3.BucketNotFound(bucketName)
 	} else if utils.IsErr(err, api.ErrObjectNotFound) {
 		return gofakes3.ObjectDeleteResult{}, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	} else if utils.IsErr(err, api.ErrObjectLocked) {
+		return gofakes3.ObjectDeleteResult{}, gofakes3.ErrorMessage(gofakes3.ErrAccessDenied, err.Error())
 	}
 
+	// a delete marker is created if versioning was ever enabled
 	return gofakes3.ObjectDeleteResult{
-		IsDeleteMarker: false, // not supported
-		VersionID:      "",    // not supported
+		IsDeleteMarker: resp.DeleteMarker,
+		VersionID:      gofakes3.VersionID(resp.VersionID),
 	}, nil
 }
 
@@ -381,10 +426,23 @@ func (s *s3) PutObject(ctx context.Context, bucketName, key string, meta map[str
 	if ct, ok := meta["Content-Type"]; ok {
 		opts.MimeType = ct
 	}
+	opts.ChecksumAlgorithm, opts.Checksum = checksumFromMetadata(meta)
+	opts.CustomerKey = sseCustomerKeysFromContext(ctx).key
+
+	// the lock is stored along with the object
+	lock, err := objectLockFromMetadata(meta)
+	if err != nil {
+		return gofakes3.PutObjectResult{}, err
+	}
+	opts.Lock = lock
 
 	ur, err := s.w.UploadObject(ctx, input, bucketName, key, opts)
 	if utils.IsErr(err, api.ErrBucketNotFound) {
 		return gofakes3.PutObjectResult{}, gofakes3.BucketNotFound(bucketName)
+	} else if utils.IsErr(err, api.ErrChecksumMismatch) {
+		return gofakes3.PutObjectResult{}, gofakes3.ErrorMessage(gofakes3.ErrBadDigest, err.Error())
+	} else if utils.IsErr(err, api.ErrBucketQuotaExceeded) || utils.IsErr(err, api.ErrObjectLocked) {
+		return gofakes3.PutObjectResult{}, gofakes3.ErrorMessage(gofakes3.ErrAccessDenied, err.Error())
 	} else if err != nil {
 		return gofakes3.PutObjectResult{}, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
 	}
@@ -398,7 +456,7 @@ func (s *s3) PutObject(ctx context.Context, bucketName, key string, meta map[str
 func (s *s3) DeleteMulti(ctx context.Context, bucketName string, objects ...string) (gofakes3.MultiDeleteResult, error) {
 	var res gofakes3.MultiDeleteResult
 	for _, objectName := range objects {
-		err := s.b.DeleteObject(ctx, bucketName, objectName, api.DeleteObjectOptions{})
+		_, err := s.b.DeleteObject(ctx, bucketName, objectName, api.DeleteObjectOptions{})
 		if err != nil && !utils.IsErr(err, api.ErrObjectNotFound) {
 			res.Error = append(res.Error, gofakes3.ErrorResult{
 				Key:     objectName,
@@ -416,12 +474,31 @@ func (s *s3) DeleteMulti(ctx context.Context, bucketName string, objects ...stri
 }
 
 func (s *s3) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, meta map[string]string) (gofakes3.CopyObjectResult, error) {
+	// the copy shares the data of the source object, so it's encrypted with
+	// the same customer key
+	if keys := sseCustomerKeysFromContext(ctx); !sameCustomerKey(keys.key, keys.copySource) {
+		return gofakes3.CopyObjectResult{}, gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "changing the customer key of an object is not supported")
+	}
+
+	// the copy doesn't stream data through the worker, so the quota of the
+	// destination bucket is checked against the size of the source object
+	bucket, err := s.b.Bucket(ctx, dstBucket)
+	if utils.IsErr(err, api.ErrBucketNotFound) {
+		return gofakes3.CopyObjectResult{}, gofakes3.BucketNotFound(dstBucket)
+	} else if err != nil {
+		return gofakes3.CopyObjectResult{}, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	} else if err := s.checkCopyQuota(ctx, srcBucket, srcKey, bucket, nil, false); err != nil {
+		return gofakes3.CopyObjectResult{}, err
+	}
+
 	convertToSiaMetadataHeaders(meta)
 	obj, err := s.b.CopyObject(ctx, srcBucket, dstBucket, "/"+srcKey, "/"+dstKey, api.CopyObjectOptions{
 		MimeType: meta["Content-Type"],
 		Metadata: api.ExtractObjectUserMetadataFrom(meta),
 	})
-	if err != nil {
+	if utils.IsErr(err, api.ErrObjectLocked) {
+		return gofakes3.CopyObjectResult{}, gofakes3.ErrorMessage(gofakes3.ErrAccessDenied, err.Error())
+	} else if err != nil {
 		return gofakes3.CopyObjectResult{}, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
 	}
 
@@ -432,6 +509,12 @@ func (s *s3) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKe
 }
 
 func (s *s3) CreateMultipartUpload(ctx context.Context, bucket, key string, meta map[string]string) (gofakes3.UploadID, error) {
+	// parts are uploaded without encryption offsets, so they can't be
+	// encrypted with a customer key
+	if sseCustomerKeysFromContext(ctx).key != nil {
+		return "", gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "SSE-C is not supported for multipart uploads")
+	}
+
 	convertToSiaMetadataHeaders(meta)
 	resp, err := s.b.CreateMultipartUpload(ctx, bucket, "/"+key, api.CreateMultipartOptions{
 		Key:      &object.NoOpKey,
@@ -446,10 +529,16 @@ func (s *s3) CreateMultipartUpload(ctx context.Context, bucket, key string, meta
 }
 
 func (s *s3) UploadPart(ctx context.Context, bucket, object string, id gofakes3.UploadID, partNumber int, contentLength int64, input io.Reader) (*gofakes3.UploadPartResult, error) {
+	if sseCustomerKeysFromContext(ctx).key != nil {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "SSE-C is not supported for multipart uploads")
+	}
+
 	res, err := s.w.UploadMultipartUploadPart(ctx, input, bucket, object, string(id), partNumber, api.UploadMultipartUploadPartOptions{
 		ContentLength: contentLength,
 	})
-	if err != nil {
+	if utils.IsErr(err, api.ErrBucketQuotaExceeded) {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrAccessDenied, err.Error())
+	} else if err != nil {
 		return nil, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
 	}
 
@@ -458,6 +547,122 @@ func (s *s3) UploadPart(ctx context.Context, bucket, object string, id gofakes3.
 	}, nil
 }
 
+// UploadPartCopy adds a part to a multipart upload that contains the given
+// range of an existing object. If possible the part references the slabs of
+// the source object, otherwise the data is downloaded and uploaded again by
+// the worker. The latter is always the case for sources that are encrypted,
+// either with a customer key or with object encryption, since the data of the
+// part has to be re-encrypted for the upload.
+func (s *s3) UploadPartCopy(ctx context.Context, srcBucket, srcObject, bucketName, objectName, uploadID string, partNumber int, rng *api.DownloadRange) (api.MultipartCopyPartResponse, error) {
+	keys := sseCustomerKeysFromContext(ctx)
+	if keys.key != nil {
+		return api.MultipartCopyPartResponse{}, gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "SSE-C is not supported for multipart uploads")
+	}
+
+	// use the contract set of the destination bucket, if it's bound to one,
+	// like uploads do
+	bucket, err := s.b.Bucket(ctx, bucketName)
+	if utils.IsErr(err, api.ErrBucketNotFound) {
+		return api.MultipartCopyPartResponse{}, gofakes3.BucketNotFound(bucketName)
+	} else if err != nil {
+		return api.MultipartCopyPartResponse{}, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	contractSet := bucket.Policy.ContractSet
+	if contractSet == "" {
+		up, err := s.b.UploadParams(ctx)
+		if err != nil {
+			return api.MultipartCopyPartResponse{}, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+		}
+		contractSet = up.ContractSet
+	}
+
+	// parts that reference the source object's data don't stream it through
+	// the worker, so the quota is checked against the size of the range
+	if err := s.checkCopyQuota(ctx, srcBucket, srcObject, bucket, rng, true); err != nil {
+		return api.MultipartCopyPartResponse{}, err
+	}
+
+	resp, err := s.b.CopyMultipartPart(ctx, srcBucket, "/"+srcObject, bucketName, "/"+objectName, contractSet, uploadID, partNumber, api.CopyMultipartPartOptions{
+		Range: rng,
+	})
+	if utils.IsErr(err, api.ErrPartCopyNotSupported) {
+		resp, err = s.uploadPartCopyFromDownload(ctx, srcBucket, srcObject, bucketName, objectName, uploadID, partNumber, rng, keys.copySource)
+	}
+	if utils.IsErr(err, api.ErrObjectNotFound) {
+		return api.MultipartCopyPartResponse{}, gofakes3.KeyNotFound(srcObject)
+	} else if utils.IsErr(err, api.ErrMultipartUploadNotFound) {
+		return api.MultipartCopyPartResponse{}, gofakes3.ErrNoSuchUpload
+	} else if utils.IsErr(err, api.ErrInvalidPartCopyRange) || utils.IsErr(err, http_range.ErrInvalid) {
+		return api.MultipartCopyPartResponse{}, gofakes3.ErrorMessage(gofakes3.ErrInvalidRange, err.Error())
+	} else if utils.IsErr(err, api.ErrCustomerKeyRequired) {
+		return api.MultipartCopyPartResponse{}, gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, err.Error())
+	} else if utils.IsErr(err, api.ErrCustomerKeyMismatch) || utils.IsErr(err, api.ErrBucketQuotaExceeded) {
+		return api.MultipartCopyPartResponse{}, gofakes3.ErrorMessage(gofakes3.ErrAccessDenied, err.Error())
+	} else if err != nil {
+		return api.MultipartCopyPartResponse{}, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	return resp, nil
+}
+
+// checkCopyQuota checks whether copying the given range of the source object,
+// or the whole object if the range is nil, exceeds the quota of the
+// destination bucket.
+func (s *s3) checkCopyQuota(ctx context.Context, srcBucket, srcObject string, bucket api.Bucket, rng *api.DownloadRange, isPart bool) error {
+	if !bucket.Policy.Quota.Enabled() {
+		return nil
+	}
+
+	var size int64
+	if rng != nil {
+		size = rng.Length
+	} else {
+		res, err := s.b.Object(ctx, srcBucket, "/"+srcObject, api.GetObjectOptions{OnlyMetadata: true})
+		if utils.IsErr(err, api.ErrObjectNotFound) || (err == nil && res.Object == nil) {
+			return gofakes3.KeyNotFound(srcObject)
+		} else if err != nil {
+			return gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+		}
+		size = res.Object.Size
+	}
+
+	err := s.w.CheckBucketQuota(ctx, bucket, size, isPart)
+	if utils.IsErr(err, api.ErrBucketQuotaExceeded) {
+		return gofakes3.ErrorMessage(gofakes3.ErrAccessDenied, err.Error())
+	} else if err != nil {
+		return gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	return nil
+}
+
+// uploadPartCopyFromDownload copies a part by streaming the data of the source
+// object through the worker, this is necessary if the source object is
+// encrypted since its data has to be re-encrypted.
+func (s *s3) uploadPartCopyFromDownload(ctx context.Context, srcBucket, srcObject, bucketName, objectName, uploadID string, partNumber int, rng *api.DownloadRange, ck *object.CustomerKey) (api.MultipartCopyPartResponse, error) {
+	res, err := s.w.GetObject(ctx, srcBucket, srcObject, api.DownloadObjectOptions{
+		Range:       rng,
+		CustomerKey: ck,
+	})
+	if err != nil {
+		return api.MultipartCopyPartResponse{}, err
+	}
+	defer res.Content.Close()
+
+	length := res.Size
+	if res.Range != nil {
+		length = res.Range.Length
+	}
+	ur, err := s.w.UploadMultipartUploadPart(ctx, res.Content, bucketName, objectName, uploadID, partNumber, api.UploadMultipartUploadPartOptions{
+		ContentLength: length,
+	})
+	if err != nil {
+		return api.MultipartCopyPartResponse{}, err
+	}
+	return api.MultipartCopyPartResponse{
+		ETag:         ur.ETag,
+		LastModified: api.TimeRFC3339(time.Now()),
+	}, nil
+}
+
 func (s *s3) ListMultipartUploads(ctx context.Context, bucket string, marker *gofakes3.UploadListMarker, prefix gofakes3.Prefix, limit int64) (*gofakes3.ListMultipartUploadsResult, error) {
 	prefix.HasPrefix = prefix.Prefix != ""
 	prefix.HasDelimiter = prefix.Delimiter != ""
@@ -531,6 +736,18 @@ func (s *s3) AbortMultipartUpload(ctx context.Context, bucket, object string, id
 }
 
 func (s *s3) CompleteMultipartUpload(ctx context.Context, bucket, object string, id gofakes3.UploadID, meta map[string]string, input *gofakes3.CompleteMultipartUploadRequest) (*gofakes3.CompleteMultipartUploadResult, error) {
+	// the size of the parts was accounted for when they were uploaded but the
+	// object only counts towards the bucket's object limit once it's completed
+	if b, err := s.b.Bucket(ctx, bucket); utils.IsErr(err, api.ErrBucketNotFound) {
+		return nil, gofakes3.BucketNotFound(bucket)
+	} else if err != nil {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	} else if err := s.w.CheckBucketQuota(ctx, b, 0, false); utils.IsErr(err, api.ErrBucketQuotaExceeded) {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrAccessDenied, err.Error())
+	} else if err != nil {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+
 	convertToSiaMetadataHeaders(meta)
 	var parts []api.MultipartCompletedPart
 	for _, part := range input.Parts {
@@ -542,7 +759,9 @@ func (s *s3) CompleteMultipartUpload(ctx context.Context, bucket, object string,
 	resp, err := s.b.CompleteMultipartUpload(ctx, bucket, "/"+object, string(id), parts, api.CompleteMultipartOptions{
 		Metadata: api.ExtractObjectUserMetadataFrom(meta),
 	})
-	if err != nil {
+	if utils.IsErr(err, api.ErrObjectLocked) {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrAccessDenied, err.Error())
+	} else if err != nil {
 		return nil, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
 	}
 	return &gofakes3.CompleteMultipartUploadResult{
@@ -550,6 +769,133 @@ func (s *s3) CompleteMultipartUpload(ctx context.Context, bucket, object string,
 	}, nil
 }
 
+// checksumFromMetadata extracts the checksum algorithm and the expected
+// checksum from the headers of a PutObject request. Algorithms that renterd
+// doesn't support, e.g. the CRC32 default of recent SDKs, are ignored.
+func checksumFromMetadata(meta map[string]string) (algorithm, checksum string) {
+	if v, ok := meta[amazonChecksumSHA256Header]; ok {
+		return api.ChecksumAlgorithmSHA256, v
+	} else if v, ok := meta[amazonChecksumCRC32CHeader]; ok {
+		return api.ChecksumAlgorithmCRC32C, v
+	}
+
+	algorithm = strings.ToUpper(meta[amazonSDKChecksumAlgorithmHeader])
+	if algorithm == "" {
+		algorithm = strings.ToUpper(meta[amazonChecksumAlgorithmHeader])
+	}
+	switch algorithm {
+	case api.ChecksumAlgorithmCRC32C, api.ChecksumAlgorithmSHA256:
+		return algorithm, ""
+	default:
+		return "", ""
+	}
+}
+
+// setChecksumMetadata adds the checksum header that corresponds to the given
+// algorithm to the metadata.
+func setChecksumMetadata(metadata map[string]string, algorithm, checksum string) {
+	if checksum == "" {
+		return
+	}
+	switch algorithm {
+	case api.ChecksumAlgorithmCRC32C:
+		metadata[amazonChecksumCRC32CHeader] = checksum
+	case api.ChecksumAlgorithmSHA256:
+		metadata[amazonChecksumSHA256Header] = checksum
+	}
+}
+
+// sameCustomerKey returns true if both keys are either nil or equal.
+func sameCustomerKey(a, b *object.CustomerKey) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// GetObjectTagging returns the tags of an object.
+func (s *s3) GetObjectTagging(ctx context.Context, bucketName, objectName string) (api.ObjectTags, error) {
+	tags, err := s.b.ObjectTags(ctx, bucketName, objectName)
+	if utils.IsErr(err, api.ErrObjectNotFound) {
+		return nil, gofakes3.KeyNotFound(objectName)
+	} else if err != nil {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	return tags, nil
+}
+
+// PutObjectTagging replaces the tags of an object.
+func (s *s3) PutObjectTagging(ctx context.Context, bucketName, objectName string, tags api.ObjectTags) error {
+	err := s.b.UpdateObjectTags(ctx, bucketName, objectName, tags)
+	if utils.IsErr(err, api.ErrObjectNotFound) {
+		return gofakes3.KeyNotFound(objectName)
+	} else if utils.IsErr(err, api.ErrInvalidObjectTags) {
+		return gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, err.Error())
+	} else if err != nil {
+		return gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	return nil
+}
+
+// DeleteObjectTagging removes all tags from an object.
+func (s *s3) DeleteObjectTagging(ctx context.Context, bucketName, objectName string) error {
+	err := s.b.DeleteObjectTags(ctx, bucketName, objectName)
+	if utils.IsErr(err, api.ErrObjectNotFound) {
+		return gofakes3.KeyNotFound(objectName)
+	} else if err != nil {
+		return gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	return nil
+}
+
+// GetObjectRetention returns the retention of an object.
+func (s *s3) GetObjectRetention(ctx context.Context, bucketName, objectName string) (api.ObjectRetention, error) {
+	r, err := s.b.ObjectRetention(ctx, bucketName, objectName)
+	if utils.IsErr(err, api.ErrObjectNotFound) {
+		return api.ObjectRetention{}, gofakes3.KeyNotFound(objectName)
+	} else if err != nil {
+		return api.ObjectRetention{}, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	return r, nil
+}
+
+// PutObjectRetention replaces the retention of an object.
+func (s *s3) PutObjectRetention(ctx context.Context, bucketName, objectName string, retention api.ObjectRetention, bypassGovernance bool) error {
+	err := s.b.UpdateObjectRetention(ctx, bucketName, objectName, retention, bypassGovernance)
+	if utils.IsErr(err, api.ErrObjectNotFound) {
+		return gofakes3.KeyNotFound(objectName)
+	} else if utils.IsErr(err, api.ErrInvalidObjectRetention) {
+		return gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, err.Error())
+	} else if utils.IsErr(err, api.ErrObjectLocked) {
+		return gofakes3.ErrorMessage(gofakes3.ErrAccessDenied, err.Error())
+	} else if err != nil {
+		return gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	return nil
+}
+
+// GetObjectLegalHold returns whether an object is under a legal hold.
+func (s *s3) GetObjectLegalHold(ctx context.Context, bucketName, objectName string) (bool, error) {
+	lh, err := s.b.ObjectLegalHold(ctx, bucketName, objectName)
+	if utils.IsErr(err, api.ErrObjectNotFound) {
+		return false, gofakes3.KeyNotFound(objectName)
+	} else if err != nil {
+		return false, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	return lh.Enabled, nil
+}
+
+// PutObjectLegalHold enables or disables the legal hold of an object.
+func (s *s3) PutObjectLegalHold(ctx context.Context, bucketName, objectName string, enabled bool) error {
+	err := s.b.UpdateObjectLegalHold(ctx, bucketName, objectName, enabled)
+	if utils.IsErr(err, api.ErrObjectNotFound) {
+		return gofakes3.KeyNotFound(objectName)
+	} else if err != nil {
+		return gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	return nil
+}
+
 func convertToSiaMetadataHeaders(metadata map[string]string) {
 	for k, v := range metadata {
 		if key := extractMetadataKey(k); key != "" {
@@ -568,3 +914,172 @@ func extractMetadataKey(key string) string {
 	}
 	return ""
 }
+
+// VersioningConfiguration must return a gofakes3.ErrNoSuchBucket error if the
+// bucket does not exist. See gofakes3.BucketNotFound() for a convenient way to
+// create one.
+func (s *s3) VersioningConfiguration(ctx context.Context, bucketName string) (gofakes3.VersioningConfiguration, error) {
+	bucket, err := s.b.Bucket(ctx, bucketName)
+	if utils.IsErr(err, api.ErrBucketNotFound) {
+		return gofakes3.VersioningConfiguration{}, gofakes3.BucketNotFound(bucketName)
+	} else if err != nil {
+		return gofakes3.VersioningConfiguration{}, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	return gofakes3.VersioningConfiguration{
+		Status: gofakes3.VersioningStatus(bucket.Policy.Versioning),
+	}, nil
+}
+
+// SetVersioningConfiguration must return a gofakes3.ErrNoSuchBucket error if
+// the bucket does not exist. See gofakes3.BucketNotFound() for a convenient
+// way to create one.
+func (s *s3) SetVersioningConfiguration(ctx context.Context, bucketName string, v gofakes3.VersioningConfiguration) error {
+	bucket, err := s.b.Bucket(ctx, bucketName)
+	if utils.IsErr(err, api.ErrBucketNotFound) {
+		return gofakes3.BucketNotFound(bucketName)
+	} else if err != nil {
+		return gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+
+	// versioning can't be disabled once it was enabled, only suspended
+	switch v.Status {
+	case gofakes3.VersioningEnabled:
+		bucket.Policy.Versioning = api.BucketVersioningEnabled
+	case gofakes3.VersioningSuspended:
+		if bucket.Policy.Versioning == "" {
+			return nil
+		}
+		bucket.Policy.Versioning = api.BucketVersioningSuspended
+	default:
+		return gofakes3.ErrorMessage(gofakes3.ErrIllegalVersioningConfiguration, "unknown versioning status "+string(v.Status))
+	}
+
+	err = s.b.UpdateBucketPolicy(ctx, bucketName, bucket.Policy)
+	if err != nil {
+		return gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	return nil
+}
+
+// GetObjectVersion retrieves a specific version of an object. It behaves
+// like GetObject, but returns gofakes3.ErrNoSuchVersion if the version does
+// not exist.
+func (s *s3) GetObjectVersion(ctx context.Context, bucketName, objectName string, versionID gofakes3.VersionID, rangeRequest *gofakes3.ObjectRangeRequest) (*gofakes3.Object, error) {
+	return s.getObject(ctx, bucketName, objectName, string(versionID), rangeRequest)
+}
+
+// HeadObjectVersion fetches a specific version of an object, but reading the
+// Contents will return io.EOF immediately.
+func (s *s3) HeadObjectVersion(ctx context.Context, bucketName, objectName string, versionID gofakes3.VersionID) (*gofakes3.Object, error) {
+	return s.headObject(ctx, bucketName, objectName, string(versionID))
+}
+
+// DeleteObjectVersion permanently deletes a specific version of an object.
+//
+// DeleteObjectVersion must not return an error if the object or the version
+// does not exist.
+func (s *s3) DeleteObjectVersion(ctx context.Context, bucketName, objectName string, versionID gofakes3.VersionID) (gofakes3.ObjectDeleteResult, error) {
+	_, err := s.b.DeleteObject(ctx, bucketName, objectName, api.DeleteObjectOptions{
+		VersionID: string(versionID),
+	})
+	if utils.IsErr(err, api.ErrBucketNotFound) {
+		return gofakes3.ObjectDeleteResult{}, gofakes3.BucketNotFound(bucketName)
+	} else if utils.IsErr(err, api.ErrObjectVersionNotFound) {
+		return gofakes3.ObjectDeleteResult{}, nil
+	} else if utils.IsErr(err, api.ErrObjectLocked) {
+		return gofakes3.ObjectDeleteResult{}, gofakes3.ErrorMessage(gofakes3.ErrAccessDenied, err.Error())
+	} else if err != nil {
+		return gofakes3.ObjectDeleteResult{}, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+	return gofakes3.ObjectDeleteResult{
+		VersionID: versionID,
+	}, nil
+}
+
+// DeleteMultiVersions deletes multiple objects, if a version is specified for
+// an object only that version is deleted.
+func (s *s3) DeleteMultiVersions(ctx context.Context, bucketName string, objects ...gofakes3.ObjectID) (gofakes3.MultiDeleteResult, error) {
+	var res gofakes3.MultiDeleteResult
+	for _, obj := range objects {
+		_, err := s.b.DeleteObject(ctx, bucketName, obj.Key, api.DeleteObjectOptions{
+			VersionID: obj.VersionID,
+		})
+		if err != nil && !utils.IsErr(err, api.ErrObjectNotFound) && !utils.IsErr(err, api.ErrObjectVersionNotFound) {
+			res.Error = append(res.Error, gofakes3.ErrorResult{
+				Key:     obj.Key,
+				Code:    gofakes3.ErrInternal,
+				Message: err.Error(),
+			})
+		} else {
+			res.Deleted = append(res.Deleted, obj)
+		}
+	}
+	return res, nil
+}
+
+// ListBucketVersions lists all versions of the objects in a bucket, including
+// delete markers, ordered by key and from newest to oldest.
+func (s *s3) ListBucketVersions(ctx context.Context, bucketName string, prefix *gofakes3.Prefix, page *gofakes3.ListBucketVersionsPage) (*gofakes3.ListBucketVersionsResult, error) {
+	if prefix == nil {
+		prefix = &gofakes3.Prefix{}
+	}
+	if prefix.Delimiter != "" {
+		// NOTE: this is a limitation of the current implementation of the bus.
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "delimiter not supported when listing versions")
+	}
+	if page == nil {
+		page = &gofakes3.ListBucketVersionsPage{}
+	}
+	if page.MaxKeys == 0 {
+		page.MaxKeys = maxKeysDefault
+	}
+
+	opts := api.ListObjectVersionsOptions{
+		Limit:  int(page.MaxKeys),
+		Prefix: "/" + prefix.Prefix,
+	}
+	if page.HasKeyMarker {
+		opts.KeyMarker = "/" + page.KeyMarker
+	}
+	if page.HasVersionIDMarker {
+		opts.VersionIDMarker = string(page.VersionIDMarker)
+	}
+
+	res, err := s.b.ObjectVersions(ctx, bucketName, opts)
+	if utils.IsErr(err, api.ErrBucketNotFound) {
+		return nil, gofakes3.BucketNotFound(bucketName)
+	} else if utils.IsErr(err, api.ErrMarkerNotFound) {
+		return nil, gofakes3.ErrorInvalidArgument("version-id-marker", opts.VersionIDMarker, err.Error())
+	} else if err != nil {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrInternal, err.Error())
+	}
+
+	response := gofakes3.NewListBucketVersionsResult(bucketName, prefix, page)
+	response.IsTruncated = res.HasMore
+	if res.HasMore {
+		response.NextKeyMarker = strings.TrimPrefix(res.NextKeyMarker, "/")
+		response.NextVersionIDMarker = gofakes3.VersionID(res.NextVersionIDMarker)
+	}
+	for _, v := range res.Versions {
+		key := strings.TrimPrefix(v.Name, "/")
+		if v.IsDeleteMarker {
+			response.Versions = append(response.Versions, &gofakes3.DeleteMarker{
+				Key:          key,
+				VersionID:    gofakes3.VersionID(v.VersionID),
+				IsLatest:     v.IsLatest,
+				LastModified: gofakes3.NewContentTime(v.ModTime.Std()),
+			})
+			continue
+		}
+		response.Versions = append(response.Versions, &gofakes3.Version{
+			Key:          key,
+			VersionID:    gofakes3.VersionID(v.VersionID),
+			IsLatest:     v.IsLatest,
+			LastModified: gofakes3.NewContentTime(v.ModTime.Std()),
+			Size:         v.Size,
+			StorageClass: gofakes3.StorageStandard,
+			ETag:         api.FormatETag(v.ETag),
+		})
+	}
+	return response, nil
+}
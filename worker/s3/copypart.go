@@ -0,0 +1,107 @@
+package s3
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.sia.tech/gofakes3"
+	"go.thebigfile.com/renterd/api"
+)
+
+const (
+	copySourceHeader      = "X-Amz-Copy-Source"
+	copySourceRangeHeader = "X-Amz-Copy-Source-Range"
+)
+
+// copyPartResult is the XML response of an UploadPartCopy request.
+type copyPartResult struct {
+	XMLName      xml.Name             `xml:"CopyPartResult"`
+	Xmlns        string               `xml:"xmlns,attr,omitempty"`
+	ETag         string               `xml:"ETag"`
+	LastModified gofakes3.ContentTime `xml:"LastModified"`
+}
+
+// isUploadPartCopyRequest returns true if the request uploads a part of a
+// multipart upload by copying the data of an existing object.
+func isUploadPartCopyRequest(rq *http.Request) bool {
+	query := rq.URL.Query()
+	return rq.Method == http.MethodPut &&
+		query.Has("uploadId") &&
+		query.Has("partNumber") &&
+		rq.Header.Get(copySourceHeader) != ""
+}
+
+// parseCopySource parses the bucket and object of the X-Amz-Copy-Source
+// header.
+func parseCopySource(source string) (bucket, object string, err error) {
+	source, query, _ := strings.Cut(source, "?")
+	if values, err := url.ParseQuery(query); err == nil && values.Has("versionId") {
+		return "", "", gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "copying parts of specific versions is not supported")
+	}
+	source, err = url.PathUnescape(source)
+	if err != nil {
+		return "", "", gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, "invalid copy source")
+	}
+	bucket, object, ok := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+	if !ok || bucket == "" || object == "" {
+		return "", "", gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, "invalid copy source")
+	}
+	return bucket, object, nil
+}
+
+// parseCopySourceRange parses the X-Amz-Copy-Source-Range header, unlike the
+// Range header it requires both the first and last byte to be set.
+func parseCopySourceRange(s string) (*api.DownloadRange, error) {
+	if s == "" {
+		return nil, nil
+	}
+	rng, ok := strings.CutPrefix(s, "bytes=")
+	if !ok {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, "copy source range must start with 'bytes='")
+	}
+	firstStr, lastStr, ok := strings.Cut(rng, "-")
+	if !ok {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, "invalid copy source range")
+	}
+	first, err := strconv.ParseInt(firstStr, 10, 64)
+	if err != nil || first < 0 {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, "invalid copy source range")
+	}
+	last, err := strconv.ParseInt(lastStr, 10, 64)
+	if err != nil || last < first {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, "invalid copy source range")
+	}
+	return &api.DownloadRange{Offset: first, Length: last - first + 1}, nil
+}
+
+// routeUploadPartCopy serves the UploadPartCopy operation.
+func (e *extensions) routeUploadPartCopy(bucket, object string, w http.ResponseWriter, rq *http.Request) error {
+	query := rq.URL.Query()
+	partNumber, err := strconv.Atoi(query.Get("partNumber"))
+	if err != nil || partNumber < 1 || partNumber > gofakes3.MaxUploadPartNumber {
+		return gofakes3.ErrInvalidPart
+	}
+	srcBucket, srcObject, err := parseCopySource(rq.Header.Get(copySourceHeader))
+	if err != nil {
+		return err
+	}
+	rng, err := parseCopySourceRange(rq.Header.Get(copySourceRangeHeader))
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.backend.UploadPartCopy(rq.Context(), srcBucket, srcObject, bucket, object, query.Get("uploadId"), partNumber, rng)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	e.writeXML(w, copyPartResult{
+		Xmlns:        "http://s3.amazonaws.com/doc/2006-03-01/",
+		ETag:         api.FormatETag(resp.ETag),
+		LastModified: gofakes3.NewContentTime(resp.LastModified.Std()),
+	})
+	return nil
+}
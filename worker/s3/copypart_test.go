@@ -0,0 +1,176 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.sia.tech/gofakes3"
+	"go.thebigfile.com/renterd/api"
+)
+
+type copyPartBus struct {
+	Bus
+
+	bucket      api.Bucket
+	contractSet string
+}
+
+func (b *copyPartBus) Bucket(_ context.Context, _ string) (api.Bucket, error) {
+	return b.bucket, nil
+}
+
+func (b *copyPartBus) Object(_ context.Context, _, _ string, _ api.GetObjectOptions) (api.ObjectsResponse, error) {
+	return api.ObjectsResponse{Object: &api.Object{ObjectMetadata: api.ObjectMetadata{Size: 50}}}, nil
+}
+
+func (b *copyPartBus) UploadParams(_ context.Context) (api.UploadParams, error) {
+	return api.UploadParams{ContractSet: "default"}, nil
+}
+
+func (b *copyPartBus) CopyMultipartPart(_ context.Context, _, _, _, _, contractSet, _ string, _ int, _ api.CopyMultipartPartOptions) (api.MultipartCopyPartResponse, error) {
+	b.contractSet = contractSet
+	return api.MultipartCopyPartResponse{}, nil
+}
+
+type quotaWorker struct {
+	Worker
+
+	err    error
+	size   int64
+	isPart bool
+}
+
+func (w *quotaWorker) CheckBucketQuota(_ context.Context, _ api.Bucket, size int64, isPart bool) error {
+	w.size, w.isPart = size, isPart
+	return w.err
+}
+
+func TestParseCopySource(t *testing.T) {
+	for _, test := range []struct {
+		source         string
+		bucket, object string
+		valid          bool
+	}{
+		{"/bucket/object", "bucket", "object", true},
+		{"bucket/dir/object", "bucket", "dir/object", true},
+		{"/bucket/my%20object", "bucket", "my object", true},
+		{"/bucket", "", "", false},
+		{"/bucket/", "", "", false},
+		{"/bucket/object?versionId=1", "", "", false},
+	} {
+		bucket, object, err := parseCopySource(test.source)
+		if test.valid && err != nil {
+			t.Fatalf("%v: unexpected error: %v", test.source, err)
+		} else if !test.valid && err == nil {
+			t.Fatalf("%v: expected error", test.source)
+		} else if bucket != test.bucket || object != test.object {
+			t.Fatalf("%v: unexpected bucket '%v' and object '%v'", test.source, bucket, object)
+		}
+	}
+}
+
+func TestParseCopySourceRange(t *testing.T) {
+	if rng, err := parseCopySourceRange(""); err != nil || rng != nil {
+		t.Fatal("unexpected", rng, err)
+	}
+	if rng, err := parseCopySourceRange("bytes=10-19"); err != nil {
+		t.Fatal(err)
+	} else if rng.Offset != 10 || rng.Length != 10 {
+		t.Fatal("unexpected range", rng)
+	}
+	for _, s := range []string{"10-19", "bytes=10-", "bytes=-10", "bytes=19-10", "bytes=a-b"} {
+		if _, err := parseCopySourceRange(s); err == nil {
+			t.Fatalf("%v: expected error", s)
+		}
+	}
+}
+
+func TestUploadPartCopyContractSet(t *testing.T) {
+	b := &copyPartBus{}
+	s := &s3{b: b}
+
+	copyPart := func() {
+		t.Helper()
+		if _, err := s.UploadPartCopy(context.Background(), "src", "object", "dst", "object", "upload", 1, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// without a bound contract set, the default set is used
+	copyPart()
+	if b.contractSet != "default" {
+		t.Fatal("unexpected contract set", b.contractSet)
+	}
+
+	// the destination bucket's contract set is used if it's bound to one
+	b.bucket.Policy.ContractSet = "bucket"
+	copyPart()
+	if b.contractSet != "bucket" {
+		t.Fatal("unexpected contract set", b.contractSet)
+	}
+}
+
+func TestUploadPartCopyQuota(t *testing.T) {
+	b := &copyPartBus{}
+	w := &quotaWorker{}
+	s := &s3{b: b, w: w}
+
+	// without a quota the worker isn't asked
+	w.size = -1
+	if _, err := s.UploadPartCopy(context.Background(), "src", "object", "dst", "object", "upload", 1, nil); err != nil {
+		t.Fatal(err)
+	} else if w.size != -1 {
+		t.Fatal("unexpected quota check")
+	}
+
+	// with a quota, the part is checked against the size of the source
+	// object or the copied range
+	b.bucket.Policy.Quota = api.BucketQuota{MaxSize: 100}
+	if _, err := s.UploadPartCopy(context.Background(), "src", "object", "dst", "object", "upload", 1, nil); err != nil {
+		t.Fatal(err)
+	} else if w.size != 50 || !w.isPart {
+		t.Fatal("unexpected quota check", w.size, w.isPart)
+	}
+	if _, err := s.UploadPartCopy(context.Background(), "src", "object", "dst", "object", "upload", 1, &api.DownloadRange{Offset: 10, Length: 10}); err != nil {
+		t.Fatal(err)
+	} else if w.size != 10 {
+		t.Fatal("unexpected quota check", w.size)
+	}
+
+	// parts that exceed the quota are rejected without being copied
+	b.contractSet = ""
+	w.err = api.ErrBucketQuotaExceeded
+	if _, err := s.UploadPartCopy(context.Background(), "src", "object", "dst", "object", "upload", 1, nil); err == nil {
+		t.Fatal("expected error")
+	} else if b.contractSet != "" {
+		t.Fatal("expected part not to be copied")
+	}
+}
+
+func TestUploadPartCopyPermissions(t *testing.T) {
+	b := &copyPartBus{}
+	ab := newAuthenticatedBackend(&s3{b: b})
+
+	perms := rootPerms
+	perms.policy = &api.S3AccessKeyPolicy{
+		Grants: []api.S3AccessGrant{
+			{Bucket: "src", Prefix: "read/", Actions: []string{api.S3ActionRead}},
+			{Bucket: "src", Prefix: "write/", Actions: []string{api.S3ActionWrite}},
+			{Bucket: "dst", Actions: []string{api.S3ActionWrite}},
+		},
+	}
+	ctx := context.WithValue(context.Background(), permissionKey, &perms)
+
+	// copying a part requires read access to the source
+	if _, err := ab.UploadPartCopy(ctx, "src", "read/object", "dst", "object", "upload", 1, nil); err != nil {
+		t.Fatal(err)
+	} else if _, err := ab.UploadPartCopy(ctx, "src", "write/object", "dst", "object", "upload", 1, nil); !errors.Is(err, gofakes3.ErrAccessDenied) {
+		t.Fatal("unexpected error", err)
+	}
+
+	// and write access to the destination
+	if _, err := ab.UploadPartCopy(ctx, "src", "read/object", "src", "read/object", "upload", 1, nil); !errors.Is(err, gofakes3.ErrAccessDenied) {
+		t.Fatal("unexpected error", err)
+	}
+}
@@ -0,0 +1,186 @@
+package s3
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.sia.tech/gofakes3"
+	"go.thebigfile.com/renterd/api"
+)
+
+const (
+	// maxObjectLockBodySize is the maximum size of a PutObjectRetention or
+	// PutObjectLegalHold request body.
+	maxObjectLockBodySize = 1 << 12 // 4 KiB
+
+	bypassGovernanceRetentionHeader = "X-Amz-Bypass-Governance-Retention"
+
+	amazonObjectLockModeHeader            = "X-Amz-Object-Lock-Mode"
+	amazonObjectLockRetainUntilDateHeader = "X-Amz-Object-Lock-Retain-Until-Date"
+	amazonObjectLockLegalHoldHeader       = "X-Amz-Object-Lock-Legal-Hold"
+
+	legalHoldStatusOn  = "ON"
+	legalHoldStatusOff = "OFF"
+)
+
+type (
+	// retention is the XML representation of an object's retention.
+	retention struct {
+		XMLName         xml.Name `xml:"Retention"`
+		Xmlns           string   `xml:"xmlns,attr,omitempty"`
+		Mode            string   `xml:"Mode,omitempty"`
+		RetainUntilDate string   `xml:"RetainUntilDate,omitempty"`
+	}
+
+	// legalHold is the XML representation of an object's legal hold.
+	legalHold struct {
+		XMLName xml.Name `xml:"LegalHold"`
+		Xmlns   string   `xml:"xmlns,attr,omitempty"`
+		Status  string   `xml:"Status"`
+	}
+)
+
+func newRetention(r api.ObjectRetention) retention {
+	res := retention{
+		Xmlns: "http://s3.amazonaws.com/doc/2006-03-01/",
+		Mode:  r.Mode,
+	}
+	if !r.RetainUntil.IsZero() {
+		res.RetainUntilDate = r.RetainUntil.Std().UTC().Format(time.RFC3339)
+	}
+	return res
+}
+
+func (r retention) objectRetention() (api.ObjectRetention, error) {
+	var res api.ObjectRetention
+	res.Mode = r.Mode
+	if r.RetainUntilDate != "" {
+		t, err := time.Parse(time.RFC3339, r.RetainUntilDate)
+		if err != nil {
+			return api.ObjectRetention{}, gofakes3.ErrorMessagef(gofakes3.ErrInvalidArgument, "invalid retain until date '%s'", r.RetainUntilDate)
+		}
+		res.RetainUntil = api.TimeRFC3339(t)
+	}
+	if err := res.Validate(); err != nil {
+		return api.ObjectRetention{}, gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, err.Error())
+	}
+	return res, nil
+}
+
+// objectLockFromMetadata parses the retention and legal hold an object is
+// uploaded with from the object lock headers of a PutObject request.
+func objectLockFromMetadata(meta map[string]string) (lock api.ObjectLock, _ error) {
+	r := retention{
+		Mode:            meta[amazonObjectLockModeHeader],
+		RetainUntilDate: meta[amazonObjectLockRetainUntilDateHeader],
+	}
+	ret, err := r.objectRetention()
+	if err != nil {
+		return api.ObjectLock{}, err
+	}
+	lock.Retention = ret
+
+	switch status := meta[amazonObjectLockLegalHoldHeader]; status {
+	case "", legalHoldStatusOff:
+	case legalHoldStatusOn:
+		lock.LegalHold = true
+	default:
+		return api.ObjectLock{}, gofakes3.ErrorMessagef(gofakes3.ErrInvalidArgument, "invalid legal hold status '%s'", status)
+	}
+	return lock, nil
+}
+
+// routeRetention serves the GetObjectRetention and PutObjectRetention
+// operations.
+func (e *extensions) routeRetention(bucket, object string, w http.ResponseWriter, rq *http.Request) error {
+	if object == "" {
+		return gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "bucket object lock configuration is not supported")
+	} else if rq.URL.Query().Get("versionId") != "" {
+		return gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "retention of specific versions is not supported")
+	}
+
+	switch rq.Method {
+	case http.MethodGet:
+		r, err := e.backend.GetObjectRetention(rq.Context(), bucket, object)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		e.writeXML(w, newRetention(r))
+		return nil
+
+	case http.MethodPut:
+		body, err := io.ReadAll(io.LimitReader(rq.Body, maxObjectLockBodySize))
+		if err != nil {
+			return gofakes3.ErrIncompleteBody
+		}
+		var r retention
+		if err := xml.Unmarshal(body, &r); err != nil {
+			return gofakes3.ErrorMessage(gofakes3.ErrMalformedXML, err.Error())
+		}
+		update, err := r.objectRetention()
+		if err != nil {
+			return err
+		}
+		bypass := strings.EqualFold(rq.Header.Get(bypassGovernanceRetentionHeader), "true")
+		if err := e.backend.PutObjectRetention(rq.Context(), bucket, object, update, bypass); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		return nil
+
+	default:
+		return gofakes3.ErrMethodNotAllowed
+	}
+}
+
+// routeLegalHold serves the GetObjectLegalHold and PutObjectLegalHold
+// operations.
+func (e *extensions) routeLegalHold(bucket, object string, w http.ResponseWriter, rq *http.Request) error {
+	if object == "" {
+		return gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "bucket legal holds are not supported")
+	} else if rq.URL.Query().Get("versionId") != "" {
+		return gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "legal holds of specific versions are not supported")
+	}
+
+	switch rq.Method {
+	case http.MethodGet:
+		enabled, err := e.backend.GetObjectLegalHold(rq.Context(), bucket, object)
+		if err != nil {
+			return err
+		}
+		lh := legalHold{
+			Xmlns:  "http://s3.amazonaws.com/doc/2006-03-01/",
+			Status: legalHoldStatusOff,
+		}
+		if enabled {
+			lh.Status = legalHoldStatusOn
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		e.writeXML(w, lh)
+		return nil
+
+	case http.MethodPut:
+		body, err := io.ReadAll(io.LimitReader(rq.Body, maxObjectLockBodySize))
+		if err != nil {
+			return gofakes3.ErrIncompleteBody
+		}
+		var lh legalHold
+		if err := xml.Unmarshal(body, &lh); err != nil {
+			return gofakes3.ErrorMessage(gofakes3.ErrMalformedXML, err.Error())
+		} else if lh.Status != legalHoldStatusOn && lh.Status != legalHoldStatusOff {
+			return gofakes3.ErrorMessagef(gofakes3.ErrInvalidArgument, "invalid legal hold status '%s'", lh.Status)
+		}
+		if err := e.backend.PutObjectLegalHold(rq.Context(), bucket, object, lh.Status == legalHoldStatusOn); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		return nil
+
+	default:
+		return gofakes3.ErrMethodNotAllowed
+	}
+}
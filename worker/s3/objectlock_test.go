@@ -0,0 +1,69 @@
+package s3
+
+import (
+	"testing"
+	"time"
+
+	"go.thebigfile.com/renterd/api"
+)
+
+func TestObjectLockFromMetadata(t *testing.T) {
+	until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	// no headers means no lock
+	if lock, err := objectLockFromMetadata(map[string]string{}); err != nil {
+		t.Fatal(err)
+	} else if lock.Locked(time.Now()) {
+		t.Fatal("unexpected lock", lock)
+	}
+
+	// retention and legal hold are parsed
+	lock, err := objectLockFromMetadata(map[string]string{
+		amazonObjectLockModeHeader:            api.ObjectLockModeCompliance,
+		amazonObjectLockRetainUntilDateHeader: until.Format(time.RFC3339),
+		amazonObjectLockLegalHoldHeader:       legalHoldStatusOn,
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if lock.Retention.Mode != api.ObjectLockModeCompliance || !lock.Retention.RetainUntil.Std().Equal(until) || !lock.LegalHold {
+		t.Fatal("unexpected lock", lock)
+	}
+
+	// invalid headers are rejected
+	for _, meta := range []map[string]string{
+		{amazonObjectLockModeHeader: api.ObjectLockModeGovernance},
+		{amazonObjectLockRetainUntilDateHeader: until.Format(time.RFC3339)},
+		{amazonObjectLockModeHeader: "foo", amazonObjectLockRetainUntilDateHeader: until.Format(time.RFC3339)},
+		{amazonObjectLockModeHeader: api.ObjectLockModeGovernance, amazonObjectLockRetainUntilDateHeader: "tomorrow"},
+		{amazonObjectLockLegalHoldHeader: "true"},
+	} {
+		if _, err := objectLockFromMetadata(meta); err == nil {
+			t.Fatal("expected error", meta)
+		}
+	}
+}
+
+func TestBypassGovernancePermission(t *testing.T) {
+	policy := api.S3AccessKeyPolicy{
+		Grants: []api.S3AccessGrant{
+			{Bucket: "bucket", Prefix: "locked/", Actions: []string{api.S3ActionWrite}},
+			{Bucket: "bucket", Prefix: "admin/", Actions: []string{api.S3ActionWrite, api.S3ActionBypassGovernance}},
+		},
+	}
+
+	// write access allows updating the retention but not bypassing the
+	// governance mode
+	if perms := scopedPerms(policy, "bucket", "locked/foo"); !perms.PutObjectRetention || perms.BypassGovernanceRetention {
+		t.Fatal("unexpected permissions", perms)
+	}
+
+	// bypassing requires an explicit grant
+	if perms := scopedPerms(policy, "bucket", "admin/foo"); !perms.PutObjectRetention || !perms.BypassGovernanceRetention {
+		t.Fatal("unexpected permissions", perms)
+	}
+
+	// keys without a policy have full access
+	if !rootPerms.BypassGovernanceRetention {
+		t.Fatal("expected root permissions to allow bypassing governance")
+	}
+}
@@ -0,0 +1,200 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.sia.tech/gofakes3"
+	"go.sia.tech/gofakes3/signature"
+)
+
+// ref: https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html
+
+const (
+	presignAlgorithm     = "AWS4-HMAC-SHA256"
+	presignDateFormat    = "20060102T150405Z"
+	presignScopeFormat   = "20060102"
+	presignUnsignedBody  = "UNSIGNED-PAYLOAD"
+	presignMaxExpiry     = 7 * 24 * time.Hour
+	presignMaxClockDrift = 15 * time.Minute
+
+	queryAlgorithm     = "X-Amz-Algorithm"
+	queryContentSHA256 = "X-Amz-Content-Sha256"
+	queryCredential    = "X-Amz-Credential"
+	queryDate          = "X-Amz-Date"
+	queryExpires       = "X-Amz-Expires"
+	querySignature     = "X-Amz-Signature"
+	querySignedHeaders = "X-Amz-SignedHeaders"
+)
+
+// isPresignedV4 returns true if the request carries a query-string V4
+// signature.
+func isPresignedV4(rq *http.Request) bool {
+	return rq.URL.Query().Get(queryAlgorithm) != ""
+}
+
+// verifyPresignedV4 verifies the query-string V4 signature of a presigned
+// request using the given access key pairs. On success it returns the access
+// key the request was signed with.
+func verifyPresignedV4(rq *http.Request, keypairs map[string]string, now time.Time) (string, *signature.APIError) {
+	query := rq.URL.Query()
+	if query.Get(queryAlgorithm) != presignAlgorithm {
+		return "", presignError("UnsupportedAlgorithm", "Encountered an unsupported algorithm.", http.StatusBadRequest)
+	}
+
+	// parse the credential, it has the form
+	// <access-key>/<date>/<region>/s3/aws4_request
+	cred := strings.Split(query.Get(queryCredential), "/")
+	if len(cred) < 5 {
+		return "", presignError("AuthorizationQueryParametersError", "X-Amz-Credential is malformed", http.StatusBadRequest)
+	}
+	accessKeyID := strings.Join(cred[:len(cred)-4], "/")
+	scope := cred[len(cred)-4:]
+	if scope[2] != "s3" || scope[3] != "aws4_request" {
+		return "", presignError("AuthorizationQueryParametersError", "X-Amz-Credential has an invalid scope", http.StatusBadRequest)
+	}
+	secretKey, ok := keypairs[accessKeyID]
+	if !ok {
+		return "", presignError("InvalidAccessKeyId", "The Access Key Id you provided does not exist in our records.", http.StatusForbidden)
+	}
+
+	// parse the date and expiry
+	date, err := time.Parse(presignDateFormat, query.Get(queryDate))
+	if err != nil || date.Format(presignScopeFormat) != scope[0] {
+		return "", presignError("AuthorizationQueryParametersError", "X-Amz-Date is malformed", http.StatusBadRequest)
+	}
+	expires, err := strconv.ParseInt(query.Get(queryExpires), 10, 64)
+	if err != nil || expires < 1 {
+		return "", presignError("AuthorizationQueryParametersError", "X-Amz-Expires must be a positive integer", http.StatusBadRequest)
+	} else if time.Duration(expires)*time.Second > presignMaxExpiry {
+		return "", presignError("AuthorizationQueryParametersError", fmt.Sprintf("X-Amz-Expires must be less than %v seconds", presignMaxExpiry.Seconds()), http.StatusBadRequest)
+	} else if date.After(now.Add(presignMaxClockDrift)) {
+		return "", presignError(string(gofakes3.ErrAccessDenied), "Request is not valid yet", http.StatusForbidden)
+	} else if now.After(date.Add(time.Duration(expires) * time.Second)) {
+		return "", presignError(string(gofakes3.ErrAccessDenied), "Request has expired", http.StatusForbidden)
+	}
+
+	// build the canonical headers, host is mandatory
+	signedHeaders := strings.Split(query.Get(querySignedHeaders), ";")
+	sort.Strings(signedHeaders)
+	var hasHost bool
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		var values []string
+		switch h {
+		case "host":
+			hasHost = true
+			values = []string{rq.Host}
+		default:
+			values = rq.Header.Values(h)
+			if len(values) == 0 {
+				return "", presignError(string(gofakes3.ErrAccessDenied), fmt.Sprintf("signed header '%s' is missing", h), http.StatusBadRequest)
+			}
+		}
+		for i := range values {
+			values[i] = strings.Join(strings.Fields(values[i]), " ")
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.Join(values, ",") + "\n")
+	}
+	if !hasHost {
+		return "", presignError(string(gofakes3.ErrAccessDenied), "host header must be signed", http.StatusBadRequest)
+	}
+
+	// the payload of presigned requests is usually not signed
+	payload := presignUnsignedBody
+	if v := query.Get(queryContentSHA256); v != "" {
+		payload = v
+	}
+
+	canonicalRequest := strings.Join([]string{
+		rq.Method,
+		uriEncode(rq.URL.Path, false),
+		canonicalQueryString(query),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payload,
+	}, "\n")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		presignAlgorithm,
+		query.Get(queryDate),
+		strings.Join(scope, "/"),
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	// derive the signing key and compare the signatures
+	key := hmacSHA256([]byte("AWS4"+secretKey), []byte(scope[0]))
+	key = hmacSHA256(key, []byte(scope[1]))
+	key = hmacSHA256(key, []byte(scope[2]))
+	key = hmacSHA256(key, []byte(scope[3]))
+	expected := hex.EncodeToString(hmacSHA256(key, []byte(stringToSign)))
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(query.Get(querySignature))) != 1 {
+		return "", presignError("SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided. Check your key and signing method.", http.StatusForbidden)
+	}
+	return accessKeyID, nil
+}
+
+// canonicalQueryString returns the sorted and encoded query string of a
+// presigned request, excluding the signature itself.
+func canonicalQueryString(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		if k != querySignature {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			params = append(params, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func presignError(code, description string, status int) *signature.APIError {
+	return &signature.APIError{
+		Code:           code,
+		Description:    description,
+		HTTPStatusCode: status,
+	}
+}
+
+// uriEncode encodes the given string as described by the AWS V4 signature
+// spec, only unreserved characters are left as-is.
+func uriEncode(s string, encodeSlash bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9':
+			sb.WriteByte(c)
+		case c == '-', c == '_', c == '.', c == '~':
+			sb.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
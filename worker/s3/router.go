@@ -0,0 +1,186 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go.sia.tech/gofakes3"
+	"go.thebigfile.com/renterd/api"
+	"go.uber.org/zap"
+)
+
+type (
+	// extensionBackend is implemented by the backends that support S3
+	// features gofakes3 doesn't provide routes for.
+	extensionBackend interface {
+		GetObjectTagging(ctx context.Context, bucketName, objectName string) (api.ObjectTags, error)
+		PutObjectTagging(ctx context.Context, bucketName, objectName string, tags api.ObjectTags) error
+		DeleteObjectTagging(ctx context.Context, bucketName, objectName string) error
+
+		GetObjectRetention(ctx context.Context, bucketName, objectName string) (api.ObjectRetention, error)
+		PutObjectRetention(ctx context.Context, bucketName, objectName string, retention api.ObjectRetention, bypassGovernance bool) error
+		GetObjectLegalHold(ctx context.Context, bucketName, objectName string) (bool, error)
+		PutObjectLegalHold(ctx context.Context, bucketName, objectName string, enabled bool) error
+
+		UploadPartCopy(ctx context.Context, srcBucket, srcObject, bucket, object, uploadID string, partNumber int, rng *api.DownloadRange) (api.MultipartCopyPartResponse, error)
+	}
+
+	// router serves the requests for S3 features that gofakes3 doesn't
+	// support and passes all other requests on to gofakes3.
+	router struct {
+		faker      http.Handler
+		extensions http.Handler
+		errors     *extensions
+
+		allowInsecureSSEC bool
+	}
+
+	// extensions handles the requests for S3 features that gofakes3 doesn't
+	// support. It runs after the authentication middleware, so the backend
+	// can check the permissions of the request.
+	extensions struct {
+		backend extensionBackend
+		logger  *zap.SugaredLogger
+
+		hostBucketEnabled bool
+		hostBucketBases   []string
+	}
+)
+
+func newRouter(faker http.Handler, backend extensionBackend, authMiddleware func(http.Handler) http.Handler, logger *zap.SugaredLogger, opts Opts) *router {
+	e := &extensions{
+		backend: backend,
+		logger:  logger,
+
+		hostBucketEnabled: opts.HostBucketEnabled,
+		hostBucketBases:   opts.HostBucketBases,
+	}
+	var ext http.Handler = e
+	if authMiddleware != nil {
+		ext = authMiddleware(ext)
+	}
+	return &router{
+		faker:      faker,
+		extensions: ext,
+		errors:     e,
+
+		allowInsecureSSEC: opts.AllowInsecureSSEC,
+	}
+}
+
+func (r *router) ServeHTTP(w http.ResponseWriter, rq *http.Request) {
+	// the backend doesn't have access to the request headers, so the SSE-C
+	// keys are passed on through the request's context
+	keys, err := parseSSECustomerKeys(rq.Header)
+	if err != nil {
+		r.errors.writeError(w, rq, err)
+		return
+	}
+
+	// customer keys are sent in plain text, so like S3 they are only accepted
+	// over TLS unless the server sits behind a proxy that terminates TLS
+	if (keys.key != nil || keys.copySource != nil) && rq.TLS == nil && !r.allowInsecureSSEC {
+		r.errors.writeError(w, rq, errInsecureSSECustomerKey)
+		return
+	}
+	rq = rq.WithContext(withSSECustomerKeys(rq.Context(), keys))
+
+	if isExtensionRequest(rq) {
+		r.extensions.ServeHTTP(w, rq)
+		return
+	}
+	r.faker.ServeHTTP(w, rq)
+}
+
+// isExtensionRequest returns true if the request targets a feature that is
+// served by renterd instead of gofakes3.
+func isExtensionRequest(rq *http.Request) bool {
+	query := rq.URL.Query()
+	return query.Has("tagging") ||
+		query.Has("retention") ||
+		query.Has("legal-hold") ||
+		isUploadPartCopyRequest(rq)
+}
+
+func (e *extensions) ServeHTTP(w http.ResponseWriter, rq *http.Request) {
+	bucket, object := e.bucketAndObject(rq)
+
+	var err error
+	query := rq.URL.Query()
+	if query.Has("tagging") {
+		err = e.routeTagging(bucket, object, w, rq)
+	} else if query.Has("retention") {
+		err = e.routeRetention(bucket, object, w, rq)
+	} else if query.Has("legal-hold") {
+		err = e.routeLegalHold(bucket, object, w, rq)
+	} else if isUploadPartCopyRequest(rq) {
+		err = e.routeUploadPartCopy(bucket, object, w, rq)
+	} else {
+		err = gofakes3.ErrNotImplemented
+	}
+	if err != nil {
+		e.writeError(w, rq, err)
+	}
+}
+
+// bucketAndObject extracts the bucket and object name from the request, the
+// same way gofakes3 does for path-style and virtual-host-style requests.
+func (e *extensions) bucketAndObject(rq *http.Request) (bucket, object string) {
+	path := rq.URL.Path
+	if bucket, ok := e.hostBucket(rq.Host); ok {
+		path = "/" + bucket + path
+	}
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+	bucket = parts[0]
+	if len(parts) == 2 {
+		object = parts[1]
+	}
+	return
+}
+
+func (e *extensions) hostBucket(host string) (string, bool) {
+	if len(e.hostBucketBases) > 0 {
+		for _, base := range e.hostBucketBases {
+			base = "." + strings.Trim(base, ".")
+			if !strings.HasSuffix(host, base) {
+				continue
+			} else if bucket := host[:len(host)-len(base)]; !strings.Contains(bucket, ".") {
+				return bucket, true
+			}
+		}
+		return "", false
+	} else if e.hostBucketEnabled {
+		return strings.SplitN(host, ".", 2)[0], true
+	}
+	return "", false
+}
+
+func (e *extensions) writeError(w http.ResponseWriter, rq *http.Request, err error) {
+	var resp gofakes3.Error
+	if code, ok := err.(gofakes3.ErrorCode); ok {
+		resp = &gofakes3.ErrorResponse{Code: code, Message: code.Message()}
+	} else if !errors.As(err, &resp) {
+		e.logger.Error(err)
+		resp = &gofakes3.ErrorResponse{Code: gofakes3.ErrInternal, Message: "Internal Error"}
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(resp.ErrorCode().Status())
+	if rq.Method != http.MethodHead {
+		e.writeXML(w, resp)
+	}
+}
+
+func (e *extensions) writeXML(w http.ResponseWriter, v any) {
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return
+	}
+	xe := xml.NewEncoder(w)
+	xe.Indent("", "  ")
+	if err := xe.Encode(v); err != nil {
+		e.logger.Error(err)
+	}
+}
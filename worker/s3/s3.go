@@ -22,6 +22,10 @@ type Opts struct {
 	AuthDisabled      bool
 	HostBucketEnabled bool
 	HostBucketBases   []string
+
+	// AllowInsecureSSEC allows SSE-C requests that weren't made over TLS,
+	// which is necessary if TLS is terminated by a reverse proxy.
+	AllowInsecureSSEC bool
 }
 
 type Bus interface {
@@ -29,15 +33,27 @@ type Bus interface {
 	CreateBucket(ctx context.Context, bucketName string, opts api.CreateBucketOptions) error
 	DeleteBucket(ctx context.Context, bucketName string) error
 	ListBuckets(ctx context.Context) (buckets []api.Bucket, err error)
+	UpdateBucketPolicy(ctx context.Context, bucketName string, policy api.BucketPolicy) (err error)
 
 	AddObject(ctx context.Context, bucket, path, contractSet string, o object.Object, opts api.AddObjectOptions) (err error)
 	CopyObject(ctx context.Context, srcBucket, dstBucket, srcPath, dstPath string, opts api.CopyObjectOptions) (om api.ObjectMetadata, err error)
-	DeleteObject(ctx context.Context, bucket, path string, opts api.DeleteObjectOptions) (err error)
+	DeleteObject(ctx context.Context, bucket, path string, opts api.DeleteObjectOptions) (resp api.DeleteObjectResponse, err error)
 	ListObjects(ctx context.Context, bucket string, opts api.ListObjectOptions) (resp api.ObjectsListResponse, err error)
 	Object(ctx context.Context, bucket, path string, opts api.GetObjectOptions) (res api.ObjectsResponse, err error)
+	ObjectVersions(ctx context.Context, bucket string, opts api.ListObjectVersionsOptions) (resp api.ObjectVersionsResponse, err error)
+
+	DeleteObjectTags(ctx context.Context, bucket, path string) (err error)
+	ObjectTags(ctx context.Context, bucket, path string) (tags api.ObjectTags, err error)
+	UpdateObjectTags(ctx context.Context, bucket, path string, tags api.ObjectTags) (err error)
+
+	ObjectLegalHold(ctx context.Context, bucket, path string) (lh api.ObjectLegalHold, err error)
+	ObjectRetention(ctx context.Context, bucket, path string) (retention api.ObjectRetention, err error)
+	UpdateObjectLegalHold(ctx context.Context, bucket, path string, enabled bool) (err error)
+	UpdateObjectRetention(ctx context.Context, bucket, path string, retention api.ObjectRetention, bypassGovernance bool) (err error)
 
 	AbortMultipartUpload(ctx context.Context, bucket, path string, uploadID string) (err error)
 	CompleteMultipartUpload(ctx context.Context, bucket, path, uploadID string, parts []api.MultipartCompletedPart, opts api.CompleteMultipartOptions) (_ api.MultipartCompleteResponse, err error)
+	CopyMultipartPart(ctx context.Context, srcBucket, srcPath, bucket, path, contractSet, uploadID string, partNumber int, opts api.CopyMultipartPartOptions) (resp api.MultipartCopyPartResponse, err error)
 	CreateMultipartUpload(ctx context.Context, bucket, path string, opts api.CreateMultipartOptions) (api.MultipartCreateResponse, error)
 	MultipartUploads(ctx context.Context, bucket, prefix, keyMarker, uploadIDMarker string, maxUploads int) (resp api.MultipartListUploadsResponse, _ error)
 	MultipartUploadParts(ctx context.Context, bucket, object string, uploadID string, marker int, limit int64) (resp api.MultipartListPartsResponse, _ error)
@@ -48,6 +64,7 @@ type Bus interface {
 }
 
 type Worker interface {
+	CheckBucketQuota(ctx context.Context, b api.Bucket, size int64, isPart bool) error
 	GetObject(ctx context.Context, bucket, path string, opts api.DownloadObjectOptions) (*api.GetObjectResponse, error)
 	HeadObject(ctx context.Context, bucket, path string, opts api.HeadObjectOptions) (*api.HeadObjectResponse, error)
 	UploadObject(ctx context.Context, r io.Reader, bucket, path string, opts api.UploadObjectOptions) (*api.UploadObjectResponse, error)
@@ -77,8 +94,12 @@ func New(b Bus, w Worker, logger *zap.Logger, opts Opts) (http.Handler, error) {
 		logger: logger.Sugar(),
 	}
 	backend := gofakes3.Backend(s3Backend)
+	extBackend := extensionBackend(s3Backend)
+	var authMiddleware func(http.Handler) http.Handler
 	if !opts.AuthDisabled {
-		backend = newAuthenticatedBackend(s3Backend)
+		authBackend := newAuthenticatedBackend(s3Backend)
+		backend, extBackend = authBackend, authBackend
+		authMiddleware = authBackend.AuthenticationMiddleware
 	}
 	faker, err := gofakes3.New(
 		backend,
@@ -86,12 +107,11 @@ func New(b Bus, w Worker, logger *zap.Logger, opts Opts) (http.Handler, error) {
 		gofakes3.WithHostBucketBase(opts.HostBucketBases...),
 		gofakes3.WithLogger(&gofakes3Logger{l: logger.Sugar()}),
 		gofakes3.WithRequestID(rand.Uint64()),
-		gofakes3.WithoutVersioning(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create s3 server: %w", err)
 	}
-	return faker.Server(), nil
+	return newRouter(faker.Server(), extBackend, authMiddleware, logger.Sugar(), opts), nil
 }
 
 // Parsev4AuthKeys parses a list of accessKey-secretKey pairs and returns a map
@@ -0,0 +1,106 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"net/http"
+
+	"go.sia.tech/gofakes3"
+	"go.thebigfile.com/renterd/object"
+)
+
+const (
+	sseCustomerAlgorithmHeader = "X-Amz-Server-Side-Encryption-Customer-Algorithm"
+	sseCustomerKeyHeader       = "X-Amz-Server-Side-Encryption-Customer-Key"
+	sseCustomerKeyMD5Header    = "X-Amz-Server-Side-Encryption-Customer-Key-Md5"
+
+	copySourceSSECustomerAlgorithmHeader = "X-Amz-Copy-Source-Server-Side-Encryption-Customer-Algorithm"
+	copySourceSSECustomerKeyHeader       = "X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key"
+	copySourceSSECustomerKeyMD5Header    = "X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key-Md5"
+
+	// sseCustomerAlgorithmAES256 is the only algorithm S3 clients accept,
+	// renterd doesn't use AES but mixes the customer key into the object's
+	// encryption key instead.
+	sseCustomerAlgorithmAES256 = "AES256"
+)
+
+// errInsecureSSECustomerKey is returned for requests that provide a customer
+// key over a connection that isn't secured by TLS.
+var errInsecureSSECustomerKey = gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, "requests specifying server side encryption with customer provided keys must be made over a secure connection")
+
+type (
+	sseCustomerKeysCtxKey struct{}
+
+	// sseCustomerKeys contains the customer keys that were provided with a
+	// request using the SSE-C headers.
+	sseCustomerKeys struct {
+		key        *object.CustomerKey
+		copySource *object.CustomerKey
+		isCopy     bool
+	}
+)
+
+// parseSSECustomerKeys parses the SSE-C headers of a request.
+func parseSSECustomerKeys(h http.Header) (keys sseCustomerKeys, err error) {
+	keys.key, err = parseSSECustomerKey(h, sseCustomerAlgorithmHeader, sseCustomerKeyHeader, sseCustomerKeyMD5Header)
+	if err != nil {
+		return sseCustomerKeys{}, err
+	}
+	keys.copySource, err = parseSSECustomerKey(h, copySourceSSECustomerAlgorithmHeader, copySourceSSECustomerKeyHeader, copySourceSSECustomerKeyMD5Header)
+	if err != nil {
+		return sseCustomerKeys{}, err
+	}
+	keys.isCopy = h.Get(copySourceHeader) != ""
+	return keys, nil
+}
+
+func parseSSECustomerKey(h http.Header, algorithmHeader, keyHeader, md5Header string) (*object.CustomerKey, error) {
+	algorithm, key, keyMD5 := h.Get(algorithmHeader), h.Get(keyHeader), h.Get(md5Header)
+	if algorithm == "" && key == "" && keyMD5 == "" {
+		return nil, nil
+	} else if algorithm != sseCustomerAlgorithmAES256 {
+		return nil, gofakes3.ErrorMessagef(gofakes3.ErrInvalidArgument, "invalid encryption algorithm '%s', only %s is supported", algorithm, sseCustomerAlgorithmAES256)
+	}
+
+	ck, err := object.ParseCustomerKey(key)
+	if err != nil {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, err.Error())
+	} else if keyMD5 != sseCustomerKeyMD5(ck) {
+		return nil, gofakes3.ErrorMessage(gofakes3.ErrInvalidArgument, "the calculated MD5 hash of the key did not match the hash that was provided")
+	}
+	return &ck, nil
+}
+
+func sseCustomerKeyMD5(ck object.CustomerKey) string {
+	h := md5.Sum(ck[:])
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// setSSECustomerKeyMetadata adds the SSE-C response headers to the metadata.
+func setSSECustomerKeyMetadata(metadata map[string]string, ck *object.CustomerKey) {
+	if ck == nil {
+		return
+	}
+	metadata[sseCustomerAlgorithmHeader] = sseCustomerAlgorithmAES256
+	metadata[sseCustomerKeyMD5Header] = sseCustomerKeyMD5(*ck)
+}
+
+func withSSECustomerKeys(ctx context.Context, keys sseCustomerKeys) context.Context {
+	return context.WithValue(ctx, sseCustomerKeysCtxKey{}, keys)
+}
+
+func sseCustomerKeysFromContext(ctx context.Context) sseCustomerKeys {
+	keys, _ := ctx.Value(sseCustomerKeysCtxKey{}).(sseCustomerKeys)
+	return keys
+}
+
+// sourceKey returns the key that is used to read an object, gofakes3 fetches
+// the source of a copy using HeadObject so the copy source key is used for
+// copy requests.
+func (keys sseCustomerKeys) sourceKey() *object.CustomerKey {
+	if keys.isCopy {
+		return keys.copySource
+	}
+	return keys.key
+}
@@ -0,0 +1,102 @@
+package s3
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.thebigfile.com/renterd/object"
+	"go.uber.org/zap"
+	"lukechampine.com/frand"
+)
+
+func TestParseSSECustomerKeys(t *testing.T) {
+	ck := object.CustomerKey(frand.Entropy256())
+	setHeaders := func(h http.Header, algorithm, key, keyMD5 string) {
+		h.Set(sseCustomerAlgorithmHeader, algorithm)
+		h.Set(sseCustomerKeyHeader, key)
+		h.Set(sseCustomerKeyMD5Header, keyMD5)
+	}
+
+	// assert a request without SSE-C headers has no keys
+	if keys, err := parseSSECustomerKeys(http.Header{}); err != nil {
+		t.Fatal(err)
+	} else if keys.key != nil || keys.copySource != nil || keys.isCopy {
+		t.Fatal("unexpected keys", keys)
+	}
+
+	// assert a valid key is parsed
+	h := make(http.Header)
+	setHeaders(h, sseCustomerAlgorithmAES256, ck.String(), sseCustomerKeyMD5(ck))
+	if keys, err := parseSSECustomerKeys(h); err != nil {
+		t.Fatal(err)
+	} else if keys.key == nil || *keys.key != ck {
+		t.Fatal("unexpected key", keys.key)
+	} else if keys.sourceKey() != keys.key {
+		t.Fatal("expected source key to be the key")
+	}
+
+	// assert the copy source key is used as source key for copies
+	h.Set("X-Amz-Copy-Source", "/bucket/object")
+	h.Set(copySourceSSECustomerAlgorithmHeader, sseCustomerAlgorithmAES256)
+	h.Set(copySourceSSECustomerKeyHeader, ck.String())
+	h.Set(copySourceSSECustomerKeyMD5Header, sseCustomerKeyMD5(ck))
+	if keys, err := parseSSECustomerKeys(h); err != nil {
+		t.Fatal(err)
+	} else if !keys.isCopy || keys.sourceKey() != keys.copySource || *keys.copySource != ck {
+		t.Fatal("unexpected keys", keys)
+	}
+
+	// assert invalid headers are rejected
+	other := object.CustomerKey(frand.Entropy256())
+	for _, tc := range [][3]string{
+		{"", ck.String(), sseCustomerKeyMD5(ck)},                            // missing algorithm
+		{"aws:kms", ck.String(), sseCustomerKeyMD5(ck)},                     // wrong algorithm
+		{sseCustomerAlgorithmAES256, "", sseCustomerKeyMD5(ck)},             // missing key
+		{sseCustomerAlgorithmAES256, "Zm9v", "rL0Y20zC+Fzt72VPzMSk2A=="},    // short key
+		{sseCustomerAlgorithmAES256, ck.String(), ""},                       // missing md5
+		{sseCustomerAlgorithmAES256, ck.String(), sseCustomerKeyMD5(other)}, // wrong md5
+	} {
+		h := make(http.Header)
+		setHeaders(h, tc[0], tc[1], tc[2])
+		if _, err := parseSSECustomerKeys(h); err == nil {
+			t.Fatal("expected error", tc)
+		}
+	}
+}
+
+func TestSSECustomerKeyRequiresTLS(t *testing.T) {
+	ck := object.CustomerKey(frand.Entropy256())
+	faker := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(r *router, secure bool) int {
+		t.Helper()
+		rq := httptest.NewRequest(http.MethodGet, "/bucket/object", nil)
+		rq.Header.Set(sseCustomerAlgorithmHeader, sseCustomerAlgorithmAES256)
+		rq.Header.Set(sseCustomerKeyHeader, ck.String())
+		rq.Header.Set(sseCustomerKeyMD5Header, sseCustomerKeyMD5(ck))
+		if secure {
+			rq.TLS = &tls.ConnectionState{}
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, rq)
+		return rec.Code
+	}
+
+	// assert customer keys are only accepted over TLS by default
+	r := newRouter(faker, nil, nil, zap.NewNop().Sugar(), Opts{})
+	if code := serve(r, false); code != http.StatusBadRequest {
+		t.Fatal("unexpected status", code)
+	} else if code := serve(r, true); code != http.StatusOK {
+		t.Fatal("unexpected status", code)
+	}
+
+	// assert insecure requests are accepted if explicitly allowed
+	r = newRouter(faker, nil, nil, zap.NewNop().Sugar(), Opts{AllowInsecureSSEC: true})
+	if code := serve(r, false); code != http.StatusOK {
+		t.Fatal("unexpected status", code)
+	}
+}
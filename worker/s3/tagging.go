@@ -0,0 +1,102 @@
+package s3
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"sort"
+
+	"go.sia.tech/gofakes3"
+	"go.thebigfile.com/renterd/api"
+)
+
+// maxTaggingBodySize is the maximum size of a PutObjectTagging request body.
+const maxTaggingBodySize = 1 << 16 // 64 KiB
+
+type (
+	// tagging is the XML representation of an object's tags.
+	tagging struct {
+		XMLName xml.Name `xml:"Tagging"`
+		Xmlns   string   `xml:"xmlns,attr,omitempty"`
+		TagSet  []tag    `xml:"TagSet>Tag"`
+	}
+
+	tag struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	}
+)
+
+func newTagging(tags api.ObjectTags) tagging {
+	t := tagging{
+		Xmlns:  "http://s3.amazonaws.com/doc/2006-03-01/",
+		TagSet: make([]tag, 0, len(tags)),
+	}
+	for k, v := range tags {
+		t.TagSet = append(t.TagSet, tag{Key: k, Value: v})
+	}
+	sort.Slice(t.TagSet, func(i, j int) bool {
+		return t.TagSet[i].Key < t.TagSet[j].Key
+	})
+	return t
+}
+
+func (t tagging) objectTags() (api.ObjectTags, error) {
+	tags := make(api.ObjectTags, len(t.TagSet))
+	for _, tag := range t.TagSet {
+		if _, exists := tags[tag.Key]; exists {
+			return nil, gofakes3.ErrorMessagef(gofakes3.ErrInvalidArgument, "duplicate tag key '%s'", tag.Key)
+		}
+		tags[tag.Key] = tag.Value
+	}
+	return tags, nil
+}
+
+// routeTagging serves the GetObjectTagging, PutObjectTagging and
+// DeleteObjectTagging operations.
+func (e *extensions) routeTagging(bucket, object string, w http.ResponseWriter, rq *http.Request) error {
+	if object == "" {
+		return gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "bucket tagging is not supported")
+	} else if rq.URL.Query().Get("versionId") != "" {
+		return gofakes3.ErrorMessage(gofakes3.ErrNotImplemented, "tagging of specific versions is not supported")
+	}
+
+	switch rq.Method {
+	case http.MethodGet:
+		tags, err := e.backend.GetObjectTagging(rq.Context(), bucket, object)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		e.writeXML(w, newTagging(tags))
+		return nil
+
+	case http.MethodPut:
+		body, err := io.ReadAll(io.LimitReader(rq.Body, maxTaggingBodySize))
+		if err != nil {
+			return gofakes3.ErrIncompleteBody
+		}
+		var t tagging
+		if err := xml.Unmarshal(body, &t); err != nil {
+			return gofakes3.ErrorMessage(gofakes3.ErrMalformedXML, err.Error())
+		}
+		tags, err := t.objectTags()
+		if err != nil {
+			return err
+		} else if err := e.backend.PutObjectTagging(rq.Context(), bucket, object, tags); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		return nil
+
+	case http.MethodDelete:
+		if err := e.backend.DeleteObjectTagging(rq.Context(), bucket, object); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+
+	default:
+		return gofakes3.ErrMethodNotAllowed
+	}
+}
@@ -4,7 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.thebigfile.com/renterd/api"
 )
@@ -21,8 +26,33 @@ type (
 		seekOffset  int64
 		dataOffset  int64
 	}
+
+	// countingWriter counts the bytes written to it
+	countingWriter int64
+
+	// notModifiedError is returned when the conditions of a read request
+	// aren't met because the object wasn't modified, it contains the
+	// validators that are sent with the 304 response
+	notModifiedError struct {
+		eTag         string
+		lastModified time.Time
+	}
 )
 
+func (e *notModifiedError) Error() string { return api.ErrNotModified.Error() }
+func (e *notModifiedError) Unwrap() error { return api.ErrNotModified }
+
+// writeNotModified writes a 304 response, RFC 9110 requires it to contain the
+// ETag and Last-Modified headers a 200 response would have contained.
+func writeNotModified(rw http.ResponseWriter, err error) {
+	var nme *notModifiedError
+	if errors.As(err, &nme) {
+		rw.Header().Set("ETag", api.FormatETag(nme.eTag))
+		rw.Header().Set("Last-Modified", nme.lastModified.UTC().Format(http.TimeFormat))
+	}
+	rw.WriteHeader(http.StatusNotModified)
+}
+
 func newContentReader(r io.Reader, size int64, offset int64) io.ReadSeeker {
 	return &contentReader{
 		r:          r,
@@ -55,18 +85,107 @@ func (cr *contentReader) Read(p []byte) (int, error) {
 	return cr.r.Read(p)
 }
 
-func serveContent(rw http.ResponseWriter, req *http.Request, name string, content io.Reader, hor api.HeadObjectResponse) {
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+// requestedRanges returns the ranges that were requested using the "Range"
+// header. The ranges are ignored if the request's If-Range precondition isn't
+// met, in which case the whole object should be served.
+func requestedRanges(req *http.Request, hor api.HeadObjectResponse) ([]api.DownloadRange, error) {
+	if ir := req.Header.Get("If-Range"); ir != "" && !ifRangeMatches(ir, hor) {
+		return nil, nil
+	}
+	return api.ParseDownloadRanges(req.Header.Get("Range"), hor.Size)
+}
+
+// ifRangeMatches evaluates an If-Range header the same way http.ServeContent
+// does, weak etags never match.
+func ifRangeMatches(ir string, hor api.HeadObjectResponse) bool {
+	if strings.HasPrefix(ir, `"`) {
+		return ir == api.FormatETag(hor.Etag)
+	} else if strings.HasPrefix(ir, "W/") {
+		return false
+	}
+	t, err := http.ParseTime(ir)
+	return err == nil && t.Equal(hor.LastModified.Std().Truncate(time.Second))
+}
+
+func setObjectHeaders(rw http.ResponseWriter, hor api.HeadObjectResponse) {
 	// set content type and etag
 	rw.Header().Set("Content-Type", hor.ContentType)
 	rw.Header().Set("ETag", api.FormatETag(hor.Etag))
+	if hor.VersionID != "" {
+		rw.Header().Set(api.ObjectVersionIDHeader, hor.VersionID)
+	}
+	if hor.Checksum != "" {
+		rw.Header().Set(api.ObjectChecksumAlgorithmHeader, hor.ChecksumAlgorithm)
+		rw.Header().Set(api.ObjectChecksumHeader, hor.Checksum)
+	}
 
 	// set the user metadata headers
 	for k, v := range hor.Metadata {
 		rw.Header().Set(fmt.Sprintf("%s%s", api.ObjectMetadataPrefix, k), v)
 	}
+}
+
+func serveContent(rw http.ResponseWriter, req *http.Request, name string, content io.Reader, hor api.HeadObjectResponse) {
+	setObjectHeaders(rw, hor)
 
 	// create a content reader
 	rs := newContentReader(content, hor.Size, hor.Range.Offset)
 
 	http.ServeContent(rw, req, name, hor.LastModified.Std(), rs)
 }
+
+// serveRanges serves multiple ranges of an object as a multipart/byteranges
+// response, the content is expected to contain the data of the ranges in the
+// order they were requested.
+func serveRanges(rw http.ResponseWriter, req *http.Request, content io.Reader, hor api.HeadObjectResponse, ranges []api.DownloadRange) {
+	setObjectHeaders(rw, hor)
+
+	partHeader := func(r api.DownloadRange) textproto.MIMEHeader {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Range", r.ContentRange(hor.Size).String())
+		if hor.ContentType != "" {
+			h.Set("Content-Type", hor.ContentType)
+		}
+		return h
+	}
+
+	// compute the size of the response by writing the parts without their
+	// content to a counting writer
+	var cw countingWriter
+	mw := multipart.NewWriter(&cw)
+	var size int64
+	for _, r := range ranges {
+		_, _ = mw.CreatePart(partHeader(r))
+		size += r.Length
+	}
+	_ = mw.Close()
+	size += int64(cw)
+	boundary := mw.Boundary()
+
+	rw.Header().Set("Accept-Ranges", "bytes")
+	rw.Header().Set("Last-Modified", hor.LastModified.Std().UTC().Format(http.TimeFormat))
+	rw.Header().Set("Content-Type", "multipart/byteranges; boundary="+boundary)
+	rw.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	rw.WriteHeader(http.StatusPartialContent)
+	if req.Method == http.MethodHead {
+		return
+	}
+
+	// write the parts using the same boundary
+	mw = multipart.NewWriter(rw)
+	_ = mw.SetBoundary(boundary)
+	for _, r := range ranges {
+		part, err := mw.CreatePart(partHeader(r))
+		if err != nil {
+			return
+		} else if _, err := io.CopyN(part, content, r.Length); err != nil {
+			return
+		}
+	}
+	_ = mw.Close()
+}
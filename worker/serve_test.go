@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.thebigfile.com/renterd/api"
+	"lukechampine.com/frand"
+)
+
+func TestRequestedRanges(t *testing.T) {
+	modTime := time.Now().Truncate(time.Second)
+	hor := api.HeadObjectResponse{
+		Etag:         "etag",
+		LastModified: api.TimeRFC3339(modTime),
+		Size:         100,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=0-9,-10")
+	assertRanges := func(expected int) {
+		t.Helper()
+		if ranges, err := requestedRanges(req, hor); err != nil {
+			t.Fatal(err)
+		} else if len(ranges) != expected {
+			t.Fatalf("expected %d ranges, got %d", expected, len(ranges))
+		}
+	}
+	assertRanges(2)
+
+	// assert the ranges are served if the If-Range precondition is met
+	req.Header.Set("If-Range", `"etag"`)
+	assertRanges(2)
+	req.Header.Set("If-Range", modTime.UTC().Format(http.TimeFormat))
+	assertRanges(2)
+
+	// assert the ranges are ignored if it isn't
+	req.Header.Set("If-Range", `"other"`)
+	assertRanges(0)
+	req.Header.Set("If-Range", `W/"etag"`)
+	assertRanges(0)
+	req.Header.Set("If-Range", modTime.Add(-time.Hour).UTC().Format(http.TimeFormat))
+	assertRanges(0)
+}
+
+func TestServeRanges(t *testing.T) {
+	data := frand.Bytes(100)
+	hor := api.HeadObjectResponse{
+		ContentType: "application/octet-stream",
+		Etag:        "etag",
+		Size:        int64(len(data)),
+	}
+	ranges := []api.DownloadRange{{Offset: 90, Length: 10}, {Offset: 0, Length: 5}, {Offset: 50, Length: 1}}
+
+	// build the content the way the worker downloads it
+	var content []byte
+	for _, r := range ranges {
+		content = append(content, data[r.Offset:r.Offset+r.Length]...)
+	}
+
+	rec := httptest.NewRecorder()
+	serveRanges(rec, httptest.NewRequest(http.MethodGet, "/", nil), bytes.NewReader(content), hor, ranges)
+	res := rec.Result()
+	if res.StatusCode != http.StatusPartialContent {
+		t.Fatal("unexpected status", res.StatusCode)
+	} else if res.Header.Get("ETag") != `"etag"` {
+		t.Fatal("unexpected etag", res.Header.Get("ETag"))
+	} else if cl := res.Header.Get("Content-Length"); cl != strconv.Itoa(rec.Body.Len()) {
+		t.Fatalf("unexpected content length %v, body is %d bytes", cl, rec.Body.Len())
+	}
+
+	// parse the multipart response
+	mediaType, params, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	} else if mediaType != "multipart/byteranges" {
+		t.Fatal("unexpected media type", mediaType)
+	}
+	mr := multipart.NewReader(res.Body, params["boundary"])
+	for _, r := range ranges {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		} else if cr := part.Header.Get("Content-Range"); cr != r.ContentRange(hor.Size).String() {
+			t.Fatal("unexpected content range", cr)
+		} else if ct := part.Header.Get("Content-Type"); ct != hor.ContentType {
+			t.Fatal("unexpected content type", ct)
+		}
+		b, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(b, data[r.Offset:r.Offset+r.Length]) {
+			t.Fatal("unexpected data")
+		}
+	}
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Fatal("expected no more parts", err)
+	}
+
+	// assert HEAD requests get the same headers without a body
+	head := httptest.NewRecorder()
+	serveRanges(head, httptest.NewRequest(http.MethodHead, "/", nil), nil, hor, ranges)
+	if head.Code != http.StatusPartialContent {
+		t.Fatal("unexpected status", head.Code)
+	} else if head.Body.Len() != 0 {
+		t.Fatal("expected empty body")
+	} else if head.Header().Get("Content-Length") != res.Header.Get("Content-Length") {
+		t.Fatal("content length mismatch")
+	}
+}
+
+func TestWriteNotModified(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := fmt.Errorf("couldn't fetch object: %w", &notModifiedError{eTag: "etag", lastModified: modTime})
+	if !errors.Is(err, api.ErrNotModified) {
+		t.Fatal("expected ErrNotModified")
+	}
+
+	// assert the validators are set
+	rec := httptest.NewRecorder()
+	writeNotModified(rec, err)
+	if rec.Code != http.StatusNotModified {
+		t.Fatal("unexpected status", rec.Code)
+	} else if etag := rec.Header().Get("ETag"); etag != api.FormatETag("etag") {
+		t.Fatal("unexpected etag", etag)
+	} else if lm := rec.Header().Get("Last-Modified"); lm != modTime.Format(http.TimeFormat) {
+		t.Fatal("unexpected last modified", lm)
+	}
+}
@@ -0,0 +1,268 @@
+package worker
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.thebigfile.com/renterd/api"
+	iworker "go.thebigfile.com/renterd/internal/worker"
+	"go.thebigfile.com/renterd/object"
+	"go.thebigfile.com/renterd/webhooks"
+	"go.uber.org/zap"
+)
+
+const (
+	slabCacheFileExt    = ".slab"
+	slabCacheTmpFileExt = ".tmp"
+)
+
+type (
+	// slabCache is an on-disk LRU cache for the data of downloaded slabs. The
+	// data is cached after the slab is decrypted and recovered but before the
+	// object's key is applied, so the cache doesn't contain any plaintext.
+	//
+	// A slab's data never changes, migrations only move its shards, so the
+	// entries are only invalidated to free up space when the objects they
+	// were downloaded for are updated or deleted.
+	slabCache struct {
+		dir     string
+		maxSize uint64
+		logger  *zap.SugaredLogger
+
+		mu      sync.Mutex
+		size    uint64
+		lru     *list.List // front is the most recently used entry
+		entries map[string]*list.Element
+		objects map[string]map[string]struct{}
+	}
+
+	slabCacheEntry struct {
+		key      string
+		objectID string
+		size     uint64
+	}
+)
+
+func newSlabCache(dir string, maxSize uint64, logger *zap.Logger) (*slabCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create slab cache dir: %w", err)
+	}
+
+	// the entries are not persisted across restarts, so we remove the files
+	// of the previous run, including the ones that weren't fully written
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read slab cache dir: %w", err)
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		} else if strings.HasSuffix(f.Name(), slabCacheFileExt) || strings.HasSuffix(f.Name(), slabCacheTmpFileExt) {
+			if err := os.Remove(filepath.Join(dir, f.Name())); err != nil {
+				return nil, fmt.Errorf("failed to remove slab cache file: %w", err)
+			}
+		}
+	}
+
+	return &slabCache{
+		dir:     dir,
+		maxSize: maxSize,
+		logger:  logger.Sugar().Named("slabcache"),
+
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		objects: make(map[string]map[string]struct{}),
+	}, nil
+}
+
+// slabCacheKey returns the key of the cache entry for the given slice, the
+// slab's encryption key is hashed to avoid leaking it through the file names.
+func slabCacheKey(slice object.SlabSlice) string {
+	h := sha256.New()
+	h.Write([]byte(slice.Key.String()))
+	binary.Write(h, binary.LittleEndian, slice.Offset)
+	binary.Write(h, binary.LittleEndian, slice.Length)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// slabCacheObjectID returns the id of an object that the cache entries are
+// associated with.
+func slabCacheObjectID(bucket, path string) string {
+	return bucket + path
+}
+
+// Get returns the cached data for the given key.
+func (c *slabCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	el, ok := c.entries[key]
+	if ok {
+		c.lru.MoveToFront(el)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false // evicted in the meantime
+	} else if err != nil {
+		c.logger.Errorw("failed to read slab cache entry", zap.Error(err))
+		c.mu.Lock()
+		if el, ok := c.entries[key]; ok {
+			c.removeEntry(el)
+		}
+		c.mu.Unlock()
+		return nil, false
+	}
+	return data, true
+}
+
+// Put adds the data to the cache, evicting the least recently used entries if
+// necessary.
+func (c *slabCache) Put(objectID, key string, data []byte) {
+	size := uint64(len(data))
+	if size > c.maxSize {
+		return
+	}
+	c.mu.Lock()
+	_, exists := c.entries[key]
+	c.mu.Unlock()
+	if exists {
+		return
+	}
+
+	// write the data to a temporary file first to never serve partially
+	// written entries
+	if err := c.writeFile(key, data); err != nil {
+		c.logger.Errorw("failed to write slab cache entry", zap.Error(err))
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[key]; exists {
+		return // added in the meantime
+	}
+	c.entries[key] = c.lru.PushFront(&slabCacheEntry{
+		key:      key,
+		objectID: objectID,
+		size:     size,
+	})
+	if _, ok := c.objects[objectID]; !ok {
+		c.objects[objectID] = make(map[string]struct{})
+	}
+	c.objects[objectID][key] = struct{}{}
+	c.size += size
+
+	for c.size > c.maxSize {
+		c.removeEntry(c.lru.Back())
+	}
+}
+
+// InvalidateObject removes the entries that were added for the object.
+func (c *slabCache) InvalidateObject(objectID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.invalidateObject(objectID)
+}
+
+// InvalidatePrefix removes the entries that were added for all objects whose
+// id starts with the given prefix.
+func (c *slabCache) InvalidatePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for objectID := range c.objects {
+		if strings.HasPrefix(objectID, prefix) {
+			c.invalidateObject(objectID)
+		}
+	}
+}
+
+// HandleEvent implements the iworker.EventHandler interface, it invalidates
+// the entries of objects that were updated or deleted.
+func (c *slabCache) HandleEvent(event webhooks.Event) error {
+	if event.Module != api.ModuleObject {
+		return nil
+	}
+	parsed, err := api.ParseEventWebhook(event)
+	if err != nil {
+		return err
+	}
+	switch e := parsed.(type) {
+	case api.EventObjectCreated:
+		c.invalidate(e.Bucket, e.Path, e.Batch)
+	case api.EventObjectDeleted:
+		c.invalidate(e.Bucket, e.Path, e.Batch)
+	}
+	return nil
+}
+
+// Subscribe implements the iworker.EventHandler interface.
+func (c *slabCache) Subscribe(e iworker.EventSubscriber) error {
+	_, err := e.AddEventHandler(c.logger.Desugar().Name(), c)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe the slab cache, error: %v", err)
+	}
+	return nil
+}
+
+func (c *slabCache) invalidate(bucket, path string, batch bool) {
+	if batch {
+		c.InvalidatePrefix(slabCacheObjectID(bucket, path))
+	} else {
+		c.InvalidateObject(slabCacheObjectID(bucket, path))
+	}
+}
+
+func (c *slabCache) invalidateObject(objectID string) {
+	for key := range c.objects[objectID] {
+		if el, ok := c.entries[key]; ok {
+			c.removeEntry(el)
+		}
+	}
+}
+
+// removeEntry removes the entry from the cache and deletes its file, the
+// caller must hold the lock.
+func (c *slabCache) removeEntry(el *list.Element) {
+	entry := c.lru.Remove(el).(*slabCacheEntry)
+	delete(c.entries, entry.key)
+	delete(c.objects[entry.objectID], entry.key)
+	if len(c.objects[entry.objectID]) == 0 {
+		delete(c.objects, entry.objectID)
+	}
+	c.size -= entry.size
+
+	if err := os.Remove(c.path(entry.key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		c.logger.Errorw("failed to remove slab cache entry", zap.Error(err))
+	}
+}
+
+func (c *slabCache) path(key string) string {
+	return filepath.Join(c.dir, key+slabCacheFileExt)
+}
+
+func (c *slabCache) writeFile(key string, data []byte) error {
+	f, err := os.CreateTemp(c.dir, key+"-*"+slabCacheTmpFileExt)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path(key))
+}
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSlabCache(t *testing.T) {
+	dir := t.TempDir()
+
+	// add a leftover file from a previous run
+	if err := os.WriteFile(filepath.Join(dir, "leftover"+slabCacheFileExt), []byte{1}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := newSlabCache(dir, 10, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFiles := func(n int) {
+		t.Helper()
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(files) != n {
+			t.Fatalf("expected %d files, got %d", n, len(files))
+		}
+	}
+	assertFiles(0)
+
+	// add two entries
+	c.Put("default/foo", "a", []byte{1, 2, 3, 4})
+	c.Put("default/dir/bar", "b", []byte{5, 6, 7, 8})
+	if data, ok := c.Get("a"); !ok || !bytes.Equal(data, []byte{1, 2, 3, 4}) {
+		t.Fatal("unexpected", data, ok)
+	} else if data, ok := c.Get("b"); !ok || !bytes.Equal(data, []byte{5, 6, 7, 8}) {
+		t.Fatal("unexpected", data, ok)
+	}
+	assertFiles(2)
+
+	// entries that are too large are not cached
+	c.Put("default/foo", "c", make([]byte, 11))
+	if _, ok := c.Get("c"); ok {
+		t.Fatal("expected entry to be skipped")
+	}
+
+	// 'a' was used least recently and should be evicted
+	c.Get("b")
+	c.Put("default/baz", "d", []byte{9, 10, 11, 12})
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected entry to be evicted")
+	} else if _, ok := c.Get("b"); !ok {
+		t.Fatal("expected entry to be cached")
+	} else if c.size != 8 {
+		t.Fatal("unexpected size", c.size)
+	}
+	assertFiles(2)
+
+	// invalidate by prefix
+	c.InvalidatePrefix("default/dir/")
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected entry to be invalidated")
+	}
+
+	// invalidate by object
+	c.InvalidateObject("default/baz")
+	if _, ok := c.Get("d"); ok {
+		t.Fatal("expected entry to be invalidated")
+	} else if c.size != 0 || len(c.entries) != 0 || len(c.objects) != 0 || c.lru.Len() != 0 {
+		t.Fatal("expected cache to be empty")
+	}
+	assertFiles(0)
+}
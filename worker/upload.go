@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"math"
 	"mime"
@@ -396,8 +397,25 @@ func (mgr *uploadManager) Upload(ctx context.Context, r io.Reader, contracts []a
 	hasher := md5.New()
 	r = io.TeeReader(r, hasher)
 
-	// create the cipher reader
-	cr, err := o.Encrypt(r, up.encryptionOffset)
+	// create the checksum hasher, checksums are not computed for multipart
+	// uploads since parts are hashed individually
+	var checksumHasher hash.Hash
+	if up.checksumAlgorithm != "" && !up.multipart {
+		checksumHasher, err = newChecksumHasher(up.checksumAlgorithm)
+		if err != nil {
+			return false, "", err
+		}
+		r = io.TeeReader(r, checksumHasher)
+	}
+
+	// create the cipher reader, if a customer key was provided the content is
+	// encrypted with a key derived from both the object's key and the
+	// customer key
+	ec := o.Key
+	if up.customerKey != nil {
+		ec = ec.WithCustomerKey(*up.customerKey)
+	}
+	cr, err := ec.Encrypt(r, up.encryptionOffset)
 	if err != nil {
 		return false, "", err
 	}
@@ -528,6 +546,21 @@ func (mgr *uploadManager) Upload(ctx context.Context, r io.Reader, contracts []a
 	// compute etag
 	eTag = hex.EncodeToString(hasher.Sum(nil))
 
+	// compute the fingerprint of the customer key
+	var fingerprint string
+	if up.customerKey != nil {
+		fingerprint = up.customerKey.Fingerprint()
+	}
+
+	// compute the checksum and verify it against the expected one
+	var checksum, checksumAlgorithm string
+	if checksumHasher != nil {
+		checksum, checksumAlgorithm = encodeChecksum(checksumHasher), up.checksumAlgorithm
+		if up.checksum != "" && up.checksum != checksum {
+			return false, "", fmt.Errorf("%w: expected '%s', got '%s'", api.ErrChecksumMismatch, up.checksum, checksum)
+		}
+	}
+
 	// add partial slabs
 	if len(partialSlab) > 0 {
 		var pss []object.SlabSlice
@@ -540,13 +573,26 @@ func (mgr *uploadManager) Upload(ctx context.Context, r io.Reader, contracts []a
 
 	if up.multipart {
 		// persist the part
-		err = mgr.os.AddMultipartPart(ctx, up.bucket, up.path, up.contractSet, eTag, up.uploadID, up.partNumber, o.Slabs)
+		err = mgr.os.AddMultipartPart(ctx, up.bucket, up.path, up.contractSet, eTag, up.uploadID, up.partNumber, o.Slabs, api.AddMultipartPartOptions{
+			Offset: up.partOffset,
+		})
 		if err != nil {
 			return bufferSizeLimitReached, "", fmt.Errorf("couldn't add multi part: %w", err)
 		}
 	} else {
 		// persist the object
-		err = mgr.os.AddObject(ctx, up.bucket, up.path, up.contractSet, o, api.AddObjectOptions{MimeType: up.mimeType, ETag: eTag, Metadata: up.metadata})
+		err = mgr.os.AddObject(ctx, up.bucket, up.path, up.contractSet, o, api.AddObjectOptions{
+			MimeType:          up.mimeType,
+			ETag:              eTag,
+			ChecksumAlgorithm: checksumAlgorithm,
+			Checksum:          checksum,
+			Metadata:          up.metadata,
+
+			CustomerKeyFingerprint: fingerprint,
+
+			Conditions: up.conditions,
+			Lock:       up.lock,
+		})
 		if err != nil {
 			return bufferSizeLimitReached, "", fmt.Errorf("couldn't add object: %w", err)
 		}
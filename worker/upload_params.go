@@ -12,6 +12,7 @@ type uploadParameters struct {
 	multipart  bool
 	uploadID   string
 	partNumber int
+	partOffset *int64
 
 	ec               object.EncryptionKey
 	encryptionOffset uint64
@@ -22,6 +23,13 @@ type uploadParameters struct {
 	packing     bool
 	mimeType    string
 
+	checksumAlgorithm string
+	checksum          string
+
+	customerKey *object.CustomerKey
+	conditions  api.ObjectConditions
+	lock        api.ObjectLock
+
 	metadata api.ObjectUserMetadata
 }
 
@@ -51,7 +59,7 @@ func WithContractSet(contractSet string) UploadOption {
 	}
 }
 
-func WithCustomKey(ec object.EncryptionKey) UploadOption {
+func WithCustomEncryptionKey(ec object.EncryptionKey) UploadOption {
 	return func(up *uploadParameters) {
 		up.ec = ec
 	}
@@ -81,6 +89,14 @@ func WithPartNumber(partNumber int) UploadOption {
 	}
 }
 
+// WithPartOffset makes sure the uploaded part is only added to the multipart
+// upload if it starts at the given offset of the upload.
+func WithPartOffset(offset int64) UploadOption {
+	return func(up *uploadParameters) {
+		up.partOffset = &offset
+	}
+}
+
 func WithUploadID(uploadID string) UploadOption {
 	return func(up *uploadParameters) {
 		up.uploadID = uploadID
@@ -93,3 +109,38 @@ func WithObjectUserMetadata(metadata api.ObjectUserMetadata) UploadOption {
 		up.metadata = metadata
 	}
 }
+
+// WithChecksum computes a checksum of the uploaded content using the given
+// algorithm, if an expected checksum is passed the upload fails if the
+// checksums don't match.
+func WithChecksum(algorithm, checksum string) UploadOption {
+	return func(up *uploadParameters) {
+		up.checksumAlgorithm = algorithm
+		up.checksum = checksum
+	}
+}
+
+// WithCustomerKey encrypts the uploaded content with a key that is derived from
+// the object's key and the given customer key, only the fingerprint of the
+// customer key is stored alongside the object.
+func WithCustomerKey(ck object.CustomerKey) UploadOption {
+	return func(up *uploadParameters) {
+		up.customerKey = &ck
+	}
+}
+
+// WithConditions sets the conditions that are evaluated by the bus when the
+// uploaded object is stored.
+func WithConditions(conditions api.ObjectConditions) UploadOption {
+	return func(up *uploadParameters) {
+		up.conditions = conditions
+	}
+}
+
+// WithObjectLock sets the retention and legal hold the uploaded object is
+// stored with.
+func WithObjectLock(lock api.ObjectLock) UploadOption {
+	return func(up *uploadParameters) {
+		up.lock = lock
+	}
+}
@@ -60,7 +60,7 @@ func TestUpload(t *testing.T) {
 
 	// download the data and assert it matches
 	var buf bytes.Buffer
-	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), w.Contracts())
+	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), w.Contracts(), "")
 	if err != nil {
 		t.Fatal(err)
 	} else if !bytes.Equal(data, buf.Bytes()) {
@@ -86,7 +86,7 @@ func TestUpload(t *testing.T) {
 
 	// download the data again and assert it matches
 	buf.Reset()
-	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), filtered)
+	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), filtered, "")
 	if err != nil {
 		t.Fatal(err)
 	} else if !bytes.Equal(data, buf.Bytes()) {
@@ -103,7 +103,7 @@ func TestUpload(t *testing.T) {
 
 	// download the data again and assert it fails
 	buf.Reset()
-	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), filtered)
+	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), filtered, "")
 	if !errors.Is(err, errDownloadNotEnoughHosts) {
 		t.Fatal("expected not enough hosts error", err)
 	}
@@ -165,7 +165,7 @@ func TestUploadPackedSlab(t *testing.T) {
 
 	// download the data and assert it matches
 	var buf bytes.Buffer
-	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), w.Contracts())
+	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), w.Contracts(), "")
 	if err != nil {
 		t.Fatal(err)
 	} else if !bytes.Equal(data, buf.Bytes()) {
@@ -201,7 +201,7 @@ func TestUploadPackedSlab(t *testing.T) {
 
 	// download the data again and assert it matches
 	buf.Reset()
-	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), w.Contracts())
+	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), w.Contracts(), "")
 	if err != nil {
 		t.Fatal(err)
 	} else if !bytes.Equal(data, buf.Bytes()) {
@@ -479,7 +479,7 @@ func TestUploadShards(t *testing.T) {
 
 	// download the data and assert it matches
 	var buf bytes.Buffer
-	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), contracts)
+	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), contracts, "")
 	if err != nil {
 		t.Fatal(err)
 	} else if !bytes.Equal(data, buf.Bytes()) {
@@ -621,7 +621,7 @@ func TestUploadRegression(t *testing.T) {
 
 	// download data for good measure
 	var buf bytes.Buffer
-	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), w.Contracts())
+	err = dl.DownloadObject(context.Background(), &buf, *o.Object.Object, 0, uint64(o.Object.Size), w.Contracts(), "")
 	if err != nil {
 		t.Fatal(err)
 	} else if !bytes.Equal(data, buf.Bytes()) {
@@ -678,3 +678,41 @@ func testOpts() []UploadOption {
 		WithContractSet(testContractSet),
 	}
 }
+
+func TestUploadParamsContractSet(t *testing.T) {
+	// create test worker
+	w := newTestWorker(t)
+	b := w.bus.(*busMock)
+	b.uploadParams = api.UploadParams{
+		ContractSet:        testContractSet,
+		ConsensusState:     api.ConsensusState{Synced: true},
+		RedundancySettings: testRedundancySettings,
+	}
+
+	assertContractSet := func(set, expected string) {
+		t.Helper()
+		up, _, err := w.prepareUploadParams(context.Background(), testBucket, set, 0, 0, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		} else if up.ContractSet != expected {
+			t.Fatalf("expected contract set %v, got %v", expected, up.ContractSet)
+		}
+	}
+
+	// without a bucket policy, the default set is used
+	assertContractSet("", testContractSet)
+
+	// the bucket's contract set takes precedence over the default set
+	w.os.policies[testBucket] = api.BucketPolicy{ContractSet: "bucketset"}
+	assertContractSet("", "bucketset")
+
+	// an explicit contract set takes precedence over the bucket's set
+	assertContractSet("explicitset", "explicitset")
+
+	// without any set the upload is rejected
+	w.os.policies[testBucket] = api.BucketPolicy{}
+	b.uploadParams.ContractSet = ""
+	if _, _, err := w.prepareUploadParams(context.Background(), testBucket, "", 0, 0, 0, false); !errors.Is(err, api.ErrContractSetNotSpecified) {
+		t.Fatal("unexpected error", err)
+	}
+}
@@ -0,0 +1,179 @@
+package worker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gotd/contrib/http_range"
+	"go.sia.tech/jape"
+	"go.thebigfile.com/renterd/api"
+	"go.thebigfile.com/renterd/internal/utils"
+)
+
+// serveWebsite serves the object at the given path of a bucket that is
+// configured as a static website. Requests for directories are served the
+// index document and requests for missing objects the error document.
+func (w *Worker) serveWebsite(jc jape.Context, bucket, key string) {
+	ctx := jc.Request.Context()
+
+	b, err := w.bus.Bucket(ctx, bucket)
+	if utils.IsErr(err, api.ErrBucketNotFound) || (err == nil && !b.Policy.WebsiteEnabled()) {
+		jc.Error(fmt.Errorf("bucket '%s' is not configured as a website", bucket), http.StatusNotFound)
+		return
+	} else if jc.Check("couldn't fetch bucket", err) != nil {
+		return
+	}
+	ws := *b.Policy.Website
+
+	// apply the redirect rules that don't depend on the object
+	key = strings.TrimPrefix(key, "/")
+	if rule, newKey, ok := ws.Redirect(key, 0); ok {
+		websiteRedirect(jc.ResponseWriter, websiteRedirectLocation(jc.Request, key, newKey, rule), rule.RedirectCode())
+		return
+	}
+
+	// serve the index document for directories
+	objectKey := key
+	if objectKey == "" || strings.HasSuffix(objectKey, "/") {
+		objectKey += ws.IndexDocument
+	}
+
+	err = w.serveWebsiteObject(jc, bucket, objectKey, http.StatusOK)
+	if utils.IsErr(err, api.ErrObjectNotFound) || utils.IsErr(err, api.ErrObjectVersionIsDeleteMarker) {
+		// redirect to the directory if the key is missing its trailing slash
+		if key != "" && !strings.HasSuffix(key, "/") {
+			if _, err := w.HeadObject(ctx, bucket, "/"+key+"/"+ws.IndexDocument, api.HeadObjectOptions{}); err == nil {
+				websiteRedirect(jc.ResponseWriter, path.Base(key)+"/", http.StatusFound)
+				return
+			}
+		}
+		w.serveWebsiteError(jc, bucket, ws, key, http.StatusNotFound)
+	} else if errors.Is(err, api.ErrCustomerKeyRequired) || errors.Is(err, api.ErrCustomerKeyMismatch) {
+		w.serveWebsiteError(jc, bucket, ws, key, http.StatusForbidden)
+	} else if errors.Is(err, http_range.ErrInvalid) {
+		jc.Error(err, http.StatusBadRequest)
+	} else if errors.Is(err, http_range.ErrNoOverlap) {
+		jc.Error(err, http.StatusRequestedRangeNotSatisfiable)
+	} else if errors.Is(err, api.ErrNotModified) {
+		writeNotModified(jc.ResponseWriter, err)
+	} else if errors.Is(err, api.ErrPreconditionFailed) {
+		jc.Error(err, http.StatusPreconditionFailed)
+	} else if err != nil {
+		jc.Check("couldn't serve object", err)
+	}
+}
+
+// serveWebsiteError redirects a request that failed with the given status code
+// if a redirect rule matches, otherwise it serves the error document.
+func (w *Worker) serveWebsiteError(jc jape.Context, bucket string, ws api.BucketWebsite, key string, status int) {
+	if rule, newKey, ok := ws.Redirect(key, status); ok {
+		websiteRedirect(jc.ResponseWriter, websiteRedirectLocation(jc.Request, key, newKey, rule), rule.RedirectCode())
+		return
+	}
+	if ws.ErrorDocument != "" {
+		err := w.serveWebsiteObject(jc, bucket, ws.ErrorDocument, status)
+		if err == nil {
+			return
+		} else if !utils.IsErr(err, api.ErrObjectNotFound) {
+			w.logger.Errorw("failed to serve website error document", "bucket", bucket, "error", err)
+		}
+	}
+	jc.Error(errors.New(http.StatusText(status)), status)
+}
+
+// serveWebsiteObject serves the object with the given key. Objects served with
+// a status other than 200 are served in full, ignoring the request's
+// conditions and ranges, which is used to serve the error document.
+func (w *Worker) serveWebsiteObject(jc jape.Context, bucket, key string, status int) error {
+	ctx := jc.Request.Context()
+	objectPath := "/" + key
+
+	var conditions api.ObjectConditions
+	if status == http.StatusOK {
+		conditions = api.ObjectConditionsFromHeader(jc.Request.Header)
+	}
+
+	// HEAD requests only fetch the object's metadata
+	if jc.Request.Method == http.MethodHead {
+		hor, err := w.HeadObject(ctx, bucket, objectPath, api.HeadObjectOptions{Conditions: conditions})
+		if err != nil {
+			return err
+		} else if status != http.StatusOK {
+			setObjectHeaders(jc.ResponseWriter, *hor)
+			jc.ResponseWriter.Header().Set("Content-Length", fmt.Sprint(hor.Size))
+			jc.ResponseWriter.WriteHeader(status)
+			return nil
+		}
+
+		ranges, err := requestedRanges(jc.Request, *hor)
+		if err != nil {
+			return err
+		} else if len(ranges) > 1 {
+			serveRanges(jc.ResponseWriter, jc.Request, nil, *hor, ranges)
+			return nil
+		} else if len(ranges) == 1 {
+			hor.Range = ranges[0].ContentRange(hor.Size)
+		}
+		serveContent(jc.ResponseWriter, jc.Request, objectPath, bytes.NewReader(nil), *hor)
+		return nil
+	}
+
+	var resolveRanges func(api.HeadObjectResponse) ([]api.DownloadRange, error)
+	if status == http.StatusOK {
+		resolveRanges = func(hor api.HeadObjectResponse) ([]api.DownloadRange, error) {
+			return requestedRanges(jc.Request, hor)
+		}
+	}
+	gor, ranges, err := w.getObject(ctx, bucket, objectPath, api.DownloadObjectOptions{Conditions: conditions}, resolveRanges)
+	if err != nil {
+		return err
+	}
+	defer gor.Content.Close()
+
+	if status != http.StatusOK {
+		setObjectHeaders(jc.ResponseWriter, gor.HeadObjectResponse)
+		jc.ResponseWriter.Header().Set("Content-Length", fmt.Sprint(gor.Size))
+		jc.ResponseWriter.WriteHeader(status)
+		_, _ = io.Copy(jc.ResponseWriter, gor.Content)
+	} else if len(ranges) > 1 {
+		serveRanges(jc.ResponseWriter, jc.Request, gor.Content, gor.HeadObjectResponse, ranges)
+	} else {
+		serveContent(jc.ResponseWriter, jc.Request, objectPath, gor.Content, gor.HeadObjectResponse)
+	}
+	return nil
+}
+
+// websiteRedirect redirects to the given location, unlike http.Redirect it
+// doesn't turn relative locations into absolute ones since the request's path
+// doesn't contain the prefix the worker's API is served under.
+func websiteRedirect(rw http.ResponseWriter, location string, code int) {
+	rw.Header().Set("Location", location)
+	rw.WriteHeader(code)
+}
+
+// websiteRedirectLocation returns the location of a redirect. Redirects
+// without a hostname are relative to the requested key, which keeps them
+// working when the worker is served behind a proxy under a different path.
+func websiteRedirectLocation(req *http.Request, key, newKey string, rule api.WebsiteRedirectRule) string {
+	if rule.HostName != "" {
+		protocol := rule.Protocol
+		if protocol == "" {
+			protocol = "http"
+			if req.TLS != nil {
+				protocol = "https"
+			}
+		}
+		return fmt.Sprintf("%s://%s/%s", protocol, rule.HostName, newKey)
+	}
+
+	location := strings.Repeat("../", strings.Count(key, "/")) + newKey
+	if location == "" {
+		location = "./"
+	}
+	return location
+}
@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+
+	"go.thebigfile.com/renterd/api"
+)
+
+func TestWebsiteRedirectLocation(t *testing.T) {
+	req := httptest.NewRequest("GET", "/website/bucket/docs/a.html", nil)
+	tlsReq := httptest.NewRequest("GET", "/website/bucket/docs/a.html", nil)
+	tlsReq.TLS = &tls.ConnectionState{}
+
+	tests := []struct {
+		key, newKey string
+		rule        api.WebsiteRedirectRule
+		tls         bool
+		location    string
+	}{
+		{"a.html", "b.html", api.WebsiteRedirectRule{}, false, "b.html"},
+		{"docs/a.html", "documents/a.html", api.WebsiteRedirectRule{}, false, "../documents/a.html"},
+		{"docs/dir/", "", api.WebsiteRedirectRule{}, false, "../../"},
+		{"", "", api.WebsiteRedirectRule{}, false, "./"},
+		{"docs/a.html", "a.html", api.WebsiteRedirectRule{HostName: "example.com"}, false, "http://example.com/a.html"},
+		{"docs/a.html", "a.html", api.WebsiteRedirectRule{HostName: "example.com"}, true, "https://example.com/a.html"},
+		{"docs/a.html", "a.html", api.WebsiteRedirectRule{HostName: "example.com", Protocol: "https"}, false, "https://example.com/a.html"},
+	}
+	for _, test := range tests {
+		r := req
+		if test.tls {
+			r = tlsReq
+		}
+		if location := websiteRedirectLocation(r, test.key, test.newKey, test.rule); location != test.location {
+			t.Fatalf("%q -> %q: unexpected location %q != %q", test.key, test.newKey, location, test.location)
+		}
+	}
+}
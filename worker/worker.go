@@ -12,6 +12,7 @@ import (
 	"os"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -114,7 +115,7 @@ type (
 
 		// NOTE: used for upload
 		AddObject(ctx context.Context, bucket, path, contractSet string, o object.Object, opts api.AddObjectOptions) error
-		AddMultipartPart(ctx context.Context, bucket, path, contractSet, ETag, uploadID string, partNumber int, slices []object.SlabSlice) (err error)
+		AddMultipartPart(ctx context.Context, bucket, path, contractSet, ETag, uploadID string, partNumber int, slices []object.SlabSlice, opts api.AddMultipartPartOptions) (err error)
 		AddPartialSlab(ctx context.Context, data []byte, minShards, totalShards uint8, contractSet string) (slabs []object.SlabSlice, slabBufferMaxSizeSoftReached bool, err error)
 		AddUploadingSector(ctx context.Context, uID api.UploadID, id types.FileContractID, root types.Hash256) error
 		FinishUpload(ctx context.Context, uID api.UploadID) error
@@ -125,12 +126,14 @@ type (
 		// NOTE: used by worker
 		Bucket(_ context.Context, bucket string) (api.Bucket, error)
 		Object(ctx context.Context, bucket, path string, opts api.GetObjectOptions) (api.ObjectsResponse, error)
-		DeleteObject(ctx context.Context, bucket, path string, opts api.DeleteObjectOptions) error
+		DeleteObject(ctx context.Context, bucket, path string, opts api.DeleteObjectOptions) (api.DeleteObjectResponse, error)
 		MultipartUpload(ctx context.Context, uploadID string) (resp api.MultipartUpload, err error)
+		ObjectsStats(ctx context.Context, opts api.ObjectsStatsOpts) (api.ObjectsStatsResponse, error)
 		PackedSlabsForUpload(ctx context.Context, lockingDuration time.Duration, minShards, totalShards uint8, set string, limit int) ([]api.PackedSlab, error)
 	}
 
 	SettingStore interface {
+		BandwidthSettings(ctx context.Context) (api.BandwidthSettings, error)
 		GougingParams(ctx context.Context) (api.GougingParams, error)
 		UploadParams(ctx context.Context) (api.UploadParams, error)
 	}
@@ -183,6 +186,9 @@ type Worker struct {
 	startTime       time.Time
 
 	eventSubscriber iworker.EventSubscriber
+	bandwidth       *bandwidthManager
+	bucketQuotas    *bucketQuotaTracker
+	slabCache       *slabCache // optional
 	downloadManager *downloadManager
 	uploadManager   *uploadManager
 
@@ -194,6 +200,8 @@ type Worker struct {
 	uploadsMu            sync.Mutex
 	uploadingPackedSlabs map[string]struct{}
 
+	resumableUploads *resumableUploadLocker
+
 	contractSpendingRecorder ContractSpendingRecorder
 	contractLockingDuration  time.Duration
 
@@ -458,6 +466,7 @@ func (w *Worker) downloadsStatsHandlerGET(jc jape.Context) {
 		AvgOverdrivePct:      math.Floor(stats.avgOverdrivePct*100*100) / 100,
 		HealthyDownloaders:   healthy,
 		NumDownloaders:       uint64(len(stats.downloaders)),
+		ThroughputMBPS:       math.Ceil(w.bandwidth.DownloadThroughput()*0.000008*100) / 100, // convert bytes per second to mbps
 		DownloadersStats:     dss,
 	})
 }
@@ -483,6 +492,7 @@ func (w *Worker) uploadsStatsHandlerGET(jc jape.Context) {
 		AvgOverdrivePct:        math.Floor(stats.avgOverdrivePct*100*100) / 100,
 		HealthyUploaders:       stats.healthyUploaders,
 		NumUploaders:           stats.numUploaders,
+		ThroughputMBPS:         math.Ceil(w.bandwidth.UploadThroughput()*0.000008*100) / 100, // convert bytes per second to mbps
 		UploadersStats:         uss,
 	})
 }
@@ -497,6 +507,10 @@ func (w *Worker) objectsHandlerHEAD(jc jape.Context) {
 	if jc.DecodeForm("ignoreDelim", &ignoreDelim) != nil {
 		return
 	}
+	var versionID string
+	if jc.DecodeForm("versionid", &versionID) != nil {
+		return
+	}
 
 	// parse path
 	path := jc.PathParam("path")
@@ -514,33 +528,57 @@ func (w *Worker) objectsHandlerHEAD(jc jape.Context) {
 		return
 	}
 
-	dr, err := api.ParseDownloadRange(jc.Request)
-	if errors.Is(err, http_range.ErrInvalid) || errors.Is(err, api.ErrMultiRangeNotSupported) {
+	// parse the customer key
+	ck, err := api.CustomerKeyFromHeader(jc.Request.Header)
+	if err != nil {
 		jc.Error(err, http.StatusBadRequest)
 		return
-	} else if errors.Is(err, http_range.ErrNoOverlap) {
-		jc.Error(err, http.StatusRequestedRangeNotSatisfiable)
-		return
-	} else if err != nil {
-		jc.Error(err, http.StatusInternalServerError)
-		return
 	}
 
 	// fetch object metadata
 	hor, err := w.HeadObject(jc.Request.Context(), bucket, path, api.HeadObjectOptions{
 		IgnoreDelim: ignoreDelim,
-		Range:       &dr,
+		VersionID:   versionID,
+		CustomerKey: ck,
+		Conditions:  api.ObjectConditionsFromHeader(jc.Request.Header),
 	})
-	if utils.IsErr(err, api.ErrObjectNotFound) {
+	if utils.IsErr(err, api.ErrObjectNotFound) ||
+		utils.IsErr(err, api.ErrObjectVersionNotFound) ||
+		utils.IsErr(err, api.ErrObjectVersionIsDeleteMarker) {
 		jc.Error(err, http.StatusNotFound)
 		return
-	} else if errors.Is(err, http_range.ErrInvalid) {
+	} else if errors.Is(err, http_range.ErrInvalid) ||
+		errors.Is(err, api.ErrCustomerKeyRequired) {
 		jc.Error(err, http.StatusBadRequest)
 		return
+	} else if errors.Is(err, api.ErrCustomerKeyMismatch) {
+		jc.Error(err, http.StatusForbidden)
+		return
+	} else if errors.Is(err, api.ErrNotModified) {
+		writeNotModified(jc.ResponseWriter, err)
+		return
+	} else if errors.Is(err, api.ErrPreconditionFailed) {
+		jc.Error(err, http.StatusPreconditionFailed)
+		return
 	} else if jc.Check("couldn't get object", err) != nil {
 		return
 	}
 
+	// resolve the requested ranges now that we know the object's size
+	ranges, err := requestedRanges(jc.Request, *hor)
+	if errors.Is(err, http_range.ErrInvalid) {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	} else if errors.Is(err, http_range.ErrNoOverlap) {
+		jc.Error(err, http.StatusRequestedRangeNotSatisfiable)
+		return
+	} else if len(ranges) > 1 {
+		serveRanges(jc.ResponseWriter, jc.Request, nil, *hor, ranges)
+		return
+	} else if len(ranges) == 1 {
+		hor.Range = ranges[0].ContentRange(hor.Size)
+	}
+
 	// serve the content to ensure we're setting the exact same headers as we
 	// would for a GET request
 	serveContent(jc.ResponseWriter, jc.Request, path, bytes.NewReader(nil), *hor)
@@ -583,6 +621,14 @@ func (w *Worker) objectsHandlerGET(jc jape.Context) {
 	if jc.DecodeForm("ignoreDelim", &ignoreDelim) != nil {
 		return
 	}
+	var versionID string
+	if jc.DecodeForm("versionid", &versionID) != nil {
+		return
+	}
+	var verifyChecksum bool
+	if jc.DecodeForm("verifychecksum", &verifyChecksum) != nil {
+		return
+	}
 
 	opts := api.GetObjectOptions{
 		Prefix:      prefix,
@@ -592,6 +638,7 @@ func (w *Worker) objectsHandlerGET(jc jape.Context) {
 		IgnoreDelim: ignoreDelim,
 		SortBy:      sortBy,
 		SortDir:     sortDir,
+		VersionID:   versionID,
 	}
 
 	path := jc.PathParam("path")
@@ -608,35 +655,55 @@ func (w *Worker) objectsHandlerGET(jc jape.Context) {
 		return
 	}
 
-	dr, err := api.ParseDownloadRange(jc.Request)
-	if errors.Is(err, http_range.ErrInvalid) || errors.Is(err, api.ErrMultiRangeNotSupported) {
+	ck, err := api.CustomerKeyFromHeader(jc.Request.Header)
+	if err != nil {
 		jc.Error(err, http.StatusBadRequest)
 		return
-	} else if errors.Is(err, http_range.ErrNoOverlap) {
-		jc.Error(err, http.StatusRequestedRangeNotSatisfiable)
-		return
-	} else if err != nil {
-		jc.Error(err, http.StatusInternalServerError)
-		return
 	}
 
-	gor, err := w.GetObject(ctx, bucket, path, api.DownloadObjectOptions{
+	// the requested ranges are resolved once the object's size is known
+	gor, ranges, err := w.getObject(ctx, bucket, path, api.DownloadObjectOptions{
 		GetObjectOptions: opts,
-		Range:            &dr,
+		CustomerKey:      ck,
+		Conditions:       api.ObjectConditionsFromHeader(jc.Request.Header),
+		VerifyChecksum:   verifyChecksum,
+	}, func(hor api.HeadObjectResponse) ([]api.DownloadRange, error) {
+		return requestedRanges(jc.Request, hor)
 	})
-	if utils.IsErr(err, api.ErrObjectNotFound) {
+	if utils.IsErr(err, api.ErrObjectNotFound) ||
+		utils.IsErr(err, api.ErrObjectVersionNotFound) ||
+		utils.IsErr(err, api.ErrObjectVersionIsDeleteMarker) {
 		jc.Error(err, http.StatusNotFound)
 		return
-	} else if errors.Is(err, http_range.ErrInvalid) {
+	} else if errors.Is(err, http_range.ErrInvalid) ||
+		errors.Is(err, api.ErrChecksumNotAvailable) ||
+		errors.Is(err, api.ErrInvalidChecksumAlgorithm) ||
+		errors.Is(err, api.ErrCustomerKeyRequired) {
 		jc.Error(err, http.StatusBadRequest)
 		return
+	} else if errors.Is(err, http_range.ErrNoOverlap) {
+		jc.Error(err, http.StatusRequestedRangeNotSatisfiable)
+		return
+	} else if errors.Is(err, api.ErrCustomerKeyMismatch) {
+		jc.Error(err, http.StatusForbidden)
+		return
+	} else if errors.Is(err, api.ErrNotModified) {
+		writeNotModified(jc.ResponseWriter, err)
+		return
+	} else if errors.Is(err, api.ErrPreconditionFailed) {
+		jc.Error(err, http.StatusPreconditionFailed)
+		return
 	} else if jc.Check("couldn't get object", err) != nil {
 		return
 	}
 	defer gor.Content.Close()
 
 	// serve the content
-	serveContent(jc.ResponseWriter, jc.Request, path, gor.Content, gor.HeadObjectResponse)
+	if len(ranges) > 1 {
+		serveRanges(jc.ResponseWriter, jc.Request, gor.Content, gor.HeadObjectResponse, ranges)
+	} else {
+		serveContent(jc.ResponseWriter, jc.Request, path, gor.Content, gor.HeadObjectResponse)
+	}
 }
 
 func (w *Worker) objectsHandlerPUT(jc jape.Context) {
@@ -673,6 +740,12 @@ func (w *Worker) objectsHandlerPUT(jc jape.Context) {
 		return
 	}
 
+	// decode the checksum algorithm from the query string
+	var checksumAlgorithm string
+	if jc.DecodeForm("checksumalgorithm", &checksumAlgorithm) != nil {
+		return
+	}
+
 	// parse headers and extract object meta
 	metadata := make(api.ObjectUserMetadata)
 	for k, v := range jc.Request.Header {
@@ -681,21 +754,49 @@ func (w *Worker) objectsHandlerPUT(jc jape.Context) {
 		}
 	}
 
+	// parse the customer key
+	ck, err := api.CustomerKeyFromHeader(jc.Request.Header)
+	if err != nil {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	}
+
+	// parse the object lock
+	lock, err := api.ObjectLockFromHeader(jc.Request.Header)
+	if err != nil {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	}
+
 	// upload the object
 	resp, err := w.UploadObject(ctx, jc.Request.Body, bucket, path, api.UploadObjectOptions{
-		MinShards:     minShards,
-		TotalShards:   totalShards,
-		ContractSet:   contractset,
-		ContentLength: jc.Request.ContentLength,
-		MimeType:      mimeType,
-		Metadata:      metadata,
+		MinShards:         minShards,
+		TotalShards:       totalShards,
+		ContractSet:       contractset,
+		ContentLength:     jc.Request.ContentLength,
+		MimeType:          mimeType,
+		Metadata:          metadata,
+		ChecksumAlgorithm: checksumAlgorithm,
+		Checksum:          jc.Request.Header.Get(api.ObjectChecksumHeader),
+		CustomerKey:       ck,
+		Conditions:        api.ObjectConditionsFromHeader(jc.Request.Header),
+		Lock:              lock,
 	})
-	if utils.IsErr(err, api.ErrInvalidRedundancySettings) {
+	if utils.IsErr(err, api.ErrInvalidRedundancySettings) ||
+		utils.IsErr(err, api.ErrInvalidChecksumAlgorithm) ||
+		utils.IsErr(err, api.ErrChecksumMismatch) ||
+		utils.IsErr(err, api.ErrInvalidObjectRetention) {
 		jc.Error(err, http.StatusBadRequest)
 		return
 	} else if utils.IsErr(err, api.ErrBucketNotFound) {
 		jc.Error(err, http.StatusNotFound)
 		return
+	} else if utils.IsErr(err, api.ErrBucketQuotaExceeded) || utils.IsErr(err, api.ErrObjectLocked) {
+		jc.Error(err, http.StatusForbidden)
+		return
+	} else if utils.IsErr(err, api.ErrPreconditionFailed) {
+		jc.Error(err, http.StatusPreconditionFailed)
+		return
 	} else if utils.IsErr(err, api.ErrContractSetNotSpecified) {
 		jc.Error(err, http.StatusBadRequest)
 		return
@@ -710,6 +811,14 @@ func (w *Worker) objectsHandlerPUT(jc jape.Context) {
 	jc.ResponseWriter.Header().Set("ETag", api.FormatETag(resp.ETag))
 }
 
+func (w *Worker) websiteHandlerHEAD(jc jape.Context) {
+	w.serveWebsite(jc, jc.PathParam("bucket"), jc.PathParam("path"))
+}
+
+func (w *Worker) websiteHandlerGET(jc jape.Context) {
+	w.serveWebsite(jc, jc.PathParam("bucket"), jc.PathParam("path"))
+}
+
 func (w *Worker) multipartUploadHandlerPUT(jc jape.Context) {
 	jc.Custom((*[]byte)(nil), nil)
 	ctx := jc.Request.Context()
@@ -753,6 +862,13 @@ func (w *Worker) multipartUploadHandlerPUT(jc jape.Context) {
 		return
 	}
 
+	// customer keys are not supported for multipart uploads, the parts of a
+	// multipart upload are not necessarily encrypted using offsets
+	if jc.Request.Header.Get(api.ObjectCustomerKeyHeader) != "" {
+		jc.Error(fmt.Errorf("%w: not supported for multipart uploads", api.ErrInvalidCustomerKey), http.StatusBadRequest)
+		return
+	}
+
 	// prepare options
 	opts := api.UploadMultipartUploadPartOptions{
 		ContractSet:      contractset,
@@ -778,6 +894,9 @@ func (w *Worker) multipartUploadHandlerPUT(jc jape.Context) {
 	} else if utils.IsErr(err, api.ErrBucketNotFound) {
 		jc.Error(err, http.StatusNotFound)
 		return
+	} else if utils.IsErr(err, api.ErrBucketQuotaExceeded) {
+		jc.Error(err, http.StatusForbidden)
+		return
 	} else if utils.IsErr(err, api.ErrContractSetNotSpecified) {
 		jc.Error(err, http.StatusBadRequest)
 		return
@@ -798,6 +917,114 @@ func (w *Worker) multipartUploadHandlerPUT(jc jape.Context) {
 	jc.ResponseWriter.Header().Set("ETag", api.FormatETag(resp.ETag))
 }
 
+func (w *Worker) resumableHandlerPOST(jc jape.Context) {
+	var req api.ResumableUploadCreateRequest
+	if jc.Decode(&req) != nil {
+		return
+	} else if req.Bucket == "" {
+		req.Bucket = api.DefaultBucketName
+	}
+	if req.Path == "" || strings.HasSuffix(req.Path, "/") {
+		jc.Error(errors.New("resumable uploads can only be created for objects, not directories"), http.StatusBadRequest)
+		return
+	} else if jc.Request.Header.Get(api.ObjectCustomerKeyHeader) != "" {
+		jc.Error(fmt.Errorf("%w: not supported for resumable uploads", api.ErrInvalidCustomerKey), http.StatusBadRequest)
+		return
+	}
+
+	ru, err := w.CreateResumableUpload(jc.Request.Context(), req.Bucket, req.Path, req.MimeType, req.Metadata)
+	if utils.IsErr(err, api.ErrBucketNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	} else if jc.Check("couldn't create resumable upload", err) != nil {
+		return
+	}
+	jc.ResponseWriter.Header().Set(api.ResumableUploadOffsetHeader, "0")
+	jc.Encode(ru)
+}
+
+func (w *Worker) resumableHandlerGET(jc jape.Context) {
+	ru, err := w.ResumableUpload(jc.Request.Context(), jc.PathParam("id"))
+	if utils.IsErr(err, api.ErrMultipartUploadNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	} else if jc.Check("couldn't fetch resumable upload", err) != nil {
+		return
+	}
+	jc.ResponseWriter.Header().Set(api.ResumableUploadOffsetHeader, fmt.Sprint(ru.Offset))
+	jc.Encode(ru)
+}
+
+func (w *Worker) resumableHandlerPATCH(jc jape.Context) {
+	jc.Custom((*[]byte)(nil), api.ResumableUpload{})
+
+	// the offset of the chunk is required to detect chunks that are uploaded
+	// twice or out of order
+	offset, err := strconv.ParseInt(jc.Request.Header.Get(api.ResumableUploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		jc.Error(fmt.Errorf("missing or invalid %s header", api.ResumableUploadOffsetHeader), http.StatusBadRequest)
+		return
+	}
+
+	// allow overriding the contract set and the redundancy settings
+	opts := api.UploadResumableChunkOptions{ContentLength: jc.Request.ContentLength}
+	if jc.DecodeForm("contractset", &opts.ContractSet) != nil {
+		return
+	} else if jc.DecodeForm("minshards", &opts.MinShards) != nil {
+		return
+	} else if jc.DecodeForm("totalshards", &opts.TotalShards) != nil {
+		return
+	}
+
+	ru, err := w.UploadResumableChunk(jc.Request.Context(), jc.Request.Body, jc.PathParam("id"), offset, opts)
+	if utils.IsErr(err, api.ErrUploadOffsetMismatch) {
+		jc.Error(err, http.StatusConflict)
+		return
+	} else if utils.IsErr(err, api.ErrMultipartUploadNotFound) ||
+		utils.IsErr(err, api.ErrBucketNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	} else if utils.IsErr(err, api.ErrInvalidRedundancySettings) ||
+		utils.IsErr(err, api.ErrContractSetNotSpecified) {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	} else if utils.IsErr(err, api.ErrBucketQuotaExceeded) {
+		jc.Error(err, http.StatusForbidden)
+		return
+	} else if utils.IsErr(err, api.ErrConsensusNotSynced) {
+		jc.Error(err, http.StatusServiceUnavailable)
+		return
+	} else if jc.Check("couldn't upload chunk", err) != nil {
+		return
+	}
+	jc.ResponseWriter.Header().Set(api.ResumableUploadOffsetHeader, fmt.Sprint(ru.Offset))
+	jc.Encode(ru)
+}
+
+func (w *Worker) resumableCompleteHandlerPOST(jc jape.Context) {
+	resp, err := w.CompleteResumableUpload(jc.Request.Context(), jc.PathParam("id"))
+	if utils.IsErr(err, api.ErrMultipartUploadNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	} else if utils.IsErr(err, api.ErrObjectLocked) {
+		jc.Error(err, http.StatusForbidden)
+		return
+	} else if jc.Check("couldn't complete resumable upload", err) != nil {
+		return
+	}
+	jc.ResponseWriter.Header().Set("ETag", api.FormatETag(resp.ETag))
+	jc.Encode(resp)
+}
+
+func (w *Worker) resumableHandlerDELETE(jc jape.Context) {
+	err := w.AbortResumableUpload(jc.Request.Context(), jc.PathParam("id"))
+	if utils.IsErr(err, api.ErrMultipartUploadNotFound) {
+		jc.Error(err, http.StatusNotFound)
+		return
+	}
+	jc.Check("couldn't abort resumable upload", err)
+}
+
 func (w *Worker) objectsHandlerDELETE(jc jape.Context) {
 	var batch bool
 	if jc.DecodeForm("batch", &batch) != nil {
@@ -807,14 +1034,63 @@ func (w *Worker) objectsHandlerDELETE(jc jape.Context) {
 	if jc.DecodeForm("bucket", &bucket) != nil {
 		return
 	}
-	err := w.bus.DeleteObject(jc.Request.Context(), bucket, jc.PathParam("path"), api.DeleteObjectOptions{Batch: batch})
+	_, err := w.bus.DeleteObject(jc.Request.Context(), bucket, jc.PathParam("path"), api.DeleteObjectOptions{Batch: batch})
 	if utils.IsErr(err, api.ErrObjectNotFound) {
 		jc.Error(err, http.StatusNotFound)
 		return
+	} else if utils.IsErr(err, api.ErrObjectLocked) {
+		jc.Error(err, http.StatusForbidden)
+		return
 	}
 	jc.Check("couldn't delete object", err)
 }
 
+func (w *Worker) archiveHandlerGET(jc jape.Context) {
+	jc.Custom(nil, []byte{})
+
+	bucket := api.DefaultBucketName
+	if jc.DecodeForm("bucket", &bucket) != nil {
+		return
+	}
+	format := api.ArchiveFormatZip
+	if jc.DecodeForm("format", &format) != nil {
+		return
+	}
+	ck, err := api.CustomerKeyFromHeader(jc.Request.Header)
+	if err != nil {
+		jc.Error(err, http.StatusBadRequest)
+		return
+	}
+
+	// name the archive after the last element of the prefix
+	prefix := jc.PathParam("prefix")
+	name := bucket
+	if trimmed := strings.Trim(prefix, "/"); trimmed != "" {
+		name = trimmed[strings.LastIndex(trimmed, "/")+1:]
+	}
+
+	aw := &archiveResponseWriter{rw: jc.ResponseWriter, format: format, name: name}
+	err = w.DownloadArchive(jc.Request.Context(), aw, bucket, prefix, api.DownloadArchiveOptions{
+		Format:      format,
+		CustomerKey: ck,
+	})
+	if err != nil && aw.written {
+		// the archive was partially written, abort the response to make sure
+		// the client doesn't mistake it for a complete archive
+		w.logger.Errorw("failed to write archive", "bucket", bucket, "prefix", prefix, zap.Error(err))
+		panic(http.ErrAbortHandler)
+	} else if errors.Is(err, api.ErrInvalidArchiveFormat) ||
+		errors.Is(err, api.ErrCustomerKeyRequired) {
+		jc.Error(err, http.StatusBadRequest)
+	} else if utils.IsErr(err, api.ErrBucketNotFound) {
+		jc.Error(err, http.StatusNotFound)
+	} else if errors.Is(err, api.ErrCustomerKeyMismatch) {
+		jc.Error(err, http.StatusForbidden)
+	} else {
+		jc.Check("couldn't download archive", err)
+	}
+}
+
 func (w *Worker) rhpContractsHandlerGET(jc jape.Context) {
 	ctx := jc.Request.Context()
 
@@ -940,6 +1216,9 @@ func New(cfg config.Worker, masterKey [32]byte, b Bus, l *zap.Logger) (*Worker,
 	if cfg.UploadMaxMemory == 0 {
 		return nil, errors.New("uploadMaxMemory cannot be 0")
 	}
+	if cfg.SlabCacheMaxSize > 0 && cfg.SlabCacheDir == "" {
+		return nil, errors.New("slabCacheDir must be set when the slab cache is enabled")
+	}
 
 	a := alerts.WithOrigin(b, fmt.Sprintf("worker.%s", cfg.ID))
 	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
@@ -951,7 +1230,7 @@ func New(cfg config.Worker, masterKey [32]byte, b Bus, l *zap.Logger) (*Worker,
 		contractLockingDuration: cfg.ContractLockTimeout,
 		cache:                   iworker.NewCache(b, l),
 		dialer:                  dialer,
-		eventSubscriber:         iworker.NewEventSubscriber(a, b, l, 10*time.Second),
+		eventSubscriber:         iworker.NewEventSubscriber(a, b, l, 10*time.Second, cfg.SlabCacheMaxSize > 0),
 		id:                      cfg.ID,
 		bus:                     b,
 		masterKey:               masterKey,
@@ -960,6 +1239,9 @@ func New(cfg config.Worker, masterKey [32]byte, b Bus, l *zap.Logger) (*Worker,
 		rhp3Client:              rhp3.New(dialer, l),
 		startTime:               time.Now(),
 		uploadingPackedSlabs:    make(map[string]struct{}),
+		resumableUploads:        newResumableUploadLocker(),
+		bandwidth:               newBandwidthManager(),
+		bucketQuotas:            newBucketQuotaTracker(),
 		shutdownCtx:             shutdownCtx,
 		shutdownCtxCancel:       shutdownCancel,
 	}
@@ -969,7 +1251,14 @@ func New(cfg config.Worker, masterKey [32]byte, b Bus, l *zap.Logger) (*Worker,
 	}
 	w.initPriceTables()
 
-	w.initDownloadManager(cfg.DownloadMaxMemory, cfg.DownloadMaxOverdrive, cfg.DownloadOverdriveTimeout, l)
+	if cfg.SlabCacheMaxSize > 0 {
+		sc, err := newSlabCache(cfg.SlabCacheDir, cfg.SlabCacheMaxSize, l)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize slab cache; %w", err)
+		}
+		w.slabCache = sc
+	}
+	w.initDownloadManager(cfg.DownloadMaxMemory, cfg.DownloadMaxOverdrive, cfg.DownloadOverdriveTimeout, w.slabCache, l)
 	w.initUploadManager(cfg.UploadMaxMemory, cfg.UploadMaxOverdrive, cfg.UploadOverdriveTimeout, l)
 
 	w.initContractSpendingRecorder(cfg.BusFlushInterval)
@@ -1001,8 +1290,19 @@ func (w *Worker) Handler() http.Handler {
 		"PUT    /objects/*path": w.objectsHandlerPUT,
 		"DELETE /objects/*path": w.objectsHandlerDELETE,
 
+		"GET    /archive/*prefix": w.archiveHandlerGET,
+
+		"HEAD   /website/:bucket/*path": w.websiteHandlerHEAD,
+		"GET    /website/:bucket/*path": w.websiteHandlerGET,
+
 		"PUT    /multipart/*path": w.multipartUploadHandlerPUT,
 
+		"POST   /resumable":     w.resumableHandlerPOST,
+		"GET    /resumable/:id": w.resumableHandlerGET,
+		"PATCH  /resumable/:id": w.resumableHandlerPATCH,
+		"POST   /resumable/:id": w.resumableCompleteHandlerPOST,
+		"DELETE /resumable/:id": w.resumableHandlerDELETE,
+
 		"GET    /state": w.stateHandlerGET,
 	})
 }
@@ -1017,6 +1317,11 @@ func (w *Worker) Setup(ctx context.Context, apiURL, apiPassword string) error {
 		}
 	}()
 
+	if w.slabCache != nil {
+		if err := w.slabCache.Subscribe(w.eventSubscriber); err != nil {
+			return err
+		}
+	}
 	return w.cache.Subscribe(w.eventSubscriber)
 }
 
@@ -1152,6 +1457,7 @@ func (w *Worker) headObject(ctx context.Context, bucket, path string, onlyMetada
 	res, err := w.bus.Object(ctx, bucket, path, api.GetObjectOptions{
 		IgnoreDelim:  opts.IgnoreDelim,
 		OnlyMetadata: onlyMetadata,
+		VersionID:    opts.VersionID,
 	})
 	if err != nil {
 		return nil, api.ObjectsResponse{}, fmt.Errorf("couldn't fetch object: %w", err)
@@ -1159,6 +1465,18 @@ func (w *Worker) headObject(ctx context.Context, bucket, path string, onlyMetada
 		return nil, api.ObjectsResponse{}, errors.New("object is a directory")
 	}
 
+	// check the customer key
+	if err := api.CheckCustomerKey(res.Object.CustomerKeyFingerprint, opts.CustomerKey); err != nil {
+		return nil, api.ObjectsResponse{}, err
+	}
+
+	// evaluate the conditions of the request
+	if err := opts.Conditions.CheckRead(res.Object.ETag, res.Object.ModTime.Std()); errors.Is(err, api.ErrNotModified) {
+		return nil, api.ObjectsResponse{}, &notModifiedError{eTag: res.Object.ETag, lastModified: res.Object.ModTime.Std()}
+	} else if err != nil {
+		return nil, api.ObjectsResponse{}, err
+	}
+
 	// adjust length
 	if opts.Range == nil {
 		opts.Range = &api.DownloadRange{Offset: 0, Length: -1}
@@ -1179,6 +1497,12 @@ func (w *Worker) headObject(ctx context.Context, bucket, path string, onlyMetada
 		Range:        opts.Range.ContentRange(res.Object.Size),
 		Size:         res.Object.Size,
 		Metadata:     res.Object.Metadata,
+		VersionID:    res.Object.VersionID,
+
+		ChecksumAlgorithm: res.Object.ChecksumAlgorithm,
+		Checksum:          res.Object.Checksum,
+
+		CustomerKeyFingerprint: res.Object.CustomerKeyFingerprint,
 	}, res, nil
 }
 
@@ -1212,38 +1536,82 @@ func (w *Worker) FundAccount(ctx context.Context, fcid types.FileContractID, hk
 }
 
 func (w *Worker) GetObject(ctx context.Context, bucket, path string, opts api.DownloadObjectOptions) (*api.GetObjectResponse, error) {
+	gor, _, err := w.getObject(ctx, bucket, path, opts, nil)
+	return gor, err
+}
+
+// getObject downloads an object. If resolveRanges is set, it is called with
+// the object's metadata to determine the ranges that are downloaded instead of
+// the range in the options. The content of multiple ranges is concatenated in
+// the order they were returned.
+func (w *Worker) getObject(ctx context.Context, bucket, path string, opts api.DownloadObjectOptions, resolveRanges func(api.HeadObjectResponse) ([]api.DownloadRange, error)) (*api.GetObjectResponse, []api.DownloadRange, error) {
 	// head object
 	hor, res, err := w.headObject(ctx, bucket, path, false, api.HeadObjectOptions{
 		IgnoreDelim: opts.IgnoreDelim,
 		Range:       opts.Range,
+		VersionID:   opts.VersionID,
+		CustomerKey: opts.CustomerKey,
+		Conditions:  opts.Conditions,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("couldn't fetch object: %w", err)
+		return nil, nil, fmt.Errorf("couldn't fetch object: %w", err)
 	}
 	obj := *res.Object.Object
 
-	// adjust range
-	if opts.Range == nil {
-		opts.Range = &api.DownloadRange{}
+	// objects that were uploaded with a customer key are decrypted with the
+	// key that's derived from the object's key and the customer key
+	if opts.CustomerKey != nil {
+		obj.Key = obj.Key.WithCustomerKey(*opts.CustomerKey)
+	}
+
+	// resolve the ranges
+	ranges := []api.DownloadRange{{Offset: hor.Range.Offset, Length: hor.Range.Length}}
+	if resolveRanges != nil {
+		resolved, err := resolveRanges(*hor)
+		if err != nil {
+			return nil, nil, err
+		} else if len(resolved) == 1 {
+			hor.Range = resolved[0].ContentRange(hor.Size)
+		}
+		if len(resolved) > 0 {
+			ranges = resolved
+		}
+	}
+
+	// verifying the checksum requires the full object to be downloaded
+	if opts.VerifyChecksum {
+		if hor.Checksum == "" {
+			return nil, nil, api.ErrChecksumNotAvailable
+		} else if len(ranges) > 1 || hor.Range.Offset != 0 || hor.Range.Length != hor.Size {
+			return nil, nil, fmt.Errorf("%w: can't verify the checksum of a partial download", http_range.ErrInvalid)
+		}
 	}
-	opts.Range.Offset = hor.Range.Offset
-	opts.Range.Length = hor.Range.Length
 
 	// fetch gouging params
 	gp, err := w.cache.GougingParams(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't fetch gouging parameters from bus: %w", err)
+		return nil, nil, fmt.Errorf("couldn't fetch gouging parameters from bus: %w", err)
 	}
 
 	// fetch all contracts
 	contracts, err := w.cache.DownloadContracts(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't fetch contracts from bus: %w", err)
+		return nil, nil, fmt.Errorf("couldn't fetch contracts from bus: %w", err)
+	}
+
+	// fetch bandwidth settings
+	bs, err := w.cache.BandwidthSettings(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't fetch bandwidth settings from bus: %w", err)
 	}
 
 	// prepare the content
+	var length int64
+	for _, r := range ranges {
+		length += r.Length
+	}
 	var content io.ReadCloser
-	if opts.Range.Length == 0 || obj.TotalSize() == 0 {
+	if length == 0 || obj.TotalSize() == 0 {
 		// if the object has no content or the requested range is 0, return an
 		// empty reader
 		content = io.NopCloser(bytes.NewReader(nil))
@@ -1251,7 +1619,7 @@ func (w *Worker) GetObject(ctx context.Context, bucket, path string, opts api.Do
 		// otherwise return a pipe reader
 		downloadFn := func(wr io.Writer, offset, length int64) error {
 			ctx = WithGougingChecker(ctx, w.bus, gp)
-			err = w.downloadManager.DownloadObject(ctx, wr, obj, uint64(offset), uint64(length), contracts)
+			err = w.downloadManager.DownloadObject(ctx, wr, obj, uint64(offset), uint64(length), contracts, slabCacheObjectID(bucket, path))
 			if err != nil {
 				w.logger.Error(err)
 				if !errors.Is(err, ErrShuttingDown) &&
@@ -1264,17 +1632,35 @@ func (w *Worker) GetObject(ctx context.Context, bucket, path string, opts api.Do
 			return nil
 		}
 		pr, pw := io.Pipe()
+
+		// all ranges of the request share the same bandwidth limiters
+		dw := w.bandwidth.DownloadWriter(ctx, pw, bucket, bs)
 		go func() {
-			err := downloadFn(pw, opts.Range.Offset, opts.Range.Length)
+			var err error
+			for _, r := range ranges {
+				if r.Length == 0 {
+					continue
+				} else if err = downloadFn(dw, r.Offset, r.Length); err != nil {
+					break
+				}
+			}
 			pw.CloseWithError(err)
 		}()
 		content = pr
 	}
 
+	// verify the checksum of the content
+	if opts.VerifyChecksum {
+		content, err = newChecksumVerifier(content, hor.ChecksumAlgorithm, hor.Checksum, hor.Size, w.logger.With("bucket", bucket, "path", path))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	return &api.GetObjectResponse{
 		Content:            content,
 		HeadObjectResponse: *hor,
-	}, nil
+	}, ranges, nil
 }
 
 func (w *Worker) HeadObject(ctx context.Context, bucket, path string, opts api.HeadObjectOptions) (*api.HeadObjectResponse, error) {
@@ -1302,8 +1688,38 @@ func (w *Worker) SyncAccount(ctx context.Context, fcid types.FileContractID, hk
 }
 
 func (w *Worker) UploadObject(ctx context.Context, r io.Reader, bucket, path string, opts api.UploadObjectOptions) (*api.UploadObjectResponse, error) {
+	// validate the checksum algorithm
+	if opts.ChecksumAlgorithm != "" {
+		if _, err := newChecksumHasher(opts.ChecksumAlgorithm); err != nil {
+			return nil, err
+		}
+	} else if opts.Checksum != "" {
+		return nil, fmt.Errorf("%w: checksum specified without an algorithm", api.ErrInvalidChecksumAlgorithm)
+	}
+
+	// validate the retention
+	if err := opts.Lock.Retention.Validate(); err != nil {
+		return nil, err
+	}
+
+	// evaluate the conditions before uploading, the bus evaluates them again
+	// when the object is stored but this avoids uploading data for nothing
+	if opts.Conditions.HasWriteConditions() {
+		var eTag string
+		res, err := w.bus.Object(ctx, bucket, path, api.GetObjectOptions{OnlyMetadata: true})
+		exists := err == nil && res.Object != nil
+		if err != nil && !utils.IsErr(err, api.ErrObjectNotFound) {
+			return nil, fmt.Errorf("couldn't fetch object: %w", err)
+		} else if exists {
+			eTag = res.Object.ETag
+		}
+		if err := opts.Conditions.CheckWrite(eTag, exists); err != nil {
+			return nil, err
+		}
+	}
+
 	// prepare upload params
-	up, err := w.prepareUploadParams(ctx, bucket, opts.ContractSet, opts.MinShards, opts.TotalShards)
+	up, quotaRemaining, err := w.prepareUploadParams(ctx, bucket, opts.ContractSet, opts.MinShards, opts.TotalShards, opts.ContentLength, false)
 	if err != nil {
 		return nil, err
 	}
@@ -1317,29 +1733,55 @@ func (w *Worker) UploadObject(ctx context.Context, r io.Reader, bucket, path str
 		return nil, fmt.Errorf("couldn't fetch contracts from bus: %w", err)
 	}
 
-	// upload
-	eTag, err := w.upload(ctx, bucket, path, up.RedundancySettings, r, contracts,
+	// prepare opts
+	uploadOpts := []UploadOption{
 		WithBlockHeight(up.CurrentHeight),
 		WithContractSet(up.ContractSet),
 		WithMimeType(opts.MimeType),
 		WithPacking(up.UploadPacking),
 		WithObjectUserMetadata(opts.Metadata),
-	)
+		WithChecksum(opts.ChecksumAlgorithm, opts.Checksum),
+		WithConditions(opts.Conditions),
+		WithObjectLock(opts.Lock),
+	}
+	if opts.CustomerKey != nil {
+		uploadOpts = append(uploadOpts, WithCustomerKey(*opts.CustomerKey))
+	}
+
+	// fetch bandwidth settings
+	bs, err := w.cache.BandwidthSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't fetch bandwidth settings from bus: %w", err)
+	}
+	r = w.bandwidth.UploadReader(ctx, r, bucket, bs)
+
+	// enforce the bucket's quota on the bytes that are actually uploaded
+	r, trackQuota := w.limitToBucketQuota(r, bucket, opts.ContentLength, quotaRemaining)
+
+	// upload
+	eTag, err := w.upload(ctx, bucket, path, up.RedundancySettings, r, contracts, uploadOpts...)
 	if err != nil {
 		w.logger.With(zap.Error(err)).With("path", path).With("bucket", bucket).Error("failed to upload object")
-		if !errors.Is(err, ErrShuttingDown) && !errors.Is(err, errUploadInterrupted) && !errors.Is(err, context.Canceled) {
+		if !errors.Is(err, ErrShuttingDown) && !errors.Is(err, errUploadInterrupted) && !errors.Is(err, context.Canceled) && !errors.Is(err, api.ErrChecksumMismatch) && !utils.IsErr(err, api.ErrPreconditionFailed) && !errors.Is(err, api.ErrBucketQuotaExceeded) {
 			w.registerAlert(newUploadFailedAlert(bucket, path, up.ContractSet, opts.MimeType, up.RedundancySettings.MinShards, up.RedundancySettings.TotalShards, len(contracts), up.UploadPacking, false, err))
 		}
 		return nil, fmt.Errorf("couldn't upload object: %w", err)
 	}
+	trackQuota()
 	return &api.UploadObjectResponse{
 		ETag: eTag,
 	}, nil
 }
 
 func (w *Worker) UploadMultipartUploadPart(ctx context.Context, r io.Reader, bucket, path, uploadID string, partNumber int, opts api.UploadMultipartUploadPartOptions) (*api.UploadMultipartUploadPartResponse, error) {
+	return w.uploadMultipartUploadPart(ctx, r, bucket, path, uploadID, partNumber, opts)
+}
+
+// uploadMultipartUploadPart uploads a part of a multipart upload, the given
+// upload options are applied on top of the ones derived from the upload.
+func (w *Worker) uploadMultipartUploadPart(ctx context.Context, r io.Reader, bucket, path, uploadID string, partNumber int, opts api.UploadMultipartUploadPartOptions, extraOpts ...UploadOption) (*api.UploadMultipartUploadPartResponse, error) {
 	// prepare upload params
-	up, err := w.prepareUploadParams(ctx, bucket, opts.ContractSet, opts.MinShards, opts.TotalShards)
+	up, quotaRemaining, err := w.prepareUploadParams(ctx, bucket, opts.ContractSet, opts.MinShards, opts.TotalShards, opts.ContentLength, true)
 	if err != nil {
 		return nil, err
 	}
@@ -1358,7 +1800,7 @@ func (w *Worker) UploadMultipartUploadPart(ctx context.Context, r io.Reader, buc
 		WithBlockHeight(up.CurrentHeight),
 		WithContractSet(up.ContractSet),
 		WithPacking(up.UploadPacking),
-		WithCustomKey(upload.Key),
+		WithCustomEncryptionKey(upload.Key),
 		WithPartNumber(partNumber),
 		WithUploadID(uploadID),
 	}
@@ -1371,6 +1813,7 @@ func (w *Worker) UploadMultipartUploadPart(ctx context.Context, r io.Reader, buc
 	} else if encryptionEnabled {
 		uploadOpts = append(uploadOpts, WithCustomEncryptionOffset(uint64(*opts.EncryptionOffset)))
 	}
+	uploadOpts = append(uploadOpts, extraOpts...)
 
 	// fetch contracts
 	contracts, err := w.bus.Contracts(ctx, api.ContractsOpts{ContractSet: up.ContractSet})
@@ -1378,15 +1821,26 @@ func (w *Worker) UploadMultipartUploadPart(ctx context.Context, r io.Reader, buc
 		return nil, fmt.Errorf("couldn't fetch contracts from bus: %w", err)
 	}
 
+	// fetch bandwidth settings
+	bs, err := w.cache.BandwidthSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't fetch bandwidth settings from bus: %w", err)
+	}
+	r = w.bandwidth.UploadReader(ctx, r, bucket, bs)
+
+	// enforce the bucket's quota on the bytes that are actually uploaded
+	r, trackQuota := w.limitToBucketQuota(r, bucket, opts.ContentLength, quotaRemaining)
+
 	// upload
 	eTag, err := w.upload(ctx, bucket, path, up.RedundancySettings, r, contracts, uploadOpts...)
 	if err != nil {
 		w.logger.With(zap.Error(err)).With("path", path).With("bucket", bucket).Error("failed to upload object")
-		if !errors.Is(err, ErrShuttingDown) && !errors.Is(err, errUploadInterrupted) && !errors.Is(err, context.Canceled) {
+		if !errors.Is(err, ErrShuttingDown) && !errors.Is(err, errUploadInterrupted) && !errors.Is(err, context.Canceled) && !utils.IsErr(err, api.ErrUploadOffsetMismatch) && !errors.Is(err, api.ErrBucketQuotaExceeded) {
 			w.registerAlert(newUploadFailedAlert(bucket, path, up.ContractSet, "", up.RedundancySettings.MinShards, up.RedundancySettings.TotalShards, len(contracts), up.UploadPacking, false, err))
 		}
 		return nil, fmt.Errorf("couldn't upload object: %w", err)
 	}
+	trackQuota()
 	return &api.UploadMultipartUploadPartResponse{
 		ETag: eTag,
 	}, nil
@@ -1400,26 +1854,33 @@ func (w *Worker) initAccounts(refillInterval time.Duration) (err error) {
 	return err
 }
 
-func (w *Worker) prepareUploadParams(ctx context.Context, bucket string, contractSet string, minShards, totalShards int) (api.UploadParams, error) {
+func (w *Worker) prepareUploadParams(ctx context.Context, bucket string, contractSet string, minShards, totalShards int, size int64, isPart bool) (_ api.UploadParams, quotaRemaining int64, _ error) {
 	// return early if the bucket does not exist
-	_, err := w.bus.Bucket(ctx, bucket)
+	b, err := w.bus.Bucket(ctx, bucket)
 	if err != nil {
-		return api.UploadParams{}, fmt.Errorf("bucket '%s' not found; %w", bucket, err)
+		return api.UploadParams{}, 0, fmt.Errorf("bucket '%s' not found; %w", bucket, err)
 	}
 
 	// fetch the upload parameters
 	up, err := w.bus.UploadParams(ctx)
 	if err != nil {
-		return api.UploadParams{}, fmt.Errorf("couldn't fetch upload parameters from bus: %w", err)
+		return api.UploadParams{}, 0, fmt.Errorf("couldn't fetch upload parameters from bus: %w", err)
 	} else if contractSet != "" {
 		up.ContractSet = contractSet
+	} else if b.Policy.ContractSet != "" {
+		up.ContractSet = b.Policy.ContractSet
 	} else if up.ContractSet == "" {
-		return api.UploadParams{}, api.ErrContractSetNotSpecified
+		return api.UploadParams{}, 0, api.ErrContractSetNotSpecified
 	}
 
 	// cancel the upload if consensus is not synced
 	if !up.ConsensusState.Synced {
-		return api.UploadParams{}, api.ErrConsensusNotSynced
+		return api.UploadParams{}, 0, api.ErrConsensusNotSynced
+	}
+
+	// apply the bucket's redundancy settings, if any
+	if b.Policy.Redundancy != nil {
+		up.RedundancySettings = *b.Policy.Redundancy
 	}
 
 	// allow overriding the redundancy settings
@@ -1431,9 +1892,15 @@ func (w *Worker) prepareUploadParams(ctx context.Context, bucket string, contrac
 	}
 	err = api.RedundancySettings{MinShards: up.RedundancySettings.MinShards, TotalShards: up.RedundancySettings.TotalShards}.Validate()
 	if err != nil {
-		return api.UploadParams{}, err
+		return api.UploadParams{}, 0, err
+	}
+
+	// reject the upload if it exceeds the bucket's quota
+	quotaRemaining, err = w.checkBucketQuota(ctx, b, size, isPart)
+	if err != nil {
+		return api.UploadParams{}, 0, err
 	}
-	return up, nil
+	return up, quotaRemaining, nil
 }
 
 // A HostErrorSet is a collection of errors from various hosts.